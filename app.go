@@ -2,18 +2,34 @@ package main
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
+	"sync"
 	"time"
 
 	"github.com/wailsapp/wails/v2/pkg/runtime"
 
+	"github.com/JB-SelfCompany/Tyr-Desktop/internal/bindings/applock"
+	"github.com/JB-SelfCompany/Tyr-Desktop/internal/bindings/archive"
+	"github.com/JB-SelfCompany/Tyr-Desktop/internal/bindings/audit"
+	"github.com/JB-SelfCompany/Tyr-Desktop/internal/bindings/autoreply"
 	"github.com/JB-SelfCompany/Tyr-Desktop/internal/bindings/config"
+	"github.com/JB-SelfCompany/Tyr-Desktop/internal/bindings/contacts"
 	"github.com/JB-SelfCompany/Tyr-Desktop/internal/bindings/events"
+	"github.com/JB-SelfCompany/Tyr-Desktop/internal/bindings/hooks"
+	"github.com/JB-SelfCompany/Tyr-Desktop/internal/bindings/identity"
+	"github.com/JB-SelfCompany/Tyr-Desktop/internal/bindings/lanaccess"
+	"github.com/JB-SelfCompany/Tyr-Desktop/internal/bindings/mail"
+	"github.com/JB-SelfCompany/Tyr-Desktop/internal/bindings/notifications"
 	"github.com/JB-SelfCompany/Tyr-Desktop/internal/bindings/peerdiscovery"
+	"github.com/JB-SelfCompany/Tyr-Desktop/internal/bindings/retention"
+	"github.com/JB-SelfCompany/Tyr-Desktop/internal/bindings/senderfilter"
 	"github.com/JB-SelfCompany/Tyr-Desktop/internal/bindings/service"
+	"github.com/JB-SelfCompany/Tyr-Desktop/internal/bindings/storage"
 	"github.com/JB-SelfCompany/Tyr-Desktop/internal/bindings/system"
 	"github.com/JB-SelfCompany/Tyr-Desktop/internal/core"
+	"github.com/JB-SelfCompany/Tyr-Desktop/internal/notify"
 	"github.com/JB-SelfCompany/Tyr-Desktop/internal/platform"
 	"github.com/JB-SelfCompany/Tyr-Desktop/internal/tray"
 	"github.com/JB-SelfCompany/Tyr-Desktop/internal/version"
@@ -30,9 +46,36 @@ type App struct {
 	// serviceManager manages the yggmail service lifecycle
 	serviceManager *core.ServiceManager
 
+	// contacts holds the address book
+	contacts *core.ContactStore
+
+	// outbox tracks the delivery state of outgoing messages
+	outbox *core.OutboxStore
+
+	// vault keeps the database and filestore encrypted at rest when enabled
+	vault *core.StorageVault
+
+	// quota checks storage usage against the configured budget
+	quota *core.QuotaMonitor
+
+	// autoResponder sends vacation replies to new mail
+	autoResponder *core.AutoResponder
+
+	// senderFilter files new mail by sender key before notifications and replies
+	senderFilter *core.SenderFilter
+
+	// hooks runs user-defined hooks on service, mail and connection events
+	hooks *core.HookRunner
+
+	// appLock hides the UI behind a passphrase while the service keeps running
+	appLock *core.AppLock
+
 	// trayManager manages the system tray
 	trayManager *tray.Manager
 
+	// notifier shows native desktop notifications (nil if unsupported)
+	notifier notify.Notifier
+
 	// eventMonitorShutdown signals the event monitoring goroutine to stop
 	eventMonitorShutdown chan struct{}
 
@@ -45,12 +88,47 @@ type App struct {
 	// statusMonitorRunning tracks if status monitoring is already running
 	statusMonitorRunning bool
 
+	// retentionShutdown signals the retention scheduler goroutine to stop
+	retentionShutdown chan struct{}
+
+	// retentionRunning tracks if the retention scheduler is already running
+	retentionRunning bool
+
+	// quotaShutdown signals the storage quota monitoring goroutine to stop
+	quotaShutdown chan struct{}
+
+	// quotaRunning tracks if storage quota monitoring is already running
+	quotaRunning bool
+
+	// appLockShutdown signals the app lock idle monitoring goroutine to stop
+	appLockShutdown chan struct{}
+
+	// appLockRunning tracks if app lock idle monitoring is already running
+	appLockRunning bool
+
+	// checkpointShutdown signals the encrypted storage checkpoint goroutine to stop
+	checkpointShutdown chan struct{}
+
+	// checkpointRunning tracks if encrypted storage checkpoints are already running
+	checkpointRunning bool
+
+	// backgroundTasks tracks the monitor and scheduler goroutines so they can be
+	// restarted when the config or service manager is replaced
+	backgroundTasks sync.WaitGroup
+
+	// teardownOnce makes sure shutdown work runs once when both the UI quit path
+	// and the Wails shutdown hook run
+	teardownOnce sync.Once
+
 	// peerDiscoveryCtx is the context for peer discovery operations
 	peerDiscoveryCtx context.Context
 
 	// peerDiscoveryCancelFunc cancels the peer discovery context
 	peerDiscoveryCancelFunc context.CancelFunc
 
+	// archiveCancelFunc cancels a running mailbox export (nil when idle)
+	archiveCancelFunc context.CancelFunc
+
 	// allowQuit controls whether the application can actually quit
 	allowQuit bool
 
@@ -65,6 +143,10 @@ func NewApp() *App {
 	return &App{
 		eventMonitorShutdown:    make(chan struct{}),
 		statusMonitorShutdown:   make(chan struct{}),
+		retentionShutdown:       make(chan struct{}),
+		quotaShutdown:           make(chan struct{}),
+		appLockShutdown:         make(chan struct{}),
+		checkpointShutdown:      make(chan struct{}),
 		peerDiscoveryCtx:        ctx,
 		peerDiscoveryCancelFunc: cancel,
 	}
@@ -89,6 +171,40 @@ func (a *App) startup(ctx context.Context) {
 		return
 	}
 	a.config = cfg
+	core.SetKDFParams(cfg.GetEncryptionSettings().KDFParams())
+	a.vault = core.NewStorageVault()
+	// Plaintext left by a crash must not stay around; the archive holds the last checkpoint
+	a.vault.RemoveStaleWorkDir()
+	a.quota = core.NewQuotaMonitor(cfg)
+	a.hooks = core.NewHookRunner(cfg)
+	a.appLock = core.NewAppLock(cfg)
+
+	// Load address book
+	contactStore, err := core.LoadContacts()
+	if err != nil {
+		// Start with an empty store so contacts can still be added or restored
+		log.Printf("Failed to load contacts: %v", err)
+		contactStore = core.NewContactStore()
+	}
+	a.contacts = contactStore
+
+	// Load outgoing message tracking
+	outbox, err := core.LoadOutbox()
+	if err != nil {
+		log.Printf("Failed to load outbox: %v", err)
+	} else {
+		a.outbox = outbox
+	}
+
+	// Load auto-responder reply history
+	responder, err := core.NewAutoResponder(cfg, a.contacts)
+	if err != nil {
+		log.Printf("Failed to load auto-reply history: %v", err)
+	} else {
+		a.autoResponder = responder
+	}
+
+	a.senderFilter = core.NewSenderFilter(cfg, a.contacts)
 
 	// Initialize global localizer with config language to ensure tray uses correct language
 	// This must be done before tray initialization in domReady
@@ -96,13 +212,29 @@ func (a *App) startup(ctx context.Context) {
 		log.Printf("Failed to set initial language: %v", err)
 	}
 
+	// Initialize native notifications, falling back to in-app notifications
+	notifier, err := notify.New("Tyr", a.showFromTray)
+	if err != nil {
+		log.Printf("Native notifications unavailable: %v", err)
+	} else {
+		a.notifier = notifier
+	}
+
+	if status := core.GetPasswordStoreStatus(); status.MasterPassphrase {
+		log.Println("Password store is protected by a master passphrase, waiting for unlock")
+	}
+
 	// Initialize service manager
-	if a.config.OnboardingComplete {
+	// Encrypted storage is created later by UnlockStorage, once the passphrase is entered
+	if a.config.OnboardingComplete && a.vault.IsEnabled() {
+		log.Println("Storage is encrypted, waiting for passphrase")
+	} else if a.config.OnboardingComplete {
 		sm, err := core.NewServiceManager(a.config)
 		if err != nil {
 			log.Printf("Failed to create service manager: %v", err)
 		} else {
 			a.serviceManager = sm
+			a.serviceManager.SetStorageVault(a.vault)
 
 			if err := a.serviceManager.Initialize(); err != nil {
 				log.Printf("Failed to initialize service: %v", err)
@@ -120,17 +252,8 @@ func (a *App) domReady(ctx context.Context) {
 	// Setup system tray
 	a.setupTray()
 
-	// Start event monitoring if service is available
-	if a.serviceManager != nil && !a.eventMonitorRunning {
-		a.eventMonitorRunning = true
-		go a.startEventMonitoring()
-	}
-
-	// Start status monitoring for system tray updates
-	if a.serviceManager != nil && !a.statusMonitorRunning {
-		a.statusMonitorRunning = true
-		go a.startStatusMonitoring()
-	}
+	// Start monitors and schedulers (the service ones only if the service is available)
+	a.startBackgroundTasks()
 }
 
 // beforeClose is called before the application window closes
@@ -146,23 +269,7 @@ func (a *App) beforeClose(ctx context.Context) (prevent bool) {
 
 // actualShutdown performs the actual shutdown operations
 func (a *App) actualShutdown() {
-	a.cancelPeerDiscoveryOperations()
-
-	if a.eventMonitorShutdown != nil {
-		select {
-		case <-a.eventMonitorShutdown:
-		default:
-			close(a.eventMonitorShutdown)
-		}
-	}
-
-	if a.statusMonitorShutdown != nil {
-		select {
-		case <-a.statusMonitorShutdown:
-		default:
-			close(a.statusMonitorShutdown)
-		}
-	}
+	a.teardown()
 
 	if a.serviceManager != nil && a.serviceManager.IsRunning() {
 		if err := a.serviceManager.SoftStop(); err != nil {
@@ -171,55 +278,58 @@ func (a *App) actualShutdown() {
 			}
 		}
 	}
-
-	// Cleanup system tray to prevent resource leaks
-	if a.trayManager != nil {
-		a.trayManager.Cleanup()
-	}
-
-	if a.config != nil {
-		if err := a.config.Save(); err != nil {
-			log.Printf("Failed to save config: %v", err)
-		}
-	}
 }
 
 // shutdown is called when the application is terminating
 func (a *App) shutdown(ctx context.Context) {
-	a.cancelPeerDiscoveryOperations()
+	a.teardown()
 
-	if a.eventMonitorShutdown != nil {
-		select {
-		case <-a.eventMonitorShutdown:
-		default:
-			close(a.eventMonitorShutdown)
+	if a.serviceManager != nil {
+		if err := a.serviceManager.Shutdown(); err != nil {
+			log.Printf("Failed to shutdown service manager: %v", err)
 		}
 	}
 
-	if a.statusMonitorShutdown != nil {
-		select {
-		case <-a.statusMonitorShutdown:
-		default:
-			close(a.statusMonitorShutdown)
+	// Encrypt the working copy and remove it now that the database is closed
+	if a.vault != nil {
+		if err := a.vault.Lock(); err != nil {
+			log.Printf("Failed to lock encrypted storage: %v", err)
 		}
 	}
 
-	// Cleanup system tray to prevent resource leaks
-	if a.trayManager != nil {
-		a.trayManager.Cleanup()
-	}
+	// Forget the master passphrase and password held for this session
+	core.LockPasswordStore()
+}
 
-	if a.config != nil {
-		if err := a.config.Save(); err != nil {
-			log.Printf("Failed to save config: %v", err)
+// teardown stops background work and releases UI resources
+// Shared by the quit path and the Wails shutdown hook; runs only once
+func (a *App) teardown() {
+	a.teardownOnce.Do(func() {
+		a.cancelPeerDiscoveryOperations()
+		a.cancelArchiveOperation()
+
+		// Don't leave copied secrets behind
+		if a.ctx != nil {
+			system.ClearSensitiveClipboard(a.ctx)
 		}
-	}
 
-	if a.serviceManager != nil {
-		if err := a.serviceManager.Shutdown(); err != nil {
-			log.Printf("Failed to shutdown service manager: %v", err)
+		a.stopBackgroundTasks()
+
+		// Cleanup system tray to prevent resource leaks
+		if a.trayManager != nil {
+			a.trayManager.Cleanup()
 		}
-	}
+
+		if a.notifier != nil {
+			a.notifier.Close()
+		}
+
+		if a.config != nil {
+			if err := a.config.Save(); err != nil {
+				log.Printf("Failed to save config: %v", err)
+			}
+		}
+	})
 }
 
 // OnStartupComplete is called after onboarding to initialize the service
@@ -234,6 +344,7 @@ func (a *App) OnStartupComplete() error {
 	}
 
 	a.serviceManager = sm
+	a.serviceManager.SetStorageVault(a.vault)
 
 	if err := a.serviceManager.Initialize(); err != nil {
 		return fmt.Errorf("failed to initialize service: %w", err)
@@ -243,6 +354,32 @@ func (a *App) OnStartupComplete() error {
 		return fmt.Errorf("failed to start service: %w", err)
 	}
 
+	a.startBackgroundTasks()
+	return nil
+}
+
+// backgroundStopTimeout is how long stopBackgroundTasks waits for the goroutines to exit
+const backgroundStopTimeout = 10 * time.Second
+
+// startBackgroundTasks starts the monitors and schedulers that aren't running yet
+// The service ones are only started once the service manager exists
+func (a *App) startBackgroundTasks() {
+	// Start app lock idle monitoring, independent of the service
+	if a.appLock != nil && !a.appLockRunning {
+		a.appLockRunning = true
+		a.goBackground(a.startAppLockMonitoring)
+	}
+
+	// Start encrypted storage checkpoints, independent of the service
+	if a.vault != nil && !a.checkpointRunning {
+		a.checkpointRunning = true
+		a.goBackground(a.startStorageCheckpoints)
+	}
+
+	if a.serviceManager == nil {
+		return
+	}
+
 	// Update tray manager with new service manager
 	if a.trayManager != nil {
 		a.trayManager.SetServiceManager(a.serviceManager)
@@ -251,16 +388,114 @@ func (a *App) OnStartupComplete() error {
 	// Start event monitoring if not already running
 	if !a.eventMonitorRunning {
 		a.eventMonitorRunning = true
-		go a.startEventMonitoring()
+		a.goBackground(a.startEventMonitoring)
 	}
 
 	// Start status monitoring for system tray updates
 	if !a.statusMonitorRunning {
 		a.statusMonitorRunning = true
-		go a.startStatusMonitoring()
+		a.goBackground(a.startStatusMonitoring)
 	}
 
-	return nil
+	// Start scheduled mailbox retention
+	if !a.retentionRunning {
+		a.retentionRunning = true
+		a.goBackground(a.startRetentionScheduler)
+	}
+
+	// Start storage quota monitoring
+	if !a.quotaRunning {
+		a.quotaRunning = true
+		a.goBackground(a.startQuotaMonitoring)
+	}
+}
+
+// goBackground runs a monitor or scheduler tracked by backgroundTasks
+func (a *App) goBackground(task func()) {
+	a.backgroundTasks.Add(1)
+	go func() {
+		defer a.backgroundTasks.Done()
+		task()
+	}()
+}
+
+// stopBackgroundTasks signals all monitors and schedulers to stop and waits for them
+func (a *App) stopBackgroundTasks() {
+	closeSignal(a.eventMonitorShutdown)
+	closeSignal(a.statusMonitorShutdown)
+	closeSignal(a.retentionShutdown)
+	closeSignal(a.quotaShutdown)
+	closeSignal(a.appLockShutdown)
+	closeSignal(a.checkpointShutdown)
+
+	done := make(chan struct{})
+	go func() {
+		a.backgroundTasks.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(backgroundStopTimeout):
+		log.Printf("Warning: background tasks did not stop within %s", backgroundStopTimeout)
+	}
+}
+
+// restartBackgroundTasks stops the monitors and schedulers and starts them again
+// Called after the config or service manager is replaced, since the running
+// goroutines keep the references they were started with
+func (a *App) restartBackgroundTasks() {
+	a.stopBackgroundTasks()
+
+	a.eventMonitorShutdown = make(chan struct{})
+	a.statusMonitorShutdown = make(chan struct{})
+	a.retentionShutdown = make(chan struct{})
+	a.quotaShutdown = make(chan struct{})
+	a.appLockShutdown = make(chan struct{})
+	a.checkpointShutdown = make(chan struct{})
+	a.eventMonitorRunning = false
+	a.statusMonitorRunning = false
+	a.retentionRunning = false
+	a.quotaRunning = false
+	a.appLockRunning = false
+	a.checkpointRunning = false
+
+	a.startBackgroundTasks()
+}
+
+// closeSignal closes a shutdown channel unless it is nil or already closed
+func closeSignal(ch chan struct{}) {
+	if ch == nil {
+		return
+	}
+	select {
+	case <-ch:
+	default:
+		close(ch)
+	}
+}
+
+// rebindConfig replaces the config and rebuilds every component holding it
+// The app lock starts unlocked, since the user is present; the restored passphrase applies from now on
+func (a *App) rebindConfig(cfg *core.Config) {
+	a.config = cfg
+	core.SetKDFParams(cfg.GetEncryptionSettings().KDFParams())
+
+	a.quota = core.NewQuotaMonitor(cfg)
+	a.hooks = core.NewHookRunner(cfg)
+	a.appLock = core.NewAppLock(cfg)
+	a.appLock.Reset()
+	a.senderFilter = core.NewSenderFilter(cfg, a.contacts)
+
+	responder, err := core.NewAutoResponder(cfg, a.contacts)
+	if err != nil {
+		log.Printf("Failed to load auto-reply history: %v", err)
+	} else {
+		a.autoResponder = responder
+	}
+
+	if a.trayManager != nil {
+		a.trayManager.SetConfig(cfg)
+	}
 }
 
 // IsOnboardingComplete returns whether the initial setup is complete
@@ -313,7 +548,7 @@ func (a *App) ToggleWindowVisibility() {
 	runtime.WindowSetAlwaysOnTop(a.ctx, true)
 	runtime.WindowSetAlwaysOnTop(a.ctx, false)
 	runtime.WindowCenter(a.ctx)
-	runtime.EventsEmit(a.ctx, "window:showing", nil)
+	a.emitEvent("window:showing", nil)
 
 	log.Println("ToggleWindowVisibility: window shown successfully")
 }
@@ -344,17 +579,19 @@ func (a *App) startStatusMonitoring() {
 		return
 	}
 
+	shutdownChan := a.statusMonitorShutdown
 	for {
 		select {
-		case <-a.statusMonitorShutdown:
+		case <-shutdownChan:
 			a.statusMonitorRunning = false
 			return
 
-		case _, ok := <-statusChan:
+		case status, ok := <-statusChan:
 			if !ok {
 				a.statusMonitorRunning = false
 				return
 			}
+			a.hooks.Dispatch(core.NewServiceStatusHookEvent(status))
 			a.UpdateSystemTrayStatus()
 		}
 	}
@@ -367,6 +604,13 @@ func (a *App) cancelPeerDiscoveryOperations() {
 	}
 }
 
+// cancelArchiveOperation stops a running mailbox export or import
+func (a *App) cancelArchiveOperation() {
+	if a.archiveCancelFunc != nil {
+		a.archiveCancelFunc()
+	}
+}
+
 // GetVersion returns the application version
 func (a *App) GetVersion() string {
 	return version.Version
@@ -383,6 +627,8 @@ func (a *App) setupTray() {
 		a.showFromTray,
 		a.showSettingsFromTray,
 		a.quitFromTray,
+		a.setDoNotDisturbFromTray,
+		a.lockFromTray,
 	)
 	a.trayManager.Setup()
 }
@@ -411,6 +657,23 @@ func (a *App) showSettingsFromTray() {
 	tray.ShowSettingsWindow(a.ctx)
 }
 
+// setDoNotDisturbFromTray toggles do not disturb from system tray
+func (a *App) setDoNotDisturbFromTray(enabled bool) {
+	if err := notifications.SetDoNotDisturb(a.config, enabled); err != nil {
+		log.Printf("Failed to set do not disturb: %v", err)
+		return
+	}
+	a.emitEvent("notifications:dnd", enabled)
+}
+
+// lockFromTray locks the app from system tray
+func (a *App) lockFromTray() {
+	if err := applock.LockApp(a.appLock, a.emitEvent); err != nil {
+		log.Printf("Failed to lock app: %v", err)
+		tray.ShowSettingsWindow(a.ctx)
+	}
+}
+
 // quitFromTray quits the application from system tray
 func (a *App) quitFromTray() {
 	a.allowQuit = true
@@ -423,17 +686,91 @@ func (a *App) quitFromTray() {
 func (a *App) startEventMonitoring() {
 	events.StartEventMonitoring(
 		a.serviceManager,
-		func(eventName string, data interface{}) {
-			if a.ctx != nil {
-				runtime.EventsEmit(a.ctx, eventName, data)
-			}
-		},
+		a.contacts,
+		a.outbox,
+		a.hooks,
+		a.emitEvent,
 		a.UpdateSystemTrayStatus,
+		a.handleNewMail,
 		a.eventMonitorShutdown,
 	)
 	a.eventMonitorRunning = false
 }
 
+// startRetentionScheduler runs mailbox retention policies when they are due
+func (a *App) startRetentionScheduler() {
+	retention.StartRetentionScheduler(
+		a.serviceManager,
+		a.config,
+		a.emitEvent,
+		a.retentionShutdown,
+	)
+	a.retentionRunning = false
+}
+
+// startQuotaMonitoring periodically checks storage usage against the budget
+func (a *App) startQuotaMonitoring() {
+	storage.StartQuotaMonitoring(
+		a.quota,
+		a.serviceManager,
+		a.emitEvent,
+		a.updateTrayStorage,
+		a.quotaShutdown,
+	)
+	a.quotaRunning = false
+}
+
+// startAppLockMonitoring locks the app after the configured idle period
+func (a *App) startAppLockMonitoring() {
+	applock.StartIdleMonitoring(a.appLock, a.emitEvent, a.appLockShutdown)
+	a.appLockRunning = false
+}
+
+// startStorageCheckpoints seals the unlocked working copy into encrypted storage
+// periodically and after mail changes
+func (a *App) startStorageCheckpoints() {
+	storage.StartCheckpointScheduler(a.vault, a.checkpointShutdown)
+	a.checkpointRunning = false
+}
+
+// emitEvent emits an event to the frontend if the runtime context is available
+func (a *App) emitEvent(eventName string, data interface{}) {
+	if a.ctx != nil {
+		runtime.EventsEmit(a.ctx, eventName, data)
+	}
+}
+
+// updateTrayStorage shows the storage quota state in the system tray
+func (a *App) updateTrayStorage(status core.QuotaStatus) {
+	if a.trayManager != nil {
+		a.trayManager.UpdateStorageStatus(status)
+	}
+}
+
+// handleNewMail applies the sender filter to a mail event, then shows a notification
+// and sends an automatic reply if enabled. Filed messages get neither
+func (a *App) handleNewMail(dto *MailEventDTO) {
+	// Mail changed, seal it into encrypted storage soon
+	if a.vault != nil {
+		a.vault.MarkChanged()
+	}
+
+	if senderfilter.HandleMailEvent(a.senderFilter, a.serviceManager, a.emitEvent, dto) {
+		return
+	}
+
+	notifications.DispatchMailNotification(
+		a.config,
+		a.contacts,
+		a.notifier,
+		a.emitEvent,
+		dto,
+	)
+
+	// Replies need IMAP and SMTP round trips, don't hold up the event loop
+	go autoreply.HandleMailEvent(a.autoResponder, a.serviceManager, a.outbox, a.emitEvent, *dto)
+}
+
 // ==================== Configuration Bindings ====================
 
 // GetConfig returns the current application configuration
@@ -482,21 +819,100 @@ func (a *App) DisablePeer(address string) error {
 	return a.config.Save()
 }
 
+// SetProxy sets the global SOCKS5 proxy used for peer connections
+func (a *App) SetProxy(enabled bool, address string) error {
+	if err := config.SetProxy(a.config, enabled, address); err != nil {
+		return err
+	}
+	return a.config.Save()
+}
+
+// SetPeerBypassProxy sets whether a peer connects directly instead of through the proxy
+func (a *App) SetPeerBypassProxy(address string, bypass bool) error {
+	if err := config.SetPeerBypassProxy(a.config, address, bypass); err != nil {
+		return err
+	}
+	return a.config.Save()
+}
+
+// TestProxy checks that a SOCKS5 proxy is reachable
+func (a *App) TestProxy(address string) ProxyTestResultDTO {
+	return config.TestProxy(address)
+}
+
 // SetPassword sets the yggmail password
-func (a *App) SetPassword(password string) error {
+func (a *App) SetPassword(password string) (err error) {
+	defer func() { core.RecordAudit(core.AuditActionSetPassword, err) }()
+	if err := applock.RequireUnlocked(a.appLock); err != nil {
+		return err
+	}
 	return config.SetPassword(a.config, a.serviceManager, password)
 }
 
 // ChangePassword changes the password after verifying the current password
-func (a *App) ChangePassword(currentPassword, newPassword string) error {
+func (a *App) ChangePassword(currentPassword, newPassword string) (err error) {
+	defer func() { core.RecordAudit(core.AuditActionChangePassword, err) }()
+	if err := applock.RequireUnlocked(a.appLock); err != nil {
+		return err
+	}
 	return config.ChangePassword(a.config, a.serviceManager, currentPassword, newPassword)
 }
 
 // RegenerateKeys regenerates Yggdrasil keys (WARNING: deletes all mail data)
-func (a *App) RegenerateKeys(password string) error {
+func (a *App) RegenerateKeys(password string) (err error) {
+	defer func() { core.RecordAudit(core.AuditActionRegenerateKeys, err) }()
+	if err := applock.RequireUnlocked(a.appLock); err != nil {
+		return err
+	}
 	return config.RegenerateKeys(a.config, a.serviceManager, password)
 }
 
+// GetPasswordStoreStatus returns how the fallback password file is protected
+func (a *App) GetPasswordStoreStatus() PasswordStoreStatusDTO {
+	return config.GetPasswordStoreStatus()
+}
+
+// UnlockPasswordStore unlocks the fallback password file with the master passphrase
+// Finishes service initialization if it was waiting for the password
+// Emits "passwordstore:unlocked" on success
+func (a *App) UnlockPasswordStore(passphrase string) error {
+	if err := config.UnlockPasswordStore(passphrase); err != nil {
+		return err
+	}
+
+	if a.serviceManager != nil && a.config != nil && !a.config.ServiceSettings.PasswordInitialized && !a.serviceManager.IsRunning() {
+		if err := a.serviceManager.Initialize(); err != nil {
+			log.Printf("Failed to initialize service: %v", err)
+		} else if a.config.UIPreferences.AutoStart {
+			if err := a.serviceManager.Start(); err != nil {
+				log.Printf("Failed to auto-start service: %v", err)
+			}
+		}
+	}
+
+	a.emitEvent("passwordstore:unlocked", nil)
+	return nil
+}
+
+// EnableMasterPassphrase encrypts the fallback password file with a master passphrase
+// The passphrase is asked once per session from then on
+func (a *App) EnableMasterPassphrase(passphrase string) error {
+	return config.EnableMasterPassphrase(passphrase)
+}
+
+// DisableMasterPassphrase returns the fallback password file to machine-ID encryption
+func (a *App) DisableMasterPassphrase(passphrase string) error {
+	if err := applock.RequireUnlocked(a.appLock); err != nil {
+		return err
+	}
+	return config.DisableMasterPassphrase(passphrase)
+}
+
+// ChangeMasterPassphrase replaces the master passphrase of the fallback password file
+func (a *App) ChangeMasterPassphrase(currentPassphrase, newPassphrase string) error {
+	return config.ChangeMasterPassphrase(currentPassphrase, newPassphrase)
+}
+
 // SetLanguage sets the UI language
 func (a *App) SetLanguage(language string) error {
 	if err := config.SetLanguage(a.config, language); err != nil {
@@ -515,7 +931,13 @@ func (a *App) SetTheme(theme string) error {
 }
 
 // SetAutoStart sets whether the service should start on system boot
-func (a *App) SetAutoStart(enabled bool) error {
+func (a *App) SetAutoStart(enabled bool) (err error) {
+	action := core.AuditActionDisableAutoStart
+	if enabled {
+		action = core.AuditActionEnableAutoStart
+	}
+	defer func() { core.RecordAudit(action, err) }()
+
 	if err := config.SetAutoStart(a.config, enabled); err != nil {
 		return err
 	}
@@ -527,6 +949,68 @@ func (a *App) GetDefaultPeers() []string {
 	return config.GetDefaultPeers()
 }
 
+// ==================== App Lock Bindings ====================
+
+// GetAppLockSettings returns the app lock settings and whether the app is locked
+func (a *App) GetAppLockSettings() AppLockSettingsDTO {
+	return applock.GetAppLockSettings(a.config, a.appLock)
+}
+
+// SaveAppLockSettings validates and saves the app lock settings
+func (a *App) SaveAppLockSettings(dto AppLockSettingsDTO) error {
+	return applock.SaveAppLockSettings(a.config, a.appLock, dto)
+}
+
+// SetAppLockPassphrase sets or changes the app lock passphrase
+func (a *App) SetAppLockPassphrase(currentPassphrase, newPassphrase string) error {
+	return applock.SetAppLockPassphrase(a.config, a.appLock, currentPassphrase, newPassphrase)
+}
+
+// LockApp hides the UI behind the passphrase prompt; the service keeps running
+// Emits "applock:locked" with true
+func (a *App) LockApp() error {
+	return applock.LockApp(a.appLock, a.emitEvent)
+}
+
+// UnlockApp unlocks the UI with the passphrase
+// Emits "applock:locked" with false
+func (a *App) UnlockApp(passphrase string) error {
+	return applock.UnlockApp(a.appLock, a.emitEvent, passphrase)
+}
+
+// IsAppLocked returns true while the UI is locked
+func (a *App) IsAppLocked() bool {
+	return a.appLock != nil && a.appLock.IsLocked()
+}
+
+// ReportActivity records user activity in the UI, postponing the idle lock
+func (a *App) ReportActivity() {
+	if a.appLock != nil {
+		a.appLock.Touch()
+	}
+}
+
+// ==================== Audit Bindings ====================
+
+// GetAuditLog returns security audit log entries, oldest first
+// A limit greater than 0 returns only the most recent entries
+func (a *App) GetAuditLog(limit int) ([]AuditEntryDTO, error) {
+	return audit.GetAuditLog(limit)
+}
+
+// VerifyAuditLog checks the audit log hash chain for tampering
+func (a *App) VerifyAuditLog() (AuditVerificationDTO, error) {
+	return audit.VerifyAuditLog()
+}
+
+// auditResult records the outcome of a binding that reports failures in a ResultDTO
+func auditResult(action string, result ResultDTO, err error) {
+	if err == nil && !result.Success {
+		err = errors.New(result.Message)
+	}
+	core.RecordAudit(action, err)
+}
+
 // ==================== Service Bindings ====================
 
 // InitializeService initializes the yggmail service
@@ -635,6 +1119,338 @@ func (a *App) CheckRecipientMessageSizeLimit(recipientEmail string, messageSizeB
 	}, nil
 }
 
+// ==================== LAN Access Bindings ====================
+
+// GetLANAccessSettings returns the TLS listener settings for serving other devices
+func (a *App) GetLANAccessSettings() LANAccessSettingsDTO {
+	return lanaccess.GetLANAccessSettings(a.config)
+}
+
+// SaveLANAccessSettings validates, saves and applies the LAN access settings
+func (a *App) SaveLANAccessSettings(dto LANAccessSettingsDTO) error {
+	if err := applock.RequireUnlocked(a.appLock); err != nil {
+		return err
+	}
+	return lanaccess.SaveLANAccessSettings(a.config, a.serviceManager, dto)
+}
+
+// GetLANAccessStatus returns the endpoints and certificate fingerprint mail clients should use
+func (a *App) GetLANAccessStatus() LANAccessStatusDTO {
+	return lanaccess.GetLANAccessStatus(a.config, a.serviceManager)
+}
+
+// ExportLocalCACertificate saves the local CA certificate for installing on client devices
+func (a *App) ExportLocalCACertificate() (ResultDTO, error) {
+	return lanaccess.ExportLocalCACertificate(a.ctx)
+}
+
+// ==================== Mail Bindings ====================
+
+// SendMail composes and sends a message through the local SMTP listener
+// The result's Data field contains the Message-ID of the queued message
+func (a *App) SendMail(message ComposeMailDTO) (ResultDTO, error) {
+	return mail.SendMail(a.ctx, a.serviceManager, a.outbox, message)
+}
+
+// ListOutgoingMessages returns recently sent messages with their delivery state
+// A limit of 0 returns the default number of messages
+func (a *App) ListOutgoingMessages(limit int) ([]OutgoingMessageDTO, error) {
+	return mail.ListOutgoingMessages(a.serviceManager, a.outbox, limit)
+}
+
+// ClearDeliveredMessages removes delivered messages from the outgoing message history
+func (a *App) ClearDeliveredMessages() error {
+	return mail.ClearDeliveredMessages(a.outbox)
+}
+
+// ListMailboxes returns all mailboxes with message and unread counts
+func (a *App) ListMailboxes() ([]MailboxDTO, error) {
+	return mail.ListMailboxes(a.serviceManager)
+}
+
+// ListMessages returns one page of message headers from a mailbox, newest first
+func (a *App) ListMessages(mailbox string, page int, pageSize int) (MessagePageDTO, error) {
+	return mail.ListMessages(a.serviceManager, mailbox, page, pageSize)
+}
+
+// GetMessage returns a message with its bodies and attachment list
+func (a *App) GetMessage(mailbox string, uid uint32) (MessageDTO, error) {
+	return mail.GetMessage(a.serviceManager, mailbox, uid)
+}
+
+// SaveAttachment downloads a message part and saves it to disk
+// Shows a save dialog when destPath is empty
+func (a *App) SaveAttachment(mailbox string, uid uint32, partID string, destPath string) (ResultDTO, error) {
+	return mail.SaveAttachment(a.ctx, a.serviceManager, mailbox, uid, partID, destPath)
+}
+
+// ==================== Contacts Bindings ====================
+
+// ListContacts returns all contacts sorted by display name
+func (a *App) ListContacts() []ContactDTO {
+	return contacts.ListContacts(a.contacts)
+}
+
+// AddContact adds a new contact
+func (a *App) AddContact(contact ContactDTO) error {
+	return contacts.AddContact(a.contacts, contact)
+}
+
+// UpdateContact updates the name, notes and verified flag of an existing contact
+func (a *App) UpdateContact(contact ContactDTO) error {
+	return contacts.UpdateContact(a.contacts, contact)
+}
+
+// RemoveContact deletes a contact
+func (a *App) RemoveContact(address string) error {
+	return contacts.RemoveContact(a.contacts, address)
+}
+
+// SetContactVerified marks a contact as verified or unverified
+func (a *App) SetContactVerified(address string, verified bool) error {
+	return contacts.SetContactVerified(a.contacts, address, verified)
+}
+
+// ImportContacts imports contacts from a vCard file
+// Shows an open file dialog if path is empty
+func (a *App) ImportContacts(path string) (ContactImportResultDTO, error) {
+	return contacts.ImportVCard(a.ctx, a.contacts, path)
+}
+
+// ExportContacts exports all contacts to a vCard 4.0 file
+// Shows a save file dialog if path is empty
+func (a *App) ExportContacts(path string) (ResultDTO, error) {
+	return contacts.ExportVCard(a.ctx, a.contacts, path)
+}
+
+// ==================== Notification Bindings ====================
+
+// GetNotificationSettings returns the new-mail notification settings
+func (a *App) GetNotificationSettings() NotificationSettingsDTO {
+	return notifications.GetNotificationSettings(a.config)
+}
+
+// SaveNotificationSettings validates and saves the new-mail notification settings
+func (a *App) SaveNotificationSettings(dto NotificationSettingsDTO) error {
+	if err := notifications.SaveNotificationSettings(a.config, dto); err != nil {
+		return err
+	}
+	a.UpdateSystemTrayMenu()
+	return nil
+}
+
+// SetDoNotDisturb enables or disables do not disturb
+func (a *App) SetDoNotDisturb(enabled bool) error {
+	if err := notifications.SetDoNotDisturb(a.config, enabled); err != nil {
+		return err
+	}
+	a.UpdateSystemTrayMenu()
+	return nil
+}
+
+// ==================== Retention Bindings ====================
+
+// GetRetentionSettings returns the mailbox retention policies and schedule
+func (a *App) GetRetentionSettings() RetentionSettingsDTO {
+	return retention.GetRetentionSettings(a.config)
+}
+
+// SaveRetentionSettings validates and saves the mailbox retention policies and schedule
+func (a *App) SaveRetentionSettings(dto RetentionSettingsDTO) error {
+	return retention.SaveRetentionSettings(a.config, dto)
+}
+
+// PreviewRetention lists what the given policies would remove without changing anything
+func (a *App) PreviewRetention(dto RetentionSettingsDTO) (RetentionReportDTO, error) {
+	return retention.PreviewRetention(a.serviceManager, dto)
+}
+
+// RunRetention applies the saved retention policies now and logs the result
+func (a *App) RunRetention() (RetentionReportDTO, error) {
+	return retention.RunRetentionNow(a.serviceManager, a.config)
+}
+
+// GetRetentionLog returns the most recent retention runs, newest first
+func (a *App) GetRetentionLog(limit int) ([]RetentionReportDTO, error) {
+	return retention.GetRetentionLog(limit)
+}
+
+// ==================== Auto-Reply Bindings ====================
+
+// GetAutoReplySettings returns the vacation auto-responder settings
+func (a *App) GetAutoReplySettings() AutoReplySettingsDTO {
+	return autoreply.GetAutoReplySettings(a.config)
+}
+
+// SaveAutoReplySettings validates and saves the vacation auto-responder settings
+func (a *App) SaveAutoReplySettings(dto AutoReplySettingsDTO) error {
+	return autoreply.SaveAutoReplySettings(a.config, dto)
+}
+
+// ResetAutoReplyHistory forgets previous replies so every sender gets a new one
+func (a *App) ResetAutoReplyHistory() error {
+	return autoreply.ResetAutoReplyHistory(a.autoResponder)
+}
+
+// ==================== Hook Bindings ====================
+
+// GetHookSettings returns the user-defined hooks
+func (a *App) GetHookSettings() HookSettingsDTO {
+	return hooks.GetHookSettings(a.config)
+}
+
+// SaveHookSettings validates and saves the user-defined hooks
+func (a *App) SaveHookSettings(dto HookSettingsDTO) error {
+	if err := applock.RequireUnlocked(a.appLock); err != nil {
+		return err
+	}
+	return hooks.SaveHookSettings(a.config, dto)
+}
+
+// TestHook runs a saved hook once with a test event and returns the result
+func (a *App) TestHook(name string) (HookRunResultDTO, error) {
+	return hooks.TestHook(a.hooks, name)
+}
+
+// GetHookLog returns the last lines of a hook's log, oldest first
+func (a *App) GetHookLog(name string, limit int) ([]string, error) {
+	return hooks.GetHookLog(name, limit)
+}
+
+// ==================== Sender Filter Bindings ====================
+
+// GetSenderFilterSettings returns the sender block and allow lists and rate limits
+func (a *App) GetSenderFilterSettings() SenderFilterSettingsDTO {
+	return senderfilter.GetSenderFilterSettings(a.config)
+}
+
+// SaveSenderFilterSettings validates and saves the sender filter settings
+func (a *App) SaveSenderFilterSettings(dto SenderFilterSettingsDTO) error {
+	return senderfilter.SaveSenderFilterSettings(a.config, dto)
+}
+
+// BlockSender adds a sender to the blocklist
+// If a message is given, it is moved to junk or deleted right away
+func (a *App) BlockSender(dto SenderActionDTO) error {
+	return senderfilter.BlockSender(a.config, a.senderFilter, a.serviceManager, dto)
+}
+
+// AllowSender adds a sender to the allowlist
+// If a message in junk or quarantine is given, it is moved back to the inbox
+func (a *App) AllowSender(dto SenderActionDTO) error {
+	return senderfilter.AllowSender(a.config, a.senderFilter, a.serviceManager, dto)
+}
+
+// UnlistSender removes a sender from the blocklist and allowlist
+func (a *App) UnlistSender(address string) error {
+	return senderfilter.UnlistSender(a.config, address)
+}
+
+// GetSenderFilterLog returns logged filtering decisions, oldest first
+func (a *App) GetSenderFilterLog(limit int) ([]SenderFilterLogEntryDTO, error) {
+	return senderfilter.GetSenderFilterLog(limit)
+}
+
+// ==================== Archive Bindings ====================
+
+// ExportMailboxes exports mailboxes to mbox, Maildir or .eml files
+// Emits "export:progress" events; an interrupted export is resumed when started again
+func (a *App) ExportMailboxes(dto ExportOptionsDTO) (ExportResultDTO, error) {
+	if err := applock.RequireUnlocked(a.appLock); err != nil {
+		return ExportResultDTO{}, err
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	a.archiveCancelFunc = cancel
+	defer cancel()
+
+	return archive.ExportMailboxes(ctx, a.serviceManager, dto, a.emitEvent)
+}
+
+// CancelExport stops a running export; it can be resumed later
+func (a *App) CancelExport() {
+	a.cancelArchiveOperation()
+}
+
+// ImportMessages imports mbox, Maildir or .eml archives into a mailbox
+// Emits "import:progress" events and returns a per-file report
+func (a *App) ImportMessages(dto ImportOptionsDTO) (ImportResultDTO, error) {
+	ctx, cancel := context.WithCancel(context.Background())
+	a.archiveCancelFunc = cancel
+	defer cancel()
+
+	return archive.ImportMessages(ctx, a.serviceManager, dto, a.emitEvent)
+}
+
+// CancelImport stops a running import after the current message
+func (a *App) CancelImport() {
+	a.cancelArchiveOperation()
+}
+
+// GetInterruptedExport returns the options of an unfinished export in the destination, or nil
+func (a *App) GetInterruptedExport(destination string) (*ExportOptionsDTO, error) {
+	return archive.GetInterruptedExport(destination)
+}
+
+// ==================== Identity Bindings ====================
+
+// ExportIdentityFile exports the identity key to a passphrase-encrypted file
+func (a *App) ExportIdentityFile(dto ExportIdentityDTO) (ResultDTO, error) {
+	if err := applock.RequireUnlocked(a.appLock); err != nil {
+		return ResultDTO{Success: false, Message: err.Error()}, nil
+	}
+	return identity.ExportIdentityFile(a.ctx, a.config, a.vault, dto)
+}
+
+// GetIdentityMnemonic returns the identity key as a 24-word recovery phrase for paper backup
+func (a *App) GetIdentityMnemonic(password string) (IdentityMnemonicDTO, error) {
+	if err := applock.RequireUnlocked(a.appLock); err != nil {
+		return IdentityMnemonicDTO{}, err
+	}
+	return identity.GetIdentityMnemonic(a.config, a.vault, password)
+}
+
+// ImportIdentity installs an identity key from a file or recovery phrase, keeping existing mail
+// Emits "identity:imported" with the new address on success
+func (a *App) ImportIdentity(dto ImportIdentityDTO) (ResultDTO, error) {
+	if err := applock.RequireUnlocked(a.appLock); err != nil {
+		return ResultDTO{Success: false, Message: err.Error()}, nil
+	}
+
+	result, err := identity.ImportIdentity(a.config, a.serviceManager, a.vault, dto)
+	if err != nil || !result.Success {
+		return result, err
+	}
+
+	// Without a service manager nothing sealed the imported key yet
+	if a.serviceManager == nil && a.vault != nil && a.vault.IsUnlocked() {
+		if err := a.vault.Seal(); err != nil {
+			log.Printf("Warning: failed to seal encrypted storage after identity import: %v", err)
+		}
+	}
+
+	a.UpdateSystemTrayStatus()
+	a.emitEvent("identity:imported", result.Data)
+	return result, nil
+}
+
+// RotateIdentity replaces the identity with a new key while keeping all mail,
+// optionally sending a signed moved notice to contacts from the old address
+// Emits "identity:rotated" with the result on success
+func (a *App) RotateIdentity(dto RotateIdentityDTO) (RotateIdentityResultDTO, error) {
+	if err := applock.RequireUnlocked(a.appLock); err != nil {
+		return RotateIdentityResultDTO{}, err
+	}
+
+	result, err := identity.RotateIdentity(a.config, a.serviceManager, a.vault, a.contacts, a.outbox, dto)
+	if err != nil {
+		return result, err
+	}
+
+	a.UpdateSystemTrayStatus()
+	a.emitEvent("identity:rotated", result)
+	return result, nil
+}
+
 // ==================== Storage Bindings ====================
 
 // GetStorageStats returns storage usage statistics
@@ -653,9 +1469,108 @@ func (a *App) GetStorageStats() (StorageStatsDTO, error) {
 		FilesSizeMB:      stats.FilesSizeMB,
 		TotalSizeMB:      stats.TotalSizeMB,
 		MaxMessageSizeMB: a.config.GetMaxMessageSizeMB(),
+		Encrypted:        stats.Encrypted,
+		EncryptedSizeMB:  stats.EncryptedSizeMB,
 	}, nil
 }
 
+// GetStorageEncryptionStatus returns whether storage is encrypted at rest and unlocked
+func (a *App) GetStorageEncryptionStatus() StorageEncryptionStatusDTO {
+	return storage.GetStorageEncryptionStatus(a.vault, a.config)
+}
+
+// SetStorageWorkDirOnDiskAllowed accepts or refuses a decrypted working copy on disk
+// Encrypted storage can only be enabled or unlocked on Windows and macOS once allowed
+func (a *App) SetStorageWorkDirOnDiskAllowed(allowed bool) error {
+	return storage.SetStorageWorkDirOnDiskAllowed(a.config, allowed)
+}
+
+// UnlockStorage decrypts the database and filestore with the passphrase and starts the service
+// Emits "storage:unlocked" on success
+func (a *App) UnlockStorage(passphrase string) error {
+	if err := storage.UnlockStorage(a.vault, a.config, passphrase); err != nil {
+		return err
+	}
+
+	if a.serviceManager == nil && a.config.OnboardingComplete {
+		sm, err := core.NewServiceManager(a.config)
+		if err != nil {
+			return fmt.Errorf("failed to create service manager: %w", err)
+		}
+		a.serviceManager = sm
+		a.serviceManager.SetStorageVault(a.vault)
+
+		if err := a.serviceManager.Initialize(); err != nil {
+			return fmt.Errorf("failed to initialize service: %w", err)
+		}
+		if a.config.UIPreferences.AutoStart {
+			if err := a.serviceManager.Start(); err != nil {
+				log.Printf("Failed to auto-start service: %v", err)
+			}
+		}
+		a.startBackgroundTasks()
+	}
+
+	a.emitEvent("storage:unlocked", nil)
+	return nil
+}
+
+// EnableStorageEncryption encrypts the database and filestore with a passphrase
+// The service restarts while existing data is migrated
+func (a *App) EnableStorageEncryption(passphrase string) error {
+	return storage.EnableStorageEncryption(a.vault, a.config, a.serviceManager, passphrase)
+}
+
+// DisableStorageEncryption decrypts the database and filestore back into the data directory
+// The service restarts while the data is migrated
+func (a *App) DisableStorageEncryption(passphrase string) error {
+	if err := applock.RequireUnlocked(a.appLock); err != nil {
+		return err
+	}
+	return storage.DisableStorageEncryption(a.vault, a.config, a.serviceManager, passphrase)
+}
+
+// ChangeStoragePassphrase replaces the passphrase protecting encrypted storage
+func (a *App) ChangeStoragePassphrase(currentPassphrase, newPassphrase string) error {
+	return storage.ChangeStoragePassphrase(a.vault, currentPassphrase, newPassphrase)
+}
+
+// GetEncryptionSettings returns the key derivation cost for newly encrypted data
+func (a *App) GetEncryptionSettings() EncryptionSettingsDTO {
+	return storage.GetEncryptionSettings(a.config)
+}
+
+// SaveEncryptionSettings validates and saves the key derivation cost
+// Applies to backups, the fallback password file and encrypted storage
+func (a *App) SaveEncryptionSettings(dto EncryptionSettingsDTO) error {
+	return storage.SaveEncryptionSettings(a.config, dto)
+}
+
+// GetQuotaSettings returns the storage quota settings
+func (a *App) GetQuotaSettings() QuotaSettingsDTO {
+	return storage.GetQuotaSettings(a.config)
+}
+
+// SaveQuotaSettings validates and saves the storage quota settings and re-checks usage
+func (a *App) SaveQuotaSettings(dto QuotaSettingsDTO) error {
+	if err := storage.SaveQuotaSettings(a.config, dto); err != nil {
+		return err
+	}
+
+	storage.CheckQuota(a.quota, a.serviceManager, a.emitEvent, a.updateTrayStorage)
+	return nil
+}
+
+// GetQuotaStatus measures storage usage now and returns it against the budget
+func (a *App) GetQuotaStatus() (QuotaStatusDTO, error) {
+	return storage.GetQuotaStatus(a.quota, a.serviceManager)
+}
+
+// GetStorageUsage breaks storage usage down by mailbox and lists the largest messages
+func (a *App) GetStorageUsage(largest int) (StorageUsageDTO, error) {
+	return storage.GetStorageUsage(a.serviceManager, largest)
+}
+
 // SetMaxMessageSizeMB sets the maximum message size and applies it to running service without restart
 func (a *App) SetMaxMessageSizeMB(sizeMB int64) error {
 	if a.config == nil {
@@ -673,6 +1588,10 @@ func (a *App) SetMaxMessageSizeMB(sizeMB int64) error {
 	}
 
 	// Apply to running service without restart (hot reload)
+	// While over the storage budget the lowered limit stays until space is freed
+	if a.quota != nil && a.quota.IsRestricted() {
+		return nil
+	}
 	if a.serviceManager != nil && a.serviceManager.IsRunning() {
 		if err := a.serviceManager.HotReloadMaxMessageSize(sizeMB); err != nil {
 			return fmt.Errorf("failed to apply new message size limit: %w", err)
@@ -719,13 +1638,54 @@ func (a *App) CopyToClipboard(text string) error {
 	return system.CopyToClipboard(a.ctx, text)
 }
 
+// CopySensitiveToClipboard copies a secret to the clipboard and clears it after
+// the configured timeout if it is still there
+// Emits "clipboard:countdown" every second and "clipboard:cleared" at the end
+func (a *App) CopySensitiveToClipboard(text string) error {
+	seconds := core.DefaultClipboardClearSeconds
+	if a.config != nil {
+		seconds = a.config.GetClipboardClearSeconds()
+	}
+	return system.CopySensitiveToClipboard(a.ctx, text, seconds)
+}
+
+// GetClipboardClearSeconds returns how long copied secrets stay in the clipboard
+func (a *App) GetClipboardClearSeconds() int {
+	if a.config == nil {
+		return core.DefaultClipboardClearSeconds
+	}
+	return a.config.GetClipboardClearSeconds()
+}
+
+// SetClipboardClearSeconds sets how long copied secrets stay in the clipboard (5-600 seconds)
+func (a *App) SetClipboardClearSeconds(seconds int) error {
+	if a.config == nil {
+		return fmt.Errorf("config not initialized")
+	}
+	if err := a.config.SetClipboardClearSeconds(seconds); err != nil {
+		return fmt.Errorf("Invalid clipboard timeout: %v", err)
+	}
+	return a.config.Save()
+}
+
 // OpenURL opens a URL in the default browser
 func (a *App) OpenURL(url string) error {
 	return system.OpenURL(a.ctx, url)
 }
 
 // CreateBackup creates an encrypted backup of the configuration and optionally database
-func (a *App) CreateBackup(options BackupOptionsDTO) (ResultDTO, error) {
+func (a *App) CreateBackup(options BackupOptionsDTO) (result ResultDTO, err error) {
+	defer func() { auditResult(core.AuditActionCreateBackup, result, err) }()
+
+	if err := applock.RequireUnlocked(a.appLock); err != nil {
+		return ResultDTO{Success: false, Message: err.Error()}, nil
+	}
+
+	// The database only exists while encrypted storage is unlocked
+	if options.IncludeDatabase && a.vault != nil && a.vault.IsEnabled() && !a.vault.IsUnlocked() {
+		return ResultDTO{Success: false, Message: "Storage is locked. Please unlock it before backing up the database."}, nil
+	}
+
 	// CRITICAL: If including database, must close service to release database file
 	// SQLite allows only one writer at a time, and reading while service has it open may fail
 	wasRunning := false
@@ -764,7 +1724,7 @@ func (a *App) CreateBackup(options BackupOptionsDTO) (ResultDTO, error) {
 	}
 
 	// Create backup
-	result, err := system.CreateBackup(a.ctx, a.config, options)
+	result, err = system.CreateBackup(a.ctx, a.config, options)
 
 	// Restart service if it was running
 	if wasRunning && result.Success && a.serviceManager != nil {
@@ -789,14 +1749,21 @@ func (a *App) CreateBackup(options BackupOptionsDTO) (ResultDTO, error) {
 }
 
 // RestoreBackup restores configuration and optionally database from an encrypted backup
-func (a *App) RestoreBackup(options RestoreOptionsDTO) (ResultDTO, error) {
+func (a *App) RestoreBackup(options RestoreOptionsDTO) (result ResultDTO, err error) {
+	defer func() { auditResult(core.AuditActionRestoreBackup, result, err) }()
+
+	// A restored database must go into the working copy, never next to the encrypted archive
+	if a.vault != nil && a.vault.IsEnabled() && !a.vault.IsUnlocked() {
+		return ResultDTO{Success: false, Message: "Storage is locked. Please unlock it before restoring a backup."}, nil
+	}
+
 	// CRITICAL: Stop and close service before restoring to prevent database conflicts
 	// The native yggmail library keeps database file open until Close() is called
 	// Restoring database while it's open will cause corruption or service will read old keys
 	wasRunning := false
 	if a.serviceManager != nil && a.serviceManager.IsRunning() {
 		log.Println("Service is running, stopping before restore...")
-		runtime.EventsEmit(a.ctx, "restore:progress", map[string]interface{}{"progress": 5, "message": "Stopping service..."})
+		a.emitEvent("restore:progress", map[string]interface{}{"progress": 5, "message": "Stopping service..."})
 		wasRunning = true
 
 		// Cancel any peer discovery operations
@@ -822,14 +1789,20 @@ func (a *App) RestoreBackup(options RestoreOptionsDTO) (ResultDTO, error) {
 		// CRITICAL: Close service to release database file
 		// Without this, the database file remains open and restoring will write to a locked file
 		log.Println("Closing service to release database file...")
-		runtime.EventsEmit(a.ctx, "restore:progress", map[string]interface{}{"progress": 8, "message": "Releasing database..."})
+		a.emitEvent("restore:progress", map[string]interface{}{"progress": 8, "message": "Releasing database..."})
 		if err := a.serviceManager.CloseService(); err != nil {
 			log.Printf("Warning: failed to close service: %v", err)
 		}
 
 		// Additional delay to ensure database is fully released
 		time.Sleep(500 * time.Millisecond)
+	} else if a.serviceManager != nil {
+		// A stopped service still keeps the database open once initialized
+		if err := a.serviceManager.CloseService(); err != nil {
+			log.Printf("Warning: failed to close service: %v", err)
+		}
 	}
+	hadService := a.serviceManager != nil
 
 	// Restore backup (config and database)
 	restoredConfig, result, err := system.RestoreBackup(a.ctx, options)
@@ -842,9 +1815,24 @@ func (a *App) RestoreBackup(options RestoreOptionsDTO) (ResultDTO, error) {
 		return result, nil
 	}
 
+	// Save the restored database to encrypted storage right away
+	if a.vault != nil && a.vault.IsUnlocked() {
+		if err := a.vault.Seal(); err != nil {
+			log.Printf("Warning: failed to seal encrypted storage after restore: %v", err)
+		}
+	}
+
+	// Reload contacts restored from the backup
+	if a.contacts != nil {
+		if err := a.contacts.Reload(); err != nil {
+			log.Printf("Warning: failed to reload contacts after restore: %v", err)
+		}
+	}
+
 	// Update app's config reference if restore was successful
+	// Everything built from the old config is rebuilt, otherwise it keeps enforcing old settings
 	if restoredConfig != nil {
-		a.config = restoredConfig
+		a.rebindConfig(restoredConfig)
 
 		// CRITICAL: Reset PasswordInitialized flag to ensure password is set in restored database
 		// The restored database needs the password to be set, even if it was previously initialized
@@ -854,10 +1842,13 @@ func (a *App) RestoreBackup(options RestoreOptionsDTO) (ResultDTO, error) {
 		}
 	}
 
-	// If restore was successful and service was running, reinitialize and restart
-	if result.Success && wasRunning && a.serviceManager != nil {
+	// The monitors and schedulers hold the old config and service manager, restart them on the new ones
+	defer a.restartBackgroundTasks()
+
+	// If restore was successful and a service existed, reinitialize it and restart it if it was running
+	if result.Success && hadService {
 		log.Println("Reinitializing service after restore...")
-		runtime.EventsEmit(a.ctx, "restore:progress", map[string]interface{}{"progress": 92, "message": "Reinitializing service..."})
+		a.emitEvent("restore:progress", map[string]interface{}{"progress": 92, "message": "Reinitializing service..."})
 
 		// CRITICAL: Create NEW ServiceManager with restored config
 		// Old ServiceManager has reference to OLD config with wrong database path!
@@ -868,6 +1859,7 @@ func (a *App) RestoreBackup(options RestoreOptionsDTO) (ResultDTO, error) {
 			return result, nil
 		}
 		a.serviceManager = newServiceManager
+		a.serviceManager.SetStorageVault(a.vault)
 
 		// Initialize service to load restored keys from database
 		// This creates a NEW yggmail.Service instance that opens the restored database
@@ -876,9 +1868,13 @@ func (a *App) RestoreBackup(options RestoreOptionsDTO) (ResultDTO, error) {
 			return result, nil
 		}
 
+		if !wasRunning {
+			return result, nil
+		}
+
 		// Restart service
 		log.Println("Restarting service after restore...")
-		runtime.EventsEmit(a.ctx, "restore:progress", map[string]interface{}{"progress": 95, "message": "Restarting service..."})
+		a.emitEvent("restore:progress", map[string]interface{}{"progress": 95, "message": "Restarting service..."})
 
 		if err := a.serviceManager.Start(); err != nil {
 			log.Printf("Warning: Failed to start service after restore: %v", err)
@@ -946,10 +1942,69 @@ func (a *App) GetSystemLanguage() string {
 }
 
 // OpenDeltaChat opens DeltaChat with auto-configured account
-func (a *App) OpenDeltaChat() error {
+func (a *App) OpenDeltaChat() (err error) {
+	defer func() { core.RecordAudit(core.AuditActionOpenDeltaChat, err) }()
+	if err := applock.RequireUnlocked(a.appLock); err != nil {
+		return err
+	}
 	return system.OpenDeltaChat(a.ctx, a.config, a.serviceManager)
 }
 
+// GetMailAddressQRCode renders the mail address as a QR code ("png" or "svg")
+func (a *App) GetMailAddressQRCode(format string, size int) (QRCodeDTO, error) {
+	return system.GetMailAddressQRCode(a.serviceManager, format, size)
+}
+
+// GetDeltaChatQRCode renders the DeltaChat setup link as a QR code ("png" or "svg")
+// The link contains the password; the user must confirm in a native dialog first
+func (a *App) GetDeltaChatQRCode(format string, size int) (QRCodeDTO, error) {
+	if err := applock.RequireUnlocked(a.appLock); err != nil {
+		return QRCodeDTO{}, err
+	}
+	return system.GetDeltaChatQRCode(a.ctx, a.config, a.serviceManager, format, size)
+}
+
+// SetAutoconfigServeQR enables or disables the mail address QR code on the autoconfig page
+func (a *App) SetAutoconfigServeQR(enabled bool) error {
+	if a.config == nil {
+		return fmt.Errorf("config not initialized")
+	}
+
+	if a.serviceManager != nil {
+		a.serviceManager.SetAutoconfigServeQR(enabled)
+	} else {
+		a.config.SetAutoconfigServeQR(enabled)
+	}
+
+	return a.config.Save()
+}
+
+// SetAutoconfigRandomPort runs the autoconfig server on a random loopback port
+// instead of 127.0.0.1:8080. Use GetAutoconfigURL to get the current address
+func (a *App) SetAutoconfigRandomPort(enabled bool) error {
+	if a.config == nil {
+		return fmt.Errorf("config not initialized")
+	}
+
+	if a.serviceManager != nil {
+		if err := a.serviceManager.SetAutoconfigRandomPort(enabled); err != nil {
+			return fmt.Errorf("Failed to restart autoconfig server. Error: %v", err)
+		}
+	} else {
+		a.config.SetAutoconfigRandomPort(enabled)
+	}
+
+	return a.config.Save()
+}
+
+// GetAutoconfigURL returns the URL of the autoconfig server, or empty if it isn't running
+func (a *App) GetAutoconfigURL() string {
+	if a.serviceManager == nil {
+		return ""
+	}
+	return a.serviceManager.GetAutoconfigURL()
+}
+
 // ==================== Peer Discovery Bindings ====================
 
 // FindAvailablePeers discovers available Yggdrasil peers
@@ -4,7 +4,7 @@ import './style.css';
 
 // Import Layout
 import { Layout } from './components/layout';
-import { LoadingSpinner, ToastProvider, ErrorBoundary } from './components';
+import { LoadingSpinner, ToastProvider, ErrorBoundary, LockScreen, ClipboardCountdown } from './components';
 
 // Lazy load screens for better performance
 const Dashboard = lazy(() => import('./screens/Dashboard'));
@@ -18,6 +18,7 @@ import { useEventStream } from './hooks/useEventStream';
 import { useUIStore } from './store/uiStore';
 import { useThemeManager } from './hooks/useThemeManager';
 import { useI18n } from './hooks/useI18n';
+import { useAppLock } from './hooks/useAppLock';
 
 // Import Wails bindings and runtime
 import { IsOnboardingComplete } from '../wailsjs/go/main/App';
@@ -33,6 +34,8 @@ import { LogPrint, EventsOn, EventsOff } from './wailsjs/runtime/runtime';
  * - Layout wrapper with sidebar
  * - Loading states
  * - Dark mode management
+ * - App lock screen
+ * - Clipboard auto-clear countdown
  */
 function App() {
   const [onboardingComplete, setOnboardingComplete] = useState<boolean>(false);
@@ -47,6 +50,9 @@ function App() {
   // Initialize i18n synchronization
   useI18n();
 
+  // App lock state; the lock screen covers the whole UI while locked
+  const { locked, unlock } = useAppLock();
+
   // UI state
   const isAppLoading = useUIStore((state) => state.isAppLoading);
   const setAppLoading = useUIStore((state) => state.setAppLoading);
@@ -160,6 +166,8 @@ function App() {
   return (
     <>
       <ToastProvider />
+      <ClipboardCountdown />
+      {locked && <LockScreen onUnlock={unlock} />}
       <ErrorBoundary>
         <Router>
           <Layout>
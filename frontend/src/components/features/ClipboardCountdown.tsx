@@ -0,0 +1,49 @@
+import React, { useCallback } from 'react';
+import { motion, AnimatePresence } from 'framer-motion';
+import { toast } from '../ui/Toast';
+import { useI18n } from '../../hooks/useI18n';
+import { useClipboardCountdown } from '../../hooks/useClipboardCountdown';
+
+/**
+ * ClipboardCountdown Component
+ *
+ * Shows how long a copied secret (password, DeltaChat login link, ...) stays
+ * in the clipboard before the backend clears it.
+ */
+export const ClipboardCountdown: React.FC = () => {
+  const { t } = useI18n();
+
+  const handleCleared = useCallback((cleared: boolean) => {
+    if (cleared) {
+      toast.success(t('clipboard.cleared'));
+    }
+  }, [t]);
+
+  const { remaining, total } = useClipboardCountdown(handleCleared);
+  const progress = remaining !== null && total ? (remaining / total) * 100 : 0;
+
+  return (
+    <AnimatePresence>
+      {remaining !== null && (
+        <motion.div
+          initial={{ opacity: 0, y: 10 }}
+          animate={{ opacity: 1, y: 0 }}
+          exit={{ opacity: 0, y: 10 }}
+          transition={{ duration: 0.2 }}
+          className="fixed bottom-4 right-4 z-50 w-64 p-3 bg-slate-800 border border-amber-500/30 rounded-xl shadow-lg"
+          role="status"
+        >
+          <p className="text-sm text-amber-300">
+            🔑 {t('clipboard.clearsIn', { seconds: remaining })}
+          </p>
+          <div className="mt-2 h-1 bg-slate-700 rounded-full overflow-hidden">
+            <div
+              className="h-full bg-amber-400 transition-all duration-1000 ease-linear"
+              style={{ width: `${progress}%` }}
+            />
+          </div>
+        </motion.div>
+      )}
+    </AnimatePresence>
+  );
+};
@@ -0,0 +1,75 @@
+import React, { useState } from 'react';
+import { motion } from 'framer-motion';
+import { Button } from '../ui/Button';
+import { Input } from '../ui/Input';
+import { useI18n } from '../../hooks/useI18n';
+
+interface LockScreenProps {
+  onUnlock: (passphrase: string) => Promise<void>;
+}
+
+/**
+ * LockScreen Component
+ *
+ * Full-screen passphrase prompt shown while the app is locked.
+ * Covers the whole UI; the mail service keeps running in the background.
+ */
+export const LockScreen: React.FC<LockScreenProps> = ({ onUnlock }) => {
+  const { t } = useI18n();
+  const [passphrase, setPassphrase] = useState('');
+  const [error, setError] = useState('');
+  const [unlocking, setUnlocking] = useState(false);
+
+  const handleSubmit = async (e: React.FormEvent) => {
+    e.preventDefault();
+    if (!passphrase || unlocking) return;
+
+    setUnlocking(true);
+    setError('');
+    try {
+      await onUnlock(passphrase);
+      setPassphrase('');
+    } catch (err) {
+      setError(err instanceof Error ? err.message : String(err));
+    } finally {
+      setUnlocking(false);
+    }
+  };
+
+  return (
+    <div className="fixed inset-0 z-[100] flex items-center justify-center bg-slate-900">
+      <motion.form
+        initial={{ opacity: 0, scale: 0.95 }}
+        animate={{ opacity: 1, scale: 1 }}
+        transition={{ duration: 0.2 }}
+        onSubmit={handleSubmit}
+        className="w-full max-w-sm space-y-4 p-6 bg-slate-800 border border-slate-700 rounded-2xl shadow-xl"
+      >
+        <div className="text-center">
+          <div className="text-4xl mb-2">🔒</div>
+          <h2 className="text-lg font-semibold text-slate-100">{t('appLock.title')}</h2>
+          <p className="text-sm text-slate-400 mt-1">{t('appLock.description')}</p>
+        </div>
+
+        <Input
+          type="password"
+          placeholder={t('appLock.passphrasePlaceholder')}
+          value={passphrase}
+          onChange={(e) => setPassphrase(e.target.value)}
+          error={error}
+          autoFocus
+        />
+
+        <Button
+          type="submit"
+          variant="primary"
+          fullWidth
+          loading={unlocking}
+          disabled={!passphrase}
+        >
+          {t('appLock.unlock')}
+        </Button>
+      </motion.form>
+    </div>
+  );
+};
@@ -10,3 +10,7 @@ export { LogViewer } from './LogViewer';
 export type { LogLevel, LogEntry } from './LogViewer';
 
 export { PeerDiscoveryModal } from './PeerDiscoveryModal';
+
+export { LockScreen } from './LockScreen';
+
+export { ClipboardCountdown } from './ClipboardCountdown';
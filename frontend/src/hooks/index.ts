@@ -35,3 +35,7 @@ export type { UseConfigOptions } from './useConfig';
 export { useI18n, useTranslate, useCurrentLanguage } from './useI18n';
 
 export { useThemeManager } from './useThemeManager';
+
+export { useAppLock, APP_LOCK_EVENT } from './useAppLock';
+
+export { useClipboardCountdown, ClipboardEventNames } from './useClipboardCountdown';
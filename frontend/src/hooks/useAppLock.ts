@@ -0,0 +1,85 @@
+/**
+ * useAppLock - Hook for the application lock state
+ * Tracks whether the UI is locked and reports user activity to postpone the idle lock
+ */
+
+import { useCallback, useEffect, useState } from 'react';
+import { EventsOn } from '../../wailsjs/runtime/runtime';
+import { IsAppLocked, UnlockApp, ReportActivity } from '../../wailsjs/go/main/App';
+
+/**
+ * Event emitted by the backend when the lock state changes
+ * The payload is true when locked and false when unlocked
+ */
+export const APP_LOCK_EVENT = 'applock:locked';
+
+/**
+ * Minimum time between two activity reports, in milliseconds
+ * The backend checks the idle timeout every 15 seconds, so reporting more often is wasted work
+ */
+const ACTIVITY_REPORT_INTERVAL = 10000;
+
+/**
+ * DOM events counted as user activity
+ */
+const ACTIVITY_EVENTS = ['mousemove', 'mousedown', 'keydown', 'wheel', 'touchstart'] as const;
+
+/**
+ * Hook that tracks the app lock state
+ * While unlocked, user input is reported to the backend (throttled)
+ *
+ * @example
+ * ```tsx
+ * const { locked, unlock } = useAppLock();
+ * if (locked) return <LockScreen onUnlock={unlock} />;
+ * ```
+ */
+export function useAppLock() {
+  const [locked, setLocked] = useState(false);
+
+  // Initial state and lock/unlock events (idle timeout, tray, other windows)
+  useEffect(() => {
+    IsAppLocked()
+      .then(setLocked)
+      .catch((error) => console.warn('Failed to get app lock state:', error));
+
+    const unsubscribe = EventsOn(APP_LOCK_EVENT, (isLocked: boolean) => {
+      setLocked(Boolean(isLocked));
+    });
+
+    return () => {
+      if (unsubscribe) unsubscribe();
+    };
+  }, []);
+
+  // Report user activity so the idle timeout starts over
+  useEffect(() => {
+    if (locked) return;
+
+    let lastReport = 0;
+    const handleActivity = () => {
+      const now = Date.now();
+      if (now - lastReport < ACTIVITY_REPORT_INTERVAL) return;
+      lastReport = now;
+      ReportActivity().catch(() => {});
+    };
+
+    ACTIVITY_EVENTS.forEach((eventName) => {
+      window.addEventListener(eventName, handleActivity, { passive: true });
+    });
+
+    return () => {
+      ACTIVITY_EVENTS.forEach((eventName) => {
+        window.removeEventListener(eventName, handleActivity);
+      });
+    };
+  }, [locked]);
+
+  // Unlock with the passphrase; throws the backend error on failure
+  const unlock = useCallback(async (passphrase: string) => {
+    await UnlockApp(passphrase);
+    setLocked(false);
+  }, []);
+
+  return { locked, unlock };
+}
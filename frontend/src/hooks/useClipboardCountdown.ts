@@ -0,0 +1,59 @@
+/**
+ * useClipboardCountdown - Hook for the sensitive clipboard countdown
+ * Tracks the seconds left before the backend clears a copied secret
+ */
+
+import { useEffect, useState } from 'react';
+import { EventsOn } from '../../wailsjs/runtime/runtime';
+
+/**
+ * Events emitted by the backend after CopySensitiveToClipboard
+ */
+export const ClipboardEventNames = {
+  COUNTDOWN: 'clipboard:countdown',
+  CLEARED: 'clipboard:cleared',
+} as const;
+
+interface ClipboardCountdownEvent {
+  remaining: number;
+  total: number;
+}
+
+interface ClipboardClearedEvent {
+  cleared: boolean;
+}
+
+/**
+ * Hook that returns the remaining seconds before the clipboard is cleared
+ * remaining is null when no secret is pending; onCleared runs when the countdown ends
+ *
+ * @example
+ * ```tsx
+ * const { remaining } = useClipboardCountdown();
+ * return remaining !== null ? <span>{remaining}s</span> : null;
+ * ```
+ */
+export function useClipboardCountdown(onCleared?: (cleared: boolean) => void) {
+  const [remaining, setRemaining] = useState<number | null>(null);
+  const [total, setTotal] = useState<number | null>(null);
+
+  useEffect(() => {
+    const unsubscribeCountdown = EventsOn(ClipboardEventNames.COUNTDOWN, (event: ClipboardCountdownEvent) => {
+      setRemaining(event.remaining);
+      setTotal(event.total);
+    });
+
+    const unsubscribeCleared = EventsOn(ClipboardEventNames.CLEARED, (event: ClipboardClearedEvent) => {
+      setRemaining(null);
+      setTotal(null);
+      if (onCleared) onCleared(Boolean(event?.cleared));
+    });
+
+    return () => {
+      if (unsubscribeCountdown) unsubscribeCountdown();
+      if (unsubscribeCleared) unsubscribeCleared();
+    };
+  }, [onCleared]);
+
+  return { remaining, total };
+}
@@ -17,6 +17,10 @@ interface MailEventDTO {
   subject: string;
   from: string;
   to: string;
+  fromName?: string;
+  toName?: string;
+  fromDisplay: string;
+  toDisplay: string;
   timestamp: string;
 }
 
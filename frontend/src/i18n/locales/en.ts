@@ -35,6 +35,20 @@ export default {
     error: "Error",
   },
 
+  // App lock screen
+  appLock: {
+    title: "Tyr is locked",
+    description: "Enter your app lock passphrase to continue. Mail keeps running in the background.",
+    passphrasePlaceholder: "App lock passphrase",
+    unlock: "Unlock",
+  },
+
+  // Sensitive clipboard
+  clipboard: {
+    clearsIn: "Clipboard will be cleared in {{seconds}} s",
+    cleared: "Clipboard cleared",
+  },
+
   // Dialog titles
   dialog: {
     error: "Error",
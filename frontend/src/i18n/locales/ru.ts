@@ -35,6 +35,20 @@ export default {
     error: "Ошибка",
   },
 
+  // App lock screen
+  appLock: {
+    title: "Tyr заблокирован",
+    description: "Введите пароль блокировки, чтобы продолжить. Почта продолжает работать в фоне.",
+    passphrasePlaceholder: "Пароль блокировки",
+    unlock: "Разблокировать",
+  },
+
+  // Sensitive clipboard
+  clipboard: {
+    clearsIn: "Буфер обмена будет очищен через {{seconds}} с",
+    cleared: "Буфер обмена очищен",
+  },
+
   // Dialog titles
   dialog: {
     error: "Ошибка",
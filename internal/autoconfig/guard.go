@@ -0,0 +1,147 @@
+package autoconfig
+
+import (
+	"log"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultListenAddr is the default autoconfig listen address
+	DefaultListenAddr = "127.0.0.1:8080"
+
+	// RandomPortListenAddr listens on a random free loopback port
+	RandomPortListenAddr = "127.0.0.1:0"
+
+	// DefaultRateLimit is the default number of requests per minute per client
+	DefaultRateLimit = 60
+
+	// maxTrackedClients bounds the rate limiter state
+	maxTrackedClients = 1024
+)
+
+// isLoopbackListenAddr returns true if addr is host:port with a loopback host
+func isLoopbackListenAddr(addr string) bool {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return false
+	}
+	if strings.EqualFold(host, "localhost") {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// allowedHost checks a request Host header against the loopback names of the server
+// Browsers send the name they resolved, so a rebound domain never matches
+func (s *Server) allowedHost(hostHeader string) bool {
+	_, listenPort, err := net.SplitHostPort(s.GetListenAddr())
+	if err != nil {
+		return false
+	}
+
+	host, port, err := net.SplitHostPort(hostHeader)
+	if err != nil {
+		// No port in the header means the default HTTP port
+		host, port = strings.Trim(hostHeader, "[]"), "80"
+	}
+	if port != listenPort {
+		return false
+	}
+
+	if strings.EqualFold(host, "localhost") {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// checkHost rejects requests whose Host header isn't a loopback name (DNS rebinding)
+func (s *Server) checkHost(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !s.allowedHost(r.Host) {
+			log.Printf("Autoconfig request rejected: invalid Host %q from %s", r.Host, r.RemoteAddr)
+			http.Error(w, "Invalid host", http.StatusMisdirectedRequest)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// limitRate rejects clients exceeding the request rate limit
+func (s *Server) limitRate(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		client, _, err := net.SplitHostPort(r.RemoteAddr)
+		if err != nil {
+			client = r.RemoteAddr
+		}
+		if ok, retryAfter := s.limiter.allow(client, time.Now()); !ok {
+			log.Printf("Autoconfig request rejected: rate limit exceeded for %s", client)
+			w.Header().Set("Retry-After", strconv.Itoa(int(retryAfter.Seconds())+1))
+			http.Error(w, "Too many requests", http.StatusTooManyRequests)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// rateLimiter counts requests per client in fixed windows
+type rateLimiter struct {
+	limit  int
+	window time.Duration
+
+	mu      sync.Mutex
+	clients map[string]*clientWindow
+}
+
+// clientWindow is the request count of one client in the current window
+type clientWindow struct {
+	start time.Time
+	count int
+}
+
+// newRateLimiter creates a limiter allowing limit requests per window per client
+func newRateLimiter(limit int, window time.Duration) *rateLimiter {
+	return &rateLimiter{
+		limit:   limit,
+		window:  window,
+		clients: make(map[string]*clientWindow),
+	}
+}
+
+// allow records a request and reports whether it is within the limit
+// If not, it also returns the time until the window resets
+func (l *rateLimiter) allow(client string, now time.Time) (bool, time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	entry, ok := l.clients[client]
+	if !ok || now.Sub(entry.start) >= l.window {
+		if !ok && len(l.clients) >= maxTrackedClients {
+			l.prune(now)
+		}
+		entry = &clientWindow{start: now}
+		l.clients[client] = entry
+	}
+
+	if entry.count >= l.limit {
+		return false, entry.start.Add(l.window).Sub(now)
+	}
+	entry.count++
+	return true, 0
+}
+
+// prune drops clients whose window has ended
+// Caller must hold l.mu
+func (l *rateLimiter) prune(now time.Time) {
+	for client, entry := range l.clients {
+		if now.Sub(entry.start) >= l.window {
+			delete(l.clients, client)
+		}
+	}
+}
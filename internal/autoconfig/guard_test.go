@@ -0,0 +1,134 @@
+package autoconfig
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+const testConfigPath = "/.well-known/autoconfig/mail/config-v1.1.xml"
+
+// newTestServer creates a server listening (nominally) on 127.0.0.1:8080
+// The handler is exercised directly, the server is never started
+func newTestServer(t *testing.T, rateLimit int) *Server {
+	t.Helper()
+
+	s, err := NewServer(ServerConfig{
+		MailDomain: "yggmail",
+		SMTPHost:   "127.0.0.1",
+		SMTPPort:   "1025",
+		IMAPHost:   "127.0.0.1",
+		IMAPPort:   "1143",
+		ListenAddr: DefaultListenAddr,
+		RateLimit:  rateLimit,
+	})
+	if err != nil {
+		t.Fatalf("NewServer: %v", err)
+	}
+	return s
+}
+
+func TestHandlerChecksHost(t *testing.T) {
+	tests := []struct {
+		name string
+		host string
+		want int
+	}{
+		{"loopback IPv4", "127.0.0.1:8080", http.StatusOK},
+		{"localhost", "localhost:8080", http.StatusOK},
+		{"localhost uppercase", "LOCALHOST:8080", http.StatusOK},
+		{"loopback IPv6", "[::1]:8080", http.StatusOK},
+		{"rebinding domain", "attacker.example:8080", http.StatusMisdirectedRequest},
+		{"rebinding domain without port", "attacker.example", http.StatusMisdirectedRequest},
+		{"loopback subdomain", "localhost.attacker.example:8080", http.StatusMisdirectedRequest},
+		{"LAN address", "192.168.1.10:8080", http.StatusMisdirectedRequest},
+		{"wrong port", "127.0.0.1:9090", http.StatusMisdirectedRequest},
+		{"default port", "127.0.0.1", http.StatusMisdirectedRequest},
+		{"empty", "", http.StatusMisdirectedRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// A fresh server per case keeps the rate limiter out of the way
+			h := newTestServer(t, 0).handler()
+
+			req := httptest.NewRequest(http.MethodGet, testConfigPath, nil)
+			req.Host = tt.host
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, req)
+
+			if rec.Code != tt.want {
+				t.Errorf("Host %q: status = %d, want %d", tt.host, rec.Code, tt.want)
+			}
+		})
+	}
+}
+
+func TestHandlerSendsNoCORSHeaders(t *testing.T) {
+	tests := []struct {
+		name   string
+		method string
+		origin string
+	}{
+		{"cross-origin GET", http.MethodGet, "https://attacker.example"},
+		{"null origin GET", http.MethodGet, "null"},
+		{"cross-origin preflight", http.MethodOptions, "https://attacker.example"},
+		{"same-origin GET", http.MethodGet, "http://127.0.0.1:8080"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := newTestServer(t, 0).handler()
+
+			req := httptest.NewRequest(tt.method, testConfigPath, nil)
+			req.Host = "127.0.0.1:8080"
+			req.Header.Set("Origin", tt.origin)
+			req.Header.Set("Access-Control-Request-Method", http.MethodGet)
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, req)
+
+			if got := rec.Header().Get("Access-Control-Allow-Origin"); got != "" {
+				t.Errorf("Access-Control-Allow-Origin = %q, want none", got)
+			}
+			if got := rec.Header().Get("Access-Control-Allow-Credentials"); got != "" {
+				t.Errorf("Access-Control-Allow-Credentials = %q, want none", got)
+			}
+		})
+	}
+}
+
+func TestHandlerLimitsRate(t *testing.T) {
+	const limit = 3
+
+	tests := []struct {
+		name       string
+		remoteAddr string
+		host       string
+		want       int
+	}{
+		{"first request", "192.0.2.1:1000", "127.0.0.1:8080", http.StatusOK},
+		{"second request", "192.0.2.1:1001", "127.0.0.1:8080", http.StatusOK},
+		// Rejected requests still count, so rebinding attempts use up the budget
+		{"third request, foreign host", "192.0.2.1:1002", "attacker.example:8080", http.StatusMisdirectedRequest},
+		{"over the limit", "192.0.2.1:1003", "127.0.0.1:8080", http.StatusTooManyRequests},
+		{"still over the limit", "192.0.2.1:1004", "127.0.0.1:8080", http.StatusTooManyRequests},
+		{"other client", "192.0.2.2:1000", "127.0.0.1:8080", http.StatusOK},
+	}
+
+	// The cases run in order against one server so the counter carries over
+	h := newTestServer(t, limit).handler()
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodGet, testConfigPath, nil)
+		req.Host = tt.host
+		req.RemoteAddr = tt.remoteAddr
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+
+		if rec.Code != tt.want {
+			t.Fatalf("%s: status = %d, want %d", tt.name, rec.Code, tt.want)
+		}
+		if tt.want == http.StatusTooManyRequests && rec.Header().Get("Retry-After") == "" {
+			t.Errorf("%s: missing Retry-After header", tt.name)
+		}
+	}
+}
@@ -3,11 +3,15 @@ package autoconfig
 import (
 	"encoding/xml"
 	"fmt"
+	"html"
 	"log"
 	"net"
 	"net/http"
 	"strings"
 	"sync"
+	"time"
+
+	"github.com/JB-SelfCompany/Tyr-Desktop/internal/qr"
 )
 
 type Server struct {
@@ -17,13 +21,18 @@ type Server struct {
 	smtpPort     string // e.g., "1025"
 	imapHost     string // e.g., "127.0.0.1"
 	imapPort     string // e.g., "1143"
-	listenAddr   string // e.g., "127.0.0.1:8080"
+	socketType   string // "plain", "SSL" or "STARTTLS"
+	fingerprint  string // SHA-256 fingerprint of the TLS certificate, empty for plain
+	listenAddr   string // e.g., "127.0.0.1:8080", port 0 picks a random port
+	boundAddr    string // address actually listened on while running
 	displayName  string // e.g., "Yggmail"
 	shortName    string // e.g., "Yggmail"
+	qrAddress    string // mail address served as QR code, empty to disable
 
 	// HTTP server
 	server   *http.Server
 	listener net.Listener
+	limiter  *rateLimiter
 
 	// State management
 	mu      sync.RWMutex
@@ -37,9 +46,13 @@ type ServerConfig struct {
 	SMTPPort    string // SMTP server port
 	IMAPHost    string // IMAP server hostname
 	IMAPPort    string // IMAP server port
-	ListenAddr  string // Address to listen on (e.g., "127.0.0.1:8080")
+	SocketType  string // Connection security: "plain" (default), "SSL" or "STARTTLS"
+	Fingerprint string // SHA-256 fingerprint of the TLS certificate (optional)
+	ListenAddr  string // Loopback address to listen on (e.g., "127.0.0.1:8080", port 0 for random)
+	RateLimit   int    // Requests per minute allowed per client (0 = default)
 	DisplayName string // Display name for email provider
 	ShortName   string // Short name for email provider
+	QRAddress   string // Mail address to serve as QR code (optional, empty to disable)
 }
 
 // ClientConfig represents the XML structure for Thunderbird/DeltaChat autoconfiguration
@@ -57,6 +70,19 @@ type EmailProvider struct {
 	DisplayShortName string          `xml:"displayShortName"`
 	IncomingServer  IncomingServer   `xml:"incomingServer"`
 	OutgoingServer  OutgoingServer   `xml:"outgoingServer"`
+	Documentation   *Documentation   `xml:"documentation,omitempty"`
+}
+
+// Documentation points clients and users to additional setup information
+type Documentation struct {
+	URL   string `xml:"url,attr"`
+	Descr Descr  `xml:"descr"`
+}
+
+// Descr is a localized description text
+type Descr struct {
+	Lang string `xml:"lang,attr"`
+	Text string `xml:",chardata"`
 }
 
 // IncomingServer contains IMAP server configuration
@@ -97,8 +123,22 @@ func NewServer(config ServerConfig) (*Server, error) {
 	if config.IMAPPort == "" {
 		return nil, fmt.Errorf("IMAP port cannot be empty")
 	}
+	switch config.SocketType {
+	case "":
+		config.SocketType = "plain"
+	case "plain", "SSL", "STARTTLS":
+	default:
+		return nil, fmt.Errorf("invalid socket type: %s", config.SocketType)
+	}
 	if config.ListenAddr == "" {
-		config.ListenAddr = "127.0.0.1:8080"
+		config.ListenAddr = DefaultListenAddr
+	}
+	// Requests are only accepted for loopback host names, so only loopback listeners make sense
+	if !isLoopbackListenAddr(config.ListenAddr) {
+		return nil, fmt.Errorf("listen address must be a loopback address: %s", config.ListenAddr)
+	}
+	if config.RateLimit <= 0 {
+		config.RateLimit = DefaultRateLimit
 	}
 	if config.DisplayName == "" {
 		config.DisplayName = "Yggmail"
@@ -113,9 +153,13 @@ func NewServer(config ServerConfig) (*Server, error) {
 		smtpPort:    config.SMTPPort,
 		imapHost:    config.IMAPHost,
 		imapPort:    config.IMAPPort,
+		socketType:  config.SocketType,
+		fingerprint: config.Fingerprint,
 		listenAddr:  config.ListenAddr,
 		displayName: config.DisplayName,
 		shortName:   config.ShortName,
+		qrAddress:   config.QRAddress,
+		limiter:     newRateLimiter(config.RateLimit, time.Minute),
 	}
 
 	return s, nil
@@ -130,32 +174,25 @@ func (s *Server) Start() error {
 		return fmt.Errorf("server already running")
 	}
 
-	// Create HTTP server
-	mux := http.NewServeMux()
-
-	// Register handlers for both standard autoconfig paths
-	mux.HandleFunc("/.well-known/autoconfig/mail/config-v1.1.xml", s.handleAutoconfig)
-	mux.HandleFunc("/mail/config-v1.1.xml", s.handleAutoconfig)
-	mux.HandleFunc("/autoconfig/mail/config-v1.1.xml", s.handleAutoconfig)
-
-	// Add a root handler for debugging
-	mux.HandleFunc("/", s.handleRoot)
-
-	s.server = &http.Server{
-		Addr:    s.listenAddr,
-		Handler: s.logRequest(mux),
-	}
-
 	// Create listener
 	listener, err := net.Listen("tcp", s.listenAddr)
 	if err != nil {
 		return fmt.Errorf("failed to create listener: %w", err)
 	}
 	s.listener = listener
+	s.boundAddr = listener.Addr().String()
+
+	// Create HTTP server
+	s.server = &http.Server{
+		Addr:              s.boundAddr,
+		Handler:           s.handler(),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
 
 	// Start server in background
+	boundAddr := s.boundAddr
 	go func() {
-		log.Printf("Autoconfig server listening on %s", s.listenAddr)
+		log.Printf("Autoconfig server listening on %s", boundAddr)
 		if err := s.server.Serve(s.listener); err != nil && err != http.ErrServerClosed {
 			log.Printf("Autoconfig server error: %v", err)
 		}
@@ -165,6 +202,26 @@ func (s *Server) Start() error {
 	return nil
 }
 
+// handler builds the request handler with the routes and the request guards
+func (s *Server) handler() http.Handler {
+	mux := http.NewServeMux()
+
+	// Register handlers for both standard autoconfig paths
+	mux.HandleFunc("/.well-known/autoconfig/mail/config-v1.1.xml", s.handleAutoconfig)
+	mux.HandleFunc("/mail/config-v1.1.xml", s.handleAutoconfig)
+	mux.HandleFunc("/autoconfig/mail/config-v1.1.xml", s.handleAutoconfig)
+
+	// Mail address QR codes (only served when enabled)
+	mux.HandleFunc("/qr/address.png", s.handleAddressQR)
+	mux.HandleFunc("/qr/address.svg", s.handleAddressQR)
+
+	// Add a root handler for debugging
+	mux.HandleFunc("/", s.handleRoot)
+
+	// Rate limit first, then reject foreign Host headers (DNS rebinding)
+	return s.logRequest(s.limitRate(s.checkHost(mux)))
+}
+
 // Stop stops the autoconfiguration HTTP server
 func (s *Server) Stop() error {
 	s.mu.Lock()
@@ -197,12 +254,71 @@ func (s *Server) IsRunning() bool {
 }
 
 // GetListenAddr returns the address the server is listening on
+// With a random port this is only known while running
 func (s *Server) GetListenAddr() string {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
+	if s.running {
+		return s.boundAddr
+	}
 	return s.listenAddr
 }
 
+// GetURL returns the base URL clients should use, or empty if not running
+func (s *Server) GetURL() string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if !s.running {
+		return ""
+	}
+	return "http://" + s.boundAddr
+}
+
+// SetQRAddress sets the mail address served as QR code
+// An empty address disables the QR endpoints
+func (s *Server) SetQRAddress(address string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.qrAddress = address
+}
+
+// getQRAddress returns the mail address served as QR code
+func (s *Server) getQRAddress() string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.qrAddress
+}
+
+// handleAddressQR serves the mail address QR code as PNG or SVG
+func (s *Server) handleAddressQR(w http.ResponseWriter, r *http.Request) {
+	address := s.getQRAddress()
+	if address == "" {
+		http.NotFound(w, r)
+		return
+	}
+
+	if strings.HasSuffix(r.URL.Path, ".svg") {
+		svg, err := qr.SVG(qr.AddressContent(address))
+		if err != nil {
+			log.Printf("Failed to generate QR code: %v", err)
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "image/svg+xml")
+		w.Write([]byte(svg))
+		return
+	}
+
+	png, err := qr.PNG(qr.AddressContent(address), qr.DefaultSize)
+	if err != nil {
+		log.Printf("Failed to generate QR code: %v", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "image/png")
+	w.Write(png)
+}
+
 // handleAutoconfig handles requests for the autoconfiguration XML
 func (s *Server) handleAutoconfig(w http.ResponseWriter, r *http.Request) {
 	log.Printf("Autoconfig request from %s: %s", r.RemoteAddr, r.URL.Path)
@@ -219,8 +335,8 @@ func (s *Server) handleAutoconfig(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Set headers
+	// No CORS headers: web pages must not be able to read the mail setup
 	w.Header().Set("Content-Type", "application/xml; charset=utf-8")
-	w.Header().Set("Access-Control-Allow-Origin", "*")
 
 	// Write XML declaration and data
 	w.Write([]byte(xml.Header))
@@ -250,10 +366,11 @@ func (s *Server) handleRoot(w http.ResponseWriter, r *http.Request) {
     <div class="info">
         <p><strong>Status:</strong> Running</p>
         <p><strong>Mail Domain:</strong> %s</p>
-        <p><strong>SMTP:</strong> %s:%s (plain text, no encryption)</p>
-        <p><strong>IMAP:</strong> %s:%s (plain text, no encryption)</p>
-    </div>
+        <p><strong>SMTP:</strong> %s:%s (%s)</p>
+        <p><strong>IMAP:</strong> %s:%s (%s)</p>
+%s    </div>
 
+%s
     <h2>Autoconfiguration URLs</h2>
     <ul>
         <li><a href="/.well-known/autoconfig/mail/config-v1.1.xml">/.well-known/autoconfig/mail/config-v1.1.xml</a></li>
@@ -263,28 +380,62 @@ func (s *Server) handleRoot(w http.ResponseWriter, r *http.Request) {
 
     <h2>DeltaChat Setup</h2>
     <p>DeltaChat will automatically discover these settings when you configure your account.</p>
-    <p>Make sure DeltaChat is configured to check <code>http://localhost:8080</code> for autoconfiguration.</p>
+    <p>Make sure DeltaChat is configured to check <code>%s</code> for autoconfiguration.</p>
 
     <h2>Manual Configuration</h2>
     <p>If autoconfiguration doesn't work, use these settings:</p>
     <ul>
         <li><strong>IMAP Server:</strong> %s:%s</li>
         <li><strong>SMTP Server:</strong> %s:%s</li>
-        <li><strong>Encryption:</strong> None (localhost only)</li>
+        <li><strong>Encryption:</strong> %s</li>
         <li><strong>Authentication:</strong> Password (cleartext)</li>
         <li><strong>Username:</strong> Your full email address</li>
     </ul>
 </body>
-</html>`, s.mailDomain, s.smtpHost, s.smtpPort, s.imapHost, s.imapPort, s.imapHost, s.imapPort, s.smtpHost, s.smtpPort)
+</html>`, s.mailDomain, s.smtpHost, s.smtpPort, s.securityDescription(), s.imapHost, s.imapPort, s.securityDescription(),
+			s.fingerprintSection(), s.qrSection(), html.EscapeString(s.GetURL()), s.imapHost, s.imapPort, s.smtpHost, s.smtpPort, s.securityDescription())
 		return
 	}
 
 	http.NotFound(w, r)
 }
 
+// securityDescription describes the connection security for the info page
+func (s *Server) securityDescription() string {
+	switch s.socketType {
+	case "SSL":
+		return "SSL/TLS"
+	case "STARTTLS":
+		return "STARTTLS"
+	default:
+		return "plain text, no encryption"
+	}
+}
+
+// fingerprintSection returns the HTML line showing the certificate fingerprint, or empty for plain
+func (s *Server) fingerprintSection() string {
+	if s.fingerprint == "" {
+		return ""
+	}
+	return fmt.Sprintf("        <p><strong>Certificate SHA-256:</strong> <code>%s</code></p>\n", html.EscapeString(s.fingerprint))
+}
+
+// qrSection returns the HTML block showing the mail address QR code, or empty if disabled
+func (s *Server) qrSection() string {
+	address := s.getQRAddress()
+	if address == "" {
+		return ""
+	}
+	return fmt.Sprintf(`
+    <h2>Mail Address</h2>
+    <p><code>%s</code></p>
+    <p><img src="/qr/address.svg" alt="Mail address QR code" width="256" height="256"></p>
+`, html.EscapeString(address))
+}
+
 // generateConfig creates the autoconfiguration XML structure
 func (s *Server) generateConfig() ClientConfig {
-	return ClientConfig{
+	config := ClientConfig{
 		Version: "1.1",
 		EmailProvider: EmailProvider{
 			ID:               s.mailDomain,
@@ -295,7 +446,7 @@ func (s *Server) generateConfig() ClientConfig {
 				Type:           "imap",
 				Hostname:       s.imapHost,
 				Port:           s.imapPort,
-				SocketType:     "plain", // No SSL for localhost
+				SocketType:     s.socketType,
 				Authentication: "password-cleartext",
 				Username:       "%EMAILADDRESS%", // Placeholder replaced by client
 			},
@@ -303,12 +454,24 @@ func (s *Server) generateConfig() ClientConfig {
 				Type:           "smtp",
 				Hostname:       s.smtpHost,
 				Port:           s.smtpPort,
-				SocketType:     "plain", // No SSL for localhost
+				SocketType:     s.socketType,
 				Authentication: "password-cleartext",
 				Username:       "%EMAILADDRESS%", // Placeholder replaced by client
 			},
 		},
 	}
+
+	// The certificate is issued by a local CA, so publish its fingerprint for manual verification
+	if s.fingerprint != "" {
+		config.EmailProvider.Documentation = &Documentation{
+			URL: s.GetURL() + "/",
+			Descr: Descr{
+				Lang: "en",
+				Text: "TLS certificate SHA-256 fingerprint: " + s.fingerprint,
+			},
+		}
+	}
+	return config
 }
 
 // logRequest is a middleware that logs all HTTP requests
@@ -0,0 +1,166 @@
+package applock
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/JB-SelfCompany/Tyr-Desktop/internal/core"
+	"github.com/JB-SelfCompany/Tyr-Desktop/internal/models"
+)
+
+// idleCheckInterval is how often the idle timeout is checked
+const idleCheckInterval = 15 * time.Second
+
+// EventEmitter is a callback function that emits events to the frontend
+type EventEmitter func(eventName string, data interface{})
+
+// StartIdleMonitoring locks the app once it has been idle for the configured period
+// Emits "applock:locked" when it locks. Blocks until shutdownChan is closed
+func StartIdleMonitoring(lock *core.AppLock, emitFunc EventEmitter, shutdownChan <-chan struct{}) {
+	if lock == nil {
+		return
+	}
+
+	ticker := time.NewTicker(idleCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-shutdownChan:
+			log.Println("App lock idle monitoring stopped")
+			return
+
+		case now := <-ticker.C:
+			if lock.CheckIdle(now) {
+				log.Println("[AppLock] Locked after idle timeout")
+				emitLocked(emitFunc, true)
+			}
+		}
+	}
+}
+
+// GetAppLockSettings returns the app lock settings and current state
+func GetAppLockSettings(cfg *core.Config, lock *core.AppLock) models.AppLockSettingsDTO {
+	dto := models.AppLockSettingsDTO{MinPassphraseLength: core.MinAppLockPassphraseLength}
+	if cfg == nil {
+		return dto
+	}
+
+	settings := cfg.GetAppLockSettings()
+	dto.Enabled = settings.Enabled
+	dto.IdleMinutes = settings.IdleMinutes
+	dto.HasPassphrase = settings.PassphraseHash != ""
+	dto.Locked = lock != nil && lock.IsLocked()
+	return dto
+}
+
+// SaveAppLockSettings validates and saves the app lock preferences
+func SaveAppLockSettings(cfg *core.Config, lock *core.AppLock, dto models.AppLockSettingsDTO) error {
+	if cfg == nil {
+		return fmt.Errorf("config not initialized")
+	}
+	if err := RequireUnlocked(lock); err != nil {
+		return err
+	}
+
+	settings := core.AppLockSettings{
+		Enabled:     dto.Enabled,
+		IdleMinutes: dto.IdleMinutes,
+	}
+	if err := cfg.SetAppLockSettings(settings); err != nil {
+		return fmt.Errorf("Invalid app lock settings: %v", err)
+	}
+
+	if err := cfg.Save(); err != nil {
+		return fmt.Errorf("failed to save config: %w", err)
+	}
+	if lock != nil {
+		lock.Reset()
+	}
+	return nil
+}
+
+// SetAppLockPassphrase sets or changes the app lock passphrase
+// The current passphrase is required if one is already set
+func SetAppLockPassphrase(cfg *core.Config, lock *core.AppLock, currentPassphrase, newPassphrase string) error {
+	if cfg == nil || lock == nil {
+		return fmt.Errorf("config not initialized")
+	}
+	if err := RequireUnlocked(lock); err != nil {
+		return err
+	}
+	if len(newPassphrase) < core.MinAppLockPassphraseLength {
+		return fmt.Errorf("Passphrase must be at least %d characters.", core.MinAppLockPassphraseLength)
+	}
+
+	if cfg.GetAppLockSettings().PassphraseHash != "" {
+		if err := lock.Verify(currentPassphrase); err != nil {
+			return passphraseError("Failed to change app lock passphrase", err)
+		}
+	}
+
+	if err := cfg.SetAppLockPassphrase(newPassphrase); err != nil {
+		return fmt.Errorf("Failed to set app lock passphrase. Error: %v", err)
+	}
+	if err := cfg.Save(); err != nil {
+		return fmt.Errorf("failed to save config: %w", err)
+	}
+	return nil
+}
+
+// LockApp locks the UI now. Emits "applock:locked"
+func LockApp(lock *core.AppLock, emitFunc EventEmitter) error {
+	if lock == nil {
+		return fmt.Errorf("config not initialized")
+	}
+	if err := lock.Lock(); err != nil {
+		return fmt.Errorf("App lock is not enabled. Please set a passphrase and enable it in settings.")
+	}
+	emitLocked(emitFunc, true)
+	return nil
+}
+
+// UnlockApp unlocks the UI with the passphrase. Emits "applock:locked" with false
+func UnlockApp(lock *core.AppLock, emitFunc EventEmitter, passphrase string) error {
+	if lock == nil {
+		return fmt.Errorf("config not initialized")
+	}
+	if !lock.IsLocked() {
+		return nil
+	}
+	if err := lock.Unlock(passphrase); err != nil {
+		return passphraseError("Failed to unlock", err)
+	}
+	emitLocked(emitFunc, false)
+	return nil
+}
+
+// RequireUnlocked returns an error if the app is locked
+// Sensitive bindings call this before doing anything
+func RequireUnlocked(lock *core.AppLock) error {
+	if lock != nil && lock.IsLocked() {
+		return fmt.Errorf("Application is locked. Please unlock it first.")
+	}
+	return nil
+}
+
+// passphraseError formats an app lock error, with a friendly message for a wrong passphrase
+func passphraseError(action string, err error) error {
+	if errors.Is(err, core.ErrWrongAppLockPassphrase) {
+		return fmt.Errorf("Passphrase is incorrect. Please check your passphrase and try again.")
+	}
+	var locked *core.AttemptLockedError
+	if errors.As(err, &locked) {
+		return fmt.Errorf("Too many failed attempts. Please try again in %s.", core.FormatRetryAfter(locked.RetryAfter))
+	}
+	return fmt.Errorf("%s. Error: %v", action, err)
+}
+
+// emitLocked notifies the frontend that the lock state changed
+func emitLocked(emitFunc EventEmitter, locked bool) {
+	if emitFunc != nil {
+		emitFunc("applock:locked", locked)
+	}
+}
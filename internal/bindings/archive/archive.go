@@ -0,0 +1,221 @@
+package archive
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/JB-SelfCompany/Tyr-Desktop/internal/core"
+	"github.com/JB-SelfCompany/Tyr-Desktop/internal/models"
+)
+
+// EventEmitter is a callback function that emits events to the frontend
+type EventEmitter func(eventName string, data interface{})
+
+// ExportMailboxes exports mailboxes to mbox, Maildir or .eml files
+// Emits "export:progress" with an ExportProgressDTO while running.
+// An interrupted export is resumed when started again with the same options
+func ExportMailboxes(ctx context.Context, sm *core.ServiceManager, dto models.ExportOptionsDTO, emitFunc EventEmitter) (models.ExportResultDTO, error) {
+	if sm == nil {
+		return models.ExportResultDTO{}, fmt.Errorf("Service manager is not initialized. Please restart the application.")
+	}
+	if !sm.IsRunning() {
+		return models.ExportResultDTO{}, fmt.Errorf("Service is not running. Please start the service first.")
+	}
+
+	opts, err := exportOptionsFromDTO(dto)
+	if err != nil {
+		return models.ExportResultDTO{}, err
+	}
+
+	emit := func(progress models.ExportProgressDTO) {
+		if emitFunc != nil {
+			emitFunc("export:progress", progress)
+		}
+	}
+	emit(models.ExportProgressDTO{Progress: 0, Message: "Preparing export..."})
+
+	result, err := sm.ExportMailboxes(ctx, opts, func(p core.ExportProgress) {
+		message := "Exporting messages..."
+		if p.Mailbox != "" {
+			message = fmt.Sprintf("Exporting %s (%d/%d)...", p.Mailbox, p.Current, p.Total)
+		}
+		emit(models.ExportProgressDTO{
+			Progress: p.Percent,
+			Message:  message,
+			Mailbox:  p.Mailbox,
+			Current:  p.Current,
+			Total:    p.Total,
+		})
+	})
+	if errors.Is(err, core.ErrExportCancelled) {
+		emit(models.ExportProgressDTO{Progress: 100, Message: "Export cancelled"})
+		dto := convertExportResult(result)
+		dto.Cancelled = true
+		return dto, nil
+	}
+	if err != nil {
+		return models.ExportResultDTO{}, fmt.Errorf("Failed to export mailboxes. Error: %v", err)
+	}
+
+	emit(models.ExportProgressDTO{Progress: 100, Message: "Export complete"})
+	return convertExportResult(result), nil
+}
+
+// GetInterruptedExport returns the options of an unfinished export in the destination
+// Returns nil if there is nothing to resume
+func GetInterruptedExport(destination string) (*models.ExportOptionsDTO, error) {
+	opts, err := core.InterruptedExport(destination)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to read export state. Error: %v", err)
+	}
+	if opts == nil {
+		return nil, nil
+	}
+
+	dto := &models.ExportOptionsDTO{
+		Destination: opts.Destination,
+		Format:      opts.Format,
+		Mailboxes:   opts.Mailboxes,
+	}
+	if !opts.Since.IsZero() {
+		dto.Since = opts.Since.Format(time.RFC3339)
+	}
+	if !opts.Before.IsZero() {
+		dto.Before = opts.Before.Format(time.RFC3339)
+	}
+	return dto, nil
+}
+
+// ImportMessages imports mbox, Maildir or .eml archives into a mailbox
+// Emits "import:progress" with an ImportProgressDTO while running
+func ImportMessages(ctx context.Context, sm *core.ServiceManager, dto models.ImportOptionsDTO, emitFunc EventEmitter) (models.ImportResultDTO, error) {
+	if sm == nil {
+		return models.ImportResultDTO{}, fmt.Errorf("Service manager is not initialized. Please restart the application.")
+	}
+	if !sm.IsRunning() {
+		return models.ImportResultDTO{}, fmt.Errorf("Service is not running. Please start the service first.")
+	}
+
+	opts := core.ImportOptions{
+		Paths:   dto.Paths,
+		Mailbox: strings.TrimSpace(dto.Mailbox),
+		Format:  strings.ToLower(strings.TrimSpace(dto.Format)),
+	}
+	if err := opts.Validate(); err != nil {
+		return models.ImportResultDTO{}, fmt.Errorf("Invalid import options: %v", err)
+	}
+
+	emit := func(progress models.ImportProgressDTO) {
+		if emitFunc != nil {
+			emitFunc("import:progress", progress)
+		}
+	}
+	emit(models.ImportProgressDTO{Progress: 0, Message: "Preparing import..."})
+
+	result, err := sm.ImportMessages(ctx, opts, func(p core.ImportProgress) {
+		emit(models.ImportProgressDTO{
+			Progress: (p.File - 1) * 100 / p.Files,
+			Message:  fmt.Sprintf("Importing %s (%d/%d)...", filepath.Base(p.Path), p.File, p.Files),
+			Path:     p.Path,
+			Messages: p.Messages,
+		})
+	})
+	if errors.Is(err, core.ErrImportCancelled) {
+		emit(models.ImportProgressDTO{Progress: 100, Message: "Import cancelled"})
+		dto := convertImportResult(result)
+		dto.Cancelled = true
+		return dto, nil
+	}
+	if err != nil {
+		return models.ImportResultDTO{}, fmt.Errorf("Failed to import messages. Error: %v", err)
+	}
+
+	emit(models.ImportProgressDTO{Progress: 100, Message: "Import complete"})
+	return convertImportResult(result), nil
+}
+
+// exportOptionsFromDTO converts and validates export options
+func exportOptionsFromDTO(dto models.ExportOptionsDTO) (core.ExportOptions, error) {
+	opts := core.ExportOptions{
+		Destination: dto.Destination,
+		Format:      strings.ToLower(strings.TrimSpace(dto.Format)),
+		Mailboxes:   dto.Mailboxes,
+	}
+
+	var err error
+	if opts.Since, err = parseDate(dto.Since); err != nil {
+		return opts, fmt.Errorf("Invalid start date: %v", err)
+	}
+	if opts.Before, err = parseDate(dto.Before); err != nil {
+		return opts, fmt.Errorf("Invalid end date: %v", err)
+	}
+
+	if err := opts.Validate(); err != nil {
+		return opts, fmt.Errorf("Invalid export options: %v", err)
+	}
+	return opts, nil
+}
+
+// parseDate parses a YYYY-MM-DD date (local midnight) or an RFC3339 time
+// An empty string returns the zero time
+func parseDate(s string) (time.Time, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.ParseInLocation("2006-01-02", s, time.Local); err == nil {
+		return t, nil
+	}
+	return time.Parse(time.RFC3339, s)
+}
+
+// convertExportResult converts an export result to a DTO
+func convertExportResult(r *core.ExportResult) models.ExportResultDTO {
+	dto := models.ExportResultDTO{
+		Destination: r.Destination,
+		Format:      r.Format,
+		Mailboxes:   r.Mailboxes,
+		Exported:    r.Exported,
+		Skipped:     r.Skipped,
+		Resumed:     r.Resumed,
+		Errors:      r.Errors,
+		StartedAt:   r.StartedAt.Format(time.RFC3339),
+		FinishedAt:  r.FinishedAt.Format(time.RFC3339),
+	}
+	if dto.Errors == nil {
+		dto.Errors = []string{}
+	}
+	return dto
+}
+
+// convertImportResult converts an import result to a DTO
+func convertImportResult(r *core.ImportResult) models.ImportResultDTO {
+	dto := models.ImportResultDTO{
+		Mailbox:    r.Mailbox,
+		Files:      make([]models.ImportFileResultDTO, 0, len(r.Files)),
+		Imported:   r.Imported,
+		Duplicates: r.Duplicates,
+		Failed:     r.Failed,
+		StartedAt:  r.StartedAt.Format(time.RFC3339),
+		FinishedAt: r.FinishedAt.Format(time.RFC3339),
+	}
+	for _, f := range r.Files {
+		file := models.ImportFileResultDTO{
+			Path:       f.Path,
+			Format:     f.Format,
+			Imported:   f.Imported,
+			Duplicates: f.Duplicates,
+			Failed:     f.Failed,
+			Errors:     f.Errors,
+		}
+		if file.Errors == nil {
+			file.Errors = []string{}
+		}
+		dto.Files = append(dto.Files, file)
+	}
+	return dto
+}
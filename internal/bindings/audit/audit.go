@@ -0,0 +1,46 @@
+package audit
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/JB-SelfCompany/Tyr-Desktop/internal/core"
+	"github.com/JB-SelfCompany/Tyr-Desktop/internal/models"
+)
+
+// GetAuditLog returns audit log entries, oldest first
+// A limit greater than 0 returns only the most recent entries
+func GetAuditLog(limit int) ([]models.AuditEntryDTO, error) {
+	entries, err := core.ReadAuditLog(limit)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to read audit log. Error: %v", err)
+	}
+
+	result := make([]models.AuditEntryDTO, 0, len(entries))
+	for _, e := range entries {
+		result = append(result, models.AuditEntryDTO{
+			Seq:     e.Seq,
+			Time:    e.Time.Format(time.RFC3339),
+			Action:  e.Action,
+			Outcome: e.Outcome,
+			Reason:  e.Reason,
+			Hash:    e.Hash,
+		})
+	}
+	return result, nil
+}
+
+// VerifyAuditLog checks the audit log hash chain
+func VerifyAuditLog() (models.AuditVerificationDTO, error) {
+	result, err := core.VerifyAuditLog()
+	if err != nil {
+		return models.AuditVerificationDTO{}, fmt.Errorf("Failed to verify audit log. Error: %v", err)
+	}
+
+	return models.AuditVerificationDTO{
+		Valid:        result.Valid,
+		Entries:      result.Entries,
+		BrokenAtLine: result.BrokenAtLine,
+		Reason:       result.Reason,
+	}, nil
+}
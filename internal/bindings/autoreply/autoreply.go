@@ -0,0 +1,106 @@
+package autoreply
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/JB-SelfCompany/Tyr-Desktop/internal/core"
+	"github.com/JB-SelfCompany/Tyr-Desktop/internal/models"
+)
+
+// EventEmitter is a callback function that emits events to the frontend
+type EventEmitter func(eventName string, data interface{})
+
+// HandleMailEvent sends an automatic reply to a new_mail event if the settings allow it
+// Emits "autoreply:sent" with an AutoReplyEventDTO when a reply was submitted.
+// Other event types are ignored. Blocks while the reply is sent, call it in a goroutine
+func HandleMailEvent(
+	responder *core.AutoResponder,
+	sm *core.ServiceManager,
+	outbox *core.OutboxStore,
+	emitFunc EventEmitter,
+	dto models.MailEventDTO,
+) {
+	if responder == nil || sm == nil || dto.Type != "new_mail" || dto.MailID <= 0 {
+		return
+	}
+
+	result, err := responder.HandleNewMail(sm, outbox, dto.Mailbox, dto.From, uint32(dto.MailID))
+	if err != nil {
+		log.Printf("[AutoReply] Failed to send automatic reply: %v", err)
+		return
+	}
+	if !result.Sent {
+		if result.Reason != "auto-reply is not active" {
+			log.Printf("[AutoReply] Skipped %s: %s", core.ShortMailAddress(result.To), result.Reason)
+		}
+		return
+	}
+
+	if emitFunc != nil {
+		emitFunc("autoreply:sent", models.AutoReplyEventDTO{
+			To:      result.To,
+			Subject: result.Subject,
+		})
+	}
+}
+
+// GetAutoReplySettings returns the auto-responder settings
+func GetAutoReplySettings(cfg *core.Config) models.AutoReplySettingsDTO {
+	if cfg == nil {
+		return models.AutoReplySettingsDTO{Allowlist: []string{}}
+	}
+
+	settings := cfg.GetAutoReplySettings()
+	dto := models.AutoReplySettingsDTO{
+		Enabled:      settings.Enabled,
+		StartDate:    settings.StartDate,
+		EndDate:      settings.EndDate,
+		IntervalDays: settings.IntervalDays,
+		Subject:      settings.Subject,
+		Body:         settings.Body,
+		ContactsOnly: settings.ContactsOnly,
+		Allowlist:    settings.Allowlist,
+	}
+	if dto.Allowlist == nil {
+		dto.Allowlist = []string{}
+	}
+	return dto
+}
+
+// SaveAutoReplySettings validates and saves the auto-responder settings
+func SaveAutoReplySettings(cfg *core.Config, dto models.AutoReplySettingsDTO) error {
+	if cfg == nil {
+		return fmt.Errorf("config not initialized")
+	}
+
+	settings := core.AutoReplySettings{
+		Enabled:      dto.Enabled,
+		StartDate:    dto.StartDate,
+		EndDate:      dto.EndDate,
+		IntervalDays: dto.IntervalDays,
+		Subject:      dto.Subject,
+		Body:         dto.Body,
+		ContactsOnly: dto.ContactsOnly,
+		Allowlist:    dto.Allowlist,
+	}
+	if err := cfg.SetAutoReplySettings(settings); err != nil {
+		return fmt.Errorf("Invalid auto-reply settings: %v", err)
+	}
+
+	if err := cfg.Save(); err != nil {
+		return fmt.Errorf("failed to save config: %w", err)
+	}
+	return nil
+}
+
+// ResetAutoReplyHistory forgets previous replies so every sender gets a new one
+func ResetAutoReplyHistory(responder *core.AutoResponder) error {
+	if responder == nil {
+		return fmt.Errorf("Auto-responder is not initialized. Please restart the application.")
+	}
+	if err := responder.ResetHistory(); err != nil {
+		return fmt.Errorf("Failed to reset auto-reply history. Error: %v", err)
+	}
+	return nil
+}
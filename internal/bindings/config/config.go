@@ -20,20 +20,25 @@ func GetConfigDTO(cfg *core.Config) models.ConfigDTO {
 	peers := make([]models.PeerConfigDTO, len(cfg.NetworkPeers))
 	for i, peer := range cfg.NetworkPeers {
 		peers[i] = models.PeerConfigDTO{
-			Address: peer.Address,
-			Enabled: peer.Enabled,
+			Address:     peer.Address,
+			Enabled:     peer.Enabled,
+			BypassProxy: peer.BypassProxy,
 		}
 	}
 
 	return models.ConfigDTO{
-		OnboardingComplete: cfg.OnboardingComplete,
-		Peers:              peers,
-		Language:           cfg.UIPreferences.Language,
-		Theme:              cfg.UIPreferences.Theme,
-		AutoStart:          cfg.UIPreferences.AutoStart,
-		SMTPAddress:        cfg.ServiceSettings.SMTPAddress,
-		IMAPAddress:        cfg.ServiceSettings.IMAPAddress,
-		DatabasePath:       cfg.ServiceSettings.DatabasePath,
+		OnboardingComplete:   cfg.OnboardingComplete,
+		Peers:                peers,
+		Language:             cfg.UIPreferences.Language,
+		Theme:                cfg.UIPreferences.Theme,
+		AutoStart:            cfg.UIPreferences.AutoStart,
+		SMTPAddress:          cfg.ServiceSettings.SMTPAddress,
+		IMAPAddress:          cfg.ServiceSettings.IMAPAddress,
+		DatabasePath:         cfg.ServiceSettings.DatabasePath,
+		ProxyEnabled:         cfg.ServiceSettings.Proxy.Enabled,
+		ProxyAddress:         cfg.ServiceSettings.Proxy.Address,
+		AutoconfigServeQR:    cfg.ServiceSettings.AutoconfigServeQR,
+		AutoconfigRandomPort: cfg.ServiceSettings.AutoconfigRandomPort,
 	}
 }
 
@@ -47,8 +52,9 @@ func UpdateConfigFromDTO(cfg *core.Config, dto models.ConfigDTO) error {
 	cfg.NetworkPeers = make([]core.PeerConfig, len(dto.Peers))
 	for i, peer := range dto.Peers {
 		cfg.NetworkPeers[i] = core.PeerConfig{
-			Address: peer.Address,
-			Enabled: peer.Enabled,
+			Address:     peer.Address,
+			Enabled:     peer.Enabled,
+			BypassProxy: peer.BypassProxy,
 		}
 	}
 
@@ -61,6 +67,13 @@ func UpdateConfigFromDTO(cfg *core.Config, dto models.ConfigDTO) error {
 	cfg.ServiceSettings.SMTPAddress = dto.SMTPAddress
 	cfg.ServiceSettings.IMAPAddress = dto.IMAPAddress
 	cfg.ServiceSettings.DatabasePath = dto.DatabasePath
+	cfg.ServiceSettings.AutoconfigServeQR = dto.AutoconfigServeQR
+	cfg.ServiceSettings.AutoconfigRandomPort = dto.AutoconfigRandomPort
+
+	// Update proxy settings (validated by config)
+	if err := cfg.SetProxy(dto.ProxyEnabled, dto.ProxyAddress); err != nil {
+		return err
+	}
 
 	return nil
 }
@@ -112,14 +125,51 @@ func setPeerEnabled(cfg *core.Config, address string, enabled bool) error {
 	return err
 }
 
+// SetProxy sets the global SOCKS5 proxy for peer connections
+// Changes take effect on the next service start or peer hot-reload
+func SetProxy(cfg *core.Config, enabled bool, address string) error {
+	if cfg == nil {
+		return fmt.Errorf("config not initialized")
+	}
+
+	return cfg.SetProxy(enabled, address)
+}
+
+// SetPeerBypassProxy sets whether a peer connects directly instead of through the proxy
+func SetPeerBypassProxy(cfg *core.Config, address string, bypass bool) error {
+	if cfg == nil {
+		return fmt.Errorf("config not initialized")
+	}
+
+	return cfg.SetPeerBypassProxy(address, bypass)
+}
+
+// TestProxy checks that the given SOCKS5 proxy is reachable
+func TestProxy(address string) models.ProxyTestResultDTO {
+	result := core.TestProxy(address)
+	if !result.Reachable {
+		log.Printf("Proxy test failed for %s: %s", address, result.ErrorMessage)
+	}
+
+	return models.ProxyTestResultDTO{
+		Reachable:    result.Reachable,
+		LatencyMs:    result.LatencyMs,
+		ErrorMessage: result.ErrorMessage,
+	}
+}
+
 // SetPassword sets the yggmail password
 // Password is stored securely in the OS keyring
 // Note: During onboarding, serviceManager doesn't exist yet.
 // The password will be set in yggmail database on first Initialize() call.
+// Only allowed during onboarding; afterwards ChangePassword verifies the current one
 func SetPassword(cfg *core.Config, sm *core.ServiceManager, password string) error {
 	if cfg == nil {
 		return fmt.Errorf("config not initialized")
 	}
+	if cfg.OnboardingComplete {
+		return fmt.Errorf("Password is already set. Please use Change Password instead.")
+	}
 
 	if password == "" {
 		return fmt.Errorf("password cannot be empty")
@@ -226,13 +276,8 @@ func ChangePassword(cfg *core.Config, sm *core.ServiceManager, currentPassword,
 	}
 
 	// Verify current password by comparing with stored password
-	storedPassword, err := cfg.GetPassword()
-	if err != nil {
-		return fmt.Errorf("Failed to retrieve password from keyring. Please try again or restart the application.")
-	}
-
-	if storedPassword != currentPassword {
-		return fmt.Errorf("Current password is incorrect. Please check your password and try again.")
+	if err := VerifyStoredPassword(cfg, currentPassword, "Current password is incorrect. Please check your password and try again."); err != nil {
+		return err
 	}
 
 	// Update password using service manager
@@ -252,6 +297,7 @@ func ChangePassword(cfg *core.Config, sm *core.ServiceManager, currentPassword,
 
 // RegenerateKeys regenerates Yggdrasil keys by deleting the database and reinitializing
 // WARNING: This will delete ALL mail data and change the email address
+// (identity.RotateIdentity changes the address but keeps the mail)
 // Requires password verification for security
 func RegenerateKeys(cfg *core.Config, sm *core.ServiceManager, password string) error {
 	if cfg == nil {
@@ -267,13 +313,8 @@ func RegenerateKeys(cfg *core.Config, sm *core.ServiceManager, password string)
 	}
 
 	// Verify password before allowing destructive operation
-	storedPassword, err := cfg.GetPassword()
-	if err != nil {
-		return fmt.Errorf("Failed to retrieve password from keyring. Please try again or restart the application.")
-	}
-
-	if storedPassword != password {
-		return fmt.Errorf("Password is incorrect. Please check your password and try again.")
+	if err := VerifyStoredPassword(cfg, password, "Password is incorrect. Please check your password and try again."); err != nil {
+		return err
 	}
 
 	// Stop service if running
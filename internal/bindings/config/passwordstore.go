@@ -0,0 +1,98 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/JB-SelfCompany/Tyr-Desktop/internal/core"
+	"github.com/JB-SelfCompany/Tyr-Desktop/internal/models"
+)
+
+// GetPasswordStoreStatus returns how the fallback password file is protected
+func GetPasswordStoreStatus() models.PasswordStoreStatusDTO {
+	status := core.GetPasswordStoreStatus()
+	return models.PasswordStoreStatusDTO{
+		InUse:               status.InUse,
+		MasterPassphrase:    status.MasterPassphrase,
+		Unlocked:            status.Unlocked,
+		MinPassphraseLength: core.MinMasterPassphraseLength,
+	}
+}
+
+// UnlockPasswordStore unlocks the fallback password file for this session
+func UnlockPasswordStore(passphrase string) error {
+	if err := core.UnlockPasswordStore(passphrase); err != nil {
+		return masterPassphraseError("Failed to unlock password store", err)
+	}
+	return nil
+}
+
+// EnableMasterPassphrase protects the fallback password file with a master passphrase
+// The existing machine-ID encrypted file is migrated
+func EnableMasterPassphrase(passphrase string) error {
+	if len(passphrase) < core.MinMasterPassphraseLength {
+		return fmt.Errorf("Passphrase must be at least %d characters.", core.MinMasterPassphraseLength)
+	}
+	if !core.GetPasswordStoreStatus().InUse {
+		return fmt.Errorf("Password is stored in the system keyring. A master passphrase is only used when the keyring is unavailable.")
+	}
+
+	if err := core.EnableMasterPassphrase(passphrase); err != nil {
+		return fmt.Errorf("Failed to enable master passphrase. Error: %v", err)
+	}
+	return nil
+}
+
+// DisableMasterPassphrase moves the password back to the machine-ID encrypted file
+func DisableMasterPassphrase(passphrase string) error {
+	if err := core.DisableMasterPassphrase(passphrase); err != nil {
+		return masterPassphraseError("Failed to disable master passphrase", err)
+	}
+	return nil
+}
+
+// ChangeMasterPassphrase replaces the master passphrase
+func ChangeMasterPassphrase(currentPassphrase, newPassphrase string) error {
+	if len(newPassphrase) < core.MinMasterPassphraseLength {
+		return fmt.Errorf("Passphrase must be at least %d characters.", core.MinMasterPassphraseLength)
+	}
+
+	if err := core.ChangeMasterPassphrase(currentPassphrase, newPassphrase); err != nil {
+		return masterPassphraseError("Failed to change master passphrase", err)
+	}
+	return nil
+}
+
+// masterPassphraseError formats a password store error, with a friendly message for a wrong passphrase
+func masterPassphraseError(action string, err error) error {
+	if errors.Is(err, core.ErrWrongMasterPassphrase) {
+		return fmt.Errorf("Passphrase is incorrect. Please check your passphrase and try again.")
+	}
+	return fmt.Errorf("%s. Error: %v", action, err)
+}
+
+// VerifyStoredPassword checks a password against the stored one
+// Failed attempts are delayed and locked out; incorrect is the message for a wrong password
+func VerifyStoredPassword(cfg *core.Config, password, incorrect string) error {
+	err := cfg.VerifyPassword(password)
+
+	var locked *core.AttemptLockedError
+	if errors.As(err, &locked) {
+		return fmt.Errorf("Too many failed attempts. Please try again in %s.", core.FormatRetryAfter(locked.RetryAfter))
+	}
+	if errors.Is(err, core.ErrIncorrectPassword) {
+		return fmt.Errorf("%s", incorrect)
+	}
+	if err != nil {
+		return retrievePasswordError(err)
+	}
+	return nil
+}
+
+// retrievePasswordError formats a failure to read the stored password
+func retrievePasswordError(err error) error {
+	if errors.Is(err, core.ErrPasswordStoreLocked) {
+		return fmt.Errorf("Password store is locked. Please enter your master passphrase first.")
+	}
+	return fmt.Errorf("Failed to retrieve password from keyring. Please try again or restart the application.")
+}
@@ -0,0 +1,190 @@
+package contacts
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+
+	"github.com/JB-SelfCompany/Tyr-Desktop/internal/core"
+	"github.com/JB-SelfCompany/Tyr-Desktop/internal/models"
+)
+
+// ListContacts returns all contacts sorted by display name
+func ListContacts(store *core.ContactStore) []models.ContactDTO {
+	if store == nil {
+		return []models.ContactDTO{}
+	}
+
+	list := store.List()
+	result := make([]models.ContactDTO, 0, len(list))
+	for _, c := range list {
+		result = append(result, convertContact(c))
+	}
+	return result
+}
+
+// AddContact adds a new contact
+func AddContact(store *core.ContactStore, dto models.ContactDTO) error {
+	if store == nil {
+		return fmt.Errorf("Contacts are not available. Please restart the application.")
+	}
+
+	if !core.IsValidMailAddress(dto.Address) {
+		return fmt.Errorf("Invalid address. A yggmail address is 64 hexadecimal characters followed by @yggmail.")
+	}
+
+	if err := store.Add(core.Contact{
+		DisplayName: dto.DisplayName,
+		Address:     dto.Address,
+		Notes:       dto.Notes,
+		Verified:    dto.Verified,
+	}); err != nil {
+		return fmt.Errorf("Failed to add contact. Error: %v", err)
+	}
+	return nil
+}
+
+// UpdateContact updates the name, notes and verified flag of an existing contact
+func UpdateContact(store *core.ContactStore, dto models.ContactDTO) error {
+	if store == nil {
+		return fmt.Errorf("Contacts are not available. Please restart the application.")
+	}
+
+	if err := store.Update(core.Contact{
+		DisplayName: dto.DisplayName,
+		Address:     dto.Address,
+		Notes:       dto.Notes,
+		Verified:    dto.Verified,
+	}); err != nil {
+		return fmt.Errorf("Failed to update contact. Error: %v", err)
+	}
+	return nil
+}
+
+// RemoveContact deletes a contact
+func RemoveContact(store *core.ContactStore, address string) error {
+	if store == nil {
+		return fmt.Errorf("Contacts are not available. Please restart the application.")
+	}
+
+	if err := store.Remove(address); err != nil {
+		return fmt.Errorf("Failed to remove contact. Error: %v", err)
+	}
+	return nil
+}
+
+// SetContactVerified marks a contact as verified or unverified
+func SetContactVerified(store *core.ContactStore, address string, verified bool) error {
+	if store == nil {
+		return fmt.Errorf("Contacts are not available. Please restart the application.")
+	}
+
+	if err := store.SetVerified(address, verified); err != nil {
+		return fmt.Errorf("Failed to update contact. Error: %v", err)
+	}
+	return nil
+}
+
+// ImportVCard imports contacts from a vCard file
+// Shows an open file dialog if path is empty
+func ImportVCard(ctx context.Context, store *core.ContactStore, path string) (models.ContactImportResultDTO, error) {
+	if store == nil {
+		return models.ContactImportResultDTO{Success: false, Message: "Contacts are not available. Please restart the application."}, nil
+	}
+
+	if path == "" {
+		if ctx == nil {
+			return models.ContactImportResultDTO{Success: false, Message: "Application context is not initialized"}, nil
+		}
+		selection, err := runtime.OpenFileDialog(ctx, runtime.OpenDialogOptions{
+			Title: "Import Contacts",
+			Filters: []runtime.FileFilter{
+				{DisplayName: "vCard (*.vcf)", Pattern: "*.vcf;*.vcard"},
+			},
+		})
+		if err != nil {
+			return models.ContactImportResultDTO{Success: false, Message: fmt.Sprintf("Failed to select file: %v", err)}, nil
+		}
+		if selection == "" {
+			return models.ContactImportResultDTO{Success: false, Message: "No file selected"}, nil
+		}
+		path = selection
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return models.ContactImportResultDTO{Success: false, Message: fmt.Sprintf("Failed to read file: %v", err)}, nil
+	}
+
+	parsed, err := core.DecodeVCards(data)
+	if err != nil {
+		return models.ContactImportResultDTO{Success: false, Message: fmt.Sprintf("Failed to parse vCard file: %v", err)}, nil
+	}
+
+	result, err := store.Import(parsed)
+	if err != nil {
+		return models.ContactImportResultDTO{Success: false, Message: fmt.Sprintf("Failed to save contacts: %v", err)}, nil
+	}
+
+	log.Printf("[ImportVCard] Imported contacts from %s: %d added, %d updated, %d skipped", path, result.Added, result.Updated, result.Skipped)
+
+	return models.ContactImportResultDTO{
+		Success: true,
+		Message: fmt.Sprintf("Imported %d contacts", result.Added+result.Updated),
+		Added:   result.Added,
+		Updated: result.Updated,
+		Skipped: result.Skipped,
+		Errors:  result.Errors,
+	}, nil
+}
+
+// ExportVCard exports all contacts to a vCard 4.0 file
+// Shows a save file dialog if path is empty
+func ExportVCard(ctx context.Context, store *core.ContactStore, path string) (models.ResultDTO, error) {
+	if store == nil {
+		return models.ResultDTO{Success: false, Message: "Contacts are not available. Please restart the application."}, nil
+	}
+
+	list := store.List()
+	if len(list) == 0 {
+		return models.ResultDTO{Success: false, Message: "There are no contacts to export"}, nil
+	}
+
+	if path == "" {
+		if ctx == nil {
+			return models.ResultDTO{Success: false, Message: "Application context is not initialized"}, nil
+		}
+		selection, err := runtime.SaveFileDialog(ctx, runtime.SaveDialogOptions{
+			Title:           "Export Contacts",
+			DefaultFilename: "tyr-contacts.vcf",
+		})
+		if err != nil {
+			return models.ResultDTO{Success: false, Message: fmt.Sprintf("Failed to show save dialog: %v", err)}, nil
+		}
+		if selection == "" {
+			return models.ResultDTO{Success: false, Message: "Export cancelled by user"}, nil
+		}
+		path = selection
+	}
+
+	if err := os.WriteFile(path, core.EncodeVCards(list), 0600); err != nil {
+		return models.ResultDTO{Success: false, Message: fmt.Sprintf("Failed to write file: %v", err)}, nil
+	}
+
+	return models.ResultDTO{Success: true, Message: fmt.Sprintf("Exported %d contacts", len(list)), Data: path}, nil
+}
+
+// convertContact converts a contact to its DTO
+func convertContact(c core.Contact) models.ContactDTO {
+	return models.ContactDTO{
+		DisplayName: c.DisplayName,
+		Address:     c.Address,
+		Notes:       c.Notes,
+		DateAdded:   c.DateAdded.Format(time.RFC3339),
+		Verified:    c.Verified,
+	}
+}
@@ -15,13 +15,24 @@ type EventEmitter func(eventName string, data interface{})
 // StatusUpdater is a callback function that updates the system tray status
 type StatusUpdater func()
 
+// MailNotifier is a callback function that handles new mail notifications
+// It may annotate the DTO (e.g., the notification action) before it is emitted
+type MailNotifier func(dto *models.MailEventDTO)
+
 // StartEventMonitoring monitors backend event channels and forwards events to frontend
 // This goroutine runs in the background and stops when shutdownChan is closed
+// Sent/error mail events update outbox tracking (may be nil); mail and connection events
+// are passed to user-defined hooks (may be nil); addresses are resolved
+// against contacts (may be nil) and passed to notifyFunc (may be nil) before emitting
 // Returns a boolean channel that will be closed when monitoring stops
 func StartEventMonitoring(
 	sm *core.ServiceManager,
+	contacts *core.ContactStore,
+	outbox *core.OutboxStore,
+	hooks *core.HookRunner,
 	emitFunc EventEmitter,
 	updateStatusFunc StatusUpdater,
+	notifyFunc MailNotifier,
 	shutdownChan <-chan struct{},
 ) {
 	if sm == nil {
@@ -58,7 +69,17 @@ func StartEventMonitoring(
 				log.Println("Mail event channel closed")
 				return
 			}
+			if outbox != nil {
+				if err := outbox.HandleMailEvent(mailEvent); err != nil {
+					log.Printf("Failed to track mail event: %v", err)
+				}
+			}
+			hooks.Dispatch(core.NewMailHookEvent(mailEvent))
 			dto := ConvertMailEvent(mailEvent)
+			ResolveMailEventContacts(&dto, contacts)
+			if notifyFunc != nil {
+				notifyFunc(&dto)
+			}
 			emitFunc("service:mail", dto)
 
 		case connEvent, ok := <-eventChans.Connection:
@@ -66,6 +87,7 @@ func StartEventMonitoring(
 				log.Println("Connection event channel closed")
 				return
 			}
+			hooks.Dispatch(core.NewConnectionHookEvent(connEvent))
 			dto := ConvertConnectionEvent(connEvent)
 			emitFunc("service:connection", dto)
 
@@ -95,12 +117,23 @@ func ConvertMailEvent(event yggmail.MailEvent) models.MailEventDTO {
 		Mailbox:      event.Mailbox,
 		From:         event.From,
 		To:           event.To,
+		FromDisplay:  event.From,
+		ToDisplay:    event.To,
 		Subject:      event.Subject,
 		MailID:       event.MailID,
 		ErrorMessage: event.ErrorMessage,
 	}
 }
 
+// ResolveMailEventContacts fills FromName/ToName and the "Name <address>" display
+// forms for known contacts. From/To keep the raw addresses for the event handlers
+func ResolveMailEventContacts(dto *models.MailEventDTO, contacts *core.ContactStore) {
+	dto.FromName = contacts.ResolveName(dto.From)
+	dto.ToName = contacts.ResolveName(dto.To)
+	dto.FromDisplay = contacts.FormatAddress(dto.From)
+	dto.ToDisplay = contacts.FormatAddress(dto.To)
+}
+
 // ConvertConnectionEvent converts yggmail.ConnectionEvent to ConnectionEventDTO
 func ConvertConnectionEvent(event yggmail.ConnectionEvent) models.ConnectionEventDTO {
 	return models.ConnectionEventDTO{
@@ -121,6 +154,7 @@ func formatTimestamp(t time.Time) string {
 // - "service:mail"       -> MailEventDTO
 // - "service:connection" -> ConnectionEventDTO
 // - "service:status"     -> string (status name)
+// - "notification:show"  -> NotificationDTO (when native notifications are unavailable)
 //
 // Frontend can subscribe to these events using:
 // import { EventsOn } from '../wailsjs/runtime/runtime';
@@ -0,0 +1,105 @@
+package hooks
+
+import (
+	"fmt"
+
+	"github.com/JB-SelfCompany/Tyr-Desktop/internal/core"
+	"github.com/JB-SelfCompany/Tyr-Desktop/internal/models"
+)
+
+// GetHookSettings returns the user-defined hooks
+func GetHookSettings(cfg *core.Config) models.HookSettingsDTO {
+	if cfg == nil {
+		return models.HookSettingsDTO{Hooks: []models.HookDTO{}}
+	}
+
+	settings := cfg.GetHookSettings()
+	dto := models.HookSettingsDTO{
+		Enabled: settings.Enabled,
+		Hooks:   make([]models.HookDTO, 0, len(settings.Hooks)),
+	}
+	for _, h := range settings.Hooks {
+		hook := models.HookDTO{
+			Name:           h.Name,
+			Enabled:        h.Enabled,
+			Events:         h.Events,
+			Command:        h.Command,
+			Args:           h.Args,
+			URL:            h.URL,
+			TimeoutSeconds: h.TimeoutSeconds,
+			MaxConcurrent:  h.MaxConcurrent,
+		}
+		if hook.Events == nil {
+			hook.Events = []string{}
+		}
+		if hook.Args == nil {
+			hook.Args = []string{}
+		}
+		dto.Hooks = append(dto.Hooks, hook)
+	}
+	return dto
+}
+
+// SaveHookSettings validates and saves the user-defined hooks
+func SaveHookSettings(cfg *core.Config, dto models.HookSettingsDTO) error {
+	if cfg == nil {
+		return fmt.Errorf("config not initialized")
+	}
+
+	settings := core.HookSettings{
+		Enabled: dto.Enabled,
+		Hooks:   make([]core.Hook, 0, len(dto.Hooks)),
+	}
+	for _, h := range dto.Hooks {
+		settings.Hooks = append(settings.Hooks, core.Hook{
+			Name:           h.Name,
+			Enabled:        h.Enabled,
+			Events:         h.Events,
+			Command:        h.Command,
+			Args:           h.Args,
+			URL:            h.URL,
+			TimeoutSeconds: h.TimeoutSeconds,
+			MaxConcurrent:  h.MaxConcurrent,
+		})
+	}
+
+	if err := cfg.SetHookSettings(settings); err != nil {
+		return fmt.Errorf("Invalid hook settings: %v", err)
+	}
+
+	if err := cfg.Save(); err != nil {
+		return fmt.Errorf("failed to save config: %w", err)
+	}
+	return nil
+}
+
+// TestHook runs a saved hook once with a test event and returns the result
+func TestHook(runner *core.HookRunner, name string) (models.HookRunResultDTO, error) {
+	if runner == nil {
+		return models.HookRunResultDTO{}, fmt.Errorf("config not initialized")
+	}
+
+	result, err := runner.Test(name)
+	if err != nil {
+		return models.HookRunResultDTO{}, fmt.Errorf("Failed to run hook. Error: %v", err)
+	}
+
+	return models.HookRunResultDTO{
+		Hook:       result.Hook,
+		Event:      result.Event,
+		Success:    result.Success,
+		ExitCode:   result.ExitCode,
+		DurationMs: result.Duration.Milliseconds(),
+		Output:     result.Output,
+		Error:      result.Error,
+	}, nil
+}
+
+// GetHookLog returns the last lines of a hook's log, oldest first
+func GetHookLog(name string, limit int) ([]string, error) {
+	lines, err := core.ReadHookLog(name, limit)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to read hook log. Error: %v", err)
+	}
+	return lines, nil
+}
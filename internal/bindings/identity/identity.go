@@ -0,0 +1,294 @@
+package identity
+
+import (
+	"context"
+	"crypto/ed25519"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/JB-SelfCompany/Tyr-Desktop/internal/bindings/config"
+	"github.com/JB-SelfCompany/Tyr-Desktop/internal/bindings/storage"
+	"github.com/JB-SelfCompany/Tyr-Desktop/internal/bindings/system"
+	"github.com/JB-SelfCompany/Tyr-Desktop/internal/core"
+	"github.com/JB-SelfCompany/Tyr-Desktop/internal/models"
+)
+
+// ExportIdentityFile writes the identity key to a passphrase-encrypted file
+// Asks for the destination if dto.Path is empty. Requires the mail password
+func ExportIdentityFile(ctx context.Context, cfg *core.Config, vault *core.StorageVault, dto models.ExportIdentityDTO) (models.ResultDTO, error) {
+	if len(dto.Passphrase) < core.MinIdentityPassphraseLength {
+		return models.ResultDTO{Success: false, Message: fmt.Sprintf("Passphrase must be at least %d characters.", core.MinIdentityPassphraseLength)}, nil
+	}
+
+	key, err := readIdentity(cfg, vault, dto.Password)
+	if err != nil {
+		return models.ResultDTO{Success: false, Message: err.Error()}, nil
+	}
+
+	path := dto.Path
+	if path == "" {
+		path, err = system.ShowSaveFileDialog(ctx, "Export Identity", "tyr-identity-"+time.Now().Format("2006-01-02")+core.IdentityFileExtension)
+		if err != nil {
+			return models.ResultDTO{Success: false, Message: fmt.Sprintf("Failed to select file: %v", err)}, nil
+		}
+		if path == "" {
+			return models.ResultDTO{Success: false, Message: "No file selected"}, nil
+		}
+	}
+
+	data, err := core.EncryptIdentity(key, dto.Passphrase)
+	if err != nil {
+		return models.ResultDTO{Success: false, Message: fmt.Sprintf("Failed to encrypt identity: %v", err)}, nil
+	}
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		return models.ResultDTO{Success: false, Message: fmt.Sprintf("Failed to write identity file: %v", err)}, nil
+	}
+
+	return models.ResultDTO{Success: true, Message: "Identity exported successfully", Data: path}, nil
+}
+
+// GetIdentityMnemonic returns the identity key as a 24-word recovery phrase
+// Requires the mail password
+func GetIdentityMnemonic(cfg *core.Config, vault *core.StorageVault, password string) (models.IdentityMnemonicDTO, error) {
+	key, err := readIdentity(cfg, vault, password)
+	if err != nil {
+		return models.IdentityMnemonicDTO{}, err
+	}
+
+	mnemonic, err := core.IdentityMnemonic(key)
+	if err != nil {
+		return models.IdentityMnemonicDTO{}, fmt.Errorf("Failed to create recovery phrase. Error: %v", err)
+	}
+	return models.IdentityMnemonicDTO{
+		Address: core.IdentityAddress(key),
+		Words:   strings.Fields(mnemonic),
+	}, nil
+}
+
+// ImportIdentity installs an identity from an exported file or a recovery phrase
+// The service is closed while the key is written and restarted afterwards.
+// Existing mail is kept; replacing a different identity requires dto.Replace
+func ImportIdentity(cfg *core.Config, sm *core.ServiceManager, vault *core.StorageVault, dto models.ImportIdentityDTO) (models.ResultDTO, error) {
+	if cfg == nil {
+		return models.ResultDTO{Success: false, Message: "Config not initialized"}, nil
+	}
+	if vault != nil && vault.IsEnabled() && !vault.IsUnlocked() {
+		return models.ResultDTO{Success: false, Message: "Storage is locked. Please unlock it first."}, nil
+	}
+
+	var key ed25519.PrivateKey
+	var err error
+	switch {
+	case dto.Mnemonic != "":
+		key, err = core.IdentityFromMnemonic(dto.Mnemonic)
+	case dto.Path != "":
+		var data []byte
+		data, err = os.ReadFile(dto.Path)
+		if err == nil {
+			key, err = core.DecryptIdentity(data, dto.Passphrase)
+		}
+	default:
+		return models.ResultDTO{Success: false, Message: "Please select an identity file or enter a recovery phrase"}, nil
+	}
+	if err != nil {
+		return models.ResultDTO{Success: false, Message: fmt.Sprintf("Failed to read identity: %v", err)}, nil
+	}
+
+	dbPath := cfg.ServiceSettings.DatabasePath
+	err = storage.WithServiceClosed(sm, func() error {
+		if err := os.MkdirAll(filepath.Dir(dbPath), 0700); err != nil {
+			return fmt.Errorf("Failed to create data directory. Error: %v", err)
+		}
+		if err := core.WriteIdentityKey(dbPath, key, dto.Replace); err != nil {
+			if errors.Is(err, core.ErrIdentityExists) {
+				return fmt.Errorf("This installation already has a different identity. Export it first, then confirm replacing it.")
+			}
+			return fmt.Errorf("Failed to import identity. Error: %v", err)
+		}
+
+		// The password is stored in the database too; set it again on the next initialize
+		cfg.ServiceSettings.PasswordInitialized = false
+		if err := cfg.Save(); err != nil {
+			return fmt.Errorf("failed to save config: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return models.ResultDTO{Success: false, Message: err.Error()}, nil
+	}
+
+	address := core.IdentityAddress(key)
+	return models.ResultDTO{Success: true, Message: "Identity imported successfully", Data: address}, nil
+}
+
+// readIdentity verifies the mail password and reads the identity key
+func readIdentity(cfg *core.Config, vault *core.StorageVault, password string) (ed25519.PrivateKey, error) {
+	if cfg == nil {
+		return nil, fmt.Errorf("config not initialized")
+	}
+	if vault != nil && vault.IsEnabled() && !vault.IsUnlocked() {
+		return nil, fmt.Errorf("Storage is locked. Please unlock it first.")
+	}
+	if password == "" {
+		return nil, fmt.Errorf("password cannot be empty")
+	}
+
+	if err := config.VerifyStoredPassword(cfg, password, "Password is incorrect. Please check your password and try again."); err != nil {
+		return nil, err
+	}
+
+	key, err := core.ReadIdentityKey(cfg.ServiceSettings.DatabasePath)
+	if errors.Is(err, core.ErrNoIdentity) {
+		return nil, fmt.Errorf("No identity has been created yet. Please start the service once first.")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("Failed to read identity. Error: %v", err)
+	}
+	return key, nil
+}
+
+// rotationQueueTimeout is how long rotation waits for queued mail, the moved
+// notices included, to be delivered from the old address
+const rotationQueueTimeout = 60 * time.Second
+
+// RotateIdentity replaces the identity with a new key, keeping all mailboxes and messages
+// If dto.SendNotice is set, a moved notice signed with the old key is first sent from
+// the old address to dto.Recipients, or to every contact if none are given.
+// The mail is exported over IMAP and imported into a new database holding the new key;
+// the old database is restored if the import fails. Mail the old identity couldn't
+// deliver in time is reported in the result. Requires a running service
+func RotateIdentity(
+	cfg *core.Config,
+	sm *core.ServiceManager,
+	vault *core.StorageVault,
+	contacts *core.ContactStore,
+	outbox *core.OutboxStore,
+	dto models.RotateIdentityDTO,
+) (models.RotateIdentityResultDTO, error) {
+	result := models.RotateIdentityResultDTO{NoticeErrors: []string{}, UndeliveredMessages: []string{}}
+
+	oldKey, err := readIdentity(cfg, vault, dto.Password)
+	if err != nil {
+		return result, err
+	}
+	if sm == nil || !sm.IsRunning() {
+		return result, fmt.Errorf("Service is not running. Please start it first, mail is moved to the new identity through it.")
+	}
+
+	_, newKey, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		return result, fmt.Errorf("Failed to generate key. Error: %v", err)
+	}
+	result.OldAddress = core.IdentityAddress(oldKey)
+	result.NewAddress = core.IdentityAddress(newKey)
+	dbPath := cfg.ServiceSettings.DatabasePath
+
+	// Sent while the old key is active, so contacts get it from the address they know
+	if dto.SendNotice {
+		sendMovedNotices(sm, contacts, outbox, oldKey, dto.Recipients, &result)
+	}
+	if _, err := core.WaitForQueue(dbPath, rotationQueueTimeout); err != nil {
+		log.Printf("[Identity] Warning: failed to check outgoing queue: %v", err)
+	}
+
+	exportDir := filepath.Join(filepath.Dir(dbPath), "rotation-export")
+	export, err := sm.ExportMail(exportDir)
+	if err != nil {
+		return result, fmt.Errorf("Failed to export mail, the identity was not changed. Error: %v", err)
+	}
+	defer os.RemoveAll(exportDir)
+
+	var pending []core.PendingDelivery
+	rotated := false
+	err = storage.WithServiceClosed(sm, func() error {
+		pending, err = core.RotateIdentityKey(dbPath, newKey)
+		if err != nil {
+			return fmt.Errorf("Failed to rotate identity. Error: %v", err)
+		}
+		rotated = true
+
+		// The password is stored in the database too; set it again on the next initialize
+		cfg.ServiceSettings.PasswordInitialized = false
+		if err := cfg.Save(); err != nil {
+			return fmt.Errorf("failed to save config: %w", err)
+		}
+		return nil
+	})
+	if !rotated {
+		return result, err
+	}
+
+	imported := 0
+	if err == nil {
+		imported, err = sm.ImportMail(export)
+	}
+	if err != nil {
+		log.Printf("[Identity] Failed to move mail to the new identity, restoring the old one: %v", err)
+		restoreErr := storage.WithServiceClosed(sm, func() error {
+			if err := core.RestoreRotatedDatabase(dbPath); err != nil {
+				return err
+			}
+			cfg.ServiceSettings.PasswordInitialized = false
+			return cfg.Save()
+		})
+		if restoreErr != nil {
+			return result, fmt.Errorf("Failed to move mail to the new identity and to restore the old one. The old database is kept in %s. Error: %v", core.RotatedDatabaseDir(dbPath), restoreErr)
+		}
+		return result, fmt.Errorf("Failed to move mail to the new identity, the identity was not changed. Error: %v", err)
+	}
+
+	if err := core.DiscardRotatedDatabase(dbPath); err != nil {
+		log.Printf("[Identity] Warning: failed to remove old database: %v", err)
+	}
+	if vault != nil {
+		vault.MarkChanged()
+	}
+
+	result.MessagesMoved = imported
+	for _, p := range pending {
+		subject := export.Subject(p.Mailbox, p.ID)
+		if subject == "" {
+			subject = "(no subject)"
+		}
+		result.UndeliveredMessages = append(result.UndeliveredMessages, fmt.Sprintf("%s: %s", p.Recipient, subject))
+	}
+	log.Printf("[Identity] Rotated identity from %s to %s, moved %d messages, %d undelivered",
+		result.OldAddress, result.NewAddress, imported, len(pending))
+	return result, nil
+}
+
+// sendMovedNotices queues a moved notice signed with oldKey for each recipient,
+// or for every contact if recipients is empty
+func sendMovedNotices(
+	sm *core.ServiceManager,
+	contacts *core.ContactStore,
+	outbox *core.OutboxStore,
+	oldKey ed25519.PrivateKey,
+	recipients []string,
+	result *models.RotateIdentityResultDTO,
+) {
+	if len(recipients) == 0 && contacts != nil {
+		for _, c := range contacts.List() {
+			recipients = append(recipients, c.Address)
+		}
+	}
+
+	// One message per recipient, so contacts don't see each other
+	notice := core.SignMovedNotice(oldKey, result.NewAddress, time.Now())
+	for _, recipient := range recipients {
+		recipient = core.NormalizeMailAddress(core.ExtractMailAddress(recipient))
+		if recipient == "" || recipient == result.OldAddress || recipient == result.NewAddress {
+			continue
+		}
+		if _, err := sm.SendMail(notice.Message(recipient), outbox, nil); err != nil {
+			result.NoticeErrors = append(result.NoticeErrors, fmt.Sprintf("%s: %v", recipient, err))
+			continue
+		}
+		result.NoticesSent++
+	}
+}
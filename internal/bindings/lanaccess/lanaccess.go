@@ -0,0 +1,114 @@
+package lanaccess
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/JB-SelfCompany/Tyr-Desktop/internal/bindings/system"
+	"github.com/JB-SelfCompany/Tyr-Desktop/internal/core"
+	"github.com/JB-SelfCompany/Tyr-Desktop/internal/models"
+)
+
+// GetLANAccessSettings returns the LAN access settings
+func GetLANAccessSettings(cfg *core.Config) models.LANAccessSettingsDTO {
+	if cfg == nil {
+		return models.LANAccessSettingsDTO{Allowlist: []string{}}
+	}
+
+	settings := cfg.GetLANAccessSettings()
+	dto := models.LANAccessSettingsDTO{
+		Enabled:                 settings.Enabled,
+		BindAddress:             settings.BindAddress,
+		TLSMode:                 settings.TLSMode,
+		SMTPPort:                settings.SMTPPort,
+		IMAPPort:                settings.IMAPPort,
+		Allowlist:               settings.Allowlist,
+		AdvertiseHost:           settings.AdvertiseHost,
+		StrictCertificateChecks: settings.StrictCertificateChecks,
+	}
+	if dto.Allowlist == nil {
+		dto.Allowlist = []string{}
+	}
+	return dto
+}
+
+// SaveLANAccessSettings validates and saves the LAN access settings
+// The TLS listeners and autoconfig server are restarted to apply them
+func SaveLANAccessSettings(cfg *core.Config, sm *core.ServiceManager, dto models.LANAccessSettingsDTO) error {
+	if cfg == nil {
+		return fmt.Errorf("config not initialized")
+	}
+
+	settings := core.LANAccessSettings{
+		Enabled:                 dto.Enabled,
+		BindAddress:             dto.BindAddress,
+		TLSMode:                 dto.TLSMode,
+		SMTPPort:                dto.SMTPPort,
+		IMAPPort:                dto.IMAPPort,
+		Allowlist:               dto.Allowlist,
+		AdvertiseHost:           dto.AdvertiseHost,
+		StrictCertificateChecks: dto.StrictCertificateChecks,
+	}
+	if err := cfg.SetLANAccessSettings(settings); err != nil {
+		return fmt.Errorf("Invalid LAN access settings: %v", err)
+	}
+
+	if err := cfg.Save(); err != nil {
+		return fmt.Errorf("failed to save config: %w", err)
+	}
+
+	if sm != nil {
+		if err := sm.ApplyLANAccessSettings(); err != nil {
+			return fmt.Errorf("Settings saved, but LAN access failed to start. Error: %v", err)
+		}
+	}
+	return nil
+}
+
+// GetLANAccessStatus returns the endpoints, security and certificate fingerprint
+// mail clients should use
+func GetLANAccessStatus(cfg *core.Config, sm *core.ServiceManager) models.LANAccessStatusDTO {
+	dto := models.LANAccessStatusDTO{}
+	if cfg != nil {
+		dto.Enabled = cfg.GetLANAccessSettings().Enabled
+	}
+	if sm == nil {
+		return dto
+	}
+
+	endpoints := sm.GetClientEndpoints()
+	dto.Running = sm.IsLANAccessRunning()
+	dto.IMAPHost = endpoints.IMAPHost
+	dto.IMAPPort = endpoints.IMAPPort
+	dto.SMTPHost = endpoints.SMTPHost
+	dto.SMTPPort = endpoints.SMTPPort
+	dto.Security = endpoints.Security
+	dto.Fingerprint = endpoints.Fingerprint
+	return dto
+}
+
+// ExportLocalCACertificate saves the local CA certificate for installing on client devices
+// The CA is created the first time LAN access starts
+func ExportLocalCACertificate(ctx context.Context) (models.ResultDTO, error) {
+	data, err := os.ReadFile(core.LocalCACertificatePath())
+	if os.IsNotExist(err) {
+		return models.ResultDTO{Success: false, Message: "No local CA yet. Enable LAN access and start the service first."}, nil
+	}
+	if err != nil {
+		return models.ResultDTO{Success: false, Message: fmt.Sprintf("Failed to read CA certificate: %v", err)}, nil
+	}
+
+	path, err := system.ShowSaveFileDialog(ctx, "Export CA Certificate", "tyr-local-ca.crt")
+	if err != nil {
+		return models.ResultDTO{Success: false, Message: fmt.Sprintf("Failed to select file: %v", err)}, nil
+	}
+	if path == "" {
+		return models.ResultDTO{Success: false, Message: "No file selected"}, nil
+	}
+
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return models.ResultDTO{Success: false, Message: fmt.Sprintf("Failed to write CA certificate: %v", err)}, nil
+	}
+	return models.ResultDTO{Success: true, Message: "CA certificate exported successfully", Data: path}, nil
+}
@@ -0,0 +1,273 @@
+package mail
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+
+	"github.com/JB-SelfCompany/Tyr-Desktop/internal/core"
+	"github.com/JB-SelfCompany/Tyr-Desktop/internal/mailclient"
+	"github.com/JB-SelfCompany/Tyr-Desktop/internal/models"
+)
+
+// SendMail composes and sends a message through the local SMTP listener
+// Emits "mail:send:progress" events while composing and submitting.
+// A successful result means the message was queued; the delivery outcome
+// arrives later as a "service:mail" event of type "sent" or "error" and in
+// the outbox tracking (see ListOutgoingMessages)
+func SendMail(ctx context.Context, sm *core.ServiceManager, outbox *core.OutboxStore, dto models.ComposeMailDTO) (models.ResultDTO, error) {
+	if sm == nil {
+		return models.ResultDTO{Success: false, Message: "Service manager is not initialized. Please restart the application."}, nil
+	}
+
+	emit := func(progress core.MailSendProgress) {
+		if ctx != nil {
+			runtime.EventsEmit(ctx, "mail:send:progress", progress)
+		}
+	}
+
+	msg := &mailclient.OutgoingMessage{
+		To:       dto.To,
+		Cc:       dto.Cc,
+		Subject:  dto.Subject,
+		TextBody: dto.TextBody,
+		HTMLBody: dto.HTMLBody,
+	}
+
+	// Load attachments from disk
+	for _, path := range dto.Attachments {
+		att, err := mailclient.LoadAttachment(path)
+		if err != nil {
+			return models.ResultDTO{Success: false, Message: fmt.Sprintf("Failed to attach file: %v", err)}, nil
+		}
+		msg.Attachments = append(msg.Attachments, att)
+	}
+
+	result, err := sm.SendMail(msg, outbox, emit)
+	if err != nil {
+		log.Printf("[SendMail] Error: %v", err)
+		return models.ResultDTO{Success: false, Message: fmt.Sprintf("Failed to send message: %v", err)}, nil
+	}
+
+	return models.ResultDTO{Success: true, Message: "Message queued for delivery", Data: result.MessageID}, nil
+}
+
+// ListOutgoingMessages returns recently sent messages with their delivery state, newest first
+// Pending messages are refreshed from the local IMAP server first when the service is running
+func ListOutgoingMessages(sm *core.ServiceManager, outbox *core.OutboxStore, limit int) ([]models.OutgoingMessageDTO, error) {
+	if outbox == nil {
+		return nil, fmt.Errorf("Outgoing message tracking is not available. Please restart the application.")
+	}
+
+	if sm != nil && sm.IsRunning() {
+		if changed, err := sm.RefreshOutbox(outbox); err != nil {
+			// Stale state is still useful, don't fail the listing
+			log.Printf("[ListOutgoingMessages] Warning: failed to refresh delivery state: %v", err)
+		} else if changed > 0 {
+			log.Printf("[ListOutgoingMessages] Updated delivery state of %d message(s)", changed)
+		}
+	}
+
+	records := outbox.List(limit)
+	result := make([]models.OutgoingMessageDTO, 0, len(records))
+	for i := range records {
+		result = append(result, convertOutgoingRecord(&records[i]))
+	}
+	return result, nil
+}
+
+// ClearDeliveredMessages removes delivered messages from the outgoing message history
+func ClearDeliveredMessages(outbox *core.OutboxStore) error {
+	if outbox == nil {
+		return fmt.Errorf("Outgoing message tracking is not available. Please restart the application.")
+	}
+	if err := outbox.Clear(); err != nil {
+		return fmt.Errorf("Failed to clear delivered messages. Error: %v", err)
+	}
+	return nil
+}
+
+// ListMailboxes returns all mailboxes with message and unread counts
+func ListMailboxes(sm *core.ServiceManager) ([]models.MailboxDTO, error) {
+	if sm == nil {
+		return nil, fmt.Errorf("Service manager is not initialized. Please restart the application.")
+	}
+
+	mailboxes, err := sm.ListMailboxes()
+	if err != nil {
+		return nil, fmt.Errorf("Failed to list mailboxes. Error: %v", err)
+	}
+
+	result := make([]models.MailboxDTO, 0, len(mailboxes))
+	for _, mbox := range mailboxes {
+		result = append(result, models.MailboxDTO{
+			Name:     mbox.Name,
+			Messages: mbox.Messages,
+			Unseen:   mbox.Unseen,
+		})
+	}
+	return result, nil
+}
+
+// ListMessages returns one page of message headers from a mailbox, newest first
+// Page is zero-based; a pageSize of 0 uses the default page size
+func ListMessages(sm *core.ServiceManager, mailbox string, page int, pageSize int) (models.MessagePageDTO, error) {
+	if sm == nil {
+		return models.MessagePageDTO{}, fmt.Errorf("Service manager is not initialized. Please restart the application.")
+	}
+
+	result, err := sm.ListMessages(mailbox, page, pageSize)
+	if err != nil {
+		return models.MessagePageDTO{}, fmt.Errorf("Failed to list messages. Error: %v", err)
+	}
+
+	dto := models.MessagePageDTO{
+		Mailbox:  result.Mailbox,
+		Total:    result.Total,
+		Page:     result.Page,
+		PageSize: result.PageSize,
+		Messages: make([]models.MessageHeaderDTO, 0, len(result.Messages)),
+	}
+	for i := range result.Messages {
+		dto.Messages = append(dto.Messages, convertHeader(&result.Messages[i]))
+	}
+	return dto, nil
+}
+
+// GetMessage returns a message with its bodies and MIME tree
+// Reading a message does not mark it as seen
+func GetMessage(sm *core.ServiceManager, mailbox string, uid uint32) (models.MessageDTO, error) {
+	if sm == nil {
+		return models.MessageDTO{}, fmt.Errorf("Service manager is not initialized. Please restart the application.")
+	}
+
+	msg, err := sm.GetMessage(mailbox, uid)
+	if err != nil {
+		return models.MessageDTO{}, fmt.Errorf("Failed to load message. Error: %v", err)
+	}
+
+	dto := models.MessageDTO{
+		Header:      convertHeader(&msg.Header),
+		TextBody:    msg.TextBody,
+		HTMLBody:    msg.HTMLBody,
+		Structure:   convertPart(msg.Structure),
+		Attachments: make([]models.MessagePartDTO, 0, len(msg.Attachments)),
+	}
+	for _, att := range msg.Attachments {
+		dto.Attachments = append(dto.Attachments, convertPart(att))
+	}
+	return dto, nil
+}
+
+// SaveAttachment downloads a message part and saves it to disk
+// If destPath is empty, a save dialog is shown with the attachment filename
+func SaveAttachment(ctx context.Context, sm *core.ServiceManager, mailbox string, uid uint32, partID string, destPath string) (models.ResultDTO, error) {
+	if sm == nil {
+		return models.ResultDTO{Success: false, Message: "Service manager is not initialized. Please restart the application."}, nil
+	}
+
+	att, err := sm.GetMessagePart(mailbox, uid, partID)
+	if err != nil {
+		log.Printf("[SaveAttachment] Error: %v", err)
+		return models.ResultDTO{Success: false, Message: fmt.Sprintf("Failed to download attachment: %v", err)}, nil
+	}
+
+	if destPath == "" {
+		if ctx == nil {
+			return models.ResultDTO{Success: false, Message: "Application context is not initialized"}, nil
+		}
+		destPath, err = runtime.SaveFileDialog(ctx, runtime.SaveDialogOptions{
+			Title:           "Save Attachment",
+			DefaultFilename: att.Filename,
+		})
+		if err != nil {
+			return models.ResultDTO{Success: false, Message: fmt.Sprintf("Failed to show save dialog: %v", err)}, nil
+		}
+		if destPath == "" {
+			return models.ResultDTO{Success: false, Message: "Save cancelled by user"}, nil
+		}
+	}
+
+	if err := os.WriteFile(destPath, att.Data, 0644); err != nil {
+		return models.ResultDTO{Success: false, Message: fmt.Sprintf("Failed to save attachment: %v", err)}, nil
+	}
+
+	return models.ResultDTO{Success: true, Message: "Attachment saved", Data: destPath}, nil
+}
+
+// convertHeader converts a message header to its DTO
+func convertHeader(h *mailclient.MessageHeader) models.MessageHeaderDTO {
+	date := h.Date
+	if date.IsZero() {
+		date = h.InternalDate
+	}
+
+	return models.MessageHeaderDTO{
+		UID:       h.UID,
+		MessageID: h.MessageID,
+		Subject:   h.Subject,
+		From:      h.From,
+		To:        h.To,
+		Cc:        h.Cc,
+		Date:      date.Format(time.RFC3339),
+		SizeBytes: h.Size,
+		Seen:      h.Seen(),
+		Flags:     h.Flags,
+	}
+}
+
+// convertPart converts a MIME tree node to its DTO
+func convertPart(p mailclient.MessagePart) models.MessagePartDTO {
+	dto := models.MessagePartDTO{
+		ID:          p.ID,
+		ContentType: p.ContentType,
+		Filename:    p.Filename,
+		Disposition: p.Disposition,
+		SizeBytes:   p.Size,
+	}
+	for _, child := range p.Children {
+		dto.Children = append(dto.Children, convertPart(child))
+	}
+	return dto
+}
+
+// convertOutgoingRecord converts a tracked outgoing message to a DTO
+func convertOutgoingRecord(r *core.OutgoingRecord) models.OutgoingMessageDTO {
+	dto := models.OutgoingMessageDTO{
+		Key:       r.Key,
+		MessageID: r.MessageID,
+		To:        r.To,
+		Subject:   r.Subject,
+		SizeBytes: r.SizeBytes,
+		State:     r.State,
+		Error:     r.Error,
+		Attempts:  r.Attempts,
+		CreatedAt: r.CreatedAt.Format(time.RFC3339),
+		UpdatedAt: r.UpdatedAt.Format(time.RFC3339),
+		History:   make([]models.DeliveryTransitionDTO, 0, len(r.History)),
+	}
+	if dto.To == nil {
+		dto.To = []string{}
+	}
+	dto.Recipients = make([]models.RecipientDeliveryDTO, 0, len(dto.To))
+	for _, addr := range dto.To {
+		recipient := models.RecipientDeliveryDTO{Address: addr, State: r.State}
+		if d, ok := r.Recipients[addr]; ok {
+			recipient.State = d.State
+			recipient.Error = d.Error
+		}
+		dto.Recipients = append(dto.Recipients, recipient)
+	}
+	for _, t := range r.History {
+		dto.History = append(dto.History, models.DeliveryTransitionDTO{
+			State:  t.State,
+			Time:   t.Time.Format(time.RFC3339),
+			Reason: t.Reason,
+		})
+	}
+	return dto
+}
@@ -0,0 +1,175 @@
+package notifications
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/JB-SelfCompany/Tyr-Desktop/internal/core"
+	"github.com/JB-SelfCompany/Tyr-Desktop/internal/models"
+	"github.com/JB-SelfCompany/Tyr-Desktop/internal/notify"
+	"github.com/JB-SelfCompany/Tyr-Desktop/internal/ui/i18n"
+)
+
+// EventEmitter is a callback function that emits events to the frontend
+type EventEmitter func(eventName string, data interface{})
+
+// DispatchMailNotification applies notification rules to a new_mail event
+// Sets dto.Notification to the decided action and shows a native notification
+// if available; otherwise emits "notification:show" for the frontend to display
+func DispatchMailNotification(
+	cfg *core.Config,
+	contacts *core.ContactStore,
+	notifier notify.Notifier,
+	emitFunc EventEmitter,
+	dto *models.MailEventDTO,
+) {
+	if cfg == nil || dto == nil || dto.Type != "new_mail" {
+		return
+	}
+
+	sender := dto.From
+	input := core.NotificationInput{
+		From:    sender,
+		Mailbox: dto.Mailbox,
+		Subject: dto.Subject,
+	}
+	if contacts != nil {
+		if contact, ok := contacts.Get(sender); ok {
+			input.IsContact = true
+			input.IsVerified = contact.Verified
+		}
+	}
+
+	decision := cfg.EvaluateNotification(input, time.Now())
+	dto.Notification = decision.Action
+	if decision.Action == core.NotificationActionSuppress {
+		log.Printf("[Notifications] Suppressed new mail notification (%s)", describeDecision(decision))
+		return
+	}
+
+	localizer := i18n.GetGlobalLocalizer()
+	senderName := dto.FromName
+	if senderName == "" {
+		senderName = core.ShortMailAddress(sender)
+	}
+	subject := dto.Subject
+	if subject == "" {
+		subject = localizer.Get("notification.no_subject")
+	}
+
+	notification := notify.Notification{
+		Title:     fmt.Sprintf(localizer.Get("notification.new_mail"), senderName),
+		Body:      subject,
+		Silent:    decision.Action == core.NotificationActionSilent,
+		OpenLabel: localizer.Get("notification.open"),
+	}
+
+	if notifier != nil {
+		err := notifier.Notify(notification)
+		if err == nil {
+			return
+		}
+		log.Printf("[Notifications] Native notification failed, falling back to in-app: %v", err)
+	}
+
+	if emitFunc != nil {
+		emitFunc("notification:show", models.NotificationDTO{
+			Title:  notification.Title,
+			Body:   notification.Body,
+			Silent: notification.Silent,
+		})
+	}
+}
+
+// GetNotificationSettings returns the notification settings
+func GetNotificationSettings(cfg *core.Config) models.NotificationSettingsDTO {
+	if cfg == nil {
+		return models.NotificationSettingsDTO{Rules: []models.NotificationRuleDTO{}}
+	}
+
+	settings := cfg.GetNotificationSettings()
+	dto := models.NotificationSettingsDTO{
+		DoNotDisturb:      settings.DoNotDisturb,
+		QuietHoursEnabled: settings.QuietHours.Enabled,
+		QuietHoursStart:   settings.QuietHours.Start,
+		QuietHoursEnd:     settings.QuietHours.End,
+		DefaultAction:     settings.DefaultAction,
+		Rules:             make([]models.NotificationRuleDTO, 0, len(settings.Rules)),
+	}
+	for _, rule := range settings.Rules {
+		dto.Rules = append(dto.Rules, models.NotificationRuleDTO{
+			Name:           rule.Name,
+			Enabled:        rule.Enabled,
+			Sender:         rule.Sender,
+			Contact:        rule.Contact,
+			Mailbox:        rule.Mailbox,
+			SubjectPattern: rule.SubjectPattern,
+			Action:         rule.Action,
+			Override:       rule.Override,
+		})
+	}
+	return dto
+}
+
+// SaveNotificationSettings validates and saves the notification settings
+func SaveNotificationSettings(cfg *core.Config, dto models.NotificationSettingsDTO) error {
+	if cfg == nil {
+		return fmt.Errorf("config not initialized")
+	}
+
+	settings := core.NotificationSettings{
+		DoNotDisturb:  dto.DoNotDisturb,
+		DefaultAction: dto.DefaultAction,
+		QuietHours: core.QuietHours{
+			Enabled: dto.QuietHoursEnabled,
+			Start:   dto.QuietHoursStart,
+			End:     dto.QuietHoursEnd,
+		},
+		Rules: make([]core.NotificationRule, 0, len(dto.Rules)),
+	}
+	for _, rule := range dto.Rules {
+		settings.Rules = append(settings.Rules, core.NotificationRule{
+			Name:           rule.Name,
+			Enabled:        rule.Enabled,
+			Sender:         rule.Sender,
+			Contact:        rule.Contact,
+			Mailbox:        rule.Mailbox,
+			SubjectPattern: rule.SubjectPattern,
+			Action:         rule.Action,
+			Override:       rule.Override,
+		})
+	}
+
+	if err := cfg.SetNotificationSettings(settings); err != nil {
+		return fmt.Errorf("Invalid notification settings: %v", err)
+	}
+
+	if err := cfg.Save(); err != nil {
+		return fmt.Errorf("failed to save config: %w", err)
+	}
+	return nil
+}
+
+// SetDoNotDisturb enables or disables do not disturb and saves the config
+func SetDoNotDisturb(cfg *core.Config, enabled bool) error {
+	if cfg == nil {
+		return fmt.Errorf("config not initialized")
+	}
+
+	cfg.SetDoNotDisturb(enabled)
+	if err := cfg.Save(); err != nil {
+		return fmt.Errorf("failed to save config: %w", err)
+	}
+
+	log.Printf("[Notifications] Do not disturb set to %v", enabled)
+	return nil
+}
+
+// describeDecision formats a decision for logging
+func describeDecision(decision core.NotificationDecision) string {
+	if decision.Rule != "" {
+		return fmt.Sprintf("rule %q", decision.Rule)
+	}
+	return decision.Reason
+}
@@ -0,0 +1,229 @@
+package retention
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/JB-SelfCompany/Tyr-Desktop/internal/core"
+	"github.com/JB-SelfCompany/Tyr-Desktop/internal/models"
+)
+
+// EventEmitter is a callback function that emits events to the frontend
+type EventEmitter func(eventName string, data interface{})
+
+// schedulerCheckInterval is how often the scheduler checks if a run is due
+const schedulerCheckInterval = 10 * time.Minute
+
+// StartRetentionScheduler runs retention policies when they are due
+// Emits "retention:completed" with a RetentionReportDTO after each scheduled run.
+// This goroutine runs in the background and stops when shutdownChan is closed
+func StartRetentionScheduler(
+	sm *core.ServiceManager,
+	cfg *core.Config,
+	emitFunc EventEmitter,
+	shutdownChan <-chan struct{},
+) {
+	if sm == nil || cfg == nil {
+		log.Println("Service manager not initialized, skipping retention scheduler")
+		return
+	}
+
+	log.Println("Starting retention scheduler...")
+
+	// First check shortly after startup so the service has time to come up
+	timer := time.NewTimer(time.Minute)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-shutdownChan:
+			log.Println("Retention scheduler stopped")
+			return
+
+		case <-timer.C:
+			if sm.IsRunning() && cfg.IsRetentionDue(time.Now()) {
+				settings := cfg.GetRetentionSettings()
+				report, err := runAndLog(sm, cfg, settings.Policies, true)
+				if err != nil {
+					log.Printf("[Retention] Scheduled run failed: %v", err)
+				} else if emitFunc != nil {
+					emitFunc("retention:completed", convertReport(report))
+				}
+			}
+			timer.Reset(schedulerCheckInterval)
+		}
+	}
+}
+
+// GetRetentionSettings returns the retention policies and schedule
+func GetRetentionSettings(cfg *core.Config) models.RetentionSettingsDTO {
+	if cfg == nil {
+		return models.RetentionSettingsDTO{Policies: []models.RetentionPolicyDTO{}}
+	}
+
+	settings := cfg.GetRetentionSettings()
+	dto := models.RetentionSettingsDTO{
+		Enabled:       settings.Enabled,
+		IntervalHours: settings.IntervalHours,
+		Policies:      make([]models.RetentionPolicyDTO, 0, len(settings.Policies)),
+	}
+	if settings.LastRun > 0 {
+		dto.LastRun = time.Unix(settings.LastRun, 0).UTC().Format(time.RFC3339)
+	}
+	for _, p := range settings.Policies {
+		dto.Policies = append(dto.Policies, models.RetentionPolicyDTO{
+			Mailbox:                   p.Mailbox,
+			Enabled:                   p.Enabled,
+			MaxAgeDays:                p.MaxAgeDays,
+			MaxMessages:               p.MaxMessages,
+			Action:                    p.Action,
+			MoveTo:                    p.MoveTo,
+			KeepFlagged:               p.KeepFlagged,
+			PurgeAttachmentsOverKB:    p.PurgeAttachmentsOverKB,
+			PurgeAttachmentsAfterDays: p.PurgeAttachmentsAfterDays,
+		})
+	}
+	return dto
+}
+
+// SaveRetentionSettings validates and saves the retention policies and schedule
+func SaveRetentionSettings(cfg *core.Config, dto models.RetentionSettingsDTO) error {
+	if cfg == nil {
+		return fmt.Errorf("config not initialized")
+	}
+
+	if err := cfg.SetRetentionSettings(settingsFromDTO(dto)); err != nil {
+		return fmt.Errorf("Invalid retention settings: %v", err)
+	}
+
+	if err := cfg.Save(); err != nil {
+		return fmt.Errorf("failed to save config: %w", err)
+	}
+	return nil
+}
+
+// PreviewRetention lists what the given policies would remove without changing anything
+// The policies don't need to be saved first
+func PreviewRetention(sm *core.ServiceManager, dto models.RetentionSettingsDTO) (models.RetentionReportDTO, error) {
+	if sm == nil {
+		return models.RetentionReportDTO{}, fmt.Errorf("Service manager is not initialized. Please restart the application.")
+	}
+
+	settings := settingsFromDTO(dto)
+	if err := settings.Prepare(); err != nil {
+		return models.RetentionReportDTO{}, fmt.Errorf("Invalid retention settings: %v", err)
+	}
+
+	report, err := sm.RunRetention(settings.Policies, true)
+	if err != nil {
+		return models.RetentionReportDTO{}, fmt.Errorf("Failed to preview retention. Error: %v", err)
+	}
+	return convertReport(report), nil
+}
+
+// RunRetentionNow applies the saved retention policies immediately and logs the result
+func RunRetentionNow(sm *core.ServiceManager, cfg *core.Config) (models.RetentionReportDTO, error) {
+	if sm == nil {
+		return models.RetentionReportDTO{}, fmt.Errorf("Service manager is not initialized. Please restart the application.")
+	}
+	if cfg == nil {
+		return models.RetentionReportDTO{}, fmt.Errorf("config not initialized")
+	}
+
+	settings := cfg.GetRetentionSettings()
+	report, err := runAndLog(sm, cfg, settings.Policies, false)
+	if err != nil {
+		return models.RetentionReportDTO{}, fmt.Errorf("Failed to run retention. Error: %v", err)
+	}
+	return convertReport(report), nil
+}
+
+// GetRetentionLog returns the most recent retention runs, newest first
+func GetRetentionLog(limit int) ([]models.RetentionReportDTO, error) {
+	reports, err := core.ReadRetentionLog(limit)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to read retention log. Error: %v", err)
+	}
+
+	result := make([]models.RetentionReportDTO, 0, len(reports))
+	for i := len(reports) - 1; i >= 0; i-- {
+		result = append(result, convertReport(&reports[i]))
+	}
+	return result, nil
+}
+
+// runAndLog runs retention for real, records the run time and appends the report to the log
+func runAndLog(sm *core.ServiceManager, cfg *core.Config, policies []core.RetentionPolicy, scheduled bool) (*core.RetentionReport, error) {
+	report, err := sm.RunRetention(policies, false)
+	if err != nil {
+		return nil, err
+	}
+	report.Scheduled = scheduled
+
+	cfg.SetRetentionLastRun(report.StartedAt)
+	if err := cfg.Save(); err != nil {
+		log.Printf("[Retention] Warning: failed to save last run time: %v", err)
+	}
+	if err := core.AppendRetentionLog(report); err != nil {
+		log.Printf("[Retention] Warning: failed to write retention log: %v", err)
+	}
+	return report, nil
+}
+
+// settingsFromDTO converts retention settings from a DTO
+func settingsFromDTO(dto models.RetentionSettingsDTO) core.RetentionSettings {
+	settings := core.RetentionSettings{
+		Enabled:       dto.Enabled,
+		IntervalHours: dto.IntervalHours,
+		Policies:      make([]core.RetentionPolicy, 0, len(dto.Policies)),
+	}
+	for _, p := range dto.Policies {
+		settings.Policies = append(settings.Policies, core.RetentionPolicy{
+			Mailbox:                   p.Mailbox,
+			Enabled:                   p.Enabled,
+			MaxAgeDays:                p.MaxAgeDays,
+			MaxMessages:               p.MaxMessages,
+			Action:                    p.Action,
+			MoveTo:                    p.MoveTo,
+			KeepFlagged:               p.KeepFlagged,
+			PurgeAttachmentsOverKB:    p.PurgeAttachmentsOverKB,
+			PurgeAttachmentsAfterDays: p.PurgeAttachmentsAfterDays,
+		})
+	}
+	return settings
+}
+
+// convertReport converts a retention report to a DTO
+func convertReport(r *core.RetentionReport) models.RetentionReportDTO {
+	dto := models.RetentionReportDTO{
+		StartedAt:  r.StartedAt.Format(time.RFC3339),
+		FinishedAt: r.FinishedAt.Format(time.RFC3339),
+		DryRun:     r.DryRun,
+		Scheduled:  r.Scheduled,
+		Items:      make([]models.RetentionItemDTO, 0, len(r.Items)),
+		Errors:     r.Errors,
+		Processed:  r.Processed,
+		FreedBytes: r.FreedBytes,
+	}
+	if dto.Errors == nil {
+		dto.Errors = []string{}
+	}
+	for _, item := range r.Items {
+		dto.Items = append(dto.Items, models.RetentionItemDTO{
+			Mailbox:     item.Mailbox,
+			UID:         item.UID,
+			Subject:     item.Subject,
+			From:        item.From,
+			Date:        item.Date.Format(time.RFC3339),
+			SizeBytes:   item.SizeBytes,
+			Action:      item.Action,
+			Target:      item.Target,
+			Reason:      item.Reason,
+			Attachments: item.Attachments,
+			FreedBytes:  item.FreedBytes,
+			Error:       item.Error,
+		})
+	}
+	return dto
+}
@@ -0,0 +1,225 @@
+package senderfilter
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/JB-SelfCompany/Tyr-Desktop/internal/core"
+	"github.com/JB-SelfCompany/Tyr-Desktop/internal/models"
+)
+
+// EventEmitter is a callback function that emits events to the frontend
+type EventEmitter func(eventName string, data interface{})
+
+// HandleMailEvent applies the sender filter to a new_mail event
+// Sets dto.Filter and suppresses the notification if the message is filed.
+// The message is moved in the background; "senderfilter:filed" is emitted with a
+// SenderFilterEventDTO once done. Returns true if the message is filed
+func HandleMailEvent(
+	filter *core.SenderFilter,
+	sm *core.ServiceManager,
+	emitFunc EventEmitter,
+	dto *models.MailEventDTO,
+) bool {
+	if filter == nil || sm == nil || dto == nil || dto.Type != "new_mail" || dto.MailID <= 0 || !filter.Enabled() {
+		return false
+	}
+
+	decision := filter.Evaluate(dto.From)
+
+	mailbox := dto.Mailbox
+	if mailbox == "" {
+		mailbox = "INBOX"
+	}
+	uid := uint32(dto.MailID)
+
+	filed := decision.Filed()
+	if filed {
+		dto.Filter = decision.Action
+		dto.Notification = core.NotificationActionSuppress
+	}
+
+	// Filing needs an IMAP round trip, don't hold up the event loop
+	go func() {
+		err := filter.Apply(sm, decision, mailbox, uid)
+		if err != nil {
+			log.Printf("[SenderFilter] Failed to %s message %d: %v", decision.Action, uid, err)
+		}
+		if filed && emitFunc != nil {
+			emitFunc("senderfilter:filed", newEventDTO(decision, mailbox, uid, err))
+		}
+	}()
+	return filed
+}
+
+// GetSenderFilterSettings returns the sender filter settings
+func GetSenderFilterSettings(cfg *core.Config) models.SenderFilterSettingsDTO {
+	if cfg == nil {
+		return models.SenderFilterSettingsDTO{Blocked: []string{}, Allowed: []string{}}
+	}
+
+	settings := cfg.GetSenderFilterSettings()
+	dto := models.SenderFilterSettingsDTO{
+		Enabled:           settings.Enabled,
+		AllowlistOnly:     settings.AllowlistOnly,
+		BlockAction:       settings.BlockAction,
+		JunkMailbox:       settings.JunkMailbox,
+		QuarantineMailbox: settings.QuarantineMailbox,
+		RateLimit:         settings.RateLimit,
+		RateWindowMinutes: settings.RateWindowMinutes,
+		Blocked:           settings.Blocked,
+		Allowed:           settings.Allowed,
+	}
+	if dto.Blocked == nil {
+		dto.Blocked = []string{}
+	}
+	if dto.Allowed == nil {
+		dto.Allowed = []string{}
+	}
+	return dto
+}
+
+// SaveSenderFilterSettings validates and saves the sender filter settings
+func SaveSenderFilterSettings(cfg *core.Config, dto models.SenderFilterSettingsDTO) error {
+	if cfg == nil {
+		return fmt.Errorf("config not initialized")
+	}
+
+	settings := core.SenderFilterSettings{
+		Enabled:           dto.Enabled,
+		AllowlistOnly:     dto.AllowlistOnly,
+		BlockAction:       dto.BlockAction,
+		JunkMailbox:       dto.JunkMailbox,
+		QuarantineMailbox: dto.QuarantineMailbox,
+		RateLimit:         dto.RateLimit,
+		RateWindowMinutes: dto.RateWindowMinutes,
+		Blocked:           dto.Blocked,
+		Allowed:           dto.Allowed,
+	}
+	if err := cfg.SetSenderFilterSettings(settings); err != nil {
+		return fmt.Errorf("Invalid sender filter settings: %v", err)
+	}
+
+	if err := cfg.Save(); err != nil {
+		return fmt.Errorf("failed to save config: %w", err)
+	}
+	return nil
+}
+
+// BlockSender adds a sender to the blocklist
+// If a message is given, it is moved to junk or deleted right away
+func BlockSender(cfg *core.Config, filter *core.SenderFilter, sm *core.ServiceManager, dto models.SenderActionDTO) error {
+	if err := setListing(cfg, dto.Address, core.SenderListBlocked); err != nil {
+		return err
+	}
+
+	settings := cfg.GetSenderFilterSettings()
+	decision := core.SenderDecision{
+		Sender: core.NormalizeMailAddress(core.ExtractMailAddress(dto.Address)),
+		Action: settings.BlockAction,
+		Reason: "sender blocked by user",
+	}
+	if decision.Action == core.SenderActionJunk {
+		decision.Target = settings.JunkMailbox
+	}
+	return fileMessage(filter, sm, decision, dto)
+}
+
+// AllowSender adds a sender to the allowlist
+// If a message is given and it was filed to junk or quarantine, it is moved back to the inbox
+func AllowSender(cfg *core.Config, filter *core.SenderFilter, sm *core.ServiceManager, dto models.SenderActionDTO) error {
+	if err := setListing(cfg, dto.Address, core.SenderListAllowed); err != nil {
+		return err
+	}
+
+	settings := cfg.GetSenderFilterSettings()
+	if !strings.EqualFold(dto.Mailbox, settings.JunkMailbox) && !strings.EqualFold(dto.Mailbox, settings.QuarantineMailbox) {
+		return nil
+	}
+	return fileMessage(filter, sm, core.SenderDecision{
+		Sender: core.NormalizeMailAddress(core.ExtractMailAddress(dto.Address)),
+		Action: core.SenderActionRestore,
+		Reason: "sender allowed by user",
+		Target: "INBOX",
+	}, dto)
+}
+
+// UnlistSender removes a sender from the blocklist and allowlist
+func UnlistSender(cfg *core.Config, address string) error {
+	return setListing(cfg, address, core.SenderListNone)
+}
+
+// GetSenderFilterLog returns logged filtering decisions, oldest first
+func GetSenderFilterLog(limit int) ([]models.SenderFilterLogEntryDTO, error) {
+	entries, err := core.ReadSenderFilterLog(limit)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to read sender filter log. Error: %v", err)
+	}
+
+	result := make([]models.SenderFilterLogEntryDTO, 0, len(entries))
+	for _, e := range entries {
+		result = append(result, models.SenderFilterLogEntryDTO{
+			Time:    e.Time.Format(time.RFC3339),
+			Sender:  e.Sender,
+			Mailbox: e.Mailbox,
+			MailID:  int(e.UID),
+			Subject: e.Subject,
+			Action:  e.Action,
+			Reason:  e.Reason,
+			Target:  e.Target,
+			Error:   e.Error,
+		})
+	}
+	return result, nil
+}
+
+// setListing updates a sender's list membership and saves the config
+func setListing(cfg *core.Config, address, list string) error {
+	if cfg == nil {
+		return fmt.Errorf("config not initialized")
+	}
+	if _, err := cfg.SetSenderListing(address, list); err != nil {
+		return fmt.Errorf("Invalid sender: %v", err)
+	}
+	if err := cfg.Save(); err != nil {
+		return fmt.Errorf("failed to save config: %w", err)
+	}
+	return nil
+}
+
+// fileMessage applies a decision to the message referenced by dto, if any
+func fileMessage(filter *core.SenderFilter, sm *core.ServiceManager, decision core.SenderDecision, dto models.SenderActionDTO) error {
+	if dto.Mailbox == "" || dto.MailID <= 0 {
+		return nil
+	}
+	if filter == nil || sm == nil {
+		return fmt.Errorf("Service manager is not initialized. Please restart the application.")
+	}
+	if !sm.IsRunning() {
+		return fmt.Errorf("Service is not running. Please start the service first.")
+	}
+
+	decision.Key = core.SenderKey(decision.Sender)
+	if err := filter.Apply(sm, decision, dto.Mailbox, uint32(dto.MailID)); err != nil {
+		return fmt.Errorf("Failed to move message. Error: %v", err)
+	}
+	return nil
+}
+
+// newEventDTO converts a carried-out decision to an event DTO
+func newEventDTO(decision core.SenderDecision, mailbox string, uid uint32, err error) models.SenderFilterEventDTO {
+	dto := models.SenderFilterEventDTO{
+		Sender:  decision.Sender,
+		Mailbox: mailbox,
+		MailID:  int(uid),
+		Action:  decision.Action,
+		Reason:  decision.Reason,
+		Target:  decision.Target,
+	}
+	if err != nil {
+		dto.Error = err.Error()
+	}
+	return dto
+}
@@ -182,20 +182,25 @@ func GetPeerStatsDTO(sm *core.ServiceManager, cfg *core.Config) []models.PeerInf
 
 	var result []models.PeerInfoDTO
 	for _, peerCfg := range cfg.NetworkPeers {
+		// Yggdrasil reports proxied peers by their socks:// URI
+		connAddress, proxied := cfg.GetConnectionAddress(peerCfg)
+
 		dto := models.PeerInfoDTO{
-			Address:   peerCfg.Address,
-			Enabled:   peerCfg.Enabled,
-			Connected: false,
-			Latency:   0,
-			Uptime:    0,
-			RXBytes:   0,
-			TXBytes:   0,
-			RXRate:    0,
-			TXRate:    0,
-			LastError: "",
+			Address:     peerCfg.Address,
+			Enabled:     peerCfg.Enabled,
+			BypassProxy: peerCfg.BypassProxy,
+			Proxied:     proxied,
+			Connected:   false,
+			Latency:     0,
+			Uptime:      0,
+			RXBytes:     0,
+			TXBytes:     0,
+			RXRate:      0,
+			TXRate:      0,
+			LastError:   "",
 		}
 
-		if stats, exists := peerStatsMap[peerCfg.Address]; exists {
+		if stats, exists := peerStatsMap[connAddress]; exists {
 			dto.Connected = stats.Status
 			dto.Latency = stats.Latency
 			dto.Uptime = stats.Uptime
@@ -222,8 +227,8 @@ func HotReloadPeers(sm *core.ServiceManager, cfg *core.Config) error {
 		return fmt.Errorf("Cannot reload peers: service is not running. Please start the service first.")
 	}
 
-	// Get enabled peers from config
-	peers := cfg.GetEnabledPeers()
+	// Get enabled peers from config (rewritten for the proxy if enabled)
+	peers := cfg.GetConnectionPeers()
 
 	// Allow empty peer list - this will disconnect from all peers
 	// This is useful when user wants to disable all peers temporarily
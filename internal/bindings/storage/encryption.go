@@ -0,0 +1,291 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/JB-SelfCompany/Tyr-Desktop/internal/core"
+	"github.com/JB-SelfCompany/Tyr-Desktop/internal/models"
+)
+
+// GetStorageEncryptionStatus returns whether storage is encrypted and unlocked
+func GetStorageEncryptionStatus(vault *core.StorageVault, cfg *core.Config) models.StorageEncryptionStatusDTO {
+	dto := models.StorageEncryptionStatusDTO{MinPassphraseLength: core.MinStoragePassphraseLength}
+	dto.WorkDirOnDiskAllowed = cfg != nil && cfg.IsStorageWorkDirOnDiskAllowed()
+	if vault == nil {
+		return dto
+	}
+
+	dto.Enabled = vault.IsEnabled()
+	dto.Unlocked = vault.IsUnlocked()
+	dto.EncryptedSizeMB = float64(vault.Size()) / (1024 * 1024)
+	if dto.Unlocked {
+		dto.WorkDir = vault.WorkDir()
+	}
+	if vault.WorkDirOnDisk() {
+		dto.WorkDirOnDisk = true
+		dto.Warning = workDirOnDiskWarning
+	}
+	return dto
+}
+
+// SetStorageWorkDirOnDiskAllowed records whether the user accepts a decrypted
+// working copy on disk, needed to enable or unlock encrypted storage on Windows and macOS
+func SetStorageWorkDirOnDiskAllowed(cfg *core.Config, allowed bool) error {
+	if cfg == nil {
+		return fmt.Errorf("config not initialized")
+	}
+	cfg.SetStorageWorkDirOnDiskAllowed(allowed)
+	if err := cfg.Save(); err != nil {
+		return fmt.Errorf("failed to save config: %w", err)
+	}
+	return nil
+}
+
+// workDirOnDiskWarning is shown where the decrypted working copy can't be kept in memory
+const workDirOnDiskWarning = "On this system the decrypted mail and identity key are kept in a temporary folder on disk while storage is unlocked. " +
+	"They are removed when storage is locked, the app exits or the app starts after a crash, but may be recoverable from the disk. " +
+	"Use full-disk encryption to protect them, and allow the on-disk working copy explicitly to use encrypted storage."
+
+// workDirOnDiskRefused is returned when enabling or unlocking needs an on-disk working copy the user hasn't allowed
+const workDirOnDiskRefused = "On this system the decrypted mail would be kept in a temporary folder on disk while storage is unlocked. " +
+	"Please review the warning and allow the on-disk working copy in the storage settings first."
+
+// UnlockStorage decrypts the database and filestore with the passphrase
+// The service manager must be created after this succeeds
+func UnlockStorage(vault *core.StorageVault, cfg *core.Config, passphrase string) error {
+	if vault == nil || cfg == nil {
+		return fmt.Errorf("config not initialized")
+	}
+	if !vault.IsEnabled() {
+		return fmt.Errorf("Storage is not encrypted.")
+	}
+
+	if err := vault.Unlock(passphrase, cfg.IsStorageWorkDirOnDiskAllowed()); err != nil {
+		return passphraseError("Failed to unlock storage", err)
+	}
+	cfg.ResetDatabasePath()
+	return nil
+}
+
+// EnableStorageEncryption encrypts the database and filestore with a passphrase
+// The service is closed while the data is migrated and restarted afterwards
+func EnableStorageEncryption(vault *core.StorageVault, cfg *core.Config, sm *core.ServiceManager, passphrase string) error {
+	if vault == nil || cfg == nil {
+		return fmt.Errorf("config not initialized")
+	}
+	if vault.IsEnabled() {
+		return fmt.Errorf("Storage is already encrypted.")
+	}
+	if len(passphrase) < core.MinStoragePassphraseLength {
+		return fmt.Errorf("Passphrase must be at least %d characters.", core.MinStoragePassphraseLength)
+	}
+
+	return WithServiceClosed(sm, func() error {
+		if err := vault.Enable(passphrase, cfg.IsStorageWorkDirOnDiskAllowed()); err != nil {
+			if errors.Is(err, core.ErrStorageWorkDirOnDisk) {
+				return fmt.Errorf(workDirOnDiskRefused)
+			}
+			return fmt.Errorf("Failed to encrypt storage. Error: %v", err)
+		}
+		cfg.ResetDatabasePath()
+		return nil
+	})
+}
+
+// DisableStorageEncryption decrypts the database and filestore back into the data directory
+// The service is closed while the data is migrated and restarted afterwards
+func DisableStorageEncryption(vault *core.StorageVault, cfg *core.Config, sm *core.ServiceManager, passphrase string) error {
+	if vault == nil || cfg == nil {
+		return fmt.Errorf("config not initialized")
+	}
+	if !vault.IsEnabled() {
+		return fmt.Errorf("Storage is not encrypted.")
+	}
+	if !vault.IsUnlocked() {
+		return fmt.Errorf("Storage is locked. Please unlock it first.")
+	}
+
+	return WithServiceClosed(sm, func() error {
+		if err := vault.Disable(passphrase); err != nil {
+			return passphraseError("Failed to decrypt storage", err)
+		}
+		cfg.ResetDatabasePath()
+		return nil
+	})
+}
+
+// ChangeStoragePassphrase replaces the storage passphrase
+func ChangeStoragePassphrase(vault *core.StorageVault, currentPassphrase, newPassphrase string) error {
+	if vault == nil {
+		return fmt.Errorf("config not initialized")
+	}
+	if !vault.IsEnabled() {
+		return fmt.Errorf("Storage is not encrypted.")
+	}
+	if len(newPassphrase) < core.MinStoragePassphraseLength {
+		return fmt.Errorf("Passphrase must be at least %d characters.", core.MinStoragePassphraseLength)
+	}
+
+	if err := vault.ChangePassphrase(currentPassphrase, newPassphrase); err != nil {
+		return passphraseError("Failed to change storage passphrase", err)
+	}
+	return nil
+}
+
+// GetEncryptionSettings returns the key derivation settings for newly encrypted data
+func GetEncryptionSettings(cfg *core.Config) models.EncryptionSettingsDTO {
+	settings := core.EncryptionSettings{
+		KDFTimeCost: core.DefaultKDFTimeCost,
+		KDFMemoryMB: core.DefaultKDFMemoryMB,
+		KDFThreads:  core.DefaultKDFThreads,
+	}
+	if cfg != nil {
+		settings = cfg.GetEncryptionSettings()
+	}
+	params := settings.KDFParams()
+	return models.EncryptionSettingsDTO{
+		KDF:         params.Algorithm,
+		KDFTimeCost: settings.KDFTimeCost,
+		KDFMemoryMB: settings.KDFMemoryMB,
+		KDFThreads:  settings.KDFThreads,
+	}
+}
+
+// SaveEncryptionSettings validates and saves the key derivation settings
+// Existing backups and the password file are upgraded the next time they are opened
+func SaveEncryptionSettings(cfg *core.Config, dto models.EncryptionSettingsDTO) error {
+	if cfg == nil {
+		return fmt.Errorf("config not initialized")
+	}
+
+	// Only the key derivation cost is edited here
+	settings := cfg.GetEncryptionSettings()
+	settings.KDFTimeCost = dto.KDFTimeCost
+	settings.KDFMemoryMB = dto.KDFMemoryMB
+	settings.KDFThreads = dto.KDFThreads
+	if err := cfg.SetEncryptionSettings(settings); err != nil {
+		return fmt.Errorf("Invalid encryption settings: %v", err)
+	}
+
+	if err := cfg.Save(); err != nil {
+		return fmt.Errorf("failed to save config: %w", err)
+	}
+	return nil
+}
+
+// passphraseError formats a storage error, with a friendly message for a wrong passphrase
+func passphraseError(action string, err error) error {
+	if errors.Is(err, core.ErrWrongStoragePassphrase) {
+		return fmt.Errorf("Passphrase is incorrect. Please check your passphrase and try again.")
+	}
+	if errors.Is(err, core.ErrStorageWorkDirOnDisk) {
+		return fmt.Errorf(workDirOnDiskRefused)
+	}
+	return fmt.Errorf("%s. Error: %v", action, err)
+}
+
+// Checkpoint timing: soon after mail changes, and periodically for changes made
+// through IMAP that raise no event
+const (
+	checkpointDelay    = 15 * time.Second
+	checkpointInterval = 5 * time.Minute
+)
+
+// StartCheckpointScheduler seals snapshots of the unlocked working copy into the
+// encrypted archive shortly after mail changes and every few minutes, so a crash or
+// power loss doesn't lose the mail received since unlock.
+// This goroutine runs in the background and stops when shutdownChan is closed
+func StartCheckpointScheduler(vault *core.StorageVault, shutdownChan <-chan struct{}) {
+	if vault == nil {
+		return
+	}
+
+	log.Println("Starting encrypted storage checkpoints...")
+
+	ticker := time.NewTicker(checkpointInterval)
+	defer ticker.Stop()
+
+	// Debounce mail changes, so a burst of messages is sealed once
+	var delay <-chan time.Time
+	for {
+		select {
+		case <-shutdownChan:
+			log.Println("Encrypted storage checkpoints stopped")
+			return
+
+		case <-vault.Changes():
+			if delay == nil {
+				delay = time.After(checkpointDelay)
+			}
+
+		case <-delay:
+			delay = nil
+			Checkpoint(vault)
+
+		case <-ticker.C:
+			Checkpoint(vault)
+		}
+	}
+}
+
+// Checkpoint seals the working copy if storage is encrypted and unlocked
+func Checkpoint(vault *core.StorageVault) {
+	if vault == nil || !vault.IsUnlocked() {
+		return
+	}
+	if err := vault.Checkpoint(); err != nil && !errors.Is(err, core.ErrStorageLocked) {
+		log.Printf("[Storage] Failed to checkpoint encrypted storage: %v", err)
+	}
+}
+
+// WithServiceClosed stops and closes the service so the database is released,
+// runs fn, then reinitializes the service and restarts it if it was running
+func WithServiceClosed(sm *core.ServiceManager, fn func() error) error {
+	if sm == nil {
+		return fn()
+	}
+
+	wasRunning := sm.IsRunning()
+	if wasRunning {
+		if err := sm.SoftStop(); err != nil {
+			if err := sm.Stop(); err != nil {
+				return fmt.Errorf("Failed to stop the service. Please stop it manually and try again. Error: %v", err)
+			}
+		}
+
+		// Wait for service to fully stop (up to 10 seconds)
+		for i := 0; i < 50 && sm.IsRunning(); i++ {
+			time.Sleep(200 * time.Millisecond)
+		}
+		if sm.IsRunning() {
+			return fmt.Errorf("Service did not stop within 10 seconds. Please try stopping it manually first.")
+		}
+	}
+
+	if err := sm.CloseService(); err != nil {
+		if wasRunning {
+			if initErr := sm.Initialize(); initErr == nil {
+				sm.Start()
+			}
+		}
+		return fmt.Errorf("Failed to close the service and release database file. Error: %v", err)
+	}
+
+	opErr := fn()
+
+	if err := sm.Initialize(); err != nil {
+		if opErr != nil {
+			return opErr
+		}
+		return fmt.Errorf("Changes were applied, but the service failed to initialize. Error: %v", err)
+	}
+	if wasRunning {
+		if err := sm.Start(); err != nil && opErr == nil {
+			return fmt.Errorf("Changes were applied, but the service failed to restart. Please start it manually. Error: %v", err)
+		}
+	}
+	return opErr
+}
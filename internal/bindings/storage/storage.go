@@ -0,0 +1,182 @@
+package storage
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/JB-SelfCompany/Tyr-Desktop/internal/core"
+	"github.com/JB-SelfCompany/Tyr-Desktop/internal/models"
+)
+
+// EventEmitter is a callback function that emits events to the frontend
+type EventEmitter func(eventName string, data interface{})
+
+// QuotaStatusFunc is called with the quota status after every check
+type QuotaStatusFunc func(status core.QuotaStatus)
+
+// quotaCheckInterval is how often storage usage is measured
+const quotaCheckInterval = 5 * time.Minute
+
+// StartQuotaMonitoring periodically checks storage usage against the budget
+// Emits "storage:quota" with a QuotaStatusDTO when the state or crossed threshold changes.
+// This goroutine runs in the background and stops when shutdownChan is closed
+func StartQuotaMonitoring(
+	monitor *core.QuotaMonitor,
+	sm *core.ServiceManager,
+	emitFunc EventEmitter,
+	statusFunc QuotaStatusFunc,
+	shutdownChan <-chan struct{},
+) {
+	if monitor == nil || sm == nil {
+		log.Println("Service manager not initialized, skipping quota monitoring")
+		return
+	}
+
+	log.Println("Starting storage quota monitoring...")
+
+	// First check shortly after startup so the service has time to come up
+	timer := time.NewTimer(30 * time.Second)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-shutdownChan:
+			log.Println("Storage quota monitoring stopped")
+			return
+
+		case <-timer.C:
+			CheckQuota(monitor, sm, emitFunc, statusFunc)
+			timer.Reset(quotaCheckInterval)
+		}
+	}
+}
+
+// CheckQuota measures storage usage once and reports the result
+// Emits "storage:quota" if the status changed since the previous check
+func CheckQuota(monitor *core.QuotaMonitor, sm *core.ServiceManager, emitFunc EventEmitter, statusFunc QuotaStatusFunc) {
+	if monitor == nil {
+		return
+	}
+
+	status, changed, err := monitor.Check(sm)
+	if err != nil {
+		log.Printf("[Quota] Failed to check storage usage: %v", err)
+		return
+	}
+
+	if statusFunc != nil {
+		statusFunc(status)
+	}
+	if !changed {
+		return
+	}
+
+	if status.State != core.QuotaStateOK {
+		log.Printf("[Quota] Storage usage %.1f MB of %d MB (%.0f%%, %s)", status.UsedMB, status.BudgetMB, status.Percent, status.State)
+	}
+	if emitFunc != nil {
+		emitFunc("storage:quota", convertStatus(status))
+	}
+}
+
+// GetQuotaSettings returns the storage quota settings
+func GetQuotaSettings(cfg *core.Config) models.QuotaSettingsDTO {
+	if cfg == nil {
+		return models.QuotaSettingsDTO{WarningThresholds: []int{}}
+	}
+
+	settings := cfg.GetQuotaSettings()
+	return models.QuotaSettingsDTO{
+		Enabled:                 settings.Enabled,
+		BudgetMB:                settings.BudgetMB,
+		WarningThresholds:       settings.WarningThresholds,
+		EnforceHardLimit:        settings.EnforceHardLimit,
+		RestrictedMessageSizeMB: settings.RestrictedMessageSizeMB,
+	}
+}
+
+// SaveQuotaSettings validates and saves the storage quota settings
+func SaveQuotaSettings(cfg *core.Config, dto models.QuotaSettingsDTO) error {
+	if cfg == nil {
+		return fmt.Errorf("config not initialized")
+	}
+
+	settings := core.QuotaSettings{
+		Enabled:                 dto.Enabled,
+		BudgetMB:                dto.BudgetMB,
+		WarningThresholds:       append([]int(nil), dto.WarningThresholds...),
+		EnforceHardLimit:        dto.EnforceHardLimit,
+		RestrictedMessageSizeMB: dto.RestrictedMessageSizeMB,
+	}
+	if err := cfg.SetQuotaSettings(settings); err != nil {
+		return fmt.Errorf("Invalid storage quota settings: %v", err)
+	}
+
+	if err := cfg.Save(); err != nil {
+		return fmt.Errorf("failed to save config: %w", err)
+	}
+	return nil
+}
+
+// GetQuotaStatus measures storage usage now and returns the quota status
+func GetQuotaStatus(monitor *core.QuotaMonitor, sm *core.ServiceManager) (models.QuotaStatusDTO, error) {
+	if monitor == nil {
+		return models.QuotaStatusDTO{}, fmt.Errorf("config not initialized")
+	}
+
+	status, _, err := monitor.Check(sm)
+	if err != nil {
+		return models.QuotaStatusDTO{}, fmt.Errorf("Failed to check storage usage. Error: %v", err)
+	}
+	return convertStatus(status), nil
+}
+
+// GetStorageUsage breaks storage usage down by mailbox and lists the largest messages
+func GetStorageUsage(sm *core.ServiceManager, largest int) (models.StorageUsageDTO, error) {
+	if sm == nil {
+		return models.StorageUsageDTO{}, fmt.Errorf("Service manager is not initialized. Please restart the application.")
+	}
+
+	usage, err := sm.GetStorageUsage(largest)
+	if err != nil {
+		return models.StorageUsageDTO{}, fmt.Errorf("Failed to analyze storage usage. Error: %v", err)
+	}
+
+	dto := models.StorageUsageDTO{
+		Mailboxes: make([]models.MailboxUsageDTO, 0, len(usage.Mailboxes)),
+		Largest:   make([]models.LargeMessageDTO, 0, len(usage.Largest)),
+	}
+	for _, mbox := range usage.Mailboxes {
+		dto.Mailboxes = append(dto.Mailboxes, models.MailboxUsageDTO{
+			Name:      mbox.Name,
+			Messages:  mbox.Messages,
+			SizeBytes: mbox.SizeBytes,
+		})
+	}
+	for _, msg := range usage.Largest {
+		dto.Largest = append(dto.Largest, models.LargeMessageDTO{
+			Mailbox:   msg.Mailbox,
+			UID:       msg.UID,
+			Subject:   msg.Subject,
+			From:      msg.From,
+			Date:      msg.Date.Format(time.RFC3339),
+			SizeBytes: msg.SizeBytes,
+		})
+	}
+	return dto, nil
+}
+
+// convertStatus converts a quota status to a DTO
+func convertStatus(status core.QuotaStatus) models.QuotaStatusDTO {
+	return models.QuotaStatusDTO{
+		Enabled:    status.Enabled,
+		UsedMB:     status.UsedMB,
+		BudgetMB:   status.BudgetMB,
+		Percent:    status.Percent,
+		State:      status.State,
+		Threshold:  status.Threshold,
+		Restricted: status.Restricted,
+		CheckedAt:  status.CheckedAt.Format(time.RFC3339),
+	}
+}
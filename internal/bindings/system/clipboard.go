@@ -0,0 +1,107 @@
+package system
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// sensitiveClipboard tracks the secret currently in the clipboard
+// Only a hash of the content is kept, to tell whether it was replaced since
+var sensitiveClipboard struct {
+	mu     sync.Mutex
+	hash   [sha256.Size]byte
+	cancel chan struct{}
+}
+
+// CopySensitiveToClipboard copies a secret to the clipboard and clears it after
+// the timeout, unless the clipboard was changed in the meantime.
+// Emits "clipboard:countdown" every second with the remaining seconds and
+// "clipboard:cleared" when the countdown ends
+func CopySensitiveToClipboard(ctx context.Context, text string, seconds int) error {
+	if ctx == nil {
+		return fmt.Errorf("context not initialized")
+	}
+	if err := runtime.ClipboardSetText(ctx, text); err != nil {
+		return err
+	}
+
+	sensitiveClipboard.mu.Lock()
+	if sensitiveClipboard.cancel != nil {
+		close(sensitiveClipboard.cancel)
+	}
+	cancel := make(chan struct{})
+	sensitiveClipboard.cancel = cancel
+	sensitiveClipboard.hash = sha256.Sum256([]byte(text))
+	sensitiveClipboard.mu.Unlock()
+
+	go runClipboardCountdown(ctx, seconds, cancel)
+	return nil
+}
+
+// ClearSensitiveClipboard clears the clipboard now if it still holds a copied secret
+// Called on shutdown so secrets don't outlive the app
+func ClearSensitiveClipboard(ctx context.Context) {
+	sensitiveClipboard.mu.Lock()
+	defer sensitiveClipboard.mu.Unlock()
+
+	if sensitiveClipboard.cancel == nil {
+		return
+	}
+	close(sensitiveClipboard.cancel)
+	sensitiveClipboard.cancel = nil
+	clearIfUnchanged(ctx)
+}
+
+// runClipboardCountdown emits the remaining time and clears the clipboard at the end
+func runClipboardCountdown(ctx context.Context, seconds int, cancel chan struct{}) {
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+
+	for remaining := seconds; remaining > 0; remaining-- {
+		runtime.EventsEmit(ctx, "clipboard:countdown", map[string]interface{}{
+			"remaining": remaining,
+			"total":     seconds,
+		})
+
+		select {
+		case <-cancel:
+			return
+		case <-ticker.C:
+		}
+	}
+
+	sensitiveClipboard.mu.Lock()
+	defer sensitiveClipboard.mu.Unlock()
+
+	// A newer copy or ClearSensitiveClipboard took over
+	if sensitiveClipboard.cancel != cancel {
+		return
+	}
+	sensitiveClipboard.cancel = nil
+	cleared := clearIfUnchanged(ctx)
+	runtime.EventsEmit(ctx, "clipboard:cleared", map[string]interface{}{"cleared": cleared})
+}
+
+// clearIfUnchanged empties the clipboard if it still holds the tracked secret
+// Caller must hold sensitiveClipboard.mu
+func clearIfUnchanged(ctx context.Context) bool {
+	current, err := runtime.ClipboardGetText(ctx)
+	if err != nil {
+		log.Printf("Failed to read clipboard: %v", err)
+		return false
+	}
+	if sha256.Sum256([]byte(current)) != sensitiveClipboard.hash {
+		return false
+	}
+	if err := runtime.ClipboardSetText(ctx, ""); err != nil {
+		log.Printf("Failed to clear clipboard: %v", err)
+		return false
+	}
+	return true
+}
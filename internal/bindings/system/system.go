@@ -2,6 +2,7 @@ package system
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
 	"net/url"
@@ -13,6 +14,7 @@ import (
 
 	"github.com/JB-SelfCompany/Tyr-Desktop/internal/core"
 	"github.com/JB-SelfCompany/Tyr-Desktop/internal/models"
+	"github.com/JB-SelfCompany/Tyr-Desktop/internal/qr"
 	uitheme "github.com/JB-SelfCompany/Tyr-Desktop/internal/ui/theme"
 )
 
@@ -206,11 +208,22 @@ func RestoreBackup(ctx context.Context, options models.RestoreOptionsDTO) (*core
 
 	// Restore backup
 	runtime.EventsEmit(ctx, "restore:progress", map[string]interface{}{"progress": 50, "message": "Decrypting backup..."})
-	restoredConfig, dbData, err := core.RestoreBackup(backupData, options.Password)
+	restoredConfig, dbData, contacts, err := core.RestoreBackup(backupData, options.Password)
+	var locked *core.AttemptLockedError
+	if errors.As(err, &locked) {
+		return nil, models.ResultDTO{Success: false, Message: fmt.Sprintf("Too many failed attempts. Please try again in %s.", core.FormatRetryAfter(locked.RetryAfter))}, nil
+	}
 	if err != nil {
 		return nil, models.ResultDTO{Success: false, Message: fmt.Sprintf("Failed to restore backup: %v", err)}, nil
 	}
 
+	// Re-encrypt legacy backups with the current key derivation
+	if upgraded, err := core.UpgradeBackupFile(backupPath, backupData, options.Password); err != nil {
+		log.Printf("[RestoreBackup] Warning: failed to upgrade backup encryption: %v", err)
+	} else if upgraded {
+		log.Printf("[RestoreBackup] Upgraded backup encryption: %s", backupPath)
+	}
+
 	// CRITICAL: Override database path with CURRENT path if available
 	// This ensures database is restored to user's current location, not backup location
 	if currentDatabasePath != "" && restoredConfig.ServiceSettings.DatabasePath != currentDatabasePath {
@@ -237,6 +250,13 @@ func RestoreBackup(ctx context.Context, options models.RestoreOptionsDTO) (*core
 		log.Printf("WARNING: No database data to restore (dbData nil=%v, len=%d)", dbData == nil, len(dbData))
 	}
 
+	// Contacts only after the database, so a failed restore leaves the address book alone
+	if err := core.RestoreContacts(contacts); err != nil {
+		log.Printf("[RestoreBackup] Warning: failed to restore contacts: %v", err)
+	} else if contacts != nil {
+		log.Printf("[RestoreBackup] Restored %d contacts", len(contacts))
+	}
+
 	// Save config to disk AFTER database restoration
 	runtime.EventsEmit(ctx, "restore:progress", map[string]interface{}{"progress": 85, "message": "Saving configuration..."})
 	if err := restoredConfig.Save(); err != nil {
@@ -341,86 +361,154 @@ func detectSystemLanguage() string {
 
 // OpenDeltaChat opens DeltaChat with auto-configured account using dclogin:// URL
 func OpenDeltaChat(ctx context.Context, cfg *core.Config, sm *core.ServiceManager) error {
-	if sm == nil {
-		return fmt.Errorf("service manager not initialized")
-	}
-
-	if cfg == nil {
-		return fmt.Errorf("config not initialized")
-	}
-
-	// Get mail address
-	mailAddress := sm.GetMailAddress()
-	if mailAddress == "" {
-		return fmt.Errorf("mail address not available - service may not be initialized")
-	}
-
-	// Get password
-	password, err := cfg.GetPassword()
-	if err != nil || password == "" {
-		return fmt.Errorf("failed to retrieve password: %w", err)
+	dcloginURL, err := buildDCLoginURL(cfg, sm)
+	if err != nil {
+		return err
 	}
 
-	// Parse SMTP and IMAP addresses
-	smtpHost, smtpPort := parseAddress(cfg.ServiceSettings.SMTPAddress)
-	imapHost, imapPort := parseAddress(cfg.ServiceSettings.IMAPAddress)
-
-	// Generate dclogin:// URL with full IMAP/SMTP configuration
-	dcloginURL := generateDCLoginURL(mailAddress, password, imapHost, imapPort, smtpHost, smtpPort)
-
 	// Try to open DeltaChat directly with the dclogin:// URL
 	if err := openDCLoginURL(dcloginURL); err != nil {
 		// If opening fails, copy URL to clipboard as fallback
+		// The URL contains the password, so it is cleared from the clipboard after a timeout
 		log.Printf("Failed to open DeltaChat automatically: %v", err)
 		log.Println("Copying dclogin URL to clipboard as fallback")
 
+		seconds := cfg.GetClipboardClearSeconds()
 		if ctx != nil {
-			if clipErr := runtime.ClipboardSetText(ctx, dcloginURL); clipErr != nil {
+			if clipErr := CopySensitiveToClipboard(ctx, dcloginURL, seconds); clipErr != nil {
 				return fmt.Errorf("failed to open DeltaChat and failed to copy URL to clipboard: %w", err)
 			}
 		}
 
 		// Return error indicating that automatic opening failed but clipboard copy succeeded
 		// The frontend should show this as a warning/info message, not an error
-		return fmt.Errorf("could not open DeltaChat automatically - dclogin URL has been copied to clipboard. Please paste it in DeltaChat manually")
+		return fmt.Errorf("could not open DeltaChat automatically - dclogin URL has been copied to clipboard and will be cleared in %d seconds. Please paste it in DeltaChat manually", seconds)
 	}
 
 	return nil
 }
 
+// GetMailAddressQRCode renders the mail address as a QR code
+// Format is "png" (default, returned as a data: URL) or "svg" (returned as markup)
+func GetMailAddressQRCode(sm *core.ServiceManager, format string, size int) (models.QRCodeDTO, error) {
+	if sm == nil {
+		return models.QRCodeDTO{}, fmt.Errorf("service manager not initialized")
+	}
+
+	mailAddress := sm.GetMailAddress()
+	if mailAddress == "" {
+		return models.QRCodeDTO{}, fmt.Errorf("mail address not available - service may not be initialized")
+	}
+
+	return renderQRCode(qr.AddressContent(mailAddress), format, size, false)
+}
+
+// GetDeltaChatQRCode renders the dclogin:// setup link as a QR code for another device
+// The link contains the password, so a native confirmation dialog is always shown first
+func GetDeltaChatQRCode(ctx context.Context, cfg *core.Config, sm *core.ServiceManager, format string, size int) (models.QRCodeDTO, error) {
+	dcloginURL, err := buildDCLoginURL(cfg, sm)
+	if err != nil {
+		return models.QRCodeDTO{}, err
+	}
+
+	confirmed, err := ShowQuestionDialog(ctx, "Show Setup QR Code",
+		"This QR code contains your password. Anyone who scans it can read and send your mail. Show it anyway?")
+	if err != nil {
+		return models.QRCodeDTO{}, fmt.Errorf("failed to show confirmation dialog: %w", err)
+	}
+	if !confirmed {
+		return models.QRCodeDTO{}, fmt.Errorf("cancelled by user")
+	}
+
+	log.Println("[GetDeltaChatQRCode] User confirmed showing setup QR code with password")
+	return renderQRCode(dcloginURL, format, size, true)
+}
+
+// renderQRCode renders content in the requested format
+func renderQRCode(content, format string, size int, containsPassword bool) (models.QRCodeDTO, error) {
+	switch strings.ToLower(format) {
+	case qr.FormatSVG:
+		svg, err := qr.SVG(content)
+		if err != nil {
+			return models.QRCodeDTO{}, err
+		}
+		return models.QRCodeDTO{Format: qr.FormatSVG, Data: svg, ContainsPassword: containsPassword}, nil
+
+	case "", qr.FormatPNG:
+		png, err := qr.PNG(content, size)
+		if err != nil {
+			return models.QRCodeDTO{}, err
+		}
+		return models.QRCodeDTO{Format: qr.FormatPNG, Data: qr.DataURL(png), ContainsPassword: containsPassword}, nil
+
+	default:
+		return models.QRCodeDTO{}, fmt.Errorf("unsupported QR code format: %s", format)
+	}
+}
+
 // Helper Functions for DeltaChat Integration
 
+// buildDCLoginURL builds the dclogin:// URL for the current account
+func buildDCLoginURL(cfg *core.Config, sm *core.ServiceManager) (string, error) {
+	if sm == nil {
+		return "", fmt.Errorf("service manager not initialized")
+	}
+
+	if cfg == nil {
+		return "", fmt.Errorf("config not initialized")
+	}
+
+	// Get mail address
+	mailAddress := sm.GetMailAddress()
+	if mailAddress == "" {
+		return "", fmt.Errorf("mail address not available - service may not be initialized")
+	}
+
+	// Get password
+	password, err := cfg.GetPassword()
+	if err != nil || password == "" {
+		return "", fmt.Errorf("failed to retrieve password: %w", err)
+	}
+
+	// Use the LAN TLS listeners if running, otherwise the loopback servers
+	endpoints := sm.GetClientEndpoints()
+
+	// Generate dclogin:// URL with full IMAP/SMTP configuration
+	return generateDCLoginURL(mailAddress, password, endpoints), nil
+}
+
 // generateDCLoginURL creates a dclogin:// URL for DeltaChat auto-configuration
 // Format: dclogin://user@host/?v=1&p=password&ih=imaphost&ip=imapport&is=plain&ic=3&sh=smtphost&sp=smtpport&ss=plain&sc=3
-func generateDCLoginURL(email, password, imapHost, imapPort, smtpHost, smtpPort string) string {
+// With TLS, is/ss are ssl or starttls and fp carries the certificate SHA-256 fingerprint
+func generateDCLoginURL(email, password string, endpoints core.ClientEndpoints) string {
+	// Certificate checks: 1 = strict (local CA installed on the device), 3 = accept invalid
+	// The local CA isn't trusted by default, so the fingerprint is published for manual verification
+	certChecks := "3"
+	if endpoints.Security != core.ClientSecurityPlain && endpoints.StrictCertificates {
+		certChecks = "1"
+	}
+
 	// Build query parameters
 	params := url.Values{}
-	params.Set("v", "1")       // Version
-	params.Set("p", password)  // Password
-	params.Set("ih", imapHost) // IMAP hostname
-	params.Set("ip", imapPort) // IMAP port
-	params.Set("is", "plain")  // IMAP security (plain = no encryption)
-	params.Set("ic", "3")      // IMAP certificate checks (3 = accept invalid, for localhost)
-	params.Set("sh", smtpHost) // SMTP hostname
-	params.Set("sp", smtpPort) // SMTP port
-	params.Set("ss", "plain")  // SMTP security (plain = no encryption)
-	params.Set("sc", "3")      // SMTP certificate checks (3 = accept invalid, for localhost)
+	params.Set("v", "1")                 // Version
+	params.Set("p", password)            // Password
+	params.Set("ih", endpoints.IMAPHost) // IMAP hostname
+	params.Set("ip", endpoints.IMAPPort) // IMAP port
+	params.Set("is", endpoints.Security) // IMAP security (plain, ssl or starttls)
+	params.Set("ic", certChecks)         // IMAP certificate checks
+	params.Set("sh", endpoints.SMTPHost) // SMTP hostname
+	params.Set("sp", endpoints.SMTPPort) // SMTP port
+	params.Set("ss", endpoints.Security) // SMTP security (plain, ssl or starttls)
+	params.Set("sc", certChecks)         // SMTP certificate checks
+	if endpoints.Fingerprint != "" {
+		params.Set("fp", endpoints.Fingerprint) // TLS certificate SHA-256 fingerprint
+	}
 
 	// Build dclogin URL
 	// Format: dclogin://user@host/?parameters
 	return fmt.Sprintf("dclogin://%s/?%s", email, params.Encode())
 }
 
-// parseAddress splits an address string into host and port
-// Example: "127.0.0.1:1025" -> ("127.0.0.1", "1025")
-func parseAddress(addr string) (host, port string) {
-	parts := strings.Split(addr, ":")
-	if len(parts) == 2 {
-		return parts[0], parts[1]
-	}
-	return addr, ""
-}
-
 // openDCLoginURL opens a dclogin:// URL with proper escaping for Windows
 func openDCLoginURL(dcloginURL string) error {
 	switch goruntime.GOOS {
@@ -0,0 +1,261 @@
+package core
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// MinAppLockPassphraseLength is the minimum length of the app lock passphrase
+	MinAppLockPassphraseLength = 4
+
+	// MaxAppLockIdleMinutes is the longest idle period before the app locks
+	MaxAppLockIdleMinutes = 24 * 60
+
+	// DefaultAppLockIdleMinutes is the default idle period before the app locks
+	DefaultAppLockIdleMinutes = 10
+)
+
+// ErrWrongAppLockPassphrase is returned when the app lock passphrase is incorrect
+var ErrWrongAppLockPassphrase = errors.New("wrong app lock passphrase")
+
+// AppLockSettings contains the application lock preferences
+type AppLockSettings struct {
+	// Enabled locks the UI after the idle period, on startup and on demand
+	Enabled bool `toml:"enabled"`
+
+	// IdleMinutes is the idle period before the app locks (0 = only on demand)
+	IdleMinutes int `toml:"idle_minutes"`
+
+	// PassphraseHash is the Argon2id hash of the unlock passphrase in PHC format
+	PassphraseHash string `toml:"passphrase_hash"`
+}
+
+// applyDefaults fills in missing app lock values
+func (s *AppLockSettings) applyDefaults() {
+	if s.IdleMinutes < 0 {
+		s.IdleMinutes = 0
+	}
+}
+
+// Validate checks the app lock settings for errors
+func (s *AppLockSettings) Validate() error {
+	if s.IdleMinutes < 0 || s.IdleMinutes > MaxAppLockIdleMinutes {
+		return fmt.Errorf("idle timeout must be between 0 and %d minutes", MaxAppLockIdleMinutes)
+	}
+	if s.Enabled && s.PassphraseHash == "" {
+		return fmt.Errorf("a passphrase must be set before enabling the app lock")
+	}
+	return nil
+}
+
+// GetAppLockSettings returns the app lock settings
+// Thread-safe with read lock
+func (c *Config) GetAppLockSettings() AppLockSettings {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.AppLock
+}
+
+// SetAppLockSettings validates and replaces the app lock preferences
+// The passphrase hash is kept; use SetAppLockPassphrase to change it
+// Thread-safe with write lock
+func (c *Config) SetAppLockSettings(settings AppLockSettings) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	settings.PassphraseHash = c.AppLock.PassphraseHash
+	settings.applyDefaults()
+	if err := settings.Validate(); err != nil {
+		return err
+	}
+	c.AppLock = settings
+	return nil
+}
+
+// SetAppLockPassphrase hashes and stores a new app lock passphrase
+// Thread-safe with write lock
+func (c *Config) SetAppLockPassphrase(passphrase string) error {
+	if len(passphrase) < MinAppLockPassphraseLength {
+		return fmt.Errorf("passphrase must be at least %d characters", MinAppLockPassphraseLength)
+	}
+	hash, err := hashAppLockPassphrase(passphrase)
+	if err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	c.AppLock.PassphraseHash = hash
+	c.mu.Unlock()
+	return nil
+}
+
+// AppLock hides the UI behind a passphrase while the service keeps running
+type AppLock struct {
+	config *Config
+
+	mu           sync.Mutex
+	locked       bool
+	lastActivity time.Time
+}
+
+// NewAppLock creates the app lock; it starts locked if the lock is enabled
+func NewAppLock(cfg *Config) *AppLock {
+	return &AppLock{
+		config:       cfg,
+		locked:       cfg.GetAppLockSettings().Enabled,
+		lastActivity: time.Now(),
+	}
+}
+
+// Enabled returns true if the app lock is configured
+func (l *AppLock) Enabled() bool {
+	return l.config.GetAppLockSettings().Enabled
+}
+
+// IsLocked returns true while the UI is locked
+func (l *AppLock) IsLocked() bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.locked
+}
+
+// Lock locks the UI now
+func (l *AppLock) Lock() error {
+	if !l.Enabled() {
+		return fmt.Errorf("app lock is not enabled")
+	}
+	l.mu.Lock()
+	l.locked = true
+	l.mu.Unlock()
+	return nil
+}
+
+// Unlock unlocks the UI if the passphrase matches
+func (l *AppLock) Unlock(passphrase string) error {
+	if err := l.Verify(passphrase); err != nil {
+		return err
+	}
+	l.mu.Lock()
+	l.locked = false
+	l.lastActivity = time.Now()
+	l.mu.Unlock()
+	return nil
+}
+
+// Verify checks the passphrase against the stored hash
+// Failed attempts are delayed and locked out (see RecordPasswordAttempt)
+func (l *AppLock) Verify(passphrase string) error {
+	hash := l.config.GetAppLockSettings().PassphraseHash
+	if hash == "" {
+		return fmt.Errorf("app lock passphrase is not set")
+	}
+	ok, err := GuardPasswordAttempt(AttemptScopeAppLock, func() (bool, error) {
+		return verifyAppLockPassphrase(hash, passphrase)
+	})
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrWrongAppLockPassphrase
+	}
+	return nil
+}
+
+// Reset clears the lock state after the lock is disabled
+func (l *AppLock) Reset() {
+	l.mu.Lock()
+	l.locked = false
+	l.lastActivity = time.Now()
+	l.mu.Unlock()
+}
+
+// Touch records user activity, postponing the idle lock
+func (l *AppLock) Touch() {
+	l.mu.Lock()
+	l.lastActivity = time.Now()
+	l.mu.Unlock()
+}
+
+// CheckIdle locks the app if it has been idle longer than the configured period
+// Returns true if the app was locked by this call
+func (l *AppLock) CheckIdle(now time.Time) bool {
+	settings := l.config.GetAppLockSettings()
+	if !settings.Enabled || settings.IdleMinutes <= 0 {
+		return false
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.locked || now.Sub(l.lastActivity) < time.Duration(settings.IdleMinutes)*time.Minute {
+		return false
+	}
+	l.locked = true
+	return true
+}
+
+// hashAppLockPassphrase hashes a passphrase with Argon2id in PHC string format:
+//
+//	$argon2id$v=19$m=<memory KiB>,t=<time>,p=<threads>$<salt>$<hash>
+func hashAppLockPassphrase(passphrase string) (string, error) {
+	params := CurrentKDFParams()
+	if params.Algorithm != KDFArgon2id {
+		params = EncryptionSettings{
+			KDFTimeCost: DefaultKDFTimeCost,
+			KDFMemoryMB: DefaultKDFMemoryMB,
+			KDFThreads:  DefaultKDFThreads,
+		}.KDFParams()
+	}
+
+	salt := make([]byte, 16)
+	if _, err := io.ReadFull(rand.Reader, salt); err != nil {
+		return "", fmt.Errorf("failed to generate salt: %w", err)
+	}
+	key, err := params.deriveKey(passphrase, salt)
+	if err != nil {
+		return "", err
+	}
+
+	b64 := base64.RawStdEncoding
+	return fmt.Sprintf("$argon2id$v=19$m=%d,t=%d,p=%d$%s$%s",
+		params.MemoryKiB, params.Time, params.Threads, b64.EncodeToString(salt), b64.EncodeToString(key)), nil
+}
+
+// verifyAppLockPassphrase checks a passphrase against a PHC Argon2id hash in constant time
+func verifyAppLockPassphrase(hash, passphrase string) (bool, error) {
+	parts := strings.Split(hash, "$")
+	if len(parts) != 6 || parts[1] != KDFArgon2id || parts[2] != "v=19" {
+		return false, fmt.Errorf("invalid app lock passphrase hash")
+	}
+
+	params := KDFParams{Algorithm: KDFArgon2id}
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &params.MemoryKiB, &params.Time, &params.Threads); err != nil {
+		return false, fmt.Errorf("invalid app lock passphrase hash: %w", err)
+	}
+	if err := params.checkBounds(); err != nil {
+		return false, err
+	}
+
+	b64 := base64.RawStdEncoding
+	salt, err := b64.DecodeString(parts[4])
+	if err != nil {
+		return false, fmt.Errorf("invalid app lock passphrase hash: %w", err)
+	}
+	want, err := b64.DecodeString(parts[5])
+	if err != nil || len(want) != AESKeySize {
+		return false, fmt.Errorf("invalid app lock passphrase hash")
+	}
+
+	got, err := params.deriveKey(passphrase, salt)
+	if err != nil {
+		return false, err
+	}
+	return subtle.ConstantTimeCompare(got, want) == 1, nil
+}
@@ -0,0 +1,258 @@
+package core
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/JB-SelfCompany/Tyr-Desktop/internal/platform"
+)
+
+// Password attempt scopes; each scope is limited separately
+const (
+	AttemptScopeMailPassword   = "mail_password"
+	AttemptScopeBackupPassword = "backup_password"
+	AttemptScopeAppLock        = "app_lock"
+)
+
+const (
+	// MaxPasswordAttempts is the number of failures that triggers a lockout
+	MaxPasswordAttempts = 5
+
+	// freePasswordAttempts is the number of failures allowed without a delay
+	freePasswordAttempts = 2
+
+	// maxAttemptDelay caps the delay between failed attempts
+	maxAttemptDelay = 30 * time.Second
+
+	// baseLockoutDuration is the first lockout; each further lockout doubles it
+	baseLockoutDuration = 5 * time.Minute
+
+	// maxLockoutDuration caps the lockout duration
+	maxLockoutDuration = time.Hour
+)
+
+var (
+	// ErrTooManyAttempts is matched by AttemptLockedError
+	ErrTooManyAttempts = errors.New("too many failed attempts")
+
+	// ErrIncorrectPassword is returned by Config.VerifyPassword for a wrong password
+	ErrIncorrectPassword = errors.New("password is incorrect")
+)
+
+// AttemptLockedError is returned while password attempts are delayed or locked out
+type AttemptLockedError struct {
+	// Scope is the attempt scope that is blocked
+	Scope string
+
+	// RetryAfter is the time until the next attempt is allowed
+	RetryAfter time.Duration
+
+	// Lockout is true after MaxPasswordAttempts failures, false for a delay
+	Lockout bool
+}
+
+// Error implements error
+func (e *AttemptLockedError) Error() string {
+	return fmt.Sprintf("too many failed attempts, try again in %s", FormatRetryAfter(e.RetryAfter))
+}
+
+// Is matches ErrTooManyAttempts
+func (e *AttemptLockedError) Is(target error) bool {
+	return target == ErrTooManyAttempts
+}
+
+// FormatRetryAfter rounds a wait time up to whole seconds for display
+func FormatRetryAfter(d time.Duration) string {
+	return (d + time.Second - 1).Truncate(time.Second).String()
+}
+
+// attemptState is the persisted failure state of one scope
+type attemptState struct {
+	// Failures counts failed attempts since the last success or lockout
+	Failures int `json:"failures"`
+
+	// Lockouts counts lockouts since the last success, for escalation
+	Lockouts int `json:"lockouts"`
+
+	// NotBefore is the earliest time the next attempt is allowed
+	NotBefore time.Time `json:"not_before"`
+
+	// Locked is true if NotBefore is a lockout rather than a delay
+	Locked bool `json:"locked,omitempty"`
+}
+
+// passwordAttempts holds the attempt state, loaded from disk on first use
+// The state is kept across restarts so restarting the app doesn't reset a lockout
+var passwordAttempts struct {
+	mu     sync.Mutex
+	loaded bool
+	scopes map[string]*attemptState
+
+	// guards serializes GuardPasswordAttempt per scope
+	guards map[string]*sync.Mutex
+}
+
+// GuardPasswordAttempt runs verify unless the scope is delayed or locked out,
+// and records the result. Returns *AttemptLockedError without calling verify
+// while blocked. An error from verify is returned as is and doesn't count as a failure
+// Attempts in one scope run one at a time, so concurrent calls can't all pass the
+// check before the first failure is recorded
+func GuardPasswordAttempt(scope string, verify func() (bool, error)) (bool, error) {
+	guard := attemptGuard(scope)
+	guard.Lock()
+	defer guard.Unlock()
+
+	if err := CheckPasswordAttempt(scope); err != nil {
+		return false, err
+	}
+
+	ok, err := verify()
+	if err != nil {
+		return false, err
+	}
+	RecordPasswordAttempt(scope, ok)
+	return ok, nil
+}
+
+// CheckPasswordAttempt returns *AttemptLockedError if the scope doesn't allow an attempt now
+func CheckPasswordAttempt(scope string) error {
+	passwordAttempts.mu.Lock()
+	defer passwordAttempts.mu.Unlock()
+
+	state := attemptStateUnsafe(scope)
+	if wait := time.Until(state.NotBefore); wait > 0 {
+		return &AttemptLockedError{Scope: scope, RetryAfter: wait, Lockout: state.Locked}
+	}
+	return nil
+}
+
+// RecordPasswordAttempt records the result of an attempt
+// A success clears the scope. Failures after the free attempts delay the next one
+// (1s, 2s, 4s... up to 30s), and MaxPasswordAttempts failures lock the scope out
+// for 5 minutes, doubling with each lockout up to an hour. Lockouts are audited
+func RecordPasswordAttempt(scope string, ok bool) {
+	passwordAttempts.mu.Lock()
+	defer passwordAttempts.mu.Unlock()
+
+	state := attemptStateUnsafe(scope)
+	if ok {
+		if state.Failures == 0 && state.Lockouts == 0 {
+			return
+		}
+		delete(passwordAttempts.scopes, scope)
+		savePasswordAttemptsUnsafe()
+		return
+	}
+
+	now := time.Now()
+	state.Failures++
+	state.Locked = false
+	if state.Failures >= MaxPasswordAttempts {
+		duration := baseLockoutDuration << state.Lockouts
+		if duration > maxLockoutDuration || duration <= 0 {
+			duration = maxLockoutDuration
+		}
+		state.Failures = 0
+		state.Lockouts++
+		state.Locked = true
+		state.NotBefore = now.Add(duration)
+
+		log.Printf("[Security] %s locked out for %s after %d failed attempts", scope, duration, MaxPasswordAttempts)
+		RecordAudit(AuditActionPasswordLockout,
+			fmt.Errorf("%s locked for %s after %d failed attempts", scope, duration, MaxPasswordAttempts))
+	} else if state.Failures > freePasswordAttempts {
+		delay := time.Second << (state.Failures - freePasswordAttempts - 1)
+		if delay > maxAttemptDelay {
+			delay = maxAttemptDelay
+		}
+		state.NotBefore = now.Add(delay)
+	}
+	savePasswordAttemptsUnsafe()
+}
+
+// ConstantTimeEqual compares two secrets without leaking where they differ
+// The inputs are hashed first so their lengths don't leak either
+func ConstantTimeEqual(a, b string) bool {
+	ha := sha256.Sum256([]byte(a))
+	hb := sha256.Sum256([]byte(b))
+	return subtle.ConstantTimeCompare(ha[:], hb[:]) == 1
+}
+
+// attemptGuard returns the mutex serializing attempts in a scope
+func attemptGuard(scope string) *sync.Mutex {
+	passwordAttempts.mu.Lock()
+	defer passwordAttempts.mu.Unlock()
+
+	if passwordAttempts.guards == nil {
+		passwordAttempts.guards = make(map[string]*sync.Mutex)
+	}
+	guard, ok := passwordAttempts.guards[scope]
+	if !ok {
+		guard = &sync.Mutex{}
+		passwordAttempts.guards[scope] = guard
+	}
+	return guard
+}
+
+// attemptStateUnsafe returns the state of a scope, loading the file on first use
+// Caller must hold passwordAttempts.mu
+func attemptStateUnsafe(scope string) *attemptState {
+	if !passwordAttempts.loaded {
+		passwordAttempts.scopes = loadPasswordAttempts()
+		passwordAttempts.loaded = true
+	}
+	state, ok := passwordAttempts.scopes[scope]
+	if !ok {
+		state = &attemptState{}
+		passwordAttempts.scopes[scope] = state
+	}
+	return state
+}
+
+// loadPasswordAttempts reads the attempt state file
+// A missing or unreadable file starts with no failures
+func loadPasswordAttempts() map[string]*attemptState {
+	scopes := make(map[string]*attemptState)
+	data, err := os.ReadFile(platform.GetPasswordAttemptsPath())
+	if err != nil {
+		if !os.IsNotExist(err) {
+			log.Printf("[Security] Failed to read password attempts: %v", err)
+		}
+		return scopes
+	}
+	if err := json.Unmarshal(data, &scopes); err != nil {
+		log.Printf("[Security] Failed to parse password attempts: %v", err)
+		return make(map[string]*attemptState)
+	}
+	return scopes
+}
+
+// savePasswordAttemptsUnsafe writes the attempt state file, dropping cleared scopes
+// Caller must hold passwordAttempts.mu
+func savePasswordAttemptsUnsafe() {
+	for scope, state := range passwordAttempts.scopes {
+		if state.Failures == 0 && state.Lockouts == 0 {
+			delete(passwordAttempts.scopes, scope)
+		}
+	}
+
+	if err := EnsureConfigDir(); err != nil {
+		log.Printf("[Security] Failed to save password attempts: %v", err)
+		return
+	}
+	data, err := json.MarshalIndent(passwordAttempts.scopes, "", "  ")
+	if err != nil {
+		log.Printf("[Security] Failed to serialize password attempts: %v", err)
+		return
+	}
+	if err := os.WriteFile(platform.GetPasswordAttemptsPath(), data, 0600); err != nil {
+		log.Printf("[Security] Failed to write password attempts: %v", err)
+	}
+}
@@ -0,0 +1,218 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/JB-SelfCompany/Tyr-Desktop/internal/platform"
+)
+
+// resetPasswordAttempts starts the test with no recorded attempts
+// Lockouts are audited, so the audit log is reset too
+func resetPasswordAttempts(t *testing.T) {
+	t.Helper()
+
+	reset := func() {
+		os.Remove(platform.GetPasswordAttemptsPath())
+		forgetPasswordAttempts()
+	}
+	reset()
+	t.Cleanup(reset)
+	resetAuditLog(t)
+}
+
+// forgetPasswordAttempts drops the in-memory state, as after a restart
+func forgetPasswordAttempts() {
+	passwordAttempts.mu.Lock()
+	passwordAttempts.loaded = false
+	passwordAttempts.scopes = nil
+	passwordAttempts.mu.Unlock()
+}
+
+// retryAfter returns the wait CheckPasswordAttempt reports, 0 if allowed
+func retryAfter(t *testing.T, scope string) (time.Duration, bool) {
+	t.Helper()
+
+	err := CheckPasswordAttempt(scope)
+	if err == nil {
+		return 0, false
+	}
+	var locked *AttemptLockedError
+	if !errors.As(err, &locked) {
+		t.Fatalf("CheckPasswordAttempt = %v, want *AttemptLockedError", err)
+	}
+	if !errors.Is(err, ErrTooManyAttempts) {
+		t.Error("AttemptLockedError doesn't match ErrTooManyAttempts")
+	}
+	return locked.RetryAfter, locked.Lockout
+}
+
+// expectWait checks a wait against the expected duration, allowing for elapsed time
+func expectWait(t *testing.T, label string, got, want time.Duration) {
+	t.Helper()
+
+	if got > want || got < want-time.Second {
+		t.Errorf("%s: retry after %s, want %s", label, got, want)
+	}
+}
+
+func TestPasswordAttemptDelays(t *testing.T) {
+	resetPasswordAttempts(t)
+
+	tests := []struct {
+		failure    int
+		wantWait   time.Duration
+		wantLocked bool
+	}{
+		{1, 0, false},
+		{2, 0, false},
+		{3, time.Second, false},
+		{4, 2 * time.Second, false},
+		{5, baseLockoutDuration, true},
+	}
+
+	for _, tt := range tests {
+		RecordPasswordAttempt(AttemptScopeMailPassword, false)
+		wait, locked := retryAfter(t, AttemptScopeMailPassword)
+		expectWait(t, fmt.Sprintf("failure %d", tt.failure), wait, tt.wantWait)
+		if locked != tt.wantLocked {
+			t.Errorf("failure %d: lockout = %v, want %v", tt.failure, locked, tt.wantLocked)
+		}
+	}
+
+	entries, _ := ReadAuditLog(0)
+	if len(entries) != 1 || entries[0].Action != AuditActionPasswordLockout {
+		t.Errorf("audit log has %d entries, want one %s", len(entries), AuditActionPasswordLockout)
+	}
+
+	// Scopes are limited separately
+	if wait, _ := retryAfter(t, AttemptScopeAppLock); wait != 0 {
+		t.Errorf("app lock scope delayed by mail password failures")
+	}
+}
+
+func TestPasswordLockoutEscalation(t *testing.T) {
+	resetPasswordAttempts(t)
+
+	want := []time.Duration{
+		baseLockoutDuration,
+		2 * baseLockoutDuration,
+		4 * baseLockoutDuration,
+		8 * baseLockoutDuration,
+		maxLockoutDuration,
+		maxLockoutDuration,
+	}
+	for i, duration := range want {
+		for j := 0; j < MaxPasswordAttempts; j++ {
+			RecordPasswordAttempt(AttemptScopeBackupPassword, false)
+		}
+		wait, locked := retryAfter(t, AttemptScopeBackupPassword)
+		if !locked {
+			t.Fatalf("lockout %d: not locked out", i+1)
+		}
+		expectWait(t, fmt.Sprintf("lockout %d", i+1), wait, duration)
+	}
+
+	// A success clears the failures and the escalation
+	RecordPasswordAttempt(AttemptScopeBackupPassword, true)
+	if wait, _ := retryAfter(t, AttemptScopeBackupPassword); wait != 0 {
+		t.Errorf("still delayed by %s after a success", wait)
+	}
+	for j := 0; j < MaxPasswordAttempts; j++ {
+		RecordPasswordAttempt(AttemptScopeBackupPassword, false)
+	}
+	wait, _ := retryAfter(t, AttemptScopeBackupPassword)
+	expectWait(t, "lockout after success", wait, baseLockoutDuration)
+}
+
+func TestPasswordAttemptsPersist(t *testing.T) {
+	resetPasswordAttempts(t)
+
+	for i := 0; i < MaxPasswordAttempts; i++ {
+		RecordPasswordAttempt(AttemptScopeMailPassword, false)
+	}
+	if _, err := os.Stat(platform.GetPasswordAttemptsPath()); err != nil {
+		t.Fatalf("attempt state not written: %v", err)
+	}
+
+	forgetPasswordAttempts()
+	wait, locked := retryAfter(t, AttemptScopeMailPassword)
+	if !locked {
+		t.Fatal("lockout didn't survive a restart")
+	}
+	expectWait(t, "after restart", wait, baseLockoutDuration)
+
+	// Escalation survives too
+	passwordAttempts.mu.Lock()
+	passwordAttempts.scopes[AttemptScopeMailPassword].NotBefore = time.Time{}
+	passwordAttempts.mu.Unlock()
+	for i := 0; i < MaxPasswordAttempts; i++ {
+		RecordPasswordAttempt(AttemptScopeMailPassword, false)
+	}
+	forgetPasswordAttempts()
+	wait, _ = retryAfter(t, AttemptScopeMailPassword)
+	expectWait(t, "second lockout after restart", wait, 2*baseLockoutDuration)
+
+	// A success removes the scope from the file
+	RecordPasswordAttempt(AttemptScopeMailPassword, true)
+	forgetPasswordAttempts()
+	if wait, _ := retryAfter(t, AttemptScopeMailPassword); wait != 0 {
+		t.Errorf("delayed by %s after a success and a restart", wait)
+	}
+}
+
+func TestGuardPasswordAttempt(t *testing.T) {
+	resetPasswordAttempts(t)
+
+	// Errors from verify don't count as failures
+	verifyErr := errors.New("keyring unavailable")
+	for i := 0; i < MaxPasswordAttempts; i++ {
+		if _, err := GuardPasswordAttempt(AttemptScopeMailPassword, func() (bool, error) {
+			return false, verifyErr
+		}); !errors.Is(err, verifyErr) {
+			t.Fatalf("GuardPasswordAttempt = %v, want the verify error", err)
+		}
+	}
+	if wait, _ := retryAfter(t, AttemptScopeMailPassword); wait != 0 {
+		t.Errorf("verify errors delayed the next attempt by %s", wait)
+	}
+
+	ok, err := GuardPasswordAttempt(AttemptScopeMailPassword, func() (bool, error) { return true, nil })
+	if !ok || err != nil {
+		t.Errorf("GuardPasswordAttempt = %v, %v, want true, nil", ok, err)
+	}
+}
+
+func TestGuardPasswordAttemptConcurrent(t *testing.T) {
+	resetPasswordAttempts(t)
+
+	// Concurrent wrong guesses must not all be verified before the first failure
+	// is recorded: only the free attempts and the one that triggers the delay run
+	const guesses = 20
+	var verified atomic.Int32
+	var wg sync.WaitGroup
+	start := make(chan struct{})
+	for i := 0; i < guesses; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			<-start
+			GuardPasswordAttempt(AttemptScopeAppLock, func() (bool, error) {
+				verified.Add(1)
+				time.Sleep(10 * time.Millisecond)
+				return false, nil
+			})
+		}()
+	}
+	close(start)
+	wg.Wait()
+
+	if got := verified.Load(); got != freePasswordAttempts+1 {
+		t.Errorf("verified %d concurrent guesses, want %d", got, freePasswordAttempts+1)
+	}
+}
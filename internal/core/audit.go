@@ -0,0 +1,252 @@
+package core
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/JB-SelfCompany/Tyr-Desktop/internal/platform"
+)
+
+// Audited actions
+const (
+	AuditActionSetPassword      = "set_password"
+	AuditActionChangePassword   = "change_password"
+	AuditActionRegenerateKeys   = "regenerate_keys"
+	AuditActionCreateBackup     = "create_backup"
+	AuditActionRestoreBackup    = "restore_backup"
+	AuditActionOpenDeltaChat    = "open_deltachat"
+	AuditActionEnableAutoStart  = "enable_autostart"
+	AuditActionDisableAutoStart = "disable_autostart"
+	AuditActionPasswordLockout  = "password_lockout"
+)
+
+// Audit outcomes
+const (
+	AuditOutcomeSuccess = "success"
+	AuditOutcomeFailure = "failure"
+)
+
+// auditGenesisHash is the previous hash of the first entry
+var auditGenesisHash = strings.Repeat("0", sha256.Size*2)
+
+// AuditEntry is one line of the security audit log
+// Each entry includes the hash of the previous one, so editing or removing
+// an entry breaks the chain from that point on
+type AuditEntry struct {
+	Seq      int64     `json:"seq"`
+	Time     time.Time `json:"time"`
+	Action   string    `json:"action"`
+	Outcome  string    `json:"outcome"`
+	Reason   string    `json:"reason,omitempty"`
+	PrevHash string    `json:"prev_hash"`
+	Hash     string    `json:"hash"`
+}
+
+// computeHash returns the SHA-256 of the entry without its own hash
+func (e AuditEntry) computeHash() string {
+	e.Hash = ""
+	data, _ := json.Marshal(&e)
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// AuditVerification is the result of checking the audit log hash chain
+type AuditVerification struct {
+	// Valid is true if every entry links to the previous one
+	Valid bool
+
+	// Entries is the number of entries checked, up to the break if any
+	Entries int
+
+	// BrokenAtLine is the 1-based line where the chain breaks (0 if valid)
+	BrokenAtLine int
+
+	// Reason describes the break
+	Reason string
+}
+
+// auditLog holds the chain head so appends don't reread the file
+var auditLog struct {
+	mu       sync.Mutex
+	loaded   bool
+	lastSeq  int64
+	lastHash string
+}
+
+// RecordAudit appends an action to the audit log; a nil err records success
+// Failures to write are logged and don't affect the action
+func RecordAudit(action string, err error) {
+	entry := AuditEntry{
+		Time:    time.Now().UTC(),
+		Action:  action,
+		Outcome: AuditOutcomeSuccess,
+	}
+	if err != nil {
+		entry.Outcome = AuditOutcomeFailure
+		entry.Reason = err.Error()
+	}
+
+	if err := appendAuditEntry(entry); err != nil {
+		log.Printf("[Audit] Failed to record %s: %v", action, err)
+	}
+}
+
+// appendAuditEntry links the entry to the chain head and appends it
+func appendAuditEntry(entry AuditEntry) error {
+	auditLog.mu.Lock()
+	defer auditLog.mu.Unlock()
+
+	if !auditLog.loaded {
+		seq, hash, err := readAuditHead()
+		if err != nil {
+			return err
+		}
+		auditLog.lastSeq, auditLog.lastHash, auditLog.loaded = seq, hash, true
+	}
+
+	entry.Seq = auditLog.lastSeq + 1
+	entry.PrevHash = auditLog.lastHash
+	entry.Hash = entry.computeHash()
+
+	line, err := json.Marshal(&entry)
+	if err != nil {
+		return fmt.Errorf("failed to serialize audit entry: %w", err)
+	}
+	if err := EnsureConfigDir(); err != nil {
+		return err
+	}
+
+	file, err := os.OpenFile(platform.GetAuditLogPath(), os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to open audit log: %w", err)
+	}
+	defer file.Close()
+	if _, err := file.Write(append(line, '\n')); err != nil {
+		return fmt.Errorf("failed to write audit log: %w", err)
+	}
+	if err := file.Sync(); err != nil {
+		return fmt.Errorf("failed to write audit log: %w", err)
+	}
+
+	auditLog.lastSeq = entry.Seq
+	auditLog.lastHash = entry.Hash
+	return nil
+}
+
+// readAuditHead returns the sequence number and hash of the last entry
+// New entries continue from the last readable entry even if the chain is broken,
+// so a break stays visible to verification
+func readAuditHead() (int64, string, error) {
+	entries, err := ReadAuditLog(0)
+	if err != nil {
+		return 0, "", err
+	}
+	for i := len(entries) - 1; i >= 0; i-- {
+		if entries[i].Hash != "" {
+			return entries[i].Seq, entries[i].Hash, nil
+		}
+	}
+	return 0, auditGenesisHash, nil
+}
+
+// ReadAuditLog returns audit entries, oldest first
+// A limit greater than 0 returns only the most recent entries.
+// Lines that can't be parsed are skipped; VerifyAuditLog reports them
+func ReadAuditLog(limit int) ([]AuditEntry, error) {
+	entries := []AuditEntry{}
+	err := scanAuditLog(func(_ int, line string) error {
+		var entry AuditEntry
+		if err := json.Unmarshal([]byte(line), &entry); err == nil {
+			entries = append(entries, entry)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if limit > 0 && len(entries) > limit {
+		entries = entries[len(entries)-limit:]
+	}
+	return entries, nil
+}
+
+// VerifyAuditLog checks that the entries form an unbroken hash chain
+// Detects edited, inserted, reordered and removed entries, except removal of
+// the most recent entries
+func VerifyAuditLog() (AuditVerification, error) {
+	result := AuditVerification{Valid: true}
+	prevHash := auditGenesisHash
+	var prevSeq int64
+
+	err := scanAuditLog(func(lineNo int, line string) error {
+		if !result.Valid {
+			return nil
+		}
+		fail := func(reason string) {
+			result.Valid = false
+			result.BrokenAtLine = lineNo
+			result.Reason = reason
+		}
+
+		var entry AuditEntry
+		if err := json.Unmarshal([]byte(line), &entry); err != nil {
+			fail("entry is not valid JSON")
+			return nil
+		}
+		result.Entries++
+
+		switch {
+		case entry.Seq != prevSeq+1:
+			fail(fmt.Sprintf("expected sequence number %d, found %d", prevSeq+1, entry.Seq))
+		case entry.PrevHash != prevHash:
+			fail("entry doesn't link to the previous entry")
+		case entry.Hash != entry.computeHash():
+			fail("entry content doesn't match its hash")
+		}
+		prevSeq, prevHash = entry.Seq, entry.Hash
+		return nil
+	})
+	if err != nil {
+		return AuditVerification{}, err
+	}
+	return result, nil
+}
+
+// scanAuditLog calls fn for each non-empty line of the audit log with its 1-based number
+func scanAuditLog(fn func(lineNo int, line string) error) error {
+	file, err := os.Open(platform.GetAuditLogPath())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return fmt.Errorf("failed to open audit log: %w", err)
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	lineNo := 0
+	for scanner.Scan() {
+		lineNo++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		if err := fn(lineNo, line); err != nil {
+			return err
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("failed to read audit log: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,188 @@
+package core
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/JB-SelfCompany/Tyr-Desktop/internal/platform"
+)
+
+// resetAuditLog starts the test with an empty audit log and forgets the cached chain head
+func resetAuditLog(t *testing.T) {
+	t.Helper()
+
+	reset := func() {
+		os.Remove(platform.GetAuditLogPath())
+		auditLog.mu.Lock()
+		auditLog.loaded, auditLog.lastSeq, auditLog.lastHash = false, 0, ""
+		auditLog.mu.Unlock()
+	}
+	reset()
+	t.Cleanup(reset)
+}
+
+// readAuditLines returns the lines of the audit log
+func readAuditLines(t *testing.T) []string {
+	t.Helper()
+
+	data, err := os.ReadFile(platform.GetAuditLogPath())
+	if err != nil {
+		t.Fatalf("failed to read audit log: %v", err)
+	}
+	return strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+}
+
+// writeAuditLines replaces the audit log and forgets the cached chain head
+func writeAuditLines(t *testing.T, lines []string) {
+	t.Helper()
+
+	if err := os.WriteFile(platform.GetAuditLogPath(), []byte(strings.Join(lines, "\n")+"\n"), 0600); err != nil {
+		t.Fatalf("failed to write audit log: %v", err)
+	}
+	auditLog.mu.Lock()
+	auditLog.loaded = false
+	auditLog.mu.Unlock()
+}
+
+// editAuditLine rewrites one entry, optionally recomputing its own hash
+func editAuditLine(t *testing.T, line string, rehash bool, edit func(e *AuditEntry)) string {
+	t.Helper()
+
+	var entry AuditEntry
+	if err := json.Unmarshal([]byte(line), &entry); err != nil {
+		t.Fatalf("failed to parse audit entry: %v", err)
+	}
+	edit(&entry)
+	if rehash {
+		entry.Hash = entry.computeHash()
+	}
+	data, _ := json.Marshal(&entry)
+	return string(data)
+}
+
+func TestAuditLogChain(t *testing.T) {
+	resetAuditLog(t)
+
+	RecordAudit(AuditActionSetPassword, nil)
+	RecordAudit(AuditActionCreateBackup, errors.New("disk full"))
+	RecordAudit(AuditActionRestoreBackup, nil)
+
+	entries, err := ReadAuditLog(0)
+	if err != nil {
+		t.Fatalf("ReadAuditLog: %v", err)
+	}
+	if len(entries) != 3 {
+		t.Fatalf("ReadAuditLog returned %d entries, want 3", len(entries))
+	}
+	if entries[0].PrevHash != auditGenesisHash {
+		t.Errorf("first entry links to %s, want the genesis hash", entries[0].PrevHash)
+	}
+	for i, entry := range entries {
+		if entry.Seq != int64(i+1) {
+			t.Errorf("entry %d has sequence number %d", i, entry.Seq)
+		}
+		if i > 0 && entry.PrevHash != entries[i-1].Hash {
+			t.Errorf("entry %d doesn't link to entry %d", i, i-1)
+		}
+	}
+	if entries[1].Outcome != AuditOutcomeFailure || entries[1].Reason != "disk full" {
+		t.Errorf("failed action recorded as %s (%q)", entries[1].Outcome, entries[1].Reason)
+	}
+
+	if limited, _ := ReadAuditLog(2); len(limited) != 2 || limited[1].Seq != 3 {
+		t.Errorf("ReadAuditLog(2) didn't return the 2 most recent entries")
+	}
+
+	result, err := VerifyAuditLog()
+	if err != nil {
+		t.Fatalf("VerifyAuditLog: %v", err)
+	}
+	if !result.Valid || result.Entries != 3 {
+		t.Errorf("VerifyAuditLog = %+v, want 3 valid entries", result)
+	}
+}
+
+func TestVerifyAuditLogDetectsTampering(t *testing.T) {
+	tests := []struct {
+		name       string
+		tamper     func(t *testing.T, lines []string) []string
+		wantValid  bool
+		brokenLine int
+	}{
+		{"untouched", func(t *testing.T, lines []string) []string {
+			return lines
+		}, true, 0},
+		{"edited entry", func(t *testing.T, lines []string) []string {
+			lines[1] = editAuditLine(t, lines[1], false, func(e *AuditEntry) { e.Outcome = AuditOutcomeSuccess })
+			return lines
+		}, false, 2},
+		{"edited entry with its hash recomputed", func(t *testing.T, lines []string) []string {
+			lines[1] = editAuditLine(t, lines[1], true, func(e *AuditEntry) { e.Action = AuditActionSetPassword })
+			return lines
+		}, false, 3},
+		{"removed entry", func(t *testing.T, lines []string) []string {
+			return append(lines[:1], lines[2:]...)
+		}, false, 2},
+		{"removed first entry", func(t *testing.T, lines []string) []string {
+			return lines[1:]
+		}, false, 1},
+		{"reordered entries", func(t *testing.T, lines []string) []string {
+			lines[1], lines[2] = lines[2], lines[1]
+			return lines
+		}, false, 2},
+		{"duplicated entry", func(t *testing.T, lines []string) []string {
+			return append(lines[:2], lines[1:]...)
+		}, false, 3},
+		{"garbage line", func(t *testing.T, lines []string) []string {
+			lines[2] = "not json"
+			return lines
+		}, false, 3},
+		// Removing the most recent entries can't be detected from the file alone
+		{"removed last entry", func(t *testing.T, lines []string) []string {
+			return lines[:len(lines)-1]
+		}, true, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resetAuditLog(t)
+			RecordAudit(AuditActionSetPassword, nil)
+			RecordAudit(AuditActionCreateBackup, errors.New("disk full"))
+			RecordAudit(AuditActionRestoreBackup, nil)
+			RecordAudit(AuditActionOpenDeltaChat, nil)
+
+			writeAuditLines(t, tt.tamper(t, readAuditLines(t)))
+			result, err := VerifyAuditLog()
+			if err != nil {
+				t.Fatalf("VerifyAuditLog: %v", err)
+			}
+			if result.Valid != tt.wantValid || result.BrokenAtLine != tt.brokenLine {
+				t.Errorf("VerifyAuditLog = valid %v at line %d (%s), want valid %v at line %d",
+					result.Valid, result.BrokenAtLine, result.Reason, tt.wantValid, tt.brokenLine)
+			}
+		})
+	}
+}
+
+func TestAuditLogStaysBrokenAfterAppend(t *testing.T) {
+	resetAuditLog(t)
+	RecordAudit(AuditActionSetPassword, nil)
+	RecordAudit(AuditActionCreateBackup, nil)
+	RecordAudit(AuditActionRestoreBackup, nil)
+
+	lines := readAuditLines(t)
+	writeAuditLines(t, append(lines[:1], lines[2:]...))
+
+	// New entries continue from the last entry and must not hide the removal
+	RecordAudit(AuditActionOpenDeltaChat, nil)
+	result, err := VerifyAuditLog()
+	if err != nil {
+		t.Fatalf("VerifyAuditLog: %v", err)
+	}
+	if result.Valid || result.BrokenAtLine != 2 {
+		t.Errorf("VerifyAuditLog = valid %v at line %d, want broken at line 2", result.Valid, result.BrokenAtLine)
+	}
+}
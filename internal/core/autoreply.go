@@ -0,0 +1,394 @@
+package core
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log"
+	"mime"
+	"net/mail"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/JB-SelfCompany/Tyr-Desktop/internal/mailclient"
+	"github.com/JB-SelfCompany/Tyr-Desktop/internal/platform"
+)
+
+const (
+	// DefaultAutoReplyIntervalDays is the minimum time between replies to the same sender
+	DefaultAutoReplyIntervalDays = 7
+
+	// DefaultAutoReplySubject is the default reply subject template
+	DefaultAutoReplySubject = "Auto: {subject}"
+
+	// DefaultAutoReplyBody is the default reply body template
+	DefaultAutoReplyBody = "I'm currently away and will read your message when I'm back."
+
+	// autoReplyHistoryDays is how long reply history is kept
+	autoReplyHistoryDays = 365
+
+	// autoReplyDateLayout is the layout of the start and end dates
+	autoReplyDateLayout = "2006-01-02"
+)
+
+// AutoReplySettings contains the vacation auto-responder configuration
+// Templates may use {subject}, {sender}, {name} and {end_date} placeholders
+type AutoReplySettings struct {
+	// Enabled turns on automatic replies
+	Enabled bool `toml:"enabled"`
+
+	// StartDate is the first day replies are sent (YYYY-MM-DD, empty for immediately)
+	StartDate string `toml:"start_date,omitempty"`
+
+	// EndDate is the last day replies are sent (YYYY-MM-DD, empty for no end)
+	EndDate string `toml:"end_date,omitempty"`
+
+	// IntervalDays is the minimum time between replies to the same sender
+	IntervalDays int `toml:"interval_days"`
+
+	// Subject is the reply subject template
+	Subject string `toml:"subject"`
+
+	// Body is the reply body template
+	Body string `toml:"body"`
+
+	// ContactsOnly restricts replies to senders in the address book
+	ContactsOnly bool `toml:"contacts_only"`
+
+	// Allowlist restricts replies to these addresses (combined with ContactsOnly)
+	Allowlist []string `toml:"allowlist,omitempty"`
+}
+
+// applyDefaults fills in missing auto-reply values
+func (s *AutoReplySettings) applyDefaults() {
+	if s.IntervalDays <= 0 {
+		s.IntervalDays = DefaultAutoReplyIntervalDays
+	}
+	if strings.TrimSpace(s.Subject) == "" {
+		s.Subject = DefaultAutoReplySubject
+	}
+	if strings.TrimSpace(s.Body) == "" {
+		s.Body = DefaultAutoReplyBody
+	}
+	for i, addr := range s.Allowlist {
+		s.Allowlist[i] = NormalizeMailAddress(addr)
+	}
+}
+
+// Validate checks the auto-reply settings for errors
+func (s *AutoReplySettings) Validate() error {
+	start, end, err := s.dateRange()
+	if err != nil {
+		return err
+	}
+	if !start.IsZero() && !end.IsZero() && end.Before(start) {
+		return fmt.Errorf("end date must not be before start date")
+	}
+	if s.IntervalDays < 1 || s.IntervalDays > autoReplyHistoryDays {
+		return fmt.Errorf("reply interval must be between 1 and %d days", autoReplyHistoryDays)
+	}
+	for _, addr := range s.Allowlist {
+		if !IsValidMailAddress(addr) {
+			return fmt.Errorf("invalid allowlist address: %s", addr)
+		}
+	}
+	return nil
+}
+
+// dateRange parses the start and end dates in local time (zero if empty)
+func (s *AutoReplySettings) dateRange() (time.Time, time.Time, error) {
+	var start, end time.Time
+	var err error
+	if s.StartDate != "" {
+		if start, err = time.ParseInLocation(autoReplyDateLayout, s.StartDate, time.Local); err != nil {
+			return start, end, fmt.Errorf("invalid start date %q (expected YYYY-MM-DD)", s.StartDate)
+		}
+	}
+	if s.EndDate != "" {
+		if end, err = time.ParseInLocation(autoReplyDateLayout, s.EndDate, time.Local); err != nil {
+			return start, end, fmt.Errorf("invalid end date %q (expected YYYY-MM-DD)", s.EndDate)
+		}
+	}
+	return start, end, nil
+}
+
+// IsActive returns true if replies are enabled and now is within the date range
+// The end date is inclusive
+func (s *AutoReplySettings) IsActive(now time.Time) bool {
+	if !s.Enabled {
+		return false
+	}
+	start, end, err := s.dateRange()
+	if err != nil {
+		return false
+	}
+	if !start.IsZero() && now.Before(start) {
+		return false
+	}
+	if !end.IsZero() && !now.Before(end.AddDate(0, 0, 1)) {
+		return false
+	}
+	return true
+}
+
+// GetAutoReplySettings returns a copy of the auto-reply settings
+// Thread-safe with read lock
+func (c *Config) GetAutoReplySettings() AutoReplySettings {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	settings := c.AutoReply
+	settings.Allowlist = append([]string(nil), c.AutoReply.Allowlist...)
+	return settings
+}
+
+// SetAutoReplySettings validates and replaces the auto-reply settings
+// Thread-safe with write lock
+func (c *Config) SetAutoReplySettings(settings AutoReplySettings) error {
+	settings.Allowlist = append([]string(nil), settings.Allowlist...)
+	settings.applyDefaults()
+	if err := settings.Validate(); err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.AutoReply = settings
+	return nil
+}
+
+// AutoReplyResult describes what the auto-responder did with a new message
+type AutoReplyResult struct {
+	// Sent indicates a reply was submitted
+	Sent bool
+
+	// To is the address the reply was (or would have been) sent to
+	To string
+
+	// Subject is the reply subject
+	Subject string
+
+	// Reason explains why no reply was sent
+	Reason string
+}
+
+// AutoResponder sends vacation replies to new mail
+// Remembers when each sender last got a reply so repeated mail is answered once per interval
+type AutoResponder struct {
+	config   *Config
+	contacts *ContactStore
+
+	// path is the reply history file location
+	path string
+
+	// replied maps sender address to the time of the last reply
+	replied map[string]time.Time
+
+	// Mutex serializes replies and protects the history
+	mu sync.Mutex
+}
+
+// NewAutoResponder creates an auto-responder and loads its reply history
+// contacts may be nil; ContactsOnly then matches nobody
+func NewAutoResponder(config *Config, contacts *ContactStore) (*AutoResponder, error) {
+	r := &AutoResponder{
+		config:   config,
+		contacts: contacts,
+		path:     platform.GetAutoReplyStatePath(),
+		replied:  make(map[string]time.Time),
+	}
+
+	data, err := os.ReadFile(r.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return r, nil
+		}
+		return nil, fmt.Errorf("failed to read auto-reply history: %w", err)
+	}
+	if err := json.Unmarshal(data, &r.replied); err != nil {
+		return nil, fmt.Errorf("failed to parse auto-reply history: %w", err)
+	}
+	return r, nil
+}
+
+// saveUnsafe writes the reply history to disk (caller must hold the lock)
+func (r *AutoResponder) saveUnsafe() error {
+	if err := EnsureConfigDir(); err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(r.replied, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to serialize auto-reply history: %w", err)
+	}
+	if err := os.WriteFile(r.path, data, 0600); err != nil {
+		return fmt.Errorf("failed to write auto-reply history: %w", err)
+	}
+	return nil
+}
+
+// ResetHistory forgets all previous replies so every sender gets a new one
+// Thread-safe
+func (r *AutoResponder) ResetHistory() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.replied = make(map[string]time.Time)
+	return r.saveUnsafe()
+}
+
+// HandleNewMail replies to a newly received message if the settings allow it
+// The message headers are read over local IMAP to detect bulk, list and
+// auto-generated mail (RFC 3834); the reply is submitted through local SMTP
+// Thread-safe
+func (r *AutoResponder) HandleNewMail(sm *ServiceManager, outbox *OutboxStore, mailbox, from string, uid uint32) (*AutoReplyResult, error) {
+	settings := r.config.GetAutoReplySettings()
+	sender := NormalizeMailAddress(ExtractMailAddress(from))
+	result := &AutoReplyResult{To: sender}
+
+	if !settings.IsActive(time.Now()) {
+		result.Reason = "auto-reply is not active"
+		return result, nil
+	}
+	if sender == "" || sender == NormalizeMailAddress(sm.GetMailAddress()) {
+		result.Reason = "no sender or message from self"
+		return result, nil
+	}
+	if reason := r.checkSender(settings, sender); reason != "" {
+		result.Reason = reason
+		return result, nil
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	interval := time.Duration(settings.IntervalDays) * 24 * time.Hour
+	if last, ok := r.replied[sender]; ok && time.Since(last) < interval {
+		result.Reason = fmt.Sprintf("already replied on %s", last.Format(autoReplyDateLayout))
+		return result, nil
+	}
+
+	var rawHeader []byte
+	err := sm.withIMAPSession(func(s *mailclient.IMAPSession) error {
+		if _, err := s.Examine(mailbox); err != nil {
+			return err
+		}
+		var err error
+		rawHeader, err = s.FetchRawHeader(uid)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	msg, err := mail.ReadMessage(bytes.NewReader(append(rawHeader, '\r', '\n')))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse message header: %w", err)
+	}
+	if reason := autoReplySuppressed(msg.Header, sender); reason != "" {
+		result.Reason = reason
+		return result, nil
+	}
+
+	subject, err := new(mime.WordDecoder).DecodeHeader(msg.Header.Get("Subject"))
+	if err != nil {
+		subject = msg.Header.Get("Subject")
+	}
+	name := sender
+	if r.contacts != nil {
+		if resolved := r.contacts.ResolveName(sender); resolved != "" {
+			name = resolved
+		}
+	}
+	replacer := strings.NewReplacer(
+		"{subject}", subject,
+		"{sender}", sender,
+		"{name}", name,
+		"{end_date}", settings.EndDate,
+	)
+
+	reply := &mailclient.OutgoingMessage{
+		To:       []string{sender},
+		Subject:  strings.TrimSpace(replacer.Replace(settings.Subject)),
+		TextBody: replacer.Replace(settings.Body),
+		Headers: map[string]string{
+			"Auto-Submitted":           "auto-replied",
+			"X-Auto-Response-Suppress": "All",
+		},
+	}
+	if messageID := strings.TrimSpace(msg.Header.Get("Message-Id")); messageID != "" {
+		reply.Headers["In-Reply-To"] = messageID
+		reply.Headers["References"] = messageID
+	}
+	result.Subject = reply.Subject
+
+	if _, err := sm.SendMail(reply, outbox, nil); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	r.replied[sender] = now
+	for addr, last := range r.replied {
+		if now.Sub(last) > autoReplyHistoryDays*24*time.Hour {
+			delete(r.replied, addr)
+		}
+	}
+	if err := r.saveUnsafe(); err != nil {
+		log.Printf("[AutoReply] Warning: %v", err)
+	}
+
+	result.Sent = true
+	log.Printf("[AutoReply] Sent automatic reply to %s", ShortMailAddress(sender))
+	return result, nil
+}
+
+// checkSender applies the contact allowlist; returns a reason if the sender is excluded
+func (r *AutoResponder) checkSender(settings AutoReplySettings, sender string) string {
+	if !settings.ContactsOnly && len(settings.Allowlist) == 0 {
+		return ""
+	}
+	for _, addr := range settings.Allowlist {
+		if addr == sender {
+			return ""
+		}
+	}
+	if settings.ContactsOnly && r.contacts != nil {
+		if _, ok := r.contacts.Get(sender); ok {
+			return ""
+		}
+	}
+	return "sender is not in the allowlist"
+}
+
+// autoReplySuppressed checks headers for mail that must not be answered automatically
+// Follows RFC 3834 section 2: auto-submitted, bulk, list and bounce messages are skipped
+func autoReplySuppressed(h mail.Header, sender string) string {
+	if v := strings.ToLower(strings.TrimSpace(h.Get("Auto-Submitted"))); v != "" && v != "no" {
+		return "message is auto-submitted (" + v + ")"
+	}
+	switch strings.ToLower(strings.TrimSpace(h.Get("Precedence"))) {
+	case "bulk", "list", "junk":
+		return "message is bulk or list mail"
+	}
+	for _, key := range []string{"List-Id", "List-Unsubscribe", "List-Post"} {
+		if h.Get(key) != "" {
+			return "message is from a mailing list"
+		}
+	}
+	if v := strings.ToLower(h.Get("X-Auto-Response-Suppress")); strings.Contains(v, "all") || strings.Contains(v, "oof") {
+		return "sender asked not to receive automatic replies"
+	}
+	if strings.TrimSpace(h.Get("Return-Path")) == "<>" {
+		return "message is a bounce"
+	}
+
+	local, _, _ := strings.Cut(sender, "@")
+	switch strings.ToLower(local) {
+	case "mailer-daemon", "postmaster", "noreply", "no-reply", "donotreply", "do-not-reply":
+		return "sender is a system address"
+	}
+	return ""
+}
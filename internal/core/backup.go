@@ -3,10 +3,14 @@ package core
 import (
 	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"log"
 	"os"
 	"time"
+
+	"github.com/JB-SelfCompany/Tyr-Desktop/internal/platform"
 )
 
 // BackupData represents the structure of an encrypted backup file
@@ -26,6 +30,9 @@ type BackupData struct {
 
 	// IncludesDatabase indicates if the database is included in this backup
 	IncludesDatabase bool `json:"includes_database"`
+
+	// Contacts contains the address book (absent in backups made before contacts existed)
+	Contacts []Contact `json:"contacts,omitempty"`
 }
 
 // ConfigBackup represents the configuration data to be backed up
@@ -55,6 +62,12 @@ type ConfigBackup struct {
 	// AutoStart indicates if auto-start is enabled
 	AutoStart bool `json:"auto_start"`
 
+	// ProxyEnabled indicates if peer connections go through the SOCKS5 proxy
+	ProxyEnabled bool `json:"proxy_enabled,omitempty"`
+
+	// ProxyAddress is the SOCKS5 proxy address
+	ProxyAddress string `json:"proxy_address,omitempty"`
+
 	// Password is the encrypted password (if available)
 	// Note: This is encrypted separately by the keyring, not by backup encryption
 	Password string `json:"password,omitempty"`
@@ -62,8 +75,9 @@ type ConfigBackup struct {
 
 // PeerBackup represents a peer configuration for backup
 type PeerBackup struct {
-	Address string `json:"address"`
-	Enabled bool   `json:"enabled"`
+	Address     string `json:"address"`
+	Enabled     bool   `json:"enabled"`
+	BypassProxy bool   `json:"bypass_proxy,omitempty"`
 }
 
 // Backup format version constants
@@ -119,12 +133,21 @@ func CreateBackup(config *Config, includeDB bool, password string) ([]byte, erro
 		configPassword = ""
 	}
 
+	// Read contacts from disk; a broken contacts file should not block the backup
+	var contacts []Contact
+	if store, err := LoadContacts(); err != nil {
+		log.Printf("[Backup] WARNING: Failed to read contacts: %v - backup will not include contacts", err)
+	} else {
+		contacts = store.List()
+	}
+
 	// Create backup data structure
 	backupData := BackupData{
 		Version:          CurrentBackupVersion,
 		Timestamp:        time.Now().UTC().Format(time.RFC3339),
 		IncludesDatabase: includeDB,
 		Database:         databaseB64,
+		Contacts:         contacts,
 		Config: ConfigBackup{
 			OnboardingComplete: config.OnboardingComplete,
 			SMTPAddress:        config.ServiceSettings.SMTPAddress,
@@ -133,6 +156,8 @@ func CreateBackup(config *Config, includeDB bool, password string) ([]byte, erro
 			Theme:              config.UIPreferences.Theme,
 			Language:           config.UIPreferences.Language,
 			AutoStart:          config.UIPreferences.AutoStart,
+			ProxyEnabled:       config.ServiceSettings.Proxy.Enabled,
+			ProxyAddress:       config.ServiceSettings.Proxy.Address,
 			Password:           configPassword,
 			Peers:              make([]PeerBackup, 0, len(config.NetworkPeers)),
 		},
@@ -142,8 +167,9 @@ func CreateBackup(config *Config, includeDB bool, password string) ([]byte, erro
 	config.mu.RLock()
 	for _, peer := range config.NetworkPeers {
 		backupData.Config.Peers = append(backupData.Config.Peers, PeerBackup{
-			Address: peer.Address,
-			Enabled: peer.Enabled,
+			Address:     peer.Address,
+			Enabled:     peer.Enabled,
+			BypassProxy: peer.BypassProxy,
 		})
 	}
 	config.mu.RUnlock()
@@ -164,33 +190,35 @@ func CreateBackup(config *Config, includeDB bool, password string) ([]byte, erro
 }
 
 // RestoreBackup decrypts and restores a backup file
-// Returns the restored configuration and optional database bytes
+// Returns the restored configuration, optional database bytes and the contacts,
+// nil for older backups without them. Write the contacts with RestoreContacts once
+// the database is restored
 // Validates backup version compatibility and data integrity
 // Thread-safe and provides detailed error messages
-func RestoreBackup(data []byte, password string) (*Config, []byte, error) {
+func RestoreBackup(data []byte, password string) (*Config, []byte, []Contact, error) {
 	// Validate inputs
 	if len(data) == 0 {
-		return nil, nil, fmt.Errorf("backup data is empty")
+		return nil, nil, nil, fmt.Errorf("backup data is empty")
 	}
 	if len(password) < MinBackupPasswordLength {
-		return nil, nil, fmt.Errorf("backup password must be at least %d characters", MinBackupPasswordLength)
+		return nil, nil, nil, fmt.Errorf("backup password must be at least %d characters", MinBackupPasswordLength)
 	}
 
 	// Decrypt backup data
-	decrypted, err := DecryptAESGCM(data, password)
+	decrypted, err := decryptBackup(data, password)
 	if err != nil {
-		return nil, nil, fmt.Errorf("failed to decrypt backup (invalid password or corrupted file): %w", err)
+		return nil, nil, nil, err
 	}
 
 	// Parse JSON
 	var backupData BackupData
 	if err := json.Unmarshal([]byte(decrypted), &backupData); err != nil {
-		return nil, nil, fmt.Errorf("failed to parse backup data (corrupted backup): %w", err)
+		return nil, nil, nil, fmt.Errorf("failed to parse backup data (corrupted backup): %w", err)
 	}
 
 	// Validate backup version
 	if !isCompatibleVersion(backupData.Version) {
-		return nil, nil, fmt.Errorf("incompatible backup version %s (current version: %s)", backupData.Version, CurrentBackupVersion)
+		return nil, nil, nil, fmt.Errorf("incompatible backup version %s (current version: %s)", backupData.Version, CurrentBackupVersion)
 	}
 
 	// Create config from backup data
@@ -200,6 +228,10 @@ func RestoreBackup(data []byte, password string) (*Config, []byte, error) {
 			SMTPAddress:  backupData.Config.SMTPAddress,
 			IMAPAddress:  backupData.Config.IMAPAddress,
 			DatabasePath: backupData.Config.DatabasePath,
+			Proxy: ProxySettings{
+				Enabled: backupData.Config.ProxyEnabled,
+				Address: backupData.Config.ProxyAddress,
+			},
 		},
 		UIPreferences: UIPreferences{
 			Theme:     backupData.Config.Theme,
@@ -212,8 +244,9 @@ func RestoreBackup(data []byte, password string) (*Config, []byte, error) {
 	// Restore peer configurations
 	for _, peer := range backupData.Config.Peers {
 		config.NetworkPeers = append(config.NetworkPeers, PeerConfig{
-			Address: peer.Address,
-			Enabled: peer.Enabled,
+			Address:     peer.Address,
+			Enabled:     peer.Enabled,
+			BypassProxy: peer.BypassProxy,
 		})
 	}
 
@@ -227,7 +260,7 @@ func RestoreBackup(data []byte, password string) (*Config, []byte, error) {
 		log.Println("[Restore] Decoding database from base64...")
 		databaseBytes, err = base64.StdEncoding.DecodeString(backupData.Database)
 		if err != nil {
-			return nil, nil, fmt.Errorf("failed to decode database from backup: %w", err)
+			return nil, nil, nil, fmt.Errorf("failed to decode database from backup: %w", err)
 		}
 		log.Printf("[Restore] Database decoded successfully (%d bytes)", len(databaseBytes))
 	} else {
@@ -242,7 +275,7 @@ func RestoreBackup(data []byte, password string) (*Config, []byte, error) {
 		}
 	}
 
-	return config, databaseBytes, nil
+	return config, databaseBytes, backupData.Contacts, nil
 }
 
 // WriteBackupFile writes encrypted backup data to a file
@@ -265,6 +298,24 @@ func WriteBackupFile(path string, data []byte) error {
 	return nil
 }
 
+// UpgradeBackupFile re-encrypts a backup file in place if it uses the legacy
+// format or a weaker key derivation than the current settings
+// Returns true if the file was rewritten
+func UpgradeBackupFile(path string, data []byte, password string) (bool, error) {
+	upgraded, changed, err := UpgradeEncryption(data, password)
+	if err != nil || !changed {
+		return false, err
+	}
+	err = writeFileAtomic(path, func(w io.Writer) error {
+		_, err := w.Write(upgraded)
+		return err
+	})
+	if err != nil {
+		return false, fmt.Errorf("failed to write upgraded backup file: %w", err)
+	}
+	return true, nil
+}
+
 // ReadBackupFile reads encrypted backup data from a file
 // Validates file exists and is readable
 // Thread-safe
@@ -312,12 +363,34 @@ func GenerateBackupFilename() string {
 }
 
 // VerifyBackupPassword verifies a backup password without full restoration
-// Useful for validating password before proceeding with restore
+// Useful for validating password before proceeding with restore.
+// Returns *AttemptLockedError while attempts are delayed or locked out
 // Thread-safe
-func VerifyBackupPassword(data []byte, password string) bool {
+func VerifyBackupPassword(data []byte, password string) (bool, error) {
 	// Try to decrypt - if successful, password is correct
-	_, err := DecryptAESGCM(data, password)
-	return err == nil
+	_, err := decryptBackup(data, password)
+	if errors.Is(err, ErrTooManyAttempts) {
+		return false, err
+	}
+	return err == nil, nil
+}
+
+// decryptBackup decrypts backup data, counting failures as wrong password attempts
+// A corrupted file can't be told apart from a wrong password, so it counts too
+func decryptBackup(data []byte, password string) (string, error) {
+	var decrypted string
+	ok, err := GuardPasswordAttempt(AttemptScopeBackupPassword, func() (bool, error) {
+		var decryptErr error
+		decrypted, decryptErr = DecryptAESGCM(data, password)
+		return decryptErr == nil, nil
+	})
+	if err != nil {
+		return "", err
+	}
+	if !ok {
+		return "", fmt.Errorf("failed to decrypt backup (invalid password or corrupted file)")
+	}
+	return decrypted, nil
 }
 
 // RestoreDatabase writes database bytes to the configured database path
@@ -347,6 +420,20 @@ func RestoreDatabase(config *Config, databaseBytes []byte) error {
 	return nil
 }
 
+// RestoreContacts replaces the contacts file with contacts from a backup
+// nil (a backup without contacts) leaves the address book untouched
+func RestoreContacts(contacts []Contact) error {
+	if contacts == nil {
+		return nil
+	}
+	store, err := LoadContacts()
+	if err != nil {
+		// Existing file is unreadable; start from an empty store at the same path
+		store = &ContactStore{path: platform.GetContactsPath(), contacts: make(map[string]Contact)}
+	}
+	return store.Replace(contacts)
+}
+
 // readDatabase reads the yggmail.db file and returns its contents
 // Returns error if file doesn't exist or can't be read
 func readDatabase(dbPath string) ([]byte, error) {
@@ -392,9 +479,9 @@ func isCompatibleVersion(version string) bool {
 // Requires correct password to decrypt metadata
 func GetBackupInfo(data []byte, password string) (version, timestamp string, includesDB bool, err error) {
 	// Decrypt backup
-	decrypted, err := DecryptAESGCM(data, password)
+	decrypted, err := decryptBackup(data, password)
 	if err != nil {
-		return "", "", false, fmt.Errorf("failed to decrypt backup: %w", err)
+		return "", "", false, err
 	}
 
 	// Parse JSON to extract metadata only
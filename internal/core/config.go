@@ -29,6 +29,33 @@ type Config struct {
 	// UIPreferences contains user interface preferences
 	UIPreferences UIPreferences `toml:"ui_preferences"`
 
+	// Notifications contains new-mail notification rules and quiet hours
+	Notifications NotificationSettings `toml:"notifications"`
+
+	// Retention contains per-mailbox retention policies and their schedule
+	Retention RetentionSettings `toml:"retention"`
+
+	// StorageQuota contains the storage budget and warning thresholds
+	StorageQuota QuotaSettings `toml:"storage_quota"`
+
+	// AutoReply contains the vacation auto-responder settings
+	AutoReply AutoReplySettings `toml:"auto_reply"`
+
+	// Hooks contains user-defined hooks run on service, mail and connection events
+	Hooks HookSettings `toml:"hooks"`
+
+	// SenderFilter contains the sender block and allow lists and rate limits
+	SenderFilter SenderFilterSettings `toml:"sender_filter"`
+
+	// Encryption contains the key derivation cost for backups and stored secrets
+	Encryption EncryptionSettings `toml:"encryption"`
+
+	// AppLock contains the application lock passphrase and idle timeout
+	AppLock AppLockSettings `toml:"app_lock"`
+
+	// LANAccess contains the TLS listeners serving IMAP/SMTP to other devices
+	LANAccess LANAccessSettings `toml:"lan_access"`
+
 	// CachedDiscoveredPeers contains cached discovered peers (TTL: 24 hours)
 	CachedDiscoveredPeers []DiscoveredPeer `toml:"cached_discovered_peers,omitempty"`
 
@@ -57,6 +84,27 @@ type ServiceSettings struct {
 	// MaxMessageSizeMB is the maximum size of individual messages in megabytes
 	// Default: 50 MB, Range: 10-500 MB
 	MaxMessageSizeMB int64 `toml:"max_message_size_mb"`
+
+	// Proxy contains the global SOCKS5 proxy used for outgoing peer connections
+	Proxy ProxySettings `toml:"proxy"`
+
+	// AutoconfigServeQR enables serving the mail address QR code on the autoconfig page
+	// The QR never contains the password
+	AutoconfigServeQR bool `toml:"autoconfig_serve_qr"`
+
+	// AutoconfigRandomPort runs the autoconfig server on a random loopback port
+	// instead of 127.0.0.1:8080; clients get the URL from GetAutoconfigURL
+	AutoconfigRandomPort bool `toml:"autoconfig_random_port"`
+}
+
+// ProxySettings contains the global SOCKS5 proxy configuration
+// When enabled, tcp:// and tls:// peers are dialed through the proxy
+type ProxySettings struct {
+	// Enabled indicates if peer connections should be routed through the proxy
+	Enabled bool `toml:"enabled"`
+
+	// Address is the SOCKS5 proxy address (e.g., 127.0.0.1:9050 for Tor)
+	Address string `toml:"address"`
 }
 
 // PeerConfig represents a Yggdrasil network peer configuration
@@ -70,6 +118,9 @@ type PeerConfig struct {
 
 	// Enabled indicates if this peer should be used for connections
 	Enabled bool `toml:"enabled"`
+
+	// BypassProxy excludes this peer from the global proxy (connects directly)
+	BypassProxy bool `toml:"bypass_proxy,omitempty"`
 }
 
 // UIPreferences contains user interface configuration
@@ -85,6 +136,9 @@ type UIPreferences struct {
 
 	// WindowState contains the window size and position
 	WindowState WindowState `toml:"window_state"`
+
+	// ClipboardClearSeconds is how long copied secrets stay in the clipboard
+	ClipboardClearSeconds int `toml:"clipboard_clear_seconds"`
 }
 
 // WindowState contains window size and position information
@@ -137,6 +191,16 @@ const (
 	// Message size constraints
 	MinMaxMessageSizeMB = 10
 	MaxMaxMessageSizeMB = 500
+
+	// DefaultProxyAddress is the default SOCKS5 proxy address (local Tor daemon)
+	DefaultProxyAddress = "127.0.0.1:9050"
+
+	// DefaultClipboardClearSeconds is how long copied secrets stay in the clipboard by default
+	DefaultClipboardClearSeconds = 30
+
+	// Clipboard clear timeout constraints
+	MinClipboardClearSeconds = 5
+	MaxClipboardClearSeconds = 600
 )
 
 // DefaultPeers is the list of default Yggdrasil network peers
@@ -257,6 +321,26 @@ func (c *Config) GetPassword() (string, error) {
 	return password, nil
 }
 
+// VerifyPassword checks a password against the stored one in constant time
+// Failed attempts are delayed and locked out (see GuardPasswordAttempt).
+// Returns ErrIncorrectPassword for a wrong password
+func (c *Config) VerifyPassword(password string) error {
+	ok, err := GuardPasswordAttempt(AttemptScopeMailPassword, func() (bool, error) {
+		storedPassword, err := c.GetPassword()
+		if err != nil {
+			return false, err
+		}
+		return ConstantTimeEqual(storedPassword, password), nil
+	})
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrIncorrectPassword
+	}
+	return nil
+}
+
 // SetPassword stores the password in the OS keyring
 // Validates password meets minimum requirements (6 characters)
 // Thread-safe and uses OS-specific secure storage
@@ -385,6 +469,101 @@ func (c *Config) GetEnabledPeers() []string {
 	return enabled
 }
 
+// GetConnectionPeers returns the enabled peer addresses in the form passed to yggmail
+// If the global proxy is enabled, tcp:// and tls:// peers are rewritten into their
+// socks:// and sockstls:// forms unless the peer opted out with BypassProxy
+// Thread-safe with read lock
+func (c *Config) GetConnectionPeers() []string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	peers := make([]string, 0, len(c.NetworkPeers))
+	for _, peer := range c.NetworkPeers {
+		if !peer.Enabled {
+			continue
+		}
+		address, _ := c.connectionAddressUnsafe(peer)
+		peers = append(peers, address)
+	}
+
+	return peers
+}
+
+// GetConnectionAddress returns the address yggmail uses for the given peer
+// and whether the connection is routed through the global proxy
+// Thread-safe with read lock
+func (c *Config) GetConnectionAddress(peer PeerConfig) (string, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return c.connectionAddressUnsafe(peer)
+}
+
+// connectionAddressUnsafe resolves the connection address without locking
+// Must be called with read lock held
+func (c *Config) connectionAddressUnsafe(peer PeerConfig) (string, bool) {
+	proxy := c.ServiceSettings.Proxy
+	if !proxy.Enabled || proxy.Address == "" || peer.BypassProxy {
+		return peer.Address, false
+	}
+
+	return ProxyPeerAddress(peer.Address, proxy.Address)
+}
+
+// SetProxy updates the global proxy settings
+// Validates the proxy address when the proxy is enabled
+// Thread-safe with write lock
+func (c *Config) SetProxy(enabled bool, address string) error {
+	address = strings.TrimSpace(address)
+	if address == "" {
+		address = DefaultProxyAddress
+	}
+	if !isValidProxyAddress(address) {
+		return fmt.Errorf("invalid proxy address format. Expected host:port (e.g., %s)", DefaultProxyAddress)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.ServiceSettings.Proxy.Enabled = enabled
+	c.ServiceSettings.Proxy.Address = address
+	return nil
+}
+
+// SetPeerBypassProxy sets whether a peer connects directly instead of through the proxy
+// Thread-safe with write lock
+func (c *Config) SetPeerBypassProxy(address string, bypass bool) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for i := range c.NetworkPeers {
+		if c.NetworkPeers[i].Address == address {
+			c.NetworkPeers[i].BypassProxy = bypass
+			return nil
+		}
+	}
+
+	return fmt.Errorf("peer not found: %s", address)
+}
+
+// SetAutoconfigServeQR sets whether the autoconfig page serves the mail address QR code
+// Thread-safe with write lock
+func (c *Config) SetAutoconfigServeQR(enabled bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.ServiceSettings.AutoconfigServeQR = enabled
+}
+
+// SetAutoconfigRandomPort sets whether the autoconfig server uses a random port
+// Thread-safe with write lock
+func (c *Config) SetAutoconfigRandomPort(enabled bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.ServiceSettings.AutoconfigRandomPort = enabled
+}
+
 // SetMaxMessageSizeMB sets the maximum message size in megabytes
 // Validates the value is within allowed range (10-500 MB)
 // Thread-safe with write lock
@@ -400,6 +579,30 @@ func (c *Config) SetMaxMessageSizeMB(sizeMB int64) error {
 	return nil
 }
 
+// SetClipboardClearSeconds sets how long copied secrets stay in the clipboard
+// Validates the value is within allowed range (5-600 seconds)
+// Thread-safe with write lock
+func (c *Config) SetClipboardClearSeconds(seconds int) error {
+	if seconds < MinClipboardClearSeconds || seconds > MaxClipboardClearSeconds {
+		return fmt.Errorf("clipboard clear timeout must be between %d and %d seconds", MinClipboardClearSeconds, MaxClipboardClearSeconds)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.UIPreferences.ClipboardClearSeconds = seconds
+	return nil
+}
+
+// GetClipboardClearSeconds returns how long copied secrets stay in the clipboard
+// Thread-safe with read lock
+func (c *Config) GetClipboardClearSeconds() int {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return c.UIPreferences.ClipboardClearSeconds
+}
+
 // GetMaxMessageSizeMB returns the current maximum message size in megabytes
 // Thread-safe with read lock
 func (c *Config) GetMaxMessageSizeMB() int64 {
@@ -426,12 +629,57 @@ func newDefaultConfig() *Config {
 			SMTPAddress:  DefaultSMTPAddress,
 			IMAPAddress:  DefaultIMAPAddress,
 			DatabasePath: platform.GetDatabasePath(),
+			Proxy: ProxySettings{
+				Enabled: false,
+				Address: DefaultProxyAddress,
+			},
 		},
 		NetworkPeers: defaultPeers,
 		UIPreferences: UIPreferences{
-			Theme:     DefaultTheme,
-			Language:  DefaultLanguage,
-			AutoStart: false,
+			Theme:                 DefaultTheme,
+			Language:              DefaultLanguage,
+			AutoStart:             false,
+			ClipboardClearSeconds: DefaultClipboardClearSeconds,
+		},
+		Notifications: NotificationSettings{
+			DefaultAction: NotificationActionNotify,
+			QuietHours: QuietHours{
+				Start: DefaultQuietHoursStart,
+				End:   DefaultQuietHoursEnd,
+			},
+		},
+		Retention: RetentionSettings{
+			IntervalHours: DefaultRetentionIntervalHours,
+		},
+		StorageQuota: QuotaSettings{
+			WarningThresholds:       append([]int(nil), DefaultQuotaWarningThresholds...),
+			RestrictedMessageSizeMB: DefaultQuotaRestrictedMessageSizeMB,
+		},
+		AutoReply: AutoReplySettings{
+			IntervalDays: DefaultAutoReplyIntervalDays,
+			Subject:      DefaultAutoReplySubject,
+			Body:         DefaultAutoReplyBody,
+		},
+		SenderFilter: SenderFilterSettings{
+			BlockAction:       SenderActionJunk,
+			JunkMailbox:       DefaultJunkMailbox,
+			QuarantineMailbox: DefaultQuarantineMailbox,
+			RateWindowMinutes: DefaultSenderRateWindowMinutes,
+		},
+		Encryption: EncryptionSettings{
+			KDFTimeCost: DefaultKDFTimeCost,
+			KDFMemoryMB: DefaultKDFMemoryMB,
+			KDFThreads:  DefaultKDFThreads,
+		},
+		AppLock: AppLockSettings{
+			IdleMinutes: DefaultAppLockIdleMinutes,
+		},
+		LANAccess: LANAccessSettings{
+			BindAddress: DefaultLANBindAddress,
+			TLSMode:     LANTLSModeImplicit,
+			SMTPPort:    DefaultLANSMTPPort,
+			IMAPPort:    DefaultLANIMAPPort,
+			Allowlist:   []string{},
 		},
 	}
 }
@@ -451,6 +699,36 @@ func (c *Config) applyDefaults() {
 	if c.ServiceSettings.MaxMessageSizeMB == 0 {
 		c.ServiceSettings.MaxMessageSizeMB = DefaultMaxMessageSizeMB
 	}
+	if c.ServiceSettings.Proxy.Address == "" {
+		c.ServiceSettings.Proxy.Address = DefaultProxyAddress
+	}
+
+	// Apply notification defaults
+	c.Notifications.applyDefaults()
+
+	// Apply retention defaults
+	c.Retention.applyDefaults()
+
+	// Apply storage quota defaults
+	c.StorageQuota.applyDefaults()
+
+	// Apply auto-reply defaults
+	c.AutoReply.applyDefaults()
+
+	// Apply hook defaults
+	c.Hooks.applyDefaults()
+
+	// Apply sender filter defaults
+	c.SenderFilter.applyDefaults()
+
+	// Apply encryption defaults
+	c.Encryption.applyDefaults()
+
+	// Apply app lock defaults
+	c.AppLock.applyDefaults()
+
+	// Apply LAN access defaults
+	c.LANAccess.applyDefaults()
 
 	// Apply UI preferences defaults
 	if c.UIPreferences.Theme == "" {
@@ -459,6 +737,9 @@ func (c *Config) applyDefaults() {
 	if c.UIPreferences.Language == "" {
 		c.UIPreferences.Language = DefaultLanguage
 	}
+	if c.UIPreferences.ClipboardClearSeconds == 0 {
+		c.UIPreferences.ClipboardClearSeconds = DefaultClipboardClearSeconds
+	}
 
 	// Apply window state defaults if not set
 	if c.UIPreferences.WindowState.Width == 0 {
@@ -0,0 +1,384 @@
+package core
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/JB-SelfCompany/Tyr-Desktop/internal/platform"
+)
+
+// Contact represents an address book entry
+type Contact struct {
+	// DisplayName is the human-readable name shown instead of the address
+	DisplayName string `json:"display_name"`
+
+	// Address is the yggmail address (64 hex characters followed by @yggmail)
+	Address string `json:"address"`
+
+	// Notes contains free-form user notes
+	Notes string `json:"notes,omitempty"`
+
+	// DateAdded is when the contact was added
+	DateAdded time.Time `json:"date_added"`
+
+	// Verified indicates the user confirmed the key belongs to this person
+	Verified bool `json:"verified"`
+}
+
+// ContactImportResult contains the outcome of a contact import
+type ContactImportResult struct {
+	// Added is the number of new contacts
+	Added int
+
+	// Updated is the number of existing contacts that were updated
+	Updated int
+
+	// Skipped is the number of entries that were ignored
+	Skipped int
+
+	// Errors describes why entries were skipped
+	Errors []string
+}
+
+// ContactStore holds the address book and persists it to the data directory
+type ContactStore struct {
+	// path is the contacts file location
+	path string
+
+	// contacts is keyed by lowercase address
+	contacts map[string]Contact
+
+	// Mutex for thread-safe access to contacts
+	mu sync.RWMutex
+}
+
+// yggmailAddressRegex validates yggmail addresses (hex-encoded ed25519 public key)
+var yggmailAddressRegex = regexp.MustCompile(`^[0-9a-f]{64}@yggmail$`)
+
+// NormalizeMailAddress lowercases and trims an address
+func NormalizeMailAddress(address string) string {
+	return strings.ToLower(strings.TrimSpace(address))
+}
+
+// IsValidMailAddress checks if an address is a valid yggmail address
+func IsValidMailAddress(address string) bool {
+	return yggmailAddressRegex.MatchString(NormalizeMailAddress(address))
+}
+
+// LoadContacts reads the contacts file from the data directory
+// Returns an empty store if the file doesn't exist
+func LoadContacts() (*ContactStore, error) {
+	return LoadContactsFrom(platform.GetContactsPath())
+}
+
+// NewContactStore returns an empty store backed by the contacts file in the data directory
+// Used when the file can't be loaded; Reload reads it again later
+func NewContactStore() *ContactStore {
+	return &ContactStore{
+		path:     platform.GetContactsPath(),
+		contacts: make(map[string]Contact),
+	}
+}
+
+// LoadContactsFrom reads a contacts file from the given path
+// Returns an empty store if the file doesn't exist
+func LoadContactsFrom(path string) (*ContactStore, error) {
+	store := &ContactStore{
+		path:     path,
+		contacts: make(map[string]Contact),
+	}
+	if err := store.Reload(); err != nil {
+		return nil, err
+	}
+	return store, nil
+}
+
+// Reload re-reads the contacts file, discarding in-memory state
+// Thread-safe with write lock
+func (s *ContactStore) Reload() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.contacts = make(map[string]Contact)
+
+	data, err := os.ReadFile(s.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return fmt.Errorf("failed to read contacts file: %w", err)
+	}
+
+	var list []Contact
+	if err := json.Unmarshal(data, &list); err != nil {
+		return fmt.Errorf("failed to parse contacts file: %w", err)
+	}
+
+	for _, c := range list {
+		c.Address = NormalizeMailAddress(c.Address)
+		if c.Address != "" {
+			s.contacts[c.Address] = c
+		}
+	}
+	return nil
+}
+
+// saveUnsafe writes contacts to disk (caller must hold the lock)
+func (s *ContactStore) saveUnsafe() error {
+	if err := EnsureConfigDir(); err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(s.listUnsafe(), "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to serialize contacts: %w", err)
+	}
+
+	// Write with user-only read/write permissions (rw-------)
+	if err := os.WriteFile(s.path, data, 0600); err != nil {
+		return fmt.Errorf("failed to write contacts file: %w", err)
+	}
+	return nil
+}
+
+// listUnsafe returns contacts sorted by display name (caller must hold the lock)
+func (s *ContactStore) listUnsafe() []Contact {
+	list := make([]Contact, 0, len(s.contacts))
+	for _, c := range s.contacts {
+		list = append(list, c)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		ni, nj := strings.ToLower(list[i].DisplayName), strings.ToLower(list[j].DisplayName)
+		if ni != nj {
+			return ni < nj
+		}
+		return list[i].Address < list[j].Address
+	})
+	return list
+}
+
+// List returns all contacts sorted by display name
+// Thread-safe with read lock
+func (s *ContactStore) List() []Contact {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.listUnsafe()
+}
+
+// Get returns the contact for an address
+// Thread-safe with read lock
+func (s *ContactStore) Get(address string) (Contact, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	c, ok := s.contacts[NormalizeMailAddress(address)]
+	return c, ok
+}
+
+// Add creates a new contact
+// Returns an error if the address is invalid or already exists
+// Thread-safe with write lock
+func (s *ContactStore) Add(contact Contact) error {
+	contact.Address = NormalizeMailAddress(contact.Address)
+	contact.DisplayName = strings.TrimSpace(contact.DisplayName)
+	if !yggmailAddressRegex.MatchString(contact.Address) {
+		return fmt.Errorf("invalid yggmail address: %s", contact.Address)
+	}
+	if contact.DisplayName == "" {
+		return fmt.Errorf("display name cannot be empty")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, exists := s.contacts[contact.Address]; exists {
+		return fmt.Errorf("contact already exists: %s", contact.Address)
+	}
+	if contact.DateAdded.IsZero() {
+		contact.DateAdded = time.Now().UTC()
+	}
+
+	s.contacts[contact.Address] = contact
+	return s.saveUnsafe()
+}
+
+// Update changes the display name, notes and verified flag of an existing contact
+// Thread-safe with write lock
+func (s *ContactStore) Update(contact Contact) error {
+	contact.Address = NormalizeMailAddress(contact.Address)
+	contact.DisplayName = strings.TrimSpace(contact.DisplayName)
+	if contact.DisplayName == "" {
+		return fmt.Errorf("display name cannot be empty")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	existing, ok := s.contacts[contact.Address]
+	if !ok {
+		return fmt.Errorf("contact not found: %s", contact.Address)
+	}
+
+	existing.DisplayName = contact.DisplayName
+	existing.Notes = contact.Notes
+	existing.Verified = contact.Verified
+	s.contacts[contact.Address] = existing
+	return s.saveUnsafe()
+}
+
+// Remove deletes a contact
+// Thread-safe with write lock
+func (s *ContactStore) Remove(address string) error {
+	address = NormalizeMailAddress(address)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.contacts[address]; !ok {
+		return fmt.Errorf("contact not found: %s", address)
+	}
+
+	delete(s.contacts, address)
+	return s.saveUnsafe()
+}
+
+// SetVerified sets the verified flag of a contact
+// Thread-safe with write lock
+func (s *ContactStore) SetVerified(address string, verified bool) error {
+	address = NormalizeMailAddress(address)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	c, ok := s.contacts[address]
+	if !ok {
+		return fmt.Errorf("contact not found: %s", address)
+	}
+
+	c.Verified = verified
+	s.contacts[address] = c
+	return s.saveUnsafe()
+}
+
+// Replace overwrites all contacts (used when restoring from backup)
+// Thread-safe with write lock
+func (s *ContactStore) Replace(contacts []Contact) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.contacts = make(map[string]Contact, len(contacts))
+	for _, c := range contacts {
+		c.Address = NormalizeMailAddress(c.Address)
+		if yggmailAddressRegex.MatchString(c.Address) {
+			s.contacts[c.Address] = c
+		}
+	}
+	return s.saveUnsafe()
+}
+
+// Import merges contacts into the store
+// New addresses are added; existing contacts get non-empty imported fields.
+// Entries with invalid addresses are skipped
+// Thread-safe with write lock
+func (s *ContactStore) Import(contacts []Contact) (*ContactImportResult, error) {
+	result := &ContactImportResult{Errors: []string{}}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, c := range contacts {
+		c.Address = NormalizeMailAddress(c.Address)
+		c.DisplayName = strings.TrimSpace(c.DisplayName)
+		if !yggmailAddressRegex.MatchString(c.Address) {
+			result.Skipped++
+			result.Errors = append(result.Errors, fmt.Sprintf("%s: not a yggmail address", c.DisplayName))
+			continue
+		}
+
+		existing, ok := s.contacts[c.Address]
+		if !ok {
+			if c.DisplayName == "" {
+				c.DisplayName = ShortMailAddress(c.Address)
+			}
+			if c.DateAdded.IsZero() {
+				c.DateAdded = time.Now().UTC()
+			}
+			s.contacts[c.Address] = c
+			result.Added++
+			continue
+		}
+
+		if c.DisplayName != "" {
+			existing.DisplayName = c.DisplayName
+		}
+		if c.Notes != "" {
+			existing.Notes = c.Notes
+		}
+		existing.Verified = existing.Verified || c.Verified
+		s.contacts[c.Address] = existing
+		result.Updated++
+	}
+
+	if result.Added+result.Updated == 0 {
+		return result, nil
+	}
+	return result, s.saveUnsafe()
+}
+
+// ResolveName returns the display name for an address, or empty string if unknown
+// Thread-safe with read lock
+func (s *ContactStore) ResolveName(address string) string {
+	if s == nil {
+		return ""
+	}
+	c, ok := s.Get(ExtractMailAddress(address))
+	if !ok {
+		return ""
+	}
+	return c.DisplayName
+}
+
+// FormatAddress returns "Name <address>" for known contacts and the input unchanged otherwise
+// Comma-separated address lists are formatted element by element
+// Thread-safe with read lock
+func (s *ContactStore) FormatAddress(addresses string) string {
+	if s == nil || addresses == "" {
+		return addresses
+	}
+
+	parts := strings.Split(addresses, ",")
+	for i, part := range parts {
+		part = strings.TrimSpace(part)
+		if name := s.ResolveName(part); name != "" {
+			parts[i] = fmt.Sprintf("%s <%s>", name, ExtractMailAddress(part))
+		} else {
+			parts[i] = part
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
+// ShortMailAddress abbreviates a yggmail address for display (e.g., "1a2b3c4d…@yggmail")
+func ShortMailAddress(address string) string {
+	local, domain, found := strings.Cut(address, "@")
+	if !found || len(local) <= 12 {
+		return address
+	}
+	return local[:8] + "…" + local[len(local)-4:] + "@" + domain
+}
+
+// ExtractMailAddress returns the bare address from "Name <address>" or "<address>" forms
+func ExtractMailAddress(s string) string {
+	s = strings.TrimSpace(s)
+	if i := strings.LastIndex(s, "<"); i >= 0 {
+		if j := strings.Index(s[i:], ">"); j > 0 {
+			return s[i+1 : i+j]
+		}
+	}
+	return s
+}
@@ -0,0 +1,655 @@
+package core
+
+import (
+	"bufio"
+	"bytes"
+	"cmp"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/JB-SelfCompany/Tyr-Desktop/internal/mailclient"
+)
+
+// Export formats
+const (
+	// ExportFormatMbox writes one mboxrd file per mailbox
+	ExportFormatMbox = "mbox"
+
+	// ExportFormatMaildir writes one Maildir directory per mailbox
+	ExportFormatMaildir = "maildir"
+
+	// ExportFormatEML writes one folder of .eml files per mailbox
+	ExportFormatEML = "eml"
+)
+
+const (
+	// exportStateFile is the resume state kept in the destination while an export runs
+	exportStateFile = ".tyr-export.json"
+
+	// exportStateSaveInterval is the number of messages between resume state saves
+	exportStateSaveInterval = 20
+)
+
+// ErrExportCancelled is returned when an export is interrupted before it finishes
+// Running the same export again resumes it
+var ErrExportCancelled = errors.New("export cancelled")
+
+// ExportOptions selects what to export and where
+type ExportOptions struct {
+	// Destination is the directory the export is written to
+	Destination string
+
+	// Format is "mbox", "maildir" or "eml"
+	Format string
+
+	// Mailboxes is the list of mailboxes to export (empty exports all)
+	Mailboxes []string
+
+	// Since only exports messages received at or after this time (zero for no limit)
+	Since time.Time
+
+	// Before only exports messages received before this time (zero for no limit)
+	Before time.Time
+}
+
+// ExportProgress describes the progress of a running export
+type ExportProgress struct {
+	// Mailbox is the mailbox being exported
+	Mailbox string
+
+	// Current is the number of messages handled so far, including ones skipped on resume
+	Current int
+
+	// Total is the number of messages selected for export
+	Total int
+
+	// Percent is the overall progress (0-100)
+	Percent int
+}
+
+// ExportResult contains the outcome of an export
+type ExportResult struct {
+	// Destination is the directory the export was written to
+	Destination string
+
+	// Format is the export format
+	Format string
+
+	// Mailboxes is the list of exported mailboxes
+	Mailboxes []string
+
+	// Exported is the number of messages written in this run
+	Exported int
+
+	// Skipped is the number of messages already written by an interrupted run
+	Skipped int
+
+	// Resumed indicates an interrupted export was continued
+	Resumed bool
+
+	// Errors lists messages or mailboxes that could not be exported
+	Errors []string
+
+	// StartedAt is when the run started
+	StartedAt time.Time
+
+	// FinishedAt is when the run finished
+	FinishedAt time.Time
+}
+
+// exportState is the resume state of an interrupted export
+type exportState struct {
+	Format    string                         `json:"format"`
+	Mailboxes []string                       `json:"mailboxes"`
+	Since     int64                          `json:"since,omitempty"`
+	Before    int64                          `json:"before,omitempty"`
+	Progress  map[string]*exportMailboxState `json:"progress"`
+}
+
+// exportMailboxState tracks how far one mailbox was exported
+// Messages are exported in ascending UID order, so LastUID marks the resume point
+type exportMailboxState struct {
+	UIDValidity uint32 `json:"uid_validity"`
+	LastUID     uint32 `json:"last_uid"`
+	Exported    int    `json:"exported"`
+	MboxSize    int64  `json:"mbox_size,omitempty"`
+}
+
+// exportPlan is the list of messages selected in one mailbox
+type exportPlan struct {
+	mailbox     string
+	uidValidity uint32
+	headers     []mailclient.MessageHeader
+}
+
+// Validate checks the export options for errors
+func (o *ExportOptions) Validate() error {
+	if strings.TrimSpace(o.Destination) == "" {
+		return fmt.Errorf("destination directory is required")
+	}
+	switch o.Format {
+	case ExportFormatMbox, ExportFormatMaildir, ExportFormatEML:
+	default:
+		return fmt.Errorf("unsupported export format: %s", o.Format)
+	}
+	if !o.Since.IsZero() && !o.Before.IsZero() && !o.Before.After(o.Since) {
+		return fmt.Errorf("end of date range must be after its start")
+	}
+	return nil
+}
+
+// matches returns true if the message falls into the selected date range
+func (o *ExportOptions) matches(h *mailclient.MessageHeader) bool {
+	t := messageTime(h)
+	if !o.Since.IsZero() && t.Before(o.Since) {
+		return false
+	}
+	if !o.Before.IsZero() && !t.Before(o.Before) {
+		return false
+	}
+	return true
+}
+
+// newExportState creates an empty resume state for the options
+func newExportState(opts ExportOptions) *exportState {
+	state := &exportState{
+		Format:    opts.Format,
+		Mailboxes: append([]string{}, opts.Mailboxes...),
+		Progress:  make(map[string]*exportMailboxState),
+	}
+	if !opts.Since.IsZero() {
+		state.Since = opts.Since.Unix()
+	}
+	if !opts.Before.IsZero() {
+		state.Before = opts.Before.Unix()
+	}
+	return state
+}
+
+// sameOptions returns true if the state was created for equivalent options
+func (s *exportState) sameOptions(other *exportState) bool {
+	return s.Format == other.Format && s.Since == other.Since && s.Before == other.Before &&
+		slices.Equal(s.Mailboxes, other.Mailboxes)
+}
+
+// options converts the state back to export options
+func (s *exportState) options(destination string) ExportOptions {
+	opts := ExportOptions{
+		Destination: destination,
+		Format:      s.Format,
+		Mailboxes:   append([]string{}, s.Mailboxes...),
+	}
+	if s.Since != 0 {
+		opts.Since = time.Unix(s.Since, 0)
+	}
+	if s.Before != 0 {
+		opts.Before = time.Unix(s.Before, 0)
+	}
+	return opts
+}
+
+// loadExportState reads the resume state from a destination directory
+// Returns nil if there is no interrupted export
+func loadExportState(destination string) (*exportState, error) {
+	data, err := os.ReadFile(filepath.Join(destination, exportStateFile))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read export state: %w", err)
+	}
+
+	var state exportState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, fmt.Errorf("failed to parse export state: %w", err)
+	}
+	if state.Progress == nil {
+		state.Progress = make(map[string]*exportMailboxState)
+	}
+	return &state, nil
+}
+
+// save writes the resume state to the destination directory
+func (s *exportState) save(destination string) error {
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to serialize export state: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(destination, exportStateFile), data, 0600); err != nil {
+		return fmt.Errorf("failed to write export state: %w", err)
+	}
+	return nil
+}
+
+// InterruptedExport returns the options of an unfinished export in the destination
+// Returns nil if the directory has no export to resume
+func InterruptedExport(destination string) (*ExportOptions, error) {
+	state, err := loadExportState(destination)
+	if err != nil || state == nil {
+		return nil, err
+	}
+	opts := state.options(destination)
+	return &opts, nil
+}
+
+// ExportMailboxes writes messages read over local IMAP to mbox, Maildir or .eml files
+// An interrupted export with the same options in the same destination is resumed.
+// progress (may be nil) is called as messages are written. Cancelling ctx stops the
+// export after the current message and returns ErrExportCancelled
+// Thread-safe
+func (sm *ServiceManager) ExportMailboxes(ctx context.Context, opts ExportOptions, progress func(ExportProgress)) (*ExportResult, error) {
+	if err := opts.Validate(); err != nil {
+		return nil, err
+	}
+	if !sm.archiveMu.TryLock() {
+		return nil, fmt.Errorf("an export or import is already in progress")
+	}
+	defer sm.archiveMu.Unlock()
+
+	if err := os.MkdirAll(opts.Destination, 0700); err != nil {
+		return nil, fmt.Errorf("failed to create destination directory: %w", err)
+	}
+
+	result := &ExportResult{
+		Destination: opts.Destination,
+		Format:      opts.Format,
+		Mailboxes:   []string{},
+		StartedAt:   time.Now().UTC(),
+	}
+
+	state := newExportState(opts)
+	if previous, err := loadExportState(opts.Destination); err != nil {
+		log.Printf("[Export] Ignoring unreadable export state: %v", err)
+	} else if previous != nil && previous.sameOptions(state) {
+		state = previous
+		result.Resumed = true
+		log.Printf("[Export] Resuming interrupted export in %s", opts.Destination)
+	}
+
+	err := sm.withIMAPSession(func(s *mailclient.IMAPSession) error {
+		plans, err := planExport(s, opts, result)
+		if err != nil {
+			return err
+		}
+
+		total := 0
+		for _, plan := range plans {
+			total += len(plan.headers)
+		}
+
+		current := 0
+		lastPercent := -1
+		report := func(mailbox string) {
+			if progress == nil {
+				return
+			}
+			percent := 100
+			if total > 0 {
+				percent = current * 100 / total
+			}
+			if percent != lastPercent {
+				lastPercent = percent
+				progress(ExportProgress{Mailbox: mailbox, Current: current, Total: total, Percent: percent})
+			}
+		}
+		report("")
+
+		for _, plan := range plans {
+			result.Mailboxes = append(result.Mailboxes, plan.mailbox)
+
+			ms := state.Progress[plan.mailbox]
+			if ms == nil || ms.UIDValidity != plan.uidValidity {
+				// UIDs from an older UIDVALIDITY don't identify the same messages
+				ms = &exportMailboxState{UIDValidity: plan.uidValidity}
+				state.Progress[plan.mailbox] = ms
+			}
+
+			err := exportMailbox(ctx, s, opts, plan, ms, state, result, func() {
+				current++
+				report(plan.mailbox)
+			})
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+
+	result.FinishedAt = time.Now().UTC()
+	if err != nil {
+		if saveErr := state.save(opts.Destination); saveErr != nil {
+			log.Printf("[Export] Warning: %v", saveErr)
+		}
+		if errors.Is(err, ErrExportCancelled) {
+			log.Printf("[Export] Export cancelled after %d messages", result.Exported)
+			return result, err
+		}
+		return nil, err
+	}
+
+	// A finished export leaves nothing to resume
+	if err := os.Remove(filepath.Join(opts.Destination, exportStateFile)); err != nil && !os.IsNotExist(err) {
+		log.Printf("[Export] Warning: failed to remove export state: %v", err)
+	}
+
+	log.Printf("[Export] Exported %d messages from %d mailboxes to %s (%s)",
+		result.Exported, len(result.Mailboxes), opts.Destination, opts.Format)
+	return result, nil
+}
+
+// planExport selects the messages to export from each requested mailbox
+// Unknown mailboxes are reported in result.Errors
+func planExport(s *mailclient.IMAPSession, opts ExportOptions, result *ExportResult) ([]exportPlan, error) {
+	mailboxes, err := s.ListMailboxes()
+	if err != nil {
+		return nil, err
+	}
+
+	names := opts.Mailboxes
+	if len(names) == 0 {
+		for _, mbox := range mailboxes {
+			names = append(names, mbox.Name)
+		}
+	}
+
+	plans := make([]exportPlan, 0, len(names))
+	for _, name := range names {
+		known := slices.ContainsFunc(mailboxes, func(m mailclient.MailboxInfo) bool { return m.Name == name })
+		if !known {
+			result.Errors = append(result.Errors, fmt.Sprintf("%s: mailbox not found", name))
+			continue
+		}
+
+		status, err := s.Examine(name)
+		if err != nil {
+			return nil, err
+		}
+		headers, err := s.AllHeaders()
+		if err != nil {
+			return nil, err
+		}
+
+		plan := exportPlan{mailbox: name, uidValidity: status.UidValidity}
+		for _, h := range headers {
+			if opts.matches(&h) {
+				plan.headers = append(plan.headers, h)
+			}
+		}
+		slices.SortFunc(plan.headers, func(a, b mailclient.MessageHeader) int {
+			return cmp.Compare(a.UID, b.UID)
+		})
+		plans = append(plans, plan)
+	}
+	return plans, nil
+}
+
+// exportMailbox writes the planned messages of one mailbox, skipping ones already exported
+// step is called once per planned message
+func exportMailbox(
+	ctx context.Context,
+	s *mailclient.IMAPSession,
+	opts ExportOptions,
+	plan exportPlan,
+	ms *exportMailboxState,
+	state *exportState,
+	result *ExportResult,
+	step func(),
+) error {
+	if _, err := s.Examine(plan.mailbox); err != nil {
+		return err
+	}
+
+	writer, err := newExportWriter(opts, plan, ms)
+	if err != nil {
+		return err
+	}
+	defer writer.Close()
+
+	sinceSave := 0
+	for i := range plan.headers {
+		h := &plan.headers[i]
+		if h.UID <= ms.LastUID {
+			result.Skipped++
+			step()
+			continue
+		}
+		if err := ctx.Err(); err != nil {
+			return ErrExportCancelled
+		}
+
+		_, raw, err := s.FetchRaw(h.UID)
+		if err != nil {
+			result.Errors = append(result.Errors, fmt.Sprintf("%s/%d: %v", plan.mailbox, h.UID, err))
+		} else if err := writer.Write(h, raw); err != nil {
+			// Disk errors affect every following message as well
+			return fmt.Errorf("failed to write %s/%d: %w", plan.mailbox, h.UID, err)
+		} else {
+			ms.Exported++
+			result.Exported++
+		}
+		ms.LastUID = h.UID
+		step()
+
+		if sinceSave++; sinceSave >= exportStateSaveInterval {
+			sinceSave = 0
+			if err := writer.Sync(); err != nil {
+				return err
+			}
+			if err := state.save(opts.Destination); err != nil {
+				return err
+			}
+		}
+	}
+	return writer.Sync()
+}
+
+// exportWriter writes messages of one mailbox in an export format
+type exportWriter interface {
+	Write(h *mailclient.MessageHeader, raw []byte) error
+	Sync() error
+	Close() error
+}
+
+// newExportWriter opens the writer for one mailbox
+func newExportWriter(opts ExportOptions, plan exportPlan, ms *exportMailboxState) (exportWriter, error) {
+	name := exportFileName(plan.mailbox)
+	switch opts.Format {
+	case ExportFormatMbox:
+		return openMboxWriter(filepath.Join(opts.Destination, name+".mbox"), ms)
+	case ExportFormatMaildir:
+		return newMaildirWriter(filepath.Join(opts.Destination, name), plan.uidValidity)
+	default:
+		return newEMLWriter(filepath.Join(opts.Destination, name))
+	}
+}
+
+// mboxWriter appends messages to an mboxrd file
+type mboxWriter struct {
+	file *os.File
+	ms   *exportMailboxState
+}
+
+// openMboxWriter opens an mbox file and drops anything written after the last saved state
+func openMboxWriter(path string, ms *exportMailboxState) (*mboxWriter, error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR, 0600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open mbox file: %w", err)
+	}
+	// A message may have been partially written after the state was saved
+	if err := file.Truncate(ms.MboxSize); err != nil {
+		file.Close()
+		return nil, fmt.Errorf("failed to prepare mbox file: %w", err)
+	}
+	if _, err := file.Seek(ms.MboxSize, 0); err != nil {
+		file.Close()
+		return nil, fmt.Errorf("failed to prepare mbox file: %w", err)
+	}
+	return &mboxWriter{file: file, ms: ms}, nil
+}
+
+// Write appends one message using mboxrd quoting
+func (w *mboxWriter) Write(h *mailclient.MessageHeader, raw []byte) error {
+	sender := "MAILER-DAEMON"
+	if len(h.From) > 0 {
+		if addr := ExtractMailAddress(h.From[0]); addr != "" && !strings.ContainsAny(addr, " \t") {
+			sender = addr
+		}
+	}
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "From %s %s\n", sender, messageTime(h).UTC().Format("Mon Jan _2 15:04:05 2006"))
+
+	scanner := bufio.NewScanner(bytes.NewReader(raw))
+	scanner.Buffer(make([]byte, 64*1024), len(raw)+1)
+	for scanner.Scan() {
+		line := strings.TrimSuffix(scanner.Text(), "\r")
+		if strings.HasPrefix(strings.TrimLeft(line, ">"), "From ") {
+			buf.WriteByte('>')
+		}
+		buf.WriteString(line)
+		buf.WriteByte('\n')
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+	buf.WriteByte('\n')
+
+	n, err := w.file.Write(buf.Bytes())
+	if err != nil {
+		return err
+	}
+	w.ms.MboxSize += int64(n)
+	return nil
+}
+
+// Sync flushes the mbox file to disk
+func (w *mboxWriter) Sync() error {
+	return w.file.Sync()
+}
+
+// Close closes the mbox file
+func (w *mboxWriter) Close() error {
+	return w.file.Close()
+}
+
+// maildirWriter stores messages in a Maildir with flags in the file name
+type maildirWriter struct {
+	dir         string
+	uidValidity uint32
+}
+
+// newMaildirWriter creates the Maildir tmp, new and cur directories
+func newMaildirWriter(dir string, uidValidity uint32) (*maildirWriter, error) {
+	for _, sub := range []string{"tmp", "new", "cur"} {
+		if err := os.MkdirAll(filepath.Join(dir, sub), 0700); err != nil {
+			return nil, fmt.Errorf("failed to create Maildir: %w", err)
+		}
+	}
+	return &maildirWriter{dir: dir, uidValidity: uidValidity}, nil
+}
+
+// Write delivers one message through tmp into cur
+// File names are derived from the UID so a resumed export overwrites partial files
+func (w *maildirWriter) Write(h *mailclient.MessageHeader, raw []byte) error {
+	base := fmt.Sprintf("%d.U%dV%d.tyr", messageTime(h).Unix(), h.UID, w.uidValidity)
+
+	// ':' is not allowed in Windows file names, '!' is the common substitute
+	separator := ":"
+	if runtime.GOOS == "windows" {
+		separator = "!"
+	}
+	name := base + separator + "2," + maildirFlags(h)
+
+	tmp := filepath.Join(w.dir, "tmp", base)
+	if err := os.WriteFile(tmp, raw, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, filepath.Join(w.dir, "cur", name))
+}
+
+// Sync does nothing; every message is a complete file
+func (w *maildirWriter) Sync() error {
+	return nil
+}
+
+// Close does nothing
+func (w *maildirWriter) Close() error {
+	return nil
+}
+
+// maildirFlags converts IMAP flags to Maildir info flags (in ASCII order)
+func maildirFlags(h *mailclient.MessageHeader) string {
+	var flags []byte
+	for _, f := range []struct {
+		imapFlag string
+		letter   byte
+	}{
+		{"\\Draft", 'D'},
+		{"\\Flagged", 'F'},
+		{"\\Answered", 'R'},
+		{"\\Seen", 'S'},
+		{"\\Deleted", 'T'},
+	} {
+		if h.HasFlag(f.imapFlag) {
+			flags = append(flags, f.letter)
+		}
+	}
+	return string(flags)
+}
+
+// emlWriter stores each message as a separate .eml file
+type emlWriter struct {
+	dir string
+}
+
+// newEMLWriter creates the mailbox folder
+func newEMLWriter(dir string) (*emlWriter, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, fmt.Errorf("failed to create export folder: %w", err)
+	}
+	return &emlWriter{dir: dir}, nil
+}
+
+// Write stores one message named by its date and UID
+func (w *emlWriter) Write(h *mailclient.MessageHeader, raw []byte) error {
+	name := fmt.Sprintf("%s_%d.eml", messageTime(h).UTC().Format("20060102-150405"), h.UID)
+	tmp := filepath.Join(w.dir, name+".part")
+	if err := os.WriteFile(tmp, raw, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, filepath.Join(w.dir, name))
+}
+
+// Sync does nothing; every message is a complete file
+func (w *emlWriter) Sync() error {
+	return nil
+}
+
+// Close does nothing
+func (w *emlWriter) Close() error {
+	return nil
+}
+
+// exportFileName converts a mailbox name to a safe file name
+func exportFileName(mailbox string) string {
+	name := strings.Map(func(r rune) rune {
+		if r < 0x20 || strings.ContainsRune(`/\:*?"<>|`, r) {
+			return '_'
+		}
+		return r
+	}, mailbox)
+	name = strings.Trim(name, " .")
+	if name == "" {
+		name = "mailbox"
+	}
+	return name
+}
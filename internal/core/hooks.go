@@ -0,0 +1,601 @@
+package core
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/JB-SelfCompany/Tyr-Desktop/internal/platform"
+	"github.com/JB-SelfCompany/Tyr-Desktop/internal/yggmail"
+)
+
+// Hook event names
+// Mail and connection events use the yggmail event type as suffix (e.g., "mail.new_mail")
+const (
+	// HookEventServiceStatus fires when the service status changes
+	HookEventServiceStatus = "service.status"
+
+	// HookEventMailPrefix prefixes mail events ("mail.new_mail", "mail.sent", "mail.error")
+	HookEventMailPrefix = "mail."
+
+	// HookEventConnectionPrefix prefixes connection events ("connection.connected", ...)
+	HookEventConnectionPrefix = "connection."
+
+	// HookEventTest is sent by TestHook
+	HookEventTest = "test"
+)
+
+const (
+	// DefaultHookTimeoutSeconds is how long a hook may run before it is killed
+	DefaultHookTimeoutSeconds = 10
+
+	// DefaultHookMaxConcurrent is how many runs of one hook may overlap
+	DefaultHookMaxConcurrent = 1
+
+	// MaxHookTimeoutSeconds is the longest allowed hook timeout
+	MaxHookTimeoutSeconds = 300
+
+	// MaxHookConcurrent is the highest allowed concurrency limit
+	MaxHookConcurrent = 16
+
+	// maxHookLogSize is the size at which a hook log is trimmed to its newer half
+	maxHookLogSize = 256 * 1024
+
+	// maxHookOutput is the amount of hook output recorded in the log
+	maxHookOutput = 4 * 1024
+)
+
+// HookSettings contains user-defined hooks
+type HookSettings struct {
+	// Enabled turns on hook execution
+	Enabled bool `toml:"enabled"`
+
+	// Hooks is the list of configured hooks
+	Hooks []Hook `toml:"hooks"`
+}
+
+// Hook runs an executable or POSTs to a localhost URL when matching events occur
+// The event is passed as TYR_* environment variables and as JSON on stdin (or as the POST body)
+type Hook struct {
+	// Name identifies the hook and its log file
+	Name string `toml:"name"`
+
+	// Enabled indicates if the hook runs
+	Enabled bool `toml:"enabled"`
+
+	// Events are event names or patterns ("mail.*", "*") the hook runs for
+	Events []string `toml:"events"`
+
+	// Command is the executable to run (empty if URL is set)
+	Command string `toml:"command,omitempty"`
+
+	// Args are passed to Command
+	Args []string `toml:"args,omitempty"`
+
+	// URL is a localhost http(s) URL to POST to (empty if Command is set)
+	URL string `toml:"url,omitempty"`
+
+	// TimeoutSeconds is how long one run may take
+	TimeoutSeconds int `toml:"timeout_seconds"`
+
+	// MaxConcurrent is how many runs may overlap; further events are dropped
+	MaxConcurrent int `toml:"max_concurrent"`
+}
+
+// HookEvent is the data passed to a hook
+type HookEvent struct {
+	// Event is the event name (e.g., "mail.new_mail")
+	Event string `json:"event"`
+
+	// Timestamp is when the event occurred
+	Timestamp time.Time `json:"timestamp"`
+
+	// Data contains the event fields
+	Data map[string]string `json:"data"`
+}
+
+// HookRunResult describes one hook run
+type HookRunResult struct {
+	// Hook is the hook name
+	Hook string
+
+	// Event is the event name
+	Event string
+
+	// Success indicates the command exited with 0 or the URL returned 2xx
+	Success bool
+
+	// ExitCode is the command exit code or the HTTP status
+	ExitCode int
+
+	// Duration is how long the run took
+	Duration time.Duration
+
+	// Output is the beginning of the command output or response body
+	Output string
+
+	// Error describes why the run failed
+	Error string
+}
+
+// applyDefaults fills in missing hook values
+func (s *HookSettings) applyDefaults() {
+	for i := range s.Hooks {
+		h := &s.Hooks[i]
+		h.Name = strings.TrimSpace(h.Name)
+		if h.TimeoutSeconds <= 0 {
+			h.TimeoutSeconds = DefaultHookTimeoutSeconds
+		}
+		if h.MaxConcurrent <= 0 {
+			h.MaxConcurrent = DefaultHookMaxConcurrent
+		}
+	}
+}
+
+// Validate checks the hook settings for errors
+func (s *HookSettings) Validate() error {
+	names := make(map[string]bool)
+	for _, h := range s.Hooks {
+		if h.Name == "" {
+			return fmt.Errorf("hook name is required")
+		}
+		if names[strings.ToLower(h.Name)] {
+			return fmt.Errorf("duplicate hook name: %s", h.Name)
+		}
+		names[strings.ToLower(h.Name)] = true
+
+		if err := h.Validate(); err != nil {
+			return fmt.Errorf("hook %q: %w", h.Name, err)
+		}
+	}
+	return nil
+}
+
+// Validate checks a single hook for errors
+func (h *Hook) Validate() error {
+	if (h.Command == "") == (h.URL == "") {
+		return fmt.Errorf("set either a command or a URL")
+	}
+	if h.URL != "" {
+		if err := validateHookURL(h.URL); err != nil {
+			return err
+		}
+	}
+	if h.Command != "" && !filepath.IsAbs(h.Command) {
+		return fmt.Errorf("command must be an absolute path")
+	}
+	if len(h.Events) == 0 {
+		return fmt.Errorf("select at least one event")
+	}
+	if h.TimeoutSeconds < 1 || h.TimeoutSeconds > MaxHookTimeoutSeconds {
+		return fmt.Errorf("timeout must be between 1 and %d seconds", MaxHookTimeoutSeconds)
+	}
+	if h.MaxConcurrent < 1 || h.MaxConcurrent > MaxHookConcurrent {
+		return fmt.Errorf("concurrency limit must be between 1 and %d", MaxHookConcurrent)
+	}
+	return nil
+}
+
+// Matches returns true if the hook runs for the event
+// Patterns are exact names, "*" or a prefix ending in ".*"
+func (h *Hook) Matches(event string) bool {
+	for _, pattern := range h.Events {
+		pattern = strings.TrimSpace(pattern)
+		switch {
+		case pattern == "*" || pattern == event:
+			return true
+		case strings.HasSuffix(pattern, ".*") && strings.HasPrefix(event, strings.TrimSuffix(pattern, "*")):
+			return true
+		}
+	}
+	return false
+}
+
+// validateHookURL only allows http(s) URLs on a loopback host
+func validateHookURL(rawURL string) error {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return fmt.Errorf("invalid URL: %w", err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return fmt.Errorf("URL must use http or https")
+	}
+	host := u.Hostname()
+	if strings.EqualFold(host, "localhost") {
+		return nil
+	}
+	if ip := net.ParseIP(host); ip != nil && ip.IsLoopback() {
+		return nil
+	}
+	return fmt.Errorf("URL must point to localhost")
+}
+
+// GetHookSettings returns a copy of the hook settings
+// Thread-safe with read lock
+func (c *Config) GetHookSettings() HookSettings {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	settings := HookSettings{
+		Enabled: c.Hooks.Enabled,
+		Hooks:   make([]Hook, len(c.Hooks.Hooks)),
+	}
+	for i, h := range c.Hooks.Hooks {
+		h.Events = append([]string(nil), h.Events...)
+		h.Args = append([]string(nil), h.Args...)
+		settings.Hooks[i] = h
+	}
+	return settings
+}
+
+// SetHookSettings validates and replaces the hook settings
+// Thread-safe with write lock
+func (c *Config) SetHookSettings(settings HookSettings) error {
+	settings.applyDefaults()
+	if err := settings.Validate(); err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.Hooks = settings
+	return nil
+}
+
+// NewServiceStatusHookEvent creates a hook event for a service status change
+func NewServiceStatusHookEvent(status yggmail.ServiceStatus) HookEvent {
+	return HookEvent{
+		Event:     HookEventServiceStatus,
+		Timestamp: time.Now(),
+		Data:      map[string]string{"status": strings.ToLower(status.String())},
+	}
+}
+
+// NewMailHookEvent creates a hook event from a mail event
+func NewMailHookEvent(event yggmail.MailEvent) HookEvent {
+	return HookEvent{
+		Event:     HookEventMailPrefix + event.Type,
+		Timestamp: event.Timestamp,
+		Data: map[string]string{
+			"type":          event.Type,
+			"mailbox":       event.Mailbox,
+			"from":          event.From,
+			"to":            event.To,
+			"subject":       event.Subject,
+			"mail_id":       fmt.Sprintf("%d", event.MailID),
+			"error_message": event.ErrorMessage,
+		},
+	}
+}
+
+// NewConnectionHookEvent creates a hook event from a connection event
+func NewConnectionHookEvent(event yggmail.ConnectionEvent) HookEvent {
+	return HookEvent{
+		Event:     HookEventConnectionPrefix + event.Type,
+		Timestamp: event.Timestamp,
+		Data: map[string]string{
+			"type":          event.Type,
+			"peer":          event.Peer,
+			"error_message": event.ErrorMessage,
+		},
+	}
+}
+
+// HookRunner dispatches events to the configured hooks
+type HookRunner struct {
+	config *Config
+
+	// running counts active runs per hook name
+	running map[string]int
+
+	// Mutex for thread-safe access to running
+	mu sync.Mutex
+
+	// logMu serializes writes to hook logs
+	logMu sync.Mutex
+}
+
+// NewHookRunner creates a hook runner for the given configuration
+func NewHookRunner(config *Config) *HookRunner {
+	return &HookRunner{
+		config:  config,
+		running: make(map[string]int),
+	}
+}
+
+// Dispatch starts every enabled hook that matches the event in the background
+// Runs beyond a hook's concurrency limit are dropped and logged
+// Thread-safe
+func (r *HookRunner) Dispatch(event HookEvent) {
+	if r == nil {
+		return
+	}
+	settings := r.config.GetHookSettings()
+	if !settings.Enabled {
+		return
+	}
+
+	for _, hook := range settings.Hooks {
+		if !hook.Enabled || !hook.Matches(event.Event) {
+			continue
+		}
+		if !r.acquire(hook) {
+			r.writeLog(hook.Name, HookRunResult{
+				Hook:  hook.Name,
+				Event: event.Event,
+				Error: fmt.Sprintf("skipped: %d run(s) already in progress", hook.MaxConcurrent),
+			})
+			continue
+		}
+		go func(hook Hook) {
+			defer r.release(hook.Name)
+			r.run(hook, event)
+		}(hook)
+	}
+}
+
+// Test runs a hook once with a test event and waits for the result
+// The hook runs even if it or hooks in general are disabled
+// Thread-safe
+func (r *HookRunner) Test(name string) (*HookRunResult, error) {
+	settings := r.config.GetHookSettings()
+	for _, hook := range settings.Hooks {
+		if !strings.EqualFold(hook.Name, name) {
+			continue
+		}
+		if !r.acquire(hook) {
+			return nil, fmt.Errorf("hook %s is already running", hook.Name)
+		}
+		defer r.release(hook.Name)
+
+		result := r.run(hook, HookEvent{
+			Event:     HookEventTest,
+			Timestamp: time.Now(),
+			Data:      map[string]string{"message": "Test event from Tyr"},
+		})
+		return &result, nil
+	}
+	return nil, fmt.Errorf("hook not found: %s", name)
+}
+
+// acquire reserves a run slot for the hook
+func (r *HookRunner) acquire(hook Hook) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.running[hook.Name] >= hook.MaxConcurrent {
+		return false
+	}
+	r.running[hook.Name]++
+	return true
+}
+
+// release frees a run slot of the hook
+func (r *HookRunner) release(name string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.running[name]--; r.running[name] <= 0 {
+		delete(r.running, name)
+	}
+}
+
+// run executes one hook and logs the result
+func (r *HookRunner) run(hook Hook, event HookEvent) HookRunResult {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		result := HookRunResult{Hook: hook.Name, Event: event.Event, Error: err.Error()}
+		r.writeLog(hook.Name, result)
+		return result
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(hook.TimeoutSeconds)*time.Second)
+	defer cancel()
+
+	start := time.Now()
+	var result HookRunResult
+	if hook.URL != "" {
+		result = postHook(ctx, hook, event, payload)
+	} else {
+		result = execHook(ctx, hook, event, payload)
+	}
+	result.Hook = hook.Name
+	result.Event = event.Event
+	result.Duration = time.Since(start)
+	if ctx.Err() == context.DeadlineExceeded {
+		result.Success = false
+		result.Error = fmt.Sprintf("timed out after %ds", hook.TimeoutSeconds)
+	}
+
+	if !result.Success {
+		log.Printf("[Hooks] Hook %s failed on %s: %s", hook.Name, event.Event, result.Error)
+	}
+	r.writeLog(hook.Name, result)
+	return result
+}
+
+// execHook runs the hook command with the event in the environment and on stdin
+func execHook(ctx context.Context, hook Hook, event HookEvent, payload []byte) HookRunResult {
+	cmd := exec.CommandContext(ctx, hook.Command, hook.Args...)
+	cmd.Env = append(os.Environ(), hookEnv(event)...)
+	cmd.Stdin = bytes.NewReader(payload)
+	configureHookCommand(cmd)
+
+	// Children of a killed command may keep the output pipes open
+	cmd.WaitDelay = time.Second
+
+	output := &limitedBuffer{limit: maxHookOutput}
+	cmd.Stdout = output
+	cmd.Stderr = output
+
+	err := cmd.Run()
+	result := HookRunResult{Output: output.String()}
+	if cmd.ProcessState != nil {
+		result.ExitCode = cmd.ProcessState.ExitCode()
+	}
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+	result.Success = true
+	return result
+}
+
+// postHook sends the event as JSON to the hook URL
+func postHook(ctx context.Context, hook Hook, event HookEvent, payload []byte) HookRunResult {
+	// Re-check in case the config file was edited by hand
+	if err := validateHookURL(hook.URL); err != nil {
+		return HookRunResult{Error: err.Error()}
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, hook.URL, bytes.NewReader(payload))
+	if err != nil {
+		return HookRunResult{Error: err.Error()}
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Tyr-Event", event.Event)
+
+	// Never follow redirects away from localhost
+	client := &http.Client{
+		CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse },
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return HookRunResult{Error: err.Error()}
+	}
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, maxHookOutput))
+	result := HookRunResult{ExitCode: resp.StatusCode, Output: string(body)}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		result.Error = resp.Status
+		return result
+	}
+	result.Success = true
+	return result
+}
+
+// hookEnv converts an event to TYR_* environment variables
+func hookEnv(event HookEvent) []string {
+	env := []string{
+		"TYR_EVENT=" + event.Event,
+		"TYR_TIMESTAMP=" + event.Timestamp.Format(time.RFC3339),
+	}
+	keys := make([]string, 0, len(event.Data))
+	for key := range event.Data {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		// Environment values can't contain NUL; newlines are kept as-is
+		value := strings.ReplaceAll(event.Data[key], "\x00", "")
+		env = append(env, "TYR_"+strings.ToUpper(key)+"="+value)
+	}
+	return env
+}
+
+// limitedBuffer keeps the first limit bytes written and discards the rest
+type limitedBuffer struct {
+	buf   bytes.Buffer
+	limit int
+}
+
+// Write stores data up to the limit; it never fails so the command isn't blocked
+func (b *limitedBuffer) Write(p []byte) (int, error) {
+	if room := b.limit - b.buf.Len(); room > 0 {
+		if len(p) > room {
+			b.buf.Write(p[:room])
+		} else {
+			b.buf.Write(p)
+		}
+	}
+	return len(p), nil
+}
+
+// String returns the stored output
+func (b *limitedBuffer) String() string {
+	return b.buf.String()
+}
+
+// hookLogPath returns the log file of a hook
+func hookLogPath(name string) string {
+	return filepath.Join(platform.GetHooksLogDir(), exportFileName(name)+".log")
+}
+
+// writeLog appends a run result to the hook's log, trimming it when it grows too large
+func (r *HookRunner) writeLog(name string, result HookRunResult) {
+	r.logMu.Lock()
+	defer r.logMu.Unlock()
+
+	status := "ok"
+	if !result.Success {
+		status = "failed"
+	}
+	line := fmt.Sprintf("%s event=%s status=%s code=%d duration=%dms",
+		time.Now().Format(time.RFC3339), result.Event, status, result.ExitCode, result.Duration.Milliseconds())
+	if result.Error != "" {
+		line += " error=" + strconv.Quote(result.Error)
+	}
+	if out := strings.TrimSpace(result.Output); out != "" {
+		line += " output=" + strconv.Quote(out)
+	}
+
+	path := hookLogPath(name)
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		log.Printf("[Hooks] Failed to create log directory: %v", err)
+		return
+	}
+	if info, err := os.Stat(path); err == nil && info.Size() > maxHookLogSize {
+		if data, err := os.ReadFile(path); err == nil {
+			data = data[len(data)/2:]
+			if i := bytes.IndexByte(data, '\n'); i >= 0 {
+				data = data[i+1:]
+			}
+			_ = os.WriteFile(path, data, 0600)
+		}
+	}
+
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0600)
+	if err != nil {
+		log.Printf("[Hooks] Failed to open log for %s: %v", name, err)
+		return
+	}
+	defer file.Close()
+	fmt.Fprintln(file, line)
+}
+
+// ReadHookLog returns the last lines of a hook's log, oldest first
+// A limit of 0 or less returns every line
+func ReadHookLog(name string, limit int) ([]string, error) {
+	data, err := os.ReadFile(hookLogPath(name))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return []string{}, nil
+		}
+		return nil, fmt.Errorf("failed to read hook log: %w", err)
+	}
+
+	lines := strings.Split(strings.TrimRight(string(data), "\n"), "\n")
+	if len(lines) == 1 && lines[0] == "" {
+		return []string{}, nil
+	}
+	if limit > 0 && len(lines) > limit {
+		lines = lines[len(lines)-limit:]
+	}
+	return lines, nil
+}
@@ -0,0 +1,8 @@
+//go:build !windows
+
+package core
+
+import "os/exec"
+
+// configureHookCommand does nothing outside Windows
+func configureHookCommand(cmd *exec.Cmd) {}
@@ -0,0 +1,19 @@
+//go:build windows
+
+package core
+
+import (
+	"os/exec"
+	"syscall"
+)
+
+// createNoWindow prevents console programs from opening a console window
+const createNoWindow = 0x08000000
+
+// configureHookCommand runs hook commands without a console window
+func configureHookCommand(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{
+		HideWindow:    true,
+		CreationFlags: createNoWindow,
+	}
+}
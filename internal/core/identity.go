@@ -0,0 +1,203 @@
+package core
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/tyler-smith/go-bip39"
+)
+
+const (
+	// IdentityFileExtension is the file extension for exported identity keys
+	IdentityFileExtension = ".tyrkey"
+
+	// CurrentIdentityFileVersion is the exported identity file format version
+	CurrentIdentityFileVersion = "1"
+
+	// MinIdentityPassphraseLength is the minimum passphrase length for identity files
+	MinIdentityPassphraseLength = 8
+
+	// identityKeyName is the yggmail database config key holding the private key
+	identityKeyName = "private_key"
+
+	// mailDomain is the domain of yggmail addresses
+	mailDomain = "yggmail"
+)
+
+var (
+	// ErrNoIdentity is returned when the database has no identity key yet
+	ErrNoIdentity = errors.New("no identity key in the database")
+
+	// ErrIdentityExists is returned when importing over a different identity without replacing it
+	ErrIdentityExists = errors.New("database already has a different identity")
+)
+
+// IdentityFile is the content of an exported identity file, encrypted as a whole
+type IdentityFile struct {
+	// Version is the identity file format version
+	Version string `json:"version"`
+
+	// Timestamp is the RFC3339 time the identity was exported
+	Timestamp string `json:"timestamp"`
+
+	// Address is the mail address of the identity
+	Address string `json:"address"`
+
+	// Seed is the hex-encoded 32-byte Ed25519 seed
+	Seed string `json:"seed"`
+}
+
+// IdentityAddress returns the mail address for an identity key
+func IdentityAddress(key ed25519.PrivateKey) string {
+	return hex.EncodeToString(key.Public().(ed25519.PublicKey)) + "@" + mailDomain
+}
+
+// ReadIdentityKey reads the identity private key from the yggmail database
+// The database may be open by the running service; it is only read
+func ReadIdentityKey(dbPath string) (ed25519.PrivateKey, error) {
+	if _, err := os.Stat(dbPath); err != nil {
+		if os.IsNotExist(err) {
+			return nil, ErrNoIdentity
+		}
+		return nil, fmt.Errorf("failed to access database: %w", err)
+	}
+
+	db, err := sql.Open("sqlite3", "file:"+dbPath+"?mode=ro&_busy_timeout=5000")
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database: %w", err)
+	}
+	defer db.Close()
+
+	var value string
+	err = db.QueryRow(`SELECT value FROM config WHERE key = ?`, identityKeyName).Scan(&value)
+	if errors.Is(err, sql.ErrNoRows) || (err != nil && strings.Contains(err.Error(), "no such table")) {
+		return nil, ErrNoIdentity
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read identity key: %w", err)
+	}
+
+	raw, err := hex.DecodeString(value)
+	if err != nil || len(raw) != ed25519.PrivateKeySize {
+		return nil, fmt.Errorf("identity key in the database is malformed")
+	}
+	return ed25519.PrivateKey(raw), nil
+}
+
+// WriteIdentityKey stores the identity private key in the yggmail database
+// Creates the database if it doesn't exist. The service must be closed.
+// Unless replace is set, a different identity already in the database is an error
+func WriteIdentityKey(dbPath string, key ed25519.PrivateKey, replace bool) error {
+	if len(key) != ed25519.PrivateKeySize {
+		return fmt.Errorf("invalid identity key")
+	}
+
+	existing, err := ReadIdentityKey(dbPath)
+	switch {
+	case err == nil:
+		if bytes.Equal(existing, key) {
+			return nil
+		}
+		if !replace {
+			return ErrIdentityExists
+		}
+	case !errors.Is(err, ErrNoIdentity):
+		return err
+	}
+
+	db, err := sql.Open("sqlite3", "file:"+dbPath+"?_busy_timeout=5000")
+	if err != nil {
+		return fmt.Errorf("failed to open database: %w", err)
+	}
+	defer db.Close()
+
+	// Same schema yggmail creates, so a fresh database is picked up on Initialize
+	if _, err := db.Exec(`CREATE TABLE IF NOT EXISTS config (
+		key 		TEXT NOT NULL,
+		value 		TEXT NOT NULL,
+		PRIMARY KEY(key)
+	)`); err != nil {
+		return fmt.Errorf("failed to prepare database: %w", err)
+	}
+	if _, err := db.Exec(`INSERT OR REPLACE INTO config (key, value) VALUES(?, ?)`,
+		identityKeyName, hex.EncodeToString(key)); err != nil {
+		return fmt.Errorf("failed to write identity key: %w", err)
+	}
+	return nil
+}
+
+// EncryptIdentity encrypts an identity key into an identity file with a passphrase
+func EncryptIdentity(key ed25519.PrivateKey, passphrase string) ([]byte, error) {
+	if len(passphrase) < MinIdentityPassphraseLength {
+		return nil, fmt.Errorf("passphrase must be at least %d characters", MinIdentityPassphraseLength)
+	}
+
+	data, err := json.Marshal(IdentityFile{
+		Version:   CurrentIdentityFileVersion,
+		Timestamp: time.Now().Format(time.RFC3339),
+		Address:   IdentityAddress(key),
+		Seed:      hex.EncodeToString(key.Seed()),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to serialize identity: %w", err)
+	}
+	return EncryptAESGCM(string(data), passphrase)
+}
+
+// DecryptIdentity decrypts an identity file and returns the identity key
+func DecryptIdentity(data []byte, passphrase string) (ed25519.PrivateKey, error) {
+	plaintext, err := DecryptAESGCM(data, passphrase)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt identity file (invalid passphrase or corrupted file): %w", err)
+	}
+
+	var file IdentityFile
+	if err := json.Unmarshal([]byte(plaintext), &file); err != nil {
+		return nil, fmt.Errorf("failed to parse identity file: %w", err)
+	}
+	if file.Version != CurrentIdentityFileVersion {
+		return nil, fmt.Errorf("unsupported identity file version %s", file.Version)
+	}
+
+	seed, err := hex.DecodeString(file.Seed)
+	if err != nil || len(seed) != ed25519.SeedSize {
+		return nil, fmt.Errorf("identity file contains a malformed key")
+	}
+	key := ed25519.NewKeyFromSeed(seed)
+	if file.Address != "" && !strings.EqualFold(file.Address, IdentityAddress(key)) {
+		return nil, fmt.Errorf("identity file address doesn't match its key")
+	}
+	return key, nil
+}
+
+// IdentityMnemonic encodes the identity seed as a 24-word BIP-39 mnemonic
+func IdentityMnemonic(key ed25519.PrivateKey) (string, error) {
+	return bip39.NewMnemonic(key.Seed())
+}
+
+// IdentityFromMnemonic decodes a 24-word BIP-39 mnemonic into an identity key
+// Word case and extra whitespace are ignored; the checksum is verified
+func IdentityFromMnemonic(mnemonic string) (ed25519.PrivateKey, error) {
+	words := strings.Fields(strings.ToLower(mnemonic))
+	if len(words) != 24 {
+		return nil, fmt.Errorf("recovery phrase must have 24 words, got %d", len(words))
+	}
+
+	seed, err := bip39.EntropyFromMnemonic(strings.Join(words, " "))
+	if err != nil {
+		return nil, fmt.Errorf("invalid recovery phrase: %w", err)
+	}
+	if len(seed) != ed25519.SeedSize {
+		return nil, fmt.Errorf("invalid recovery phrase")
+	}
+	return ed25519.NewKeyFromSeed(seed), nil
+}
@@ -0,0 +1,137 @@
+package core
+
+import (
+	"crypto/ed25519"
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// zeroSeedMnemonic is the BIP-39 mnemonic of 32 zero bytes
+var zeroSeedMnemonic = strings.Repeat("abandon ", 23) + "art"
+
+func TestIdentityMnemonicRoundTrip(t *testing.T) {
+	zeroKey := ed25519.NewKeyFromSeed(make([]byte, ed25519.SeedSize))
+	mnemonic, err := IdentityMnemonic(zeroKey)
+	if err != nil {
+		t.Fatalf("IdentityMnemonic: %v", err)
+	}
+	if mnemonic != zeroSeedMnemonic {
+		t.Errorf("IdentityMnemonic(zero seed) = %q, want %q", mnemonic, zeroSeedMnemonic)
+	}
+
+	for i := 0; i < 10; i++ {
+		_, key, err := ed25519.GenerateKey(nil)
+		if err != nil {
+			t.Fatalf("GenerateKey: %v", err)
+		}
+		mnemonic, err := IdentityMnemonic(key)
+		if err != nil {
+			t.Fatalf("IdentityMnemonic: %v", err)
+		}
+		if words := len(strings.Fields(mnemonic)); words != 24 {
+			t.Fatalf("mnemonic has %d words, want 24", words)
+		}
+
+		got, err := IdentityFromMnemonic(mnemonic)
+		if err != nil {
+			t.Fatalf("IdentityFromMnemonic: %v", err)
+		}
+		if !got.Equal(key) {
+			t.Fatalf("IdentityFromMnemonic returned a different key for %q", mnemonic)
+		}
+	}
+}
+
+func TestIdentityFromMnemonic(t *testing.T) {
+	words := strings.Fields(zeroSeedMnemonic)
+
+	tests := []struct {
+		name     string
+		mnemonic string
+		wantErr  bool
+	}{
+		{"canonical", zeroSeedMnemonic, false},
+		{"upper case", strings.ToUpper(zeroSeedMnemonic), false},
+		{"extra whitespace", "  " + strings.Join(words, " \t\n ") + "\n", false},
+		{"too few words", strings.Join(words[:23], " "), true},
+		{"too many words", zeroSeedMnemonic + " abandon", true},
+		{"12 words", strings.Repeat("abandon ", 11) + "about", true},
+		{"bad checksum", strings.Repeat("abandon ", 24), true},
+		{"unknown word", strings.Repeat("abandon ", 23) + "tyr", true},
+		{"empty", "", true},
+	}
+
+	zeroKey := ed25519.NewKeyFromSeed(make([]byte, ed25519.SeedSize))
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			key, err := IdentityFromMnemonic(tt.mnemonic)
+			if tt.wantErr {
+				if err == nil {
+					t.Error("IdentityFromMnemonic accepted an invalid phrase")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("IdentityFromMnemonic: %v", err)
+			}
+			if !key.Equal(zeroKey) {
+				t.Error("IdentityFromMnemonic returned the wrong key")
+			}
+		})
+	}
+}
+
+func TestIdentityFileRoundTrip(t *testing.T) {
+	useFastKDF(t)
+
+	_, key, _ := ed25519.GenerateKey(nil)
+	if _, err := EncryptIdentity(key, "short"); err == nil {
+		t.Error("EncryptIdentity accepted a short passphrase")
+	}
+
+	data, err := EncryptIdentity(key, "long passphrase")
+	if err != nil {
+		t.Fatalf("EncryptIdentity: %v", err)
+	}
+	got, err := DecryptIdentity(data, "long passphrase")
+	if err != nil {
+		t.Fatalf("DecryptIdentity: %v", err)
+	}
+	if !got.Equal(key) {
+		t.Error("DecryptIdentity returned a different key")
+	}
+	if _, err := DecryptIdentity(data, "wrong passphrase"); err == nil {
+		t.Error("DecryptIdentity with wrong passphrase succeeded")
+	}
+}
+
+func TestWriteIdentityKey(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "yggmail.db")
+	_, first, _ := ed25519.GenerateKey(nil)
+	_, second, _ := ed25519.GenerateKey(nil)
+
+	if _, err := ReadIdentityKey(dbPath); !errors.Is(err, ErrNoIdentity) {
+		t.Errorf("ReadIdentityKey on a new database = %v, want ErrNoIdentity", err)
+	}
+	if err := WriteIdentityKey(dbPath, first, false); err != nil {
+		t.Fatalf("WriteIdentityKey: %v", err)
+	}
+	if err := WriteIdentityKey(dbPath, first, false); err != nil {
+		t.Errorf("WriteIdentityKey with the same key = %v, want nil", err)
+	}
+	if err := WriteIdentityKey(dbPath, second, false); !errors.Is(err, ErrIdentityExists) {
+		t.Errorf("WriteIdentityKey over another key = %v, want ErrIdentityExists", err)
+	}
+	if key, _ := ReadIdentityKey(dbPath); !key.Equal(first) {
+		t.Error("refused WriteIdentityKey replaced the key")
+	}
+
+	if err := WriteIdentityKey(dbPath, second, true); err != nil {
+		t.Fatalf("WriteIdentityKey with replace: %v", err)
+	}
+	if key, _ := ReadIdentityKey(dbPath); !key.Equal(second) {
+		t.Error("WriteIdentityKey with replace kept the old key")
+	}
+}
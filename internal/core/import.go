@@ -0,0 +1,564 @@
+package core
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/mail"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/JB-SelfCompany/Tyr-Desktop/internal/mailclient"
+)
+
+// ErrImportCancelled is returned when an import is interrupted before it finishes
+var ErrImportCancelled = errors.New("import cancelled")
+
+// ImportOptions selects what to import and where
+type ImportOptions struct {
+	// Paths are mbox files, .eml files, Maildir directories or folders of .eml files
+	Paths []string
+
+	// Mailbox is the target mailbox (created if missing; Outbox is not allowed)
+	Mailbox string
+
+	// Format forces "mbox", "maildir" or "eml" for every path (empty detects per path)
+	Format string
+}
+
+// ImportFileResult contains the outcome for one imported path
+type ImportFileResult struct {
+	// Path is the imported file or directory
+	Path string
+
+	// Format is the detected or forced format
+	Format string
+
+	// Imported is the number of messages appended
+	Imported int
+
+	// Duplicates is the number of messages skipped because their Message-ID exists
+	Duplicates int
+
+	// Failed is the number of messages that could not be imported
+	Failed int
+
+	// Errors lists the failures
+	Errors []string
+}
+
+// ImportResult contains the outcome of an import
+type ImportResult struct {
+	// Mailbox is the target mailbox
+	Mailbox string
+
+	// Files contains one entry per imported path (per file for folders of .eml files)
+	Files []ImportFileResult
+
+	// Imported is the total number of messages appended
+	Imported int
+
+	// Duplicates is the total number of skipped duplicates
+	Duplicates int
+
+	// Failed is the total number of failed messages
+	Failed int
+
+	// StartedAt is when the run started
+	StartedAt time.Time
+
+	// FinishedAt is when the run finished
+	FinishedAt time.Time
+}
+
+// ImportProgress describes the progress of a running import
+type ImportProgress struct {
+	// Path is the file being imported
+	Path string
+
+	// File is the one-based index of the current path
+	File int
+
+	// Files is the number of paths
+	Files int
+
+	// Messages is the number of messages handled so far
+	Messages int
+}
+
+// importMessage is one message read from an archive
+type importMessage struct {
+	// Source identifies the message in error messages (file name or mbox position)
+	Source string
+
+	// Raw is the message with CRLF line endings
+	Raw []byte
+
+	// Flags are the IMAP flags recovered from the archive
+	Flags []string
+
+	// Date is the internal date recovered from the archive (zero if unknown)
+	Date time.Time
+
+	// Err is set if the message could not be read
+	Err error
+}
+
+// importSource is one unit of the per-file report
+type importSource struct {
+	path   string
+	format string
+}
+
+// Validate checks the import options for errors
+func (o *ImportOptions) Validate() error {
+	if len(o.Paths) == 0 {
+		return fmt.Errorf("no files selected for import")
+	}
+	if strings.TrimSpace(o.Mailbox) == "" {
+		return fmt.Errorf("target mailbox is required")
+	}
+	if strings.EqualFold(o.Mailbox, outboxMailbox) {
+		return fmt.Errorf("cannot import messages into Outbox")
+	}
+	switch o.Format {
+	case "", ExportFormatMbox, ExportFormatMaildir, ExportFormatEML:
+	default:
+		return fmt.Errorf("unsupported import format: %s", o.Format)
+	}
+	return nil
+}
+
+// ImportMessages appends messages from mbox, Maildir or .eml files to a mailbox over local IMAP
+// Flags and internal dates are preserved where the archive records them. Messages whose
+// Message-ID already exists in the mailbox are skipped. Cancelling ctx stops the import
+// after the current message and returns the partial result with ErrImportCancelled
+// Thread-safe
+func (sm *ServiceManager) ImportMessages(ctx context.Context, opts ImportOptions, progress func(ImportProgress)) (*ImportResult, error) {
+	if err := opts.Validate(); err != nil {
+		return nil, err
+	}
+	if !sm.archiveMu.TryLock() {
+		return nil, fmt.Errorf("an export or import is already in progress")
+	}
+	defer sm.archiveMu.Unlock()
+
+	sources, failed := expandImportPaths(opts)
+	result := &ImportResult{
+		Mailbox:   opts.Mailbox,
+		Files:     failed,
+		StartedAt: time.Now().UTC(),
+	}
+	for _, f := range failed {
+		result.Failed += f.Failed
+	}
+
+	err := sm.withIMAPSession(func(s *mailclient.IMAPSession) error {
+		if err := ensureMailbox(s, opts.Mailbox); err != nil {
+			return err
+		}
+
+		// Collect existing Message-IDs for duplicate detection
+		if _, err := s.Examine(opts.Mailbox); err != nil {
+			return err
+		}
+		headers, err := s.AllHeaders()
+		if err != nil {
+			return err
+		}
+		seen := make(map[string]bool, len(headers))
+		for _, h := range headers {
+			if id := normalizeMessageID(h.MessageID); id != "" {
+				seen[id] = true
+			}
+		}
+
+		messages := 0
+		for i, src := range sources {
+			if progress != nil {
+				progress(ImportProgress{Path: src.path, File: i + 1, Files: len(sources), Messages: messages})
+			}
+
+			file := ImportFileResult{Path: src.path, Format: src.format, Errors: []string{}}
+			err := readImportSource(src, func(msg importMessage) error {
+				if err := ctx.Err(); err != nil {
+					return ErrImportCancelled
+				}
+				messages++
+				if msg.Err != nil {
+					file.Failed++
+					file.Errors = append(file.Errors, fmt.Sprintf("%s: %v", msg.Source, msg.Err))
+					return nil
+				}
+
+				id := normalizeMessageID(headerValue(msg.Raw, "Message-Id"))
+				if id != "" && seen[id] {
+					file.Duplicates++
+					return nil
+				}
+
+				date := msg.Date
+				if date.IsZero() {
+					date = time.Now()
+				}
+				if err := s.AppendMessage(opts.Mailbox, msg.Flags, date, msg.Raw); err != nil {
+					file.Failed++
+					file.Errors = append(file.Errors, fmt.Sprintf("%s: %v", msg.Source, err))
+					return nil
+				}
+				if id != "" {
+					seen[id] = true
+				}
+				file.Imported++
+				return nil
+			})
+			if err != nil && !errors.Is(err, ErrImportCancelled) {
+				file.Errors = append(file.Errors, err.Error())
+			}
+
+			result.Files = append(result.Files, file)
+			result.Imported += file.Imported
+			result.Duplicates += file.Duplicates
+			result.Failed += file.Failed
+			if errors.Is(err, ErrImportCancelled) {
+				return err
+			}
+		}
+		return nil
+	})
+
+	result.FinishedAt = time.Now().UTC()
+	if err != nil && !errors.Is(err, ErrImportCancelled) {
+		return nil, err
+	}
+
+	log.Printf("[Import] Imported %d messages into %s (%d duplicates, %d failed)",
+		result.Imported, opts.Mailbox, result.Duplicates, result.Failed)
+	return result, err
+}
+
+// expandImportPaths detects the format of each path and expands folders of .eml files
+// Paths that can't be read are returned as failed file results
+func expandImportPaths(opts ImportOptions) ([]importSource, []ImportFileResult) {
+	var (
+		sources []importSource
+		failed  []ImportFileResult
+	)
+	for _, path := range opts.Paths {
+		format, err := detectImportFormat(path, opts.Format)
+		if err != nil {
+			failed = append(failed, ImportFileResult{Path: path, Failed: 1, Errors: []string{err.Error()}})
+			continue
+		}
+
+		info, err := os.Stat(path)
+		if err == nil && format == ExportFormatEML && info.IsDir() {
+			entries, err := os.ReadDir(path)
+			if err != nil {
+				failed = append(failed, ImportFileResult{Path: path, Format: format, Failed: 1, Errors: []string{err.Error()}})
+				continue
+			}
+			for _, entry := range entries {
+				if !entry.IsDir() && strings.EqualFold(filepath.Ext(entry.Name()), ".eml") {
+					sources = append(sources, importSource{path: filepath.Join(path, entry.Name()), format: format})
+				}
+			}
+			continue
+		}
+		sources = append(sources, importSource{path: path, format: format})
+	}
+	return sources, failed
+}
+
+// detectImportFormat returns the archive format of a path
+// A forced format is only checked against the path type
+func detectImportFormat(path, forced string) (string, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return "", err
+	}
+
+	if info.IsDir() {
+		if forced == ExportFormatMbox {
+			return "", fmt.Errorf("an mbox archive must be a file")
+		}
+		if forced != "" {
+			return forced, nil
+		}
+		if st, err := os.Stat(filepath.Join(path, "cur")); err == nil && st.IsDir() {
+			return ExportFormatMaildir, nil
+		}
+		return ExportFormatEML, nil
+	}
+
+	if forced == ExportFormatMaildir {
+		return "", fmt.Errorf("a Maildir archive must be a directory")
+	}
+	if forced != "" {
+		return forced, nil
+	}
+	if strings.EqualFold(filepath.Ext(path), ".eml") {
+		return ExportFormatEML, nil
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+	head := make([]byte, 5)
+	if n, _ := io.ReadFull(file, head); n == 5 && string(head) == "From " {
+		return ExportFormatMbox, nil
+	}
+	return ExportFormatEML, nil
+}
+
+// readImportSource reads the messages of one source and passes them to fn
+// Stops at the first error returned by fn
+func readImportSource(src importSource, fn func(importMessage) error) error {
+	switch src.format {
+	case ExportFormatMbox:
+		return readMbox(src.path, fn)
+	case ExportFormatMaildir:
+		return readMaildir(src.path, fn)
+	default:
+		raw, err := os.ReadFile(src.path)
+		if err != nil {
+			return err
+		}
+		msg := importMessage{Source: filepath.Base(src.path), Raw: toCRLF(raw)}
+		msg.Flags = statusFlags(msg.Raw)
+		msg.Date = headerDate(msg.Raw)
+		if msg.Date.IsZero() {
+			if info, err := os.Stat(src.path); err == nil {
+				msg.Date = info.ModTime()
+			}
+		}
+		return fn(msg)
+	}
+}
+
+// readMbox splits an mbox file into messages
+// "From " lines start a new message; mboxrd quoting (">From ") is undone
+func readMbox(path string, fn func(importMessage) error) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	var (
+		buf      bytes.Buffer
+		date     time.Time
+		count    int
+		inBody   bool
+		prevLine = "\n"
+	)
+	flush := func() error {
+		if !inBody {
+			return nil
+		}
+		// The blank line before the next "From " belongs to the mbox format
+		raw := bytes.TrimSuffix(buf.Bytes(), []byte("\r\n"))
+		msg := importMessage{
+			Source: fmt.Sprintf("message %d", count),
+			Raw:    append([]byte(nil), raw...),
+			Date:   date,
+		}
+		msg.Flags = statusFlags(msg.Raw)
+		if msg.Date.IsZero() {
+			msg.Date = headerDate(msg.Raw)
+		}
+		buf.Reset()
+		return fn(msg)
+	}
+
+	reader := bufio.NewReader(file)
+	for {
+		line, err := reader.ReadString('\n')
+		if line == "" && err != nil {
+			if err == io.EOF {
+				break
+			}
+			return err
+		}
+
+		if strings.HasPrefix(line, "From ") && (prevLine == "\n" || prevLine == "\r\n") {
+			if err := flush(); err != nil {
+				return err
+			}
+			count++
+			inBody = true
+			date = mboxFromLineDate(line)
+			prevLine = line
+			continue
+		}
+		prevLine = line
+
+		if !inBody {
+			continue
+		}
+		content := strings.TrimRight(line, "\r\n")
+		if strings.HasPrefix(strings.TrimLeft(content, ">"), "From ") && strings.HasPrefix(content, ">") {
+			content = content[1:]
+		}
+		buf.WriteString(content)
+		buf.WriteString("\r\n")
+
+		if err == io.EOF {
+			break
+		}
+	}
+	return flush()
+}
+
+// mboxFromLineDate parses the asctime date at the end of an mbox "From " line
+func mboxFromLineDate(line string) time.Time {
+	fields := strings.Fields(line)
+	if len(fields) < 7 {
+		return time.Time{}
+	}
+	value := strings.Join(fields[len(fields)-5:], " ")
+	if t, err := time.Parse("Mon Jan _2 15:04:05 2006", value); err == nil {
+		return t
+	}
+	if t, err := time.Parse("Mon Jan 2 15:04:05 2006", value); err == nil {
+		return t
+	}
+	return time.Time{}
+}
+
+// readMaildir reads messages from the cur and new directories of a Maildir
+// Flags come from the ":2," info suffix and the date from the file name timestamp
+func readMaildir(dir string, fn func(importMessage) error) error {
+	var paths []string
+	for _, sub := range []string{"cur", "new"} {
+		entries, err := os.ReadDir(filepath.Join(dir, sub))
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return err
+		}
+		for _, entry := range entries {
+			if !entry.IsDir() && !strings.HasPrefix(entry.Name(), ".") {
+				paths = append(paths, filepath.Join(dir, sub, entry.Name()))
+			}
+		}
+	}
+	sort.Strings(paths)
+
+	for _, path := range paths {
+		name := filepath.Base(path)
+		raw, err := os.ReadFile(path)
+		if err != nil {
+			if err := fn(importMessage{Source: name, Err: err}); err != nil {
+				return err
+			}
+			continue
+		}
+
+		msg := importMessage{
+			Source: name,
+			Raw:    toCRLF(raw),
+			Flags:  maildirInfoFlags(name),
+		}
+		if sec, err := strconv.ParseInt(strings.SplitN(name, ".", 2)[0], 10, 64); err == nil && sec > 0 {
+			msg.Date = time.Unix(sec, 0)
+		} else if info, err := os.Stat(path); err == nil {
+			msg.Date = info.ModTime()
+		}
+		if err := fn(msg); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// maildirInfoFlags converts the Maildir info suffix of a file name to IMAP flags
+// Both ':' and the Windows substitute '!' are accepted as separators
+func maildirInfoFlags(name string) []string {
+	i := strings.LastIndexAny(name, ":!")
+	if i < 0 || !strings.HasPrefix(name[i+1:], "2,") {
+		return []string{}
+	}
+
+	flags := []string{}
+	for _, letter := range name[i+3:] {
+		switch letter {
+		case 'D':
+			flags = append(flags, "\\Draft")
+		case 'F':
+			flags = append(flags, "\\Flagged")
+		case 'R':
+			flags = append(flags, "\\Answered")
+		case 'S':
+			flags = append(flags, "\\Seen")
+		}
+	}
+	return flags
+}
+
+// statusFlags recovers flags from the Status and X-Status headers written by mbox clients
+// \Deleted is not restored so imported messages aren't expunged
+func statusFlags(raw []byte) []string {
+	flags := []string{}
+	if strings.Contains(headerValue(raw, "Status"), "R") {
+		flags = append(flags, "\\Seen")
+	}
+	xstatus := headerValue(raw, "X-Status")
+	if strings.Contains(xstatus, "A") {
+		flags = append(flags, "\\Answered")
+	}
+	if strings.Contains(xstatus, "F") {
+		flags = append(flags, "\\Flagged")
+	}
+	if strings.Contains(xstatus, "T") {
+		flags = append(flags, "\\Draft")
+	}
+	return flags
+}
+
+// headerValue returns a header field of a raw message (empty if missing or unparsable)
+func headerValue(raw []byte, name string) string {
+	msg, err := mail.ReadMessage(bytes.NewReader(raw))
+	if err != nil {
+		return ""
+	}
+	return msg.Header.Get(name)
+}
+
+// headerDate returns the Date header of a raw message (zero if missing or unparsable)
+func headerDate(raw []byte) time.Time {
+	msg, err := mail.ReadMessage(bytes.NewReader(raw))
+	if err != nil {
+		return time.Time{}
+	}
+	date, err := msg.Header.Date()
+	if err != nil {
+		return time.Time{}
+	}
+	return date
+}
+
+// normalizeMessageID strips whitespace and angle brackets from a Message-ID
+func normalizeMessageID(id string) string {
+	return strings.Trim(strings.TrimSpace(id), "<>")
+}
+
+// toCRLF converts bare LF line endings to CRLF as required by IMAP APPEND
+func toCRLF(raw []byte) []byte {
+	if !bytes.Contains(raw, []byte("\n")) {
+		return raw
+	}
+	normalized := bytes.ReplaceAll(raw, []byte("\r\n"), []byte("\n"))
+	return bytes.ReplaceAll(normalized, []byte("\n"), []byte("\r\n"))
+}
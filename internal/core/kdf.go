@@ -0,0 +1,364 @@
+package core
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"sync"
+
+	"golang.org/x/crypto/argon2"
+)
+
+// Key derivation function names
+const (
+	// KDFArgon2id is the memory-hard Argon2id function used for new data
+	KDFArgon2id = "argon2id"
+
+	// KDFPBKDF2 is PBKDF2-SHA256, used by older files
+	KDFPBKDF2 = "pbkdf2-sha256"
+)
+
+const (
+	// DefaultKDFTimeCost is the default number of Argon2id passes
+	DefaultKDFTimeCost = 3
+
+	// DefaultKDFMemoryMB is the default Argon2id memory cost in megabytes
+	DefaultKDFMemoryMB = 64
+
+	// DefaultKDFThreads is the default Argon2id parallelism
+	DefaultKDFThreads = 4
+
+	// containerMagic identifies data encrypted with a versioned header
+	containerMagic = "TYRENC"
+
+	// containerVersion is the current encrypted container format version
+	containerVersion = 1
+
+	// Key derivation function ids stored in the container header
+	containerKDFPBKDF2   = 1
+	containerKDFArgon2id = 2
+)
+
+// KDFParams describes how an encryption key is derived from a password
+type KDFParams struct {
+	// Algorithm is KDFArgon2id or KDFPBKDF2
+	Algorithm string `json:"kdf"`
+
+	// Iterations is the PBKDF2 iteration count
+	Iterations uint32 `json:"iterations,omitempty"`
+
+	// Time is the number of Argon2id passes
+	Time uint32 `json:"time,omitempty"`
+
+	// MemoryKiB is the Argon2id memory cost in kibibytes
+	MemoryKiB uint32 `json:"memory_kib,omitempty"`
+
+	// Threads is the Argon2id parallelism
+	Threads uint8 `json:"threads,omitempty"`
+}
+
+// deriveKey derives a 32-byte key from the password with these parameters
+func (p KDFParams) deriveKey(password string, salt []byte) ([]byte, error) {
+	switch p.Algorithm {
+	case KDFArgon2id:
+		if p.Time == 0 || p.MemoryKiB == 0 || p.Threads == 0 {
+			return nil, fmt.Errorf("invalid Argon2id parameters")
+		}
+		return argon2.IDKey([]byte(password), salt, p.Time, p.MemoryKiB, p.Threads, AESKeySize), nil
+	case KDFPBKDF2:
+		if p.Iterations == 0 {
+			return nil, fmt.Errorf("invalid PBKDF2 parameters")
+		}
+		return DeriveKey(password, salt, int(p.Iterations)), nil
+	default:
+		return nil, fmt.Errorf("unsupported key derivation function: %s", p.Algorithm)
+	}
+}
+
+// checkBounds refuses parameters read from a file that would exhaust memory or run for hours
+func (p KDFParams) checkBounds() error {
+	if p.Time > 64 || p.MemoryKiB > 4*1024*1024 || p.Iterations > 10_000_000 {
+		return fmt.Errorf("key derivation parameters are out of range")
+	}
+	return nil
+}
+
+// weakerThan returns true if data protected with p should be re-encrypted with q
+func (p KDFParams) weakerThan(q KDFParams) bool {
+	if p.Algorithm != q.Algorithm {
+		return p.Algorithm == KDFPBKDF2
+	}
+	if p.Algorithm == KDFArgon2id {
+		return p.Time < q.Time || p.MemoryKiB < q.MemoryKiB
+	}
+	return p.Iterations < q.Iterations
+}
+
+// EncryptionSettings contains the key derivation cost for newly encrypted data
+// Applies to backups, the fallback password file and encrypted storage
+type EncryptionSettings struct {
+	// KDFTimeCost is the number of Argon2id passes
+	KDFTimeCost int `toml:"kdf_time_cost"`
+
+	// KDFMemoryMB is the Argon2id memory cost in megabytes
+	KDFMemoryMB int `toml:"kdf_memory_mb"`
+
+	// KDFThreads is the Argon2id parallelism
+	KDFThreads int `toml:"kdf_threads"`
+
+	// AllowWorkDirOnDisk accepts a decrypted storage working copy in the temp
+	// directory on systems without a memory filesystem (Windows, macOS)
+	AllowWorkDirOnDisk bool `toml:"allow_work_dir_on_disk"`
+}
+
+// applyDefaults fills in missing encryption values
+func (s *EncryptionSettings) applyDefaults() {
+	if s.KDFTimeCost <= 0 {
+		s.KDFTimeCost = DefaultKDFTimeCost
+	}
+	if s.KDFMemoryMB <= 0 {
+		s.KDFMemoryMB = DefaultKDFMemoryMB
+	}
+	if s.KDFThreads <= 0 {
+		s.KDFThreads = DefaultKDFThreads
+	}
+}
+
+// Validate checks the encryption settings for errors
+func (s *EncryptionSettings) Validate() error {
+	if s.KDFTimeCost < 1 || s.KDFTimeCost > 10 {
+		return fmt.Errorf("time cost must be between 1 and 10")
+	}
+	if s.KDFMemoryMB < 16 || s.KDFMemoryMB > 1024 {
+		return fmt.Errorf("memory cost must be between 16 and 1024 MB")
+	}
+	if s.KDFThreads < 1 || s.KDFThreads > 16 {
+		return fmt.Errorf("threads must be between 1 and 16")
+	}
+	return nil
+}
+
+// KDFParams returns the Argon2id parameters for these settings
+func (s EncryptionSettings) KDFParams() KDFParams {
+	return KDFParams{
+		Algorithm: KDFArgon2id,
+		Time:      uint32(s.KDFTimeCost),
+		MemoryKiB: uint32(s.KDFMemoryMB) * 1024,
+		Threads:   uint8(s.KDFThreads),
+	}
+}
+
+// GetEncryptionSettings returns the key derivation settings
+// Thread-safe with read lock
+func (c *Config) GetEncryptionSettings() EncryptionSettings {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.Encryption
+}
+
+// SetEncryptionSettings validates and replaces the key derivation settings
+// The new cost applies to data encrypted from now on
+// Thread-safe with write lock
+func (c *Config) SetEncryptionSettings(settings EncryptionSettings) error {
+	settings.applyDefaults()
+	if err := settings.Validate(); err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	c.Encryption = settings
+	c.mu.Unlock()
+
+	SetKDFParams(settings.KDFParams())
+	return nil
+}
+
+// kdfParams are the parameters used for newly encrypted data
+var (
+	kdfParams = KDFParams{
+		Algorithm: KDFArgon2id,
+		Time:      DefaultKDFTimeCost,
+		MemoryKiB: DefaultKDFMemoryMB * 1024,
+		Threads:   DefaultKDFThreads,
+	}
+	kdfParamsMu sync.RWMutex
+)
+
+// SetKDFParams sets the key derivation parameters used for newly encrypted data
+func SetKDFParams(params KDFParams) {
+	kdfParamsMu.Lock()
+	defer kdfParamsMu.Unlock()
+	kdfParams = params
+}
+
+// CurrentKDFParams returns the key derivation parameters used for newly encrypted data
+func CurrentKDFParams() KDFParams {
+	kdfParamsMu.RLock()
+	defer kdfParamsMu.RUnlock()
+	return kdfParams
+}
+
+// encryptContainer encrypts plaintext into the versioned container format:
+//
+//	magic "TYRENC" | version | KDF id | KDF parameters | salt length | salt | nonce | ciphertext
+//
+// The header is authenticated as additional data, so parameters can't be altered
+func encryptContainer(plaintext []byte, password string, params KDFParams) ([]byte, error) {
+	salt := make([]byte, SaltSize)
+	if _, err := io.ReadFull(rand.Reader, salt); err != nil {
+		return nil, fmt.Errorf("failed to generate salt: %w", err)
+	}
+	nonce := make([]byte, GCMNonceSize)
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, fmt.Errorf("failed to generate nonce: %w", err)
+	}
+
+	var header bytes.Buffer
+	header.WriteString(containerMagic)
+	header.WriteByte(containerVersion)
+	switch params.Algorithm {
+	case KDFArgon2id:
+		header.WriteByte(containerKDFArgon2id)
+		binary.Write(&header, binary.BigEndian, params.Time)
+		binary.Write(&header, binary.BigEndian, params.MemoryKiB)
+		header.WriteByte(params.Threads)
+	case KDFPBKDF2:
+		header.WriteByte(containerKDFPBKDF2)
+		binary.Write(&header, binary.BigEndian, params.Iterations)
+	default:
+		return nil, fmt.Errorf("unsupported key derivation function: %s", params.Algorithm)
+	}
+	header.WriteByte(byte(len(salt)))
+	header.Write(salt)
+	header.Write(nonce)
+
+	key, err := params.deriveKey(password, salt)
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+
+	out := header.Bytes()
+	return gcm.Seal(out, nonce, plaintext, out), nil
+}
+
+// isContainer returns true if data starts with the container magic
+func isContainer(data []byte) bool {
+	return bytes.HasPrefix(data, []byte(containerMagic))
+}
+
+// parseContainer reads the container header
+// Returns the KDF parameters, salt, nonce, the header bytes and the ciphertext
+func parseContainer(data []byte) (KDFParams, []byte, []byte, []byte, []byte, error) {
+	var params KDFParams
+	invalid := fmt.Errorf("invalid encrypted data header")
+
+	r := bytes.NewReader(data[len(containerMagic):])
+	version, err := r.ReadByte()
+	if err != nil {
+		return params, nil, nil, nil, nil, invalid
+	}
+	if version != containerVersion {
+		return params, nil, nil, nil, nil, fmt.Errorf("unsupported encrypted data version %d", version)
+	}
+
+	kdf, err := r.ReadByte()
+	if err != nil {
+		return params, nil, nil, nil, nil, invalid
+	}
+	switch kdf {
+	case containerKDFArgon2id:
+		params.Algorithm = KDFArgon2id
+		if binary.Read(r, binary.BigEndian, &params.Time) != nil ||
+			binary.Read(r, binary.BigEndian, &params.MemoryKiB) != nil ||
+			binary.Read(r, binary.BigEndian, &params.Threads) != nil {
+			return params, nil, nil, nil, nil, invalid
+		}
+	case containerKDFPBKDF2:
+		params.Algorithm = KDFPBKDF2
+		if binary.Read(r, binary.BigEndian, &params.Iterations) != nil {
+			return params, nil, nil, nil, nil, invalid
+		}
+	default:
+		return params, nil, nil, nil, nil, fmt.Errorf("unsupported key derivation function id %d", kdf)
+	}
+
+	if err := params.checkBounds(); err != nil {
+		return params, nil, nil, nil, nil, err
+	}
+
+	saltLen, err := r.ReadByte()
+	if err != nil || saltLen < 16 {
+		return params, nil, nil, nil, nil, invalid
+	}
+	salt := make([]byte, saltLen)
+	nonce := make([]byte, GCMNonceSize)
+	if _, err := io.ReadFull(r, salt); err != nil {
+		return params, nil, nil, nil, nil, invalid
+	}
+	if _, err := io.ReadFull(r, nonce); err != nil {
+		return params, nil, nil, nil, nil, invalid
+	}
+
+	headerLen := len(data) - r.Len()
+	if r.Len() < 16 {
+		return params, nil, nil, nil, nil, fmt.Errorf("ciphertext too small (corrupted or invalid)")
+	}
+	return params, salt, nonce, data[:headerLen], data[headerLen:], nil
+}
+
+// decryptContainer decrypts data in the versioned container format
+func decryptContainer(data []byte, password string) ([]byte, KDFParams, error) {
+	params, salt, nonce, header, ciphertext, err := parseContainer(data)
+	if err != nil {
+		return nil, params, err
+	}
+	key, err := params.deriveKey(password, salt)
+	if err != nil {
+		return nil, params, err
+	}
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, params, err
+	}
+	plaintext, err := gcm.Open(nil, nonce, ciphertext, header)
+	if err != nil {
+		return nil, params, fmt.Errorf("decryption failed (invalid password or corrupted data): %w", err)
+	}
+	return plaintext, params, nil
+}
+
+// NeedsEncryptionUpgrade returns true if data uses the legacy headerless format
+// or a weaker key derivation than the current parameters
+func NeedsEncryptionUpgrade(data []byte) bool {
+	if !isContainer(data) {
+		return true
+	}
+	params, _, _, _, _, err := parseContainer(data)
+	if err != nil {
+		return false
+	}
+	return params.weakerThan(CurrentKDFParams())
+}
+
+// UpgradeEncryption re-encrypts data with the current parameters if needed
+// Returns the new data and true if it was upgraded
+func UpgradeEncryption(data []byte, password string) ([]byte, bool, error) {
+	if !NeedsEncryptionUpgrade(data) {
+		return data, false, nil
+	}
+	plaintext, err := DecryptAESGCM(data, password)
+	if err != nil {
+		return nil, false, err
+	}
+	upgraded, err := EncryptAESGCM(plaintext, password)
+	if err != nil {
+		return nil, false, err
+	}
+	return upgraded, true, nil
+}
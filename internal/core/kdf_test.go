@@ -0,0 +1,211 @@
+package core
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/binary"
+	"io"
+	"testing"
+)
+
+// fastKDFParams keep Argon2id cheap so tests don't spend seconds per key
+var fastKDFParams = KDFParams{Algorithm: KDFArgon2id, Time: 2, MemoryKiB: 64, Threads: 1}
+
+// useFastKDF makes fastKDFParams the current parameters for the test
+func useFastKDF(t *testing.T) {
+	t.Helper()
+
+	previous := CurrentKDFParams()
+	SetKDFParams(fastKDFParams)
+	t.Cleanup(func() { SetKDFParams(previous) })
+}
+
+// encryptLegacy encrypts in the headerless format written before TYRENC containers
+func encryptLegacy(t *testing.T, plaintext, password string) []byte {
+	t.Helper()
+
+	salt := make([]byte, SaltSize)
+	nonce := make([]byte, GCMNonceSize)
+	io.ReadFull(rand.Reader, salt)
+	io.ReadFull(rand.Reader, nonce)
+	gcm, err := newGCM(DeriveKey(password, salt, PBKDF2Iterations))
+	if err != nil {
+		t.Fatalf("newGCM: %v", err)
+	}
+	out := append(append([]byte{}, salt...), nonce...)
+	return gcm.Seal(out, nonce, []byte(plaintext), nil)
+}
+
+func TestContainerRoundTrip(t *testing.T) {
+	tests := []struct {
+		name   string
+		params KDFParams
+	}{
+		{"argon2id", fastKDFParams},
+		{"pbkdf2", KDFParams{Algorithm: KDFPBKDF2, Iterations: 1000}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := encryptContainer([]byte("secret"), "password", tt.params)
+			if err != nil {
+				t.Fatalf("encryptContainer: %v", err)
+			}
+			if !isContainer(data) {
+				t.Fatalf("output doesn't start with %q", containerMagic)
+			}
+
+			params, salt, _, _, _, err := parseContainer(data)
+			if err != nil {
+				t.Fatalf("parseContainer: %v", err)
+			}
+			if params != tt.params {
+				t.Errorf("params = %+v, want %+v", params, tt.params)
+			}
+			if len(salt) != SaltSize {
+				t.Errorf("salt length = %d, want %d", len(salt), SaltSize)
+			}
+
+			plaintext, _, err := decryptContainer(data, "password")
+			if err != nil || string(plaintext) != "secret" {
+				t.Errorf("decryptContainer = %q, %v, want %q", plaintext, err, "secret")
+			}
+			if _, _, err := decryptContainer(data, "wrong"); err == nil {
+				t.Error("decryptContainer with wrong password succeeded")
+			}
+		})
+	}
+}
+
+func TestParseContainerRejectsBadHeaders(t *testing.T) {
+	valid, err := encryptContainer([]byte("secret"), "password", fastKDFParams)
+	if err != nil {
+		t.Fatalf("encryptContainer: %v", err)
+	}
+
+	// Offsets in an Argon2id header: magic | version | KDF id | time | memory | threads | salt length
+	versionAt := len(containerMagic)
+	kdfAt := versionAt + 1
+	timeAt := kdfAt + 1
+	saltLenAt := timeAt + 4 + 4 + 1
+
+	tests := []struct {
+		name   string
+		mutate func(data []byte) []byte
+	}{
+		{"unsupported version", func(data []byte) []byte {
+			data[versionAt] = containerVersion + 1
+			return data
+		}},
+		{"unknown KDF", func(data []byte) []byte {
+			data[kdfAt] = 9
+			return data
+		}},
+		{"time cost out of range", func(data []byte) []byte {
+			binary.BigEndian.PutUint32(data[timeAt:], 1000)
+			return data
+		}},
+		{"short salt", func(data []byte) []byte {
+			data[saltLenAt] = 8
+			return data
+		}},
+		{"truncated header", func(data []byte) []byte {
+			return data[:saltLenAt]
+		}},
+		{"missing ciphertext", func(data []byte) []byte {
+			return data[:saltLenAt+1+SaltSize+GCMNonceSize]
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := tt.mutate(append([]byte{}, valid...))
+			if _, _, _, _, _, err := parseContainer(data); err == nil {
+				t.Error("parseContainer accepted a bad header")
+			}
+		})
+	}
+}
+
+func TestContainerHeaderIsAuthenticated(t *testing.T) {
+	data, err := encryptContainer([]byte("secret"), "password", fastKDFParams)
+	if err != nil {
+		t.Fatalf("encryptContainer: %v", err)
+	}
+
+	// The last salt byte: the header still parses but no longer matches the ciphertext
+	saltEnd := len(containerMagic) + 2 + 4 + 4 + 1 + 1 + SaltSize
+	data[saltEnd-1] ^= 0xff
+	if _, _, _, _, _, err := parseContainer(data); err != nil {
+		t.Fatalf("parseContainer: %v", err)
+	}
+	if _, _, err := decryptContainer(data, "password"); err == nil {
+		t.Error("decryptContainer accepted an altered header")
+	}
+}
+
+func TestUpgradeEncryption(t *testing.T) {
+	useFastKDF(t)
+
+	weaker := fastKDFParams
+	weaker.Time--
+	current, err := EncryptAESGCM("secret", "password")
+	if err != nil {
+		t.Fatalf("EncryptAESGCM: %v", err)
+	}
+
+	tests := []struct {
+		name string
+		data func(t *testing.T) []byte
+		want bool
+	}{
+		{"legacy format", func(t *testing.T) []byte {
+			return encryptLegacy(t, "secret", "password")
+		}, true},
+		{"pbkdf2 container", func(t *testing.T) []byte {
+			data, _ := encryptContainer([]byte("secret"), "password", KDFParams{Algorithm: KDFPBKDF2, Iterations: 1000})
+			return data
+		}, true},
+		{"weaker argon2id", func(t *testing.T) []byte {
+			data, _ := encryptContainer([]byte("secret"), "password", weaker)
+			return data
+		}, true},
+		{"current parameters", func(t *testing.T) []byte {
+			return current
+		}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := tt.data(t)
+			if got := NeedsEncryptionUpgrade(data); got != tt.want {
+				t.Errorf("NeedsEncryptionUpgrade = %v, want %v", got, tt.want)
+			}
+
+			upgraded, ok, err := UpgradeEncryption(data, "password")
+			if err != nil {
+				t.Fatalf("UpgradeEncryption: %v", err)
+			}
+			if ok != tt.want {
+				t.Errorf("UpgradeEncryption upgraded = %v, want %v", ok, tt.want)
+			}
+			if !ok && !bytes.Equal(upgraded, data) {
+				t.Error("UpgradeEncryption changed data that needed no upgrade")
+			}
+			if NeedsEncryptionUpgrade(upgraded) {
+				t.Error("upgraded data still needs an upgrade")
+			}
+			if plaintext, err := DecryptAESGCM(upgraded, "password"); err != nil || plaintext != "secret" {
+				t.Errorf("DecryptAESGCM = %q, %v, want %q", plaintext, err, "secret")
+			}
+		})
+	}
+}
+
+func TestUpgradeEncryptionRejectsWrongPassword(t *testing.T) {
+	useFastKDF(t)
+
+	if _, _, err := UpgradeEncryption(encryptLegacy(t, "secret", "password"), "wrong"); err == nil {
+		t.Error("UpgradeEncryption with wrong password succeeded")
+	}
+}
@@ -0,0 +1,292 @@
+package core
+
+import (
+	"fmt"
+	"net"
+	"net/netip"
+	"strconv"
+	"strings"
+)
+
+// LAN access TLS modes
+const (
+	// LANTLSModeImplicit wraps the whole connection in TLS (IMAPS/SMTPS)
+	LANTLSModeImplicit = "tls"
+
+	// LANTLSModeSTARTTLS accepts a plain connection that must upgrade with STARTTLS
+	LANTLSModeSTARTTLS = "starttls"
+)
+
+// Client connection security, as used in dclogin URLs
+const (
+	// ClientSecurityPlain is an unencrypted connection
+	ClientSecurityPlain = "plain"
+
+	// ClientSecuritySSL is an implicit TLS connection
+	ClientSecuritySSL = "ssl"
+
+	// ClientSecuritySTARTTLS is a connection upgraded with STARTTLS
+	ClientSecuritySTARTTLS = "starttls"
+)
+
+const (
+	// DefaultLANBindAddress listens on all interfaces
+	DefaultLANBindAddress = "0.0.0.0"
+
+	// DefaultLANSMTPPort is the default TLS SMTP listener port
+	DefaultLANSMTPPort = 1465
+
+	// DefaultLANIMAPPort is the default TLS IMAP listener port
+	DefaultLANIMAPPort = 1993
+)
+
+// LANAccessSettings contains the TLS listeners serving other devices on the LAN
+// The yggmail IMAP/SMTP servers stay on loopback; these listeners terminate TLS
+// and forward allowed connections to them
+type LANAccessSettings struct {
+	// Enabled starts the TLS listeners with the service
+	Enabled bool `toml:"enabled"`
+
+	// BindAddress is the IP the listeners bind to (0.0.0.0 or :: for all interfaces)
+	BindAddress string `toml:"bind_address"`
+
+	// TLSMode is "tls" for implicit TLS or "starttls"
+	TLSMode string `toml:"tls_mode"`
+
+	// SMTPPort is the port of the TLS SMTP listener
+	SMTPPort int `toml:"smtp_port"`
+
+	// IMAPPort is the port of the TLS IMAP listener
+	IMAPPort int `toml:"imap_port"`
+
+	// Allowlist contains the IPs and CIDR ranges allowed to connect
+	// Empty allows loopback and private network addresses only
+	Allowlist []string `toml:"allowlist"`
+
+	// AdvertiseHost is the host name or IP given to clients (empty = detect)
+	AdvertiseHost string `toml:"advertise_host"`
+
+	// StrictCertificateChecks tells clients to verify the certificate
+	// Enable once the local CA is installed on the client devices
+	StrictCertificateChecks bool `toml:"strict_certificate_checks"`
+}
+
+// applyDefaults fills in missing LAN access values
+func (s *LANAccessSettings) applyDefaults() {
+	s.BindAddress = strings.TrimSpace(s.BindAddress)
+	if s.BindAddress == "" {
+		s.BindAddress = DefaultLANBindAddress
+	}
+	s.TLSMode = strings.ToLower(strings.TrimSpace(s.TLSMode))
+	if s.TLSMode == "" {
+		s.TLSMode = LANTLSModeImplicit
+	}
+	if s.SMTPPort == 0 {
+		s.SMTPPort = DefaultLANSMTPPort
+	}
+	if s.IMAPPort == 0 {
+		s.IMAPPort = DefaultLANIMAPPort
+	}
+	s.AdvertiseHost = strings.TrimSpace(s.AdvertiseHost)
+
+	allowlist := make([]string, 0, len(s.Allowlist))
+	for _, entry := range s.Allowlist {
+		if entry = strings.TrimSpace(entry); entry != "" {
+			allowlist = append(allowlist, entry)
+		}
+	}
+	s.Allowlist = allowlist
+}
+
+// Validate checks the LAN access settings for errors
+func (s *LANAccessSettings) Validate() error {
+	if _, err := netip.ParseAddr(s.BindAddress); err != nil {
+		return fmt.Errorf("bind address must be an IP address: %s", s.BindAddress)
+	}
+	if s.TLSMode != LANTLSModeImplicit && s.TLSMode != LANTLSModeSTARTTLS {
+		return fmt.Errorf("invalid TLS mode: %s (expected %s or %s)", s.TLSMode, LANTLSModeImplicit, LANTLSModeSTARTTLS)
+	}
+	if s.SMTPPort < 1 || s.SMTPPort > 65535 {
+		return fmt.Errorf("invalid SMTP port: %d", s.SMTPPort)
+	}
+	if s.IMAPPort < 1 || s.IMAPPort > 65535 {
+		return fmt.Errorf("invalid IMAP port: %d", s.IMAPPort)
+	}
+	if s.SMTPPort == s.IMAPPort {
+		return fmt.Errorf("SMTP and IMAP ports must differ")
+	}
+	if _, err := parseAllowlist(s.Allowlist); err != nil {
+		return err
+	}
+	if strings.ContainsAny(s.AdvertiseHost, " /:@?#") && net.ParseIP(s.AdvertiseHost) == nil {
+		return fmt.Errorf("invalid advertised host: %s", s.AdvertiseHost)
+	}
+	return nil
+}
+
+// GetLANAccessSettings returns a copy of the LAN access settings
+// Thread-safe with read lock
+func (c *Config) GetLANAccessSettings() LANAccessSettings {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	settings := c.LANAccess
+	settings.Allowlist = append([]string(nil), c.LANAccess.Allowlist...)
+	return settings
+}
+
+// SetLANAccessSettings validates and replaces the LAN access settings
+// Thread-safe with write lock
+func (c *Config) SetLANAccessSettings(settings LANAccessSettings) error {
+	settings.Allowlist = append([]string(nil), settings.Allowlist...)
+	settings.applyDefaults()
+	if err := settings.Validate(); err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.LANAccess = settings
+	return nil
+}
+
+// ClientEndpoints describes how mail clients should connect to the local servers
+type ClientEndpoints struct {
+	IMAPHost string
+	IMAPPort string
+	SMTPHost string
+	SMTPPort string
+
+	// Security is ClientSecurityPlain, ClientSecuritySSL or ClientSecuritySTARTTLS
+	Security string
+
+	// Fingerprint is the SHA-256 fingerprint of the TLS certificate (empty for plain)
+	Fingerprint string
+
+	// StrictCertificates tells clients to verify the certificate chain
+	StrictCertificates bool
+}
+
+// AutoconfigSocketType returns the socket type for the autoconfig XML
+func (e ClientEndpoints) AutoconfigSocketType() string {
+	switch e.Security {
+	case ClientSecuritySSL:
+		return "SSL"
+	case ClientSecuritySTARTTLS:
+		return "STARTTLS"
+	default:
+		return "plain"
+	}
+}
+
+// loopbackEndpoints returns the plain endpoints of the yggmail servers
+func loopbackEndpoints(settings ServiceSettings) ClientEndpoints {
+	smtpHost, smtpPort, _ := net.SplitHostPort(settings.SMTPAddress)
+	imapHost, imapPort, _ := net.SplitHostPort(settings.IMAPAddress)
+	return ClientEndpoints{
+		IMAPHost: imapHost,
+		IMAPPort: imapPort,
+		SMTPHost: smtpHost,
+		SMTPPort: smtpPort,
+		Security: ClientSecurityPlain,
+	}
+}
+
+// lanEndpoints returns the endpoints of the TLS listeners
+func lanEndpoints(settings LANAccessSettings, fingerprint string) ClientEndpoints {
+	host := advertisedHost(settings)
+	security := ClientSecuritySSL
+	if settings.TLSMode == LANTLSModeSTARTTLS {
+		security = ClientSecuritySTARTTLS
+	}
+	return ClientEndpoints{
+		IMAPHost:           host,
+		IMAPPort:           strconv.Itoa(settings.IMAPPort),
+		SMTPHost:           host,
+		SMTPPort:           strconv.Itoa(settings.SMTPPort),
+		Security:           security,
+		Fingerprint:        fingerprint,
+		StrictCertificates: settings.StrictCertificateChecks,
+	}
+}
+
+// advertisedHost returns the host clients should connect to
+// Uses the configured host, then the bind address, then the first private LAN address
+func advertisedHost(settings LANAccessSettings) string {
+	if settings.AdvertiseHost != "" {
+		return settings.AdvertiseHost
+	}
+	if addr, err := netip.ParseAddr(settings.BindAddress); err == nil && !addr.IsUnspecified() {
+		return addr.String()
+	}
+	if addr, ok := detectLANAddress(); ok {
+		return addr.String()
+	}
+	return "127.0.0.1"
+}
+
+// detectLANAddress returns the first private IPv4 address of an active interface
+func detectLANAddress() (netip.Addr, bool) {
+	interfaces, err := net.Interfaces()
+	if err != nil {
+		return netip.Addr{}, false
+	}
+	for _, iface := range interfaces {
+		if iface.Flags&net.FlagUp == 0 || iface.Flags&net.FlagLoopback != 0 {
+			continue
+		}
+		addrs, err := iface.Addrs()
+		if err != nil {
+			continue
+		}
+		for _, a := range addrs {
+			ipNet, ok := a.(*net.IPNet)
+			if !ok {
+				continue
+			}
+			addr, ok := netip.AddrFromSlice(ipNet.IP)
+			if ok && addr.Unmap().Is4() && addr.IsPrivate() {
+				return addr.Unmap(), true
+			}
+		}
+	}
+	return netip.Addr{}, false
+}
+
+// parseAllowlist parses IP and CIDR allowlist entries into prefixes
+func parseAllowlist(entries []string) ([]netip.Prefix, error) {
+	prefixes := make([]netip.Prefix, 0, len(entries))
+	for _, entry := range entries {
+		if strings.Contains(entry, "/") {
+			prefix, err := netip.ParsePrefix(entry)
+			if err != nil {
+				return nil, fmt.Errorf("invalid allowlist range: %s", entry)
+			}
+			prefixes = append(prefixes, prefix.Masked())
+			continue
+		}
+		addr, err := netip.ParseAddr(entry)
+		if err != nil {
+			return nil, fmt.Errorf("invalid allowlist address: %s", entry)
+		}
+		addr = addr.Unmap()
+		prefixes = append(prefixes, netip.PrefixFrom(addr, addr.BitLen()))
+	}
+	return prefixes, nil
+}
+
+// lanAddressAllowed checks a remote address against the allowlist
+// An empty allowlist allows loopback and private network addresses
+func lanAddressAllowed(allowlist []netip.Prefix, addr netip.Addr) bool {
+	addr = addr.Unmap()
+	if len(allowlist) == 0 {
+		return addr.IsLoopback() || addr.IsPrivate() || addr.IsLinkLocalUnicast()
+	}
+	for _, prefix := range allowlist {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
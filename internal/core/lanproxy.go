@@ -0,0 +1,408 @@
+package core
+
+import (
+	"bufio"
+	"crypto/tls"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/netip"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// lanHandshakeTimeout limits the plain-text dialogue and TLS handshake
+	lanHandshakeTimeout = 30 * time.Second
+
+	// lanMaxPreTLSCommands limits commands accepted before STARTTLS
+	lanMaxPreTLSCommands = 10
+
+	// lanMaxLineLength limits command lines read before STARTTLS
+	lanMaxLineLength = 1024
+)
+
+// Mail protocols served by the LAN proxy
+const (
+	lanProtocolSMTP = "SMTP"
+	lanProtocolIMAP = "IMAP"
+)
+
+// LANProxy serves the local IMAP and SMTP servers to the LAN over TLS
+// Each connection is checked against the allowlist, TLS is terminated and the
+// plain stream is forwarded to the yggmail server on loopback
+type LANProxy struct {
+	settings  LANAccessSettings
+	allowlist []netip.Prefix
+	tlsConfig *tls.Config
+	endpoints ClientEndpoints
+
+	smtpBackend string
+	imapBackend string
+
+	mu        sync.Mutex
+	listeners []net.Listener
+	conns     map[net.Conn]struct{}
+	running   bool
+	wg        sync.WaitGroup
+}
+
+// NewLANProxy creates the LAN proxy and loads or issues its certificate
+// smtpBackend and imapBackend are the addresses of the yggmail servers
+func NewLANProxy(settings LANAccessSettings, smtpBackend, imapBackend string) (*LANProxy, error) {
+	settings.applyDefaults()
+	if err := settings.Validate(); err != nil {
+		return nil, err
+	}
+	allowlist, err := parseAllowlist(settings.Allowlist)
+	if err != nil {
+		return nil, err
+	}
+
+	host := advertisedHost(settings)
+	hosts := []string{"localhost", "127.0.0.1", "::1", host}
+	if addr, err := netip.ParseAddr(settings.BindAddress); err == nil && !addr.IsUnspecified() && addr.String() != host {
+		hosts = append(hosts, addr.String())
+	}
+	cert, err := LoadLocalCertificate(hosts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load TLS certificate: %w", err)
+	}
+
+	return &LANProxy{
+		settings:  settings,
+		allowlist: allowlist,
+		tlsConfig: &tls.Config{
+			Certificates: []tls.Certificate{cert},
+			MinVersion:   tls.VersionTLS12,
+		},
+		endpoints:   lanEndpoints(settings, CertificateFingerprint(cert.Leaf)),
+		smtpBackend: smtpBackend,
+		imapBackend: imapBackend,
+		conns:       make(map[net.Conn]struct{}),
+	}, nil
+}
+
+// Start opens the SMTP and IMAP listeners
+func (p *LANProxy) Start() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.running {
+		return fmt.Errorf("LAN proxy already running")
+	}
+
+	services := []struct {
+		protocol string
+		port     int
+		backend  string
+	}{
+		{lanProtocolSMTP, p.settings.SMTPPort, p.smtpBackend},
+		{lanProtocolIMAP, p.settings.IMAPPort, p.imapBackend},
+	}
+
+	listeners := make([]net.Listener, 0, len(services))
+	for _, service := range services {
+		addr := net.JoinHostPort(p.settings.BindAddress, strconv.Itoa(service.port))
+		listener, err := net.Listen("tcp", addr)
+		if err != nil {
+			for _, l := range listeners {
+				l.Close()
+			}
+			return fmt.Errorf("failed to listen for %s on %s: %w", service.protocol, addr, err)
+		}
+		listeners = append(listeners, listener)
+
+		p.wg.Add(1)
+		go p.serve(listener, service.protocol, service.backend)
+		log.Printf("[LANAccess] Listening for %s (%s) on %s", service.protocol, p.settings.TLSMode, addr)
+	}
+
+	p.listeners = listeners
+	p.running = true
+	return nil
+}
+
+// Stop closes the listeners and all open connections
+func (p *LANProxy) Stop() error {
+	p.mu.Lock()
+	if !p.running {
+		p.mu.Unlock()
+		return nil
+	}
+	p.running = false
+	for _, listener := range p.listeners {
+		listener.Close()
+	}
+	p.listeners = nil
+	for conn := range p.conns {
+		conn.Close()
+	}
+	p.mu.Unlock()
+
+	p.wg.Wait()
+	log.Println("[LANAccess] Stopped")
+	return nil
+}
+
+// IsRunning returns true while the listeners are open
+func (p *LANProxy) IsRunning() bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.running
+}
+
+// Endpoints returns the addresses, security and fingerprint for clients
+func (p *LANProxy) Endpoints() ClientEndpoints {
+	return p.endpoints
+}
+
+// serve accepts connections until the listener is closed
+func (p *LANProxy) serve(listener net.Listener, protocol, backend string) {
+	defer p.wg.Done()
+
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			if !p.IsRunning() {
+				return
+			}
+			log.Printf("[LANAccess] %s accept error: %v", protocol, err)
+			time.Sleep(100 * time.Millisecond)
+			continue
+		}
+
+		if !p.allowed(conn.RemoteAddr()) {
+			log.Printf("[LANAccess] Rejected %s connection from %s (not in allowlist)", protocol, conn.RemoteAddr())
+			conn.Close()
+			continue
+		}
+		if !p.track(conn) {
+			conn.Close()
+			return
+		}
+
+		p.wg.Add(1)
+		go func() {
+			defer p.wg.Done()
+			defer p.untrack(conn)
+			if err := p.handle(conn, protocol, backend); err != nil {
+				log.Printf("[LANAccess] %s connection from %s: %v", protocol, conn.RemoteAddr(), err)
+			}
+		}()
+	}
+}
+
+// handle secures a client connection and forwards it to the backend
+func (p *LANProxy) handle(conn net.Conn, protocol, backend string) error {
+	conn.SetDeadline(time.Now().Add(lanHandshakeTimeout))
+
+	if p.settings.TLSMode == LANTLSModeSTARTTLS {
+		upgrade, err := negotiateSTARTTLS(conn, protocol)
+		if err != nil || !upgrade {
+			return err
+		}
+	}
+
+	tlsConn := tls.Server(conn, p.tlsConfig)
+	if err := tlsConn.Handshake(); err != nil {
+		return fmt.Errorf("TLS handshake failed: %w", err)
+	}
+	conn.SetDeadline(time.Time{})
+
+	upstream, err := net.DialTimeout("tcp", backend, 10*time.Second)
+	if err != nil {
+		return fmt.Errorf("failed to connect to local %s server: %w", protocol, err)
+	}
+	defer upstream.Close()
+
+	// After STARTTLS the client expects no new greeting, so the server's is dropped
+	var upstreamReader io.Reader = upstream
+	if p.settings.TLSMode == LANTLSModeSTARTTLS {
+		reader := bufio.NewReader(upstream)
+		upstream.SetReadDeadline(time.Now().Add(lanHandshakeTimeout))
+		if err := skipGreeting(reader, protocol); err != nil {
+			return fmt.Errorf("failed to read local %s server greeting: %w", protocol, err)
+		}
+		upstream.SetReadDeadline(time.Time{})
+		upstreamReader = reader
+	}
+
+	done := make(chan struct{}, 2)
+	go func() {
+		io.Copy(upstream, tlsConn)
+		upstream.Close()
+		done <- struct{}{}
+	}()
+	go func() {
+		io.Copy(tlsConn, upstreamReader)
+		tlsConn.Close()
+		done <- struct{}{}
+	}()
+	<-done
+	<-done
+	return nil
+}
+
+// allowed checks the remote address against the allowlist
+func (p *LANProxy) allowed(remote net.Addr) bool {
+	tcpAddr, ok := remote.(*net.TCPAddr)
+	if !ok {
+		return false
+	}
+	addr, ok := netip.AddrFromSlice(tcpAddr.IP)
+	return ok && lanAddressAllowed(p.allowlist, addr)
+}
+
+// track registers an open connection so Stop can close it
+func (p *LANProxy) track(conn net.Conn) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if !p.running {
+		return false
+	}
+	p.conns[conn] = struct{}{}
+	return true
+}
+
+// untrack closes and forgets a connection
+func (p *LANProxy) untrack(conn net.Conn) {
+	conn.Close()
+	p.mu.Lock()
+	delete(p.conns, conn)
+	p.mu.Unlock()
+}
+
+// negotiateSTARTTLS speaks the plain-text part of the protocol until the client
+// issues STARTTLS. Returns false if the client quit first
+func negotiateSTARTTLS(conn net.Conn, protocol string) (bool, error) {
+	reader := bufio.NewReaderSize(conn, lanMaxLineLength)
+	if protocol == lanProtocolSMTP {
+		return negotiateSMTPSTARTTLS(conn, reader)
+	}
+	return negotiateIMAPSTARTTLS(conn, reader)
+}
+
+// negotiateSMTPSTARTTLS handles EHLO, HELO, NOOP, RSET, QUIT and STARTTLS (RFC 3207)
+func negotiateSMTPSTARTTLS(conn net.Conn, reader *bufio.Reader) (bool, error) {
+	if _, err := io.WriteString(conn, "220 tyr ESMTP ready\r\n"); err != nil {
+		return false, err
+	}
+
+	for i := 0; i < lanMaxPreTLSCommands; i++ {
+		line, err := readCommandLine(reader)
+		if err != nil {
+			return false, err
+		}
+		verb, _, _ := strings.Cut(line, " ")
+
+		var reply string
+		switch strings.ToUpper(verb) {
+		case "EHLO":
+			reply = "250-tyr\r\n250-8BITMIME\r\n250 STARTTLS\r\n"
+		case "HELO":
+			reply = "250 tyr\r\n"
+		case "NOOP", "RSET":
+			reply = "250 OK\r\n"
+		case "QUIT":
+			io.WriteString(conn, "221 Bye\r\n")
+			return false, nil
+		case "STARTTLS":
+			if reader.Buffered() > 0 {
+				// Commands pipelined before the handshake could be injected in plain text
+				return false, fmt.Errorf("data sent before TLS handshake")
+			}
+			_, err := io.WriteString(conn, "220 Ready to start TLS\r\n")
+			return err == nil, err
+		default:
+			reply = "530 5.7.0 Must issue a STARTTLS command first\r\n"
+		}
+		if _, err := io.WriteString(conn, reply); err != nil {
+			return false, err
+		}
+	}
+	io.WriteString(conn, "421 Too many commands without STARTTLS\r\n")
+	return false, fmt.Errorf("too many commands without STARTTLS")
+}
+
+// negotiateIMAPSTARTTLS handles CAPABILITY, NOOP, LOGOUT and STARTTLS (RFC 3501)
+func negotiateIMAPSTARTTLS(conn net.Conn, reader *bufio.Reader) (bool, error) {
+	const capability = "IMAP4rev1 STARTTLS LOGINDISABLED"
+	if _, err := io.WriteString(conn, "* OK [CAPABILITY "+capability+"] tyr ready\r\n"); err != nil {
+		return false, err
+	}
+
+	for i := 0; i < lanMaxPreTLSCommands; i++ {
+		line, err := readCommandLine(reader)
+		if err != nil {
+			return false, err
+		}
+		tag, rest, _ := strings.Cut(line, " ")
+		command, _, _ := strings.Cut(rest, " ")
+
+		var reply string
+		switch strings.ToUpper(command) {
+		case "CAPABILITY":
+			reply = "* CAPABILITY " + capability + "\r\n" + tag + " OK CAPABILITY completed\r\n"
+		case "NOOP":
+			reply = tag + " OK NOOP completed\r\n"
+		case "LOGOUT":
+			io.WriteString(conn, "* BYE Logging out\r\n"+tag+" OK LOGOUT completed\r\n")
+			return false, nil
+		case "STARTTLS":
+			if reader.Buffered() > 0 {
+				// Commands pipelined before the handshake could be injected in plain text
+				return false, fmt.Errorf("data sent before TLS handshake")
+			}
+			_, err := io.WriteString(conn, tag+" OK Begin TLS negotiation now\r\n")
+			return err == nil, err
+		case "LOGIN", "AUTHENTICATE":
+			reply = tag + " NO [PRIVACYREQUIRED] STARTTLS required\r\n"
+		default:
+			reply = tag + " BAD STARTTLS required\r\n"
+		}
+		if _, err := io.WriteString(conn, reply); err != nil {
+			return false, err
+		}
+	}
+	io.WriteString(conn, "* BYE Too many commands without STARTTLS\r\n")
+	return false, fmt.Errorf("too many commands without STARTTLS")
+}
+
+// readCommandLine reads one CRLF-terminated command line of limited length
+func readCommandLine(reader *bufio.Reader) (string, error) {
+	line, isPrefix, err := reader.ReadLine()
+	if err != nil {
+		return "", err
+	}
+	if isPrefix {
+		return "", fmt.Errorf("command line too long")
+	}
+	return strings.TrimSpace(string(line)), nil
+}
+
+// skipGreeting reads the greeting of the local server
+// SMTP greetings may span several "220-" lines; IMAP greetings are one line
+func skipGreeting(reader *bufio.Reader, protocol string) error {
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return err
+		}
+		if protocol == lanProtocolIMAP {
+			if !strings.HasPrefix(line, "* OK") {
+				return fmt.Errorf("unexpected greeting: %s", strings.TrimSpace(line))
+			}
+			return nil
+		}
+		if !strings.HasPrefix(line, "220") {
+			return fmt.Errorf("unexpected greeting: %s", strings.TrimSpace(line))
+		}
+		if len(line) < 4 || line[3] != '-' {
+			return nil
+		}
+	}
+}
@@ -0,0 +1,340 @@
+package core
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/hex"
+	"encoding/pem"
+	"fmt"
+	"log"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/JB-SelfCompany/Tyr-Desktop/internal/platform"
+)
+
+const (
+	// localCAValidity is how long the generated local CA is valid
+	localCAValidity = 10 * 365 * 24 * time.Hour
+
+	// localCertValidity is how long a generated listener certificate is valid
+	localCertValidity = 365 * 24 * time.Hour
+
+	// localCertRenewBefore renews the listener certificate this long before it expires
+	localCertRenewBefore = 30 * 24 * time.Hour
+)
+
+// localCAPrivateRanges are always permitted by the local CA name constraints, so
+// a changing LAN address doesn't need a new CA: loopback, RFC 1918, carrier-grade
+// NAT, link-local, unique local and Yggdrasil (0200::/7) addresses
+var localCAPrivateRanges = []string{
+	"127.0.0.0/8",
+	"10.0.0.0/8",
+	"172.16.0.0/12",
+	"192.168.0.0/16",
+	"100.64.0.0/10",
+	"169.254.0.0/16",
+	"::1/128",
+	"fc00::/7",
+	"fe80::/10",
+	"200::/7",
+}
+
+// Files in the TLS directory
+const (
+	localCACertFile     = "ca.pem"
+	localCAKeyFile      = "ca-key.pem"
+	localServerCertFile = "server.pem"
+	localServerKeyFile  = "server-key.pem"
+)
+
+// LocalCACertificatePath returns the path of the local CA certificate
+// Install it on client devices to enable strict certificate checks
+func LocalCACertificatePath() string {
+	return filepath.Join(platform.GetTLSDir(), localCACertFile)
+}
+
+// LoadLocalCertificate returns the listener certificate for the given hosts
+// The local CA and certificate are created on first use. The certificate is
+// reissued when it is about to expire or doesn't cover all hosts
+func LoadLocalCertificate(hosts []string) (tls.Certificate, error) {
+	dir := platform.GetTLSDir()
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return tls.Certificate{}, fmt.Errorf("failed to create TLS directory: %w", err)
+	}
+
+	caCert, caKey, err := loadOrCreateLocalCA(dir, hosts)
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+
+	certPath := filepath.Join(dir, localServerCertFile)
+	keyPath := filepath.Join(dir, localServerKeyFile)
+	if cert, err := tls.LoadX509KeyPair(certPath, keyPath); err == nil {
+		if leaf, err := x509.ParseCertificate(cert.Certificate[0]); err == nil && certificateUsable(leaf, caCert, hosts) {
+			cert.Leaf = leaf
+			return cert, nil
+		}
+	}
+
+	return issueLocalCertificate(caCert, caKey, hosts, certPath, keyPath)
+}
+
+// CertificateFingerprint returns the SHA-256 fingerprint of a certificate
+// as colon-separated upper-case hex
+func CertificateFingerprint(cert *x509.Certificate) string {
+	if cert == nil {
+		return ""
+	}
+	sum := sha256.Sum256(cert.Raw)
+	encoded := strings.ToUpper(hex.EncodeToString(sum[:]))
+
+	pairs := make([]string, 0, len(sum))
+	for i := 0; i < len(encoded); i += 2 {
+		pairs = append(pairs, encoded[i:i+2])
+	}
+	return strings.Join(pairs, ":")
+}
+
+// loadOrCreateLocalCA loads the local CA, generating it if it doesn't exist
+// The CA is name constrained to the hosts and private address ranges, so an installed
+// CA can't be used to impersonate other servers. It is replaced if it doesn't permit
+// all hosts (or predates the constraints), and must then be installed again
+func loadOrCreateLocalCA(dir string, hosts []string) (*x509.Certificate, *ecdsa.PrivateKey, error) {
+	certPath := filepath.Join(dir, localCACertFile)
+	keyPath := filepath.Join(dir, localCAKeyFile)
+
+	if pair, err := tls.LoadX509KeyPair(certPath, keyPath); err == nil {
+		cert, err := x509.ParseCertificate(pair.Certificate[0])
+		key, ok := pair.PrivateKey.(*ecdsa.PrivateKey)
+		if err == nil && ok && time.Now().Before(cert.NotAfter) {
+			if localCAPermits(cert, hosts) {
+				return cert, key, nil
+			}
+			log.Printf("[LANAccess] Local CA doesn't cover %v, issuing a new one; install it on client devices again", hosts)
+		}
+	} else if fileExists(certPath) {
+		// Keep a broken CA for the user to inspect instead of replacing an installed one
+		return nil, nil, fmt.Errorf("failed to load local CA: %w", err)
+	}
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to generate CA key: %w", err)
+	}
+	serial, err := randomSerial()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	domains, ranges := localCAConstraints(hosts)
+	now := time.Now()
+	template := &x509.Certificate{
+		SerialNumber:                serial,
+		Subject:                     pkix.Name{CommonName: "Tyr Local CA", Organization: []string{"Tyr"}},
+		NotBefore:                   now.Add(-time.Hour),
+		NotAfter:                    now.Add(localCAValidity),
+		KeyUsage:                    x509.KeyUsageCertSign | x509.KeyUsageCRLSign,
+		BasicConstraintsValid:       true,
+		IsCA:                        true,
+		MaxPathLenZero:              true,
+		PermittedDNSDomainsCritical: true,
+		PermittedDNSDomains:         domains,
+		PermittedIPRanges:           ranges,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create CA certificate: %w", err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to parse CA certificate: %w", err)
+	}
+
+	if err := writeKeyPair(certPath, keyPath, der, key); err != nil {
+		return nil, nil, err
+	}
+	return cert, key, nil
+}
+
+// localCAConstraints returns the DNS names and IP ranges the local CA may sign for:
+// the DNS hosts, the private ranges, and any host IP outside them
+func localCAConstraints(hosts []string) ([]string, []*net.IPNet) {
+	var domains []string
+	var ranges []*net.IPNet
+	for _, cidr := range localCAPrivateRanges {
+		_, ipNet, _ := net.ParseCIDR(cidr)
+		ranges = append(ranges, ipNet)
+	}
+
+	for _, host := range hosts {
+		if host == "" {
+			continue
+		}
+		if ip := net.ParseIP(host); ip != nil {
+			if !ipInRanges(ip, ranges) {
+				bits := 128
+				if ip.To4() != nil {
+					ip, bits = ip.To4(), 32
+				}
+				ranges = append(ranges, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			}
+			continue
+		}
+		name := strings.ToLower(strings.TrimSuffix(host, "."))
+		if !slices.Contains(domains, name) {
+			domains = append(domains, name)
+		}
+	}
+	return domains, ranges
+}
+
+// localCAPermits reports whether a CA is name constrained and permits all hosts
+func localCAPermits(caCert *x509.Certificate, hosts []string) bool {
+	if !caCert.PermittedDNSDomainsCritical || len(caCert.PermittedIPRanges) == 0 {
+		return false
+	}
+	for _, host := range hosts {
+		if host == "" {
+			continue
+		}
+		if ip := net.ParseIP(host); ip != nil {
+			if !ipInRanges(ip, caCert.PermittedIPRanges) {
+				return false
+			}
+			continue
+		}
+		name := strings.ToLower(strings.TrimSuffix(host, "."))
+		permitted := false
+		for _, domain := range caCert.PermittedDNSDomains {
+			if name == domain || strings.HasSuffix(name, "."+domain) {
+				permitted = true
+				break
+			}
+		}
+		if !permitted {
+			return false
+		}
+	}
+	return true
+}
+
+// ipInRanges reports whether ip is in any of the ranges
+func ipInRanges(ip net.IP, ranges []*net.IPNet) bool {
+	for _, ipNet := range ranges {
+		if ipNet.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// issueLocalCertificate signs a new listener certificate for the hosts with the local CA
+func issueLocalCertificate(caCert *x509.Certificate, caKey *ecdsa.PrivateKey, hosts []string, certPath, keyPath string) (tls.Certificate, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return tls.Certificate{}, fmt.Errorf("failed to generate certificate key: %w", err)
+	}
+	serial, err := randomSerial()
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+
+	now := time.Now()
+	template := &x509.Certificate{
+		SerialNumber: serial,
+		Subject:      pkix.Name{CommonName: "Tyr mail server", Organization: []string{"Tyr"}},
+		NotBefore:    now.Add(-time.Hour),
+		NotAfter:     now.Add(localCertValidity),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	if template.NotAfter.After(caCert.NotAfter) {
+		template.NotAfter = caCert.NotAfter
+	}
+	for _, host := range hosts {
+		if ip := net.ParseIP(host); ip != nil {
+			template.IPAddresses = append(template.IPAddresses, ip)
+		} else if host != "" {
+			template.DNSNames = append(template.DNSNames, host)
+		}
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, caCert, &key.PublicKey, caKey)
+	if err != nil {
+		return tls.Certificate{}, fmt.Errorf("failed to create certificate: %w", err)
+	}
+	if err := writeKeyPair(certPath, keyPath, der, key); err != nil {
+		return tls.Certificate{}, err
+	}
+
+	leaf, err := x509.ParseCertificate(der)
+	if err != nil {
+		return tls.Certificate{}, fmt.Errorf("failed to parse certificate: %w", err)
+	}
+	return tls.Certificate{
+		Certificate: [][]byte{der, caCert.Raw},
+		PrivateKey:  key,
+		Leaf:        leaf,
+	}, nil
+}
+
+// certificateUsable checks that a certificate is signed by the CA, not about to
+// expire and valid for all hosts
+func certificateUsable(cert, caCert *x509.Certificate, hosts []string) bool {
+	if cert.CheckSignatureFrom(caCert) != nil {
+		return false
+	}
+	if time.Until(cert.NotAfter) < localCertRenewBefore {
+		return false
+	}
+	for _, host := range hosts {
+		if host != "" && cert.VerifyHostname(host) != nil {
+			return false
+		}
+	}
+	return true
+}
+
+// writeKeyPair writes a certificate (with the CA appended for servers) and its key as PEM
+func writeKeyPair(certPath, keyPath string, der []byte, key *ecdsa.PrivateKey) error {
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		return fmt.Errorf("failed to encode key: %w", err)
+	}
+
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	if filepath.Base(certPath) == localServerCertFile {
+		// Serve the chain so clients that trust the CA can verify it
+		if caPEM, err := os.ReadFile(filepath.Join(filepath.Dir(certPath), localCACertFile)); err == nil {
+			certPEM = append(certPEM, caPEM...)
+		}
+	}
+
+	if err := os.WriteFile(keyPath, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0600); err != nil {
+		return fmt.Errorf("failed to write key: %w", err)
+	}
+	if err := os.WriteFile(certPath, certPEM, 0644); err != nil {
+		return fmt.Errorf("failed to write certificate: %w", err)
+	}
+	return nil
+}
+
+// randomSerial returns a random 128-bit certificate serial number
+func randomSerial() (*big.Int, error) {
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate serial number: %w", err)
+	}
+	return serial, nil
+}
@@ -0,0 +1,91 @@
+package core
+
+import (
+	"fmt"
+
+	"github.com/JB-SelfCompany/Tyr-Desktop/internal/mailclient"
+)
+
+// OpenIMAPSession logs into the local IMAP listener with the stored credentials
+// The caller must Close the session when done
+// Thread-safe
+func (sm *ServiceManager) OpenIMAPSession() (*mailclient.IMAPSession, error) {
+	if !sm.IsRunning() {
+		return nil, fmt.Errorf("service must be running to access mail")
+	}
+
+	mailAddress := sm.GetMailAddress()
+	if mailAddress == "" {
+		return nil, fmt.Errorf("mail address not available")
+	}
+
+	password, err := sm.config.GetPassword()
+	if err != nil || password == "" {
+		return nil, fmt.Errorf("failed to retrieve password: %v", err)
+	}
+
+	return mailclient.DialIMAP(mailclient.IMAPCredentials{
+		Address:  sm.config.ServiceSettings.IMAPAddress,
+		Username: mailAddress,
+		Password: password,
+	})
+}
+
+// withIMAPSession opens a session, runs fn and closes the session
+func (sm *ServiceManager) withIMAPSession(fn func(s *mailclient.IMAPSession) error) error {
+	session, err := sm.OpenIMAPSession()
+	if err != nil {
+		return err
+	}
+	defer session.Close()
+
+	return fn(session)
+}
+
+// ListMailboxes returns all mailboxes with message and unseen counts
+// Thread-safe
+func (sm *ServiceManager) ListMailboxes() ([]mailclient.MailboxInfo, error) {
+	var mailboxes []mailclient.MailboxInfo
+	err := sm.withIMAPSession(func(s *mailclient.IMAPSession) error {
+		var err error
+		mailboxes, err = s.ListMailboxes()
+		return err
+	})
+	return mailboxes, err
+}
+
+// ListMessages returns one page of message headers from a mailbox, newest first
+// Thread-safe
+func (sm *ServiceManager) ListMessages(mailbox string, page, pageSize int) (*mailclient.MessagePage, error) {
+	var result *mailclient.MessagePage
+	err := sm.withIMAPSession(func(s *mailclient.IMAPSession) error {
+		var err error
+		result, err = s.ListMessages(mailbox, page, pageSize)
+		return err
+	})
+	return result, err
+}
+
+// GetMessage returns a message with its bodies and MIME tree without marking it as seen
+// Thread-safe
+func (sm *ServiceManager) GetMessage(mailbox string, uid uint32) (*mailclient.Message, error) {
+	var result *mailclient.Message
+	err := sm.withIMAPSession(func(s *mailclient.IMAPSession) error {
+		var err error
+		result, err = s.FetchMessage(mailbox, uid)
+		return err
+	})
+	return result, err
+}
+
+// GetMessagePart returns the decoded content of a single message part (e.g., an attachment)
+// Thread-safe
+func (sm *ServiceManager) GetMessagePart(mailbox string, uid uint32, partID string) (*mailclient.Attachment, error) {
+	var result *mailclient.Attachment
+	err := sm.withIMAPSession(func(s *mailclient.IMAPSession) error {
+		var err error
+		result, err = s.FetchAttachment(mailbox, uid, partID)
+		return err
+	})
+	return result, err
+}
@@ -0,0 +1,119 @@
+package core
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/JB-SelfCompany/Tyr-Desktop/internal/mailclient"
+)
+
+// MailSendProgress represents progress information while sending a message
+type MailSendProgress struct {
+	// Progress is the completion percentage (0-100)
+	Progress int `json:"progress"`
+
+	// Message describes the current step
+	Message string `json:"message"`
+}
+
+// MailSendResult contains the outcome of submitting a message
+type MailSendResult struct {
+	// MessageID is the Message-ID header of the submitted message
+	MessageID string
+
+	// SizeBytes is the size of the rendered message in bytes
+	SizeBytes int64
+
+	// Recipients is the list of envelope recipients
+	Recipients []string
+}
+
+// SendMail composes and submits a message through the local SMTP listener
+// Authenticates with the stored password and checks each recipient's message
+// size limit before submitting. Returns once the message is queued; the delivery
+// outcome arrives later as a "sent" or "error" MailEvent (OnMailSent/OnMailError)
+// If outbox is not nil, the message is tracked there from submission onwards
+// Thread-safe
+func (sm *ServiceManager) SendMail(msg *mailclient.OutgoingMessage, outbox *OutboxStore, progress func(MailSendProgress)) (*MailSendResult, error) {
+	report := func(percent int, message string) {
+		if progress != nil {
+			progress(MailSendProgress{Progress: percent, Message: message})
+		}
+	}
+
+	if msg == nil {
+		return nil, fmt.Errorf("message cannot be nil")
+	}
+
+	if !sm.IsRunning() {
+		return nil, fmt.Errorf("service must be running to send mail")
+	}
+
+	mailAddress := sm.GetMailAddress()
+	if mailAddress == "" {
+		return nil, fmt.Errorf("mail address not available")
+	}
+	msg.From = mailAddress
+
+	password, err := sm.config.GetPassword()
+	if err != nil || password == "" {
+		return nil, fmt.Errorf("failed to retrieve password: %v", err)
+	}
+
+	report(10, "Composing message...")
+	data, messageID, err := mailclient.BuildMessage(msg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compose message: %w", err)
+	}
+
+	recipients := msg.Recipients()
+	size := int64(len(data))
+
+	report(30, "Checking recipient size limits...")
+	for _, rcpt := range recipients {
+		result, err := sm.CheckRecipientMessageSizeLimit(rcpt, size)
+		if err != nil {
+			// Recipient may be offline - the queue will retry, so don't block on it
+			log.Printf("[SendMail] Warning: size limit check failed for %s: %v", rcpt, err)
+			continue
+		}
+		if !result.CanSend {
+			return nil, fmt.Errorf("recipient %s cannot accept a %.2f MB message: %s", rcpt, result.MessageSizeMB, result.ErrorMessage)
+		}
+	}
+
+	// Tracking is best-effort and must never block sending
+	track := func(state, reason string) {}
+	if outbox != nil {
+		key, err := outbox.Track(messageID, recipients, msg.Subject, size, DeliveryStateSending)
+		if err != nil {
+			log.Printf("[SendMail] Warning: failed to track message %s: %v", messageID, err)
+		}
+		track = func(state, reason string) {
+			if err := outbox.Transition(key, state, reason); err != nil {
+				log.Printf("[SendMail] Warning: failed to update tracking for %s: %v", messageID, err)
+			}
+		}
+	}
+
+	report(60, "Submitting to local SMTP server...")
+	creds := mailclient.SMTPCredentials{
+		Address:  sm.config.ServiceSettings.SMTPAddress,
+		Username: mailAddress,
+		Password: password,
+	}
+	if err := mailclient.Submit(creds, mailAddress, recipients, data); err != nil {
+		track(DeliveryStateFailed, err.Error())
+		return nil, fmt.Errorf("failed to submit message: %w", err)
+	}
+	track(DeliveryStateQueued, "")
+
+	report(100, "Message queued for delivery")
+	log.Printf("[SendMail] Queued message %s (%d bytes) to %d recipient(s)", messageID, size, len(recipients))
+
+	return &MailSendResult{
+		MessageID:  messageID,
+		SizeBytes:  size,
+		Recipients: recipients,
+	}, nil
+}
@@ -0,0 +1,342 @@
+package core
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// Notification actions applied to new mail
+const (
+	// NotificationActionNotify shows a desktop notification with sound
+	NotificationActionNotify = "notify"
+
+	// NotificationActionSilent shows a desktop notification without sound
+	NotificationActionSilent = "silent"
+
+	// NotificationActionSuppress shows no notification (mail still appears in the dashboard)
+	NotificationActionSuppress = "suppress"
+)
+
+// Contact conditions for notification rules
+const (
+	// ContactMatchAny matches every sender
+	ContactMatchAny = ""
+
+	// ContactMatchKnown matches senders in the address book
+	ContactMatchKnown = "known"
+
+	// ContactMatchUnknown matches senders not in the address book
+	ContactMatchUnknown = "unknown"
+
+	// ContactMatchVerified matches verified contacts only
+	ContactMatchVerified = "verified"
+)
+
+const (
+	// DefaultQuietHoursStart is the default start of quiet hours (local time)
+	DefaultQuietHoursStart = "22:00"
+
+	// DefaultQuietHoursEnd is the default end of quiet hours (local time)
+	DefaultQuietHoursEnd = "07:00"
+)
+
+// quietHoursTimeRegex validates HH:MM times
+var quietHoursTimeRegex = regexp.MustCompile(`^([01][0-9]|2[0-3]):[0-5][0-9]$`)
+
+// NotificationSettings contains new-mail notification preferences
+type NotificationSettings struct {
+	// DoNotDisturb suppresses all notifications except rules marked as Override
+	DoNotDisturb bool `toml:"do_not_disturb"`
+
+	// QuietHours turns notifications silent during a daily time window
+	QuietHours QuietHours `toml:"quiet_hours"`
+
+	// DefaultAction applies when no rule matches (default: notify)
+	DefaultAction string `toml:"default_action"`
+
+	// Rules are evaluated in order; the first enabled matching rule wins
+	Rules []NotificationRule `toml:"rules,omitempty"`
+}
+
+// QuietHours defines a daily window in local time during which notifications are silent
+// The window may cross midnight (e.g., 22:00-07:00)
+type QuietHours struct {
+	// Enabled indicates if quiet hours are active
+	Enabled bool `toml:"enabled"`
+
+	// Start is the start time in HH:MM format
+	Start string `toml:"start"`
+
+	// End is the end time in HH:MM format
+	End string `toml:"end"`
+}
+
+// NotificationRule matches new mail and decides how it is notified
+// Empty match fields match everything
+type NotificationRule struct {
+	// Name is a user-visible label for the rule
+	Name string `toml:"name"`
+
+	// Enabled indicates if the rule is evaluated
+	Enabled bool `toml:"enabled"`
+
+	// Sender is a case-insensitive address or glob pattern (e.g., "1a2b*@yggmail")
+	Sender string `toml:"sender,omitempty"`
+
+	// Contact restricts the rule by address book membership ("", known, unknown, verified)
+	Contact string `toml:"contact,omitempty"`
+
+	// Mailbox is the mailbox name to match (e.g., "INBOX"), case-insensitive
+	Mailbox string `toml:"mailbox,omitempty"`
+
+	// SubjectPattern is a case-insensitive regular expression matched against the subject
+	SubjectPattern string `toml:"subject_pattern,omitempty"`
+
+	// Action is the notification action (notify, silent, suppress)
+	Action string `toml:"action"`
+
+	// Override applies the action even during quiet hours and do not disturb
+	Override bool `toml:"override,omitempty"`
+}
+
+// NotificationInput describes a new mail event for rule evaluation
+type NotificationInput struct {
+	// From is the bare sender address
+	From string
+
+	// Mailbox is the mailbox the message was delivered to
+	Mailbox string
+
+	// Subject is the message subject
+	Subject string
+
+	// IsContact indicates the sender is in the address book
+	IsContact bool
+
+	// IsVerified indicates the sender is a verified contact
+	IsVerified bool
+}
+
+// NotificationDecision is the outcome of rule evaluation
+type NotificationDecision struct {
+	// Action is the final notification action
+	Action string
+
+	// Rule is the name of the matching rule, empty if the default action applied
+	Rule string
+
+	// Reason explains the decision (e.g., "quiet hours")
+	Reason string
+}
+
+// applyDefaults fills in missing notification settings
+func (n *NotificationSettings) applyDefaults() {
+	if !isValidNotificationAction(n.DefaultAction) {
+		n.DefaultAction = NotificationActionNotify
+	}
+	if n.QuietHours.Start == "" {
+		n.QuietHours.Start = DefaultQuietHoursStart
+	}
+	if n.QuietHours.End == "" {
+		n.QuietHours.End = DefaultQuietHoursEnd
+	}
+}
+
+// Validate checks that all times, actions and patterns are well-formed
+func (n *NotificationSettings) Validate() error {
+	if !isValidNotificationAction(n.DefaultAction) {
+		return fmt.Errorf("invalid default action: %s", n.DefaultAction)
+	}
+	if !quietHoursTimeRegex.MatchString(n.QuietHours.Start) || !quietHoursTimeRegex.MatchString(n.QuietHours.End) {
+		return fmt.Errorf("quiet hours must use HH:MM format")
+	}
+	for i, rule := range n.Rules {
+		if !isValidNotificationAction(rule.Action) {
+			return fmt.Errorf("rule %d: invalid action: %s", i+1, rule.Action)
+		}
+		switch rule.Contact {
+		case ContactMatchAny, ContactMatchKnown, ContactMatchUnknown, ContactMatchVerified:
+		default:
+			return fmt.Errorf("rule %d: invalid contact condition: %s", i+1, rule.Contact)
+		}
+		if rule.Sender != "" {
+			if _, err := path.Match(strings.ToLower(rule.Sender), ""); err != nil {
+				return fmt.Errorf("rule %d: invalid sender pattern: %w", i+1, err)
+			}
+		}
+		if rule.SubjectPattern != "" {
+			if _, err := regexp.Compile("(?i)" + rule.SubjectPattern); err != nil {
+				return fmt.Errorf("rule %d: invalid subject pattern: %w", i+1, err)
+			}
+		}
+	}
+	return nil
+}
+
+// Evaluate applies rules, quiet hours and do not disturb to a new mail event
+// The first enabled matching rule decides the action; quiet hours downgrade
+// notify to silent and do not disturb suppresses, unless the rule has Override
+func (n *NotificationSettings) Evaluate(input NotificationInput, now time.Time) NotificationDecision {
+	decision := NotificationDecision{Action: n.DefaultAction, Reason: "default"}
+	if !isValidNotificationAction(decision.Action) {
+		decision.Action = NotificationActionNotify
+	}
+
+	override := false
+	for _, rule := range n.Rules {
+		if rule.Enabled && rule.matches(input) {
+			decision = NotificationDecision{Action: rule.Action, Rule: rule.Name, Reason: "rule"}
+			override = rule.Override
+			break
+		}
+	}
+
+	if override || decision.Action == NotificationActionSuppress {
+		return decision
+	}
+
+	if n.DoNotDisturb {
+		decision.Action = NotificationActionSuppress
+		decision.Reason = "do not disturb"
+		return decision
+	}
+
+	if decision.Action == NotificationActionNotify && n.QuietHours.isActive(now) {
+		decision.Action = NotificationActionSilent
+		decision.Reason = "quiet hours"
+	}
+
+	return decision
+}
+
+// matches returns true if all non-empty conditions of the rule match
+func (r *NotificationRule) matches(input NotificationInput) bool {
+	if r.Sender != "" {
+		matched, err := path.Match(strings.ToLower(r.Sender), strings.ToLower(input.From))
+		if err != nil || !matched {
+			return false
+		}
+	}
+
+	switch r.Contact {
+	case ContactMatchKnown:
+		if !input.IsContact {
+			return false
+		}
+	case ContactMatchUnknown:
+		if input.IsContact {
+			return false
+		}
+	case ContactMatchVerified:
+		if !input.IsVerified {
+			return false
+		}
+	}
+
+	if r.Mailbox != "" && !strings.EqualFold(r.Mailbox, input.Mailbox) {
+		return false
+	}
+
+	if r.SubjectPattern != "" {
+		re, err := regexp.Compile("(?i)" + r.SubjectPattern)
+		if err != nil || !re.MatchString(input.Subject) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// isActive returns true if now falls inside the quiet hours window
+func (q *QuietHours) isActive(now time.Time) bool {
+	if !q.Enabled {
+		return false
+	}
+
+	start, okStart := parseClock(q.Start)
+	end, okEnd := parseClock(q.End)
+	if !okStart || !okEnd || start == end {
+		return false
+	}
+
+	current := now.Hour()*60 + now.Minute()
+	if start < end {
+		return current >= start && current < end
+	}
+	// Window crosses midnight
+	return current >= start || current < end
+}
+
+// parseClock converts HH:MM to minutes since midnight
+func parseClock(s string) (int, bool) {
+	if !quietHoursTimeRegex.MatchString(s) {
+		return 0, false
+	}
+	t, err := time.Parse("15:04", s)
+	if err != nil {
+		return 0, false
+	}
+	return t.Hour()*60 + t.Minute(), true
+}
+
+// isValidNotificationAction checks if an action is supported
+func isValidNotificationAction(action string) bool {
+	switch action {
+	case NotificationActionNotify, NotificationActionSilent, NotificationActionSuppress:
+		return true
+	}
+	return false
+}
+
+// GetNotificationSettings returns a copy of the notification settings
+// Thread-safe with read lock
+func (c *Config) GetNotificationSettings() NotificationSettings {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	settings := c.Notifications
+	settings.Rules = append([]NotificationRule(nil), c.Notifications.Rules...)
+	return settings
+}
+
+// SetNotificationSettings validates and replaces the notification settings
+// Thread-safe with write lock
+func (c *Config) SetNotificationSettings(settings NotificationSettings) error {
+	settings.applyDefaults()
+	if err := settings.Validate(); err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.Notifications = settings
+	return nil
+}
+
+// IsDoNotDisturb returns true if do not disturb is enabled
+// Thread-safe with read lock
+func (c *Config) IsDoNotDisturb() bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.Notifications.DoNotDisturb
+}
+
+// SetDoNotDisturb enables or disables do not disturb
+// Thread-safe with write lock
+func (c *Config) SetDoNotDisturb(enabled bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.Notifications.DoNotDisturb = enabled
+}
+
+// EvaluateNotification decides how a new mail event should be notified
+// Thread-safe with read lock
+func (c *Config) EvaluateNotification(input NotificationInput, now time.Time) NotificationDecision {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.Notifications.Evaluate(input, now)
+}
@@ -0,0 +1,619 @@
+package core
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/JB-SelfCompany/Tyr-Desktop/internal/mailclient"
+	"github.com/JB-SelfCompany/Tyr-Desktop/internal/platform"
+	"github.com/JB-SelfCompany/Tyr-Desktop/internal/yggmail"
+)
+
+// Delivery states of an outgoing message
+const (
+	// DeliveryStateSending means the message is being submitted to the local SMTP listener
+	DeliveryStateSending = "sending"
+
+	// DeliveryStateQueued means the message is waiting in the yggmail queue (Outbox)
+	DeliveryStateQueued = "queued"
+
+	// DeliveryStateDelivered means the recipient accepted the message
+	DeliveryStateDelivered = "delivered"
+
+	// DeliveryStateFailed means submission or delivery failed (see Error)
+	DeliveryStateFailed = "failed"
+
+	// DeliveryStateRetried means a failed message was picked up again by the queue
+	DeliveryStateRetried = "retried"
+)
+
+const (
+	// MaxOutboxRecords is the number of outgoing messages kept in the history
+	MaxOutboxRecords = 500
+
+	// DefaultOutboxListLimit is the number of records returned when no limit is given
+	DefaultOutboxListLimit = 50
+
+	// outboxEventMatchWindow is how far back a sent/error event may match a record
+	// that has no Message-ID to go by
+	outboxEventMatchWindow = 7 * 24 * time.Hour
+
+	// outboxMissingGrace is how long a queued message may be absent from both
+	// Outbox and Sent before it is considered dropped (covers the move between them)
+	outboxMissingGrace = time.Minute
+
+	// yggmail keeps queued messages in Outbox and moves them to Sent once delivered
+	outboxMailbox = "Outbox"
+	sentMailbox   = "Sent"
+)
+
+// DeliveryTransition is a single state change of an outgoing message
+type DeliveryTransition struct {
+	// State is the new state
+	State string `json:"state"`
+
+	// Time is when the transition happened
+	Time time.Time `json:"time"`
+
+	// Reason explains the transition (e.g., the delivery error)
+	Reason string `json:"reason,omitempty"`
+}
+
+// OutgoingRecord tracks the delivery of one outgoing message
+type OutgoingRecord struct {
+	// Key identifies the record: the Message-ID if known, otherwise
+	// a hash of recipients, subject and creation time
+	Key string `json:"key"`
+
+	// MessageID is the Message-ID header (may be empty for messages sent outside Tyr)
+	MessageID string `json:"message_id,omitempty"`
+
+	// To is the list of envelope recipients
+	To []string `json:"to"`
+
+	// Subject is the message subject
+	Subject string `json:"subject"`
+
+	// SizeBytes is the size of the rendered message
+	SizeBytes int64 `json:"size_bytes,omitempty"`
+
+	// State is the current delivery state
+	State string `json:"state"`
+
+	// Error is the reason of the last failure
+	Error string `json:"error,omitempty"`
+
+	// Attempts is the number of delivery attempts seen (1 + retries)
+	Attempts int `json:"attempts"`
+
+	// CreatedAt is when the message was first tracked
+	CreatedAt time.Time `json:"created_at"`
+
+	// UpdatedAt is when the state last changed
+	UpdatedAt time.Time `json:"updated_at"`
+
+	// History lists all state transitions, oldest first
+	History []DeliveryTransition `json:"history"`
+
+	// Recipients holds the delivery state reported for each recipient, keyed by address
+	// Recipients without an entry haven't been reported on yet
+	Recipients map[string]RecipientDelivery `json:"recipients,omitempty"`
+}
+
+// RecipientDelivery is the delivery state of one recipient of an outgoing message
+type RecipientDelivery struct {
+	// State is delivered or failed
+	State string `json:"state"`
+
+	// Error is the delivery error for this recipient
+	Error string `json:"error,omitempty"`
+
+	// UpdatedAt is when the state was reported
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// IsFinal returns true if the message needs no further tracking
+func (r *OutgoingRecord) IsFinal() bool {
+	return r.State == DeliveryStateDelivered
+}
+
+// OutboxStore tracks outgoing messages and persists them to the data directory
+type OutboxStore struct {
+	// path is the outbox file location
+	path string
+
+	// records is keyed by record key
+	records map[string]*OutgoingRecord
+
+	// Mutex for thread-safe access to records
+	mu sync.RWMutex
+}
+
+// LoadOutbox reads the outbox file from the data directory
+// Returns an empty store if the file doesn't exist
+func LoadOutbox() (*OutboxStore, error) {
+	return LoadOutboxFrom(platform.GetOutboxPath())
+}
+
+// LoadOutboxFrom reads an outbox file from the given path
+// Returns an empty store if the file doesn't exist
+func LoadOutboxFrom(path string) (*OutboxStore, error) {
+	store := &OutboxStore{
+		path:    path,
+		records: make(map[string]*OutgoingRecord),
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return store, nil
+		}
+		return nil, fmt.Errorf("failed to read outbox file: %w", err)
+	}
+
+	var list []*OutgoingRecord
+	if err := json.Unmarshal(data, &list); err != nil {
+		return nil, fmt.Errorf("failed to parse outbox file: %w", err)
+	}
+	for _, r := range list {
+		if r != nil && r.Key != "" {
+			store.records[r.Key] = r
+		}
+	}
+	return store, nil
+}
+
+// OutboxKey returns the record key for a message
+// Uses the Message-ID when present, otherwise (to, subject, time)
+func OutboxKey(messageID string, to []string, subject string, created time.Time) string {
+	if messageID = strings.Trim(strings.TrimSpace(messageID), "<>"); messageID != "" {
+		return messageID
+	}
+
+	sum := sha256.Sum256([]byte(strings.Join(normalizeRecipients(to), ",") + "\n" + subject + "\n" + created.UTC().Format(time.RFC3339Nano)))
+	return "local-" + hex.EncodeToString(sum[:8])
+}
+
+// Track starts tracking an outgoing message in the given state
+// Returns the record key
+// Thread-safe with write lock
+func (s *OutboxStore) Track(messageID string, to []string, subject string, sizeBytes int64, state string) (string, error) {
+	now := time.Now().UTC()
+	record := &OutgoingRecord{
+		Key:       OutboxKey(messageID, to, subject, now),
+		MessageID: strings.Trim(strings.TrimSpace(messageID), "<>"),
+		To:        normalizeRecipients(to),
+		Subject:   subject,
+		SizeBytes: sizeBytes,
+		State:     state,
+		Attempts:  1,
+		CreatedAt: now,
+		UpdatedAt: now,
+		History:   []DeliveryTransition{{State: state, Time: now}},
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.records[record.Key] = record
+	s.pruneUnsafe()
+	return record.Key, s.saveUnsafe()
+}
+
+// Transition moves a tracked message to a new state and records it in the history
+// Moving a failed message back into the queue or to delivered records a retry first
+// Thread-safe with write lock
+func (s *OutboxStore) Transition(key, state, reason string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	record, ok := s.records[key]
+	if !ok {
+		return fmt.Errorf("outgoing message not found: %s", key)
+	}
+	if !s.transitionUnsafe(record, state, reason, time.Now().UTC()) {
+		return nil
+	}
+	return s.saveUnsafe()
+}
+
+// transitionUnsafe applies a state change (caller must hold the lock)
+// Returns false if the record is already in that state
+func (s *OutboxStore) transitionUnsafe(record *OutgoingRecord, state, reason string, at time.Time) bool {
+	if record.State == state && record.Error == reason {
+		return false
+	}
+
+	// Any news about a failed message means the queue made another attempt
+	if record.State == DeliveryStateFailed {
+		record.Attempts++
+		record.History = append(record.History, DeliveryTransition{State: DeliveryStateRetried, Time: at})
+	}
+
+	switch state {
+	case DeliveryStateDelivered:
+		// The message reached Sent, so every recipient accepted it
+		for _, addr := range record.To {
+			record.Recipients = setRecipientDelivery(record.Recipients, addr, DeliveryStateDelivered, "", at)
+		}
+	case DeliveryStateQueued:
+		// Failed recipients are being retried
+		for addr, d := range record.Recipients {
+			if d.State == DeliveryStateFailed {
+				delete(record.Recipients, addr)
+			}
+		}
+	}
+
+	record.State = state
+	record.UpdatedAt = at
+	if state == DeliveryStateFailed {
+		record.Error = reason
+	} else {
+		record.Error = ""
+	}
+	record.History = append(record.History, DeliveryTransition{State: state, Time: at, Reason: reason})
+	return true
+}
+
+// HandleMailEvent applies a yggmail "sent" or "error" event to the matching record
+// Events are matched by Message-ID when they carry one, otherwise by subject and
+// a shared recipient against the most recent undelivered record. yggmail reports
+// each recipient separately, so the record is delivered once every recipient is.
+// Unmatched events are tracked as new records
+// Thread-safe with write lock
+func (s *OutboxStore) HandleMailEvent(event yggmail.MailEvent) error {
+	var state string
+	switch event.Type {
+	case "sent":
+		state = DeliveryStateDelivered
+	case "error":
+		state = DeliveryStateFailed
+	default:
+		return nil
+	}
+
+	to := normalizeRecipients(strings.Split(event.To, ","))
+	messageID := strings.Trim(strings.TrimSpace(event.MessageID), "<>")
+	at := event.Timestamp.UTC()
+	if event.Timestamp.IsZero() {
+		at = time.Now().UTC()
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	record := s.matchUnsafe(messageID, to, event.Subject, at)
+	if record == nil {
+		record = &OutgoingRecord{
+			Key:       OutboxKey(messageID, to, event.Subject, at),
+			MessageID: messageID,
+			To:        to,
+			Subject:   event.Subject,
+			State:     DeliveryStateQueued,
+			Attempts:  1,
+			CreatedAt: at,
+			UpdatedAt: at,
+			History:   []DeliveryTransition{{State: DeliveryStateQueued, Time: at}},
+		}
+		s.records[record.Key] = record
+		s.pruneUnsafe()
+	}
+
+	for _, addr := range to {
+		if containsRecipient(record.To, addr) {
+			record.Recipients = setRecipientDelivery(record.Recipients, addr, state, event.ErrorMessage, at)
+		}
+	}
+	recordState, reason := aggregateDelivery(record, state, event.ErrorMessage)
+	s.transitionUnsafe(record, recordState, reason, at)
+	return s.saveUnsafe()
+}
+
+// matchUnsafe finds the record an event belongs to (caller must hold the lock)
+// A Message-ID identifies the record directly. Otherwise it is the newest record
+// with the same subject that has an undelivered recipient in common with the event
+func (s *OutboxStore) matchUnsafe(messageID string, to []string, subject string, at time.Time) *OutgoingRecord {
+	if messageID != "" {
+		if r, ok := s.records[messageID]; ok {
+			return r
+		}
+	}
+
+	var best *OutgoingRecord
+	for _, r := range s.records {
+		if r.IsFinal() || r.Subject != subject || !hasUndeliveredRecipient(r, to) {
+			continue
+		}
+		if at.Sub(r.CreatedAt) > outboxEventMatchWindow {
+			continue
+		}
+		if best == nil || r.CreatedAt.After(best.CreatedAt) {
+			best = r
+		}
+	}
+	return best
+}
+
+// hasUndeliveredRecipient returns true if one of the addresses is a recipient
+// of the record that hasn't been delivered yet
+func hasUndeliveredRecipient(r *OutgoingRecord, to []string) bool {
+	for _, addr := range to {
+		if containsRecipient(r.To, addr) && r.Recipients[addr].State != DeliveryStateDelivered {
+			return true
+		}
+	}
+	return false
+}
+
+// aggregateDelivery derives the record state from the per-recipient states
+// The record is delivered once every recipient is, and failed while any recipient failed.
+// state and reason are the event values, used for records without recipients
+func aggregateDelivery(r *OutgoingRecord, state, reason string) (string, string) {
+	if len(r.To) == 0 {
+		return state, reason
+	}
+
+	delivered := 0
+	var failures []string
+	for _, addr := range r.To {
+		switch d := r.Recipients[addr]; d.State {
+		case DeliveryStateDelivered:
+			delivered++
+		case DeliveryStateFailed:
+			failures = append(failures, fmt.Sprintf("%s: %s", addr, d.Error))
+		}
+	}
+
+	switch {
+	case delivered == len(r.To):
+		return DeliveryStateDelivered, ""
+	case len(failures) > 0:
+		return DeliveryStateFailed, strings.Join(failures, "; ")
+	default:
+		// Some recipients delivered, the rest are still in the queue
+		return DeliveryStateQueued, ""
+	}
+}
+
+// setRecipientDelivery records the state of one recipient, creating the map if needed
+func setRecipientDelivery(m map[string]RecipientDelivery, addr, state, reason string, at time.Time) map[string]RecipientDelivery {
+	if m == nil {
+		m = make(map[string]RecipientDelivery)
+	}
+	if state != DeliveryStateFailed {
+		reason = ""
+	}
+	m[addr] = RecipientDelivery{State: state, Error: reason, UpdatedAt: at}
+	return m
+}
+
+// containsRecipient returns true if addr is in the normalized recipient list
+func containsRecipient(to []string, addr string) bool {
+	for _, a := range to {
+		if a == addr {
+			return true
+		}
+	}
+	return false
+}
+
+// Pending returns copies of records that are not delivered yet and have a Message-ID
+// Thread-safe with read lock
+func (s *OutboxStore) Pending() []OutgoingRecord {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var result []OutgoingRecord
+	for _, r := range s.records {
+		if !r.IsFinal() && r.MessageID != "" {
+			result = append(result, copyRecord(r))
+		}
+	}
+	return result
+}
+
+// List returns the most recent records, newest first
+// A limit of 0 or less uses DefaultOutboxListLimit
+// Thread-safe with read lock
+func (s *OutboxStore) List(limit int) []OutgoingRecord {
+	if limit <= 0 {
+		limit = DefaultOutboxListLimit
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	list := s.sortedUnsafe()
+	if len(list) > limit {
+		list = list[:limit]
+	}
+
+	result := make([]OutgoingRecord, 0, len(list))
+	for _, r := range list {
+		result = append(result, copyRecord(r))
+	}
+	return result
+}
+
+// Get returns a copy of a record by key
+// Thread-safe with read lock
+func (s *OutboxStore) Get(key string) (OutgoingRecord, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	r, ok := s.records[key]
+	if !ok {
+		return OutgoingRecord{}, false
+	}
+	return copyRecord(r), true
+}
+
+// Clear removes delivered records from the history
+// Thread-safe with write lock
+func (s *OutboxStore) Clear() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for key, r := range s.records {
+		if r.IsFinal() {
+			delete(s.records, key)
+		}
+	}
+	return s.saveUnsafe()
+}
+
+// sortedUnsafe returns records sorted newest first (caller must hold the lock)
+func (s *OutboxStore) sortedUnsafe() []*OutgoingRecord {
+	list := make([]*OutgoingRecord, 0, len(s.records))
+	for _, r := range s.records {
+		list = append(list, r)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].CreatedAt.After(list[j].CreatedAt)
+	})
+	return list
+}
+
+// pruneUnsafe drops the oldest records above MaxOutboxRecords (caller must hold the lock)
+func (s *OutboxStore) pruneUnsafe() {
+	if len(s.records) <= MaxOutboxRecords {
+		return
+	}
+	for _, r := range s.sortedUnsafe()[MaxOutboxRecords:] {
+		delete(s.records, r.Key)
+	}
+}
+
+// saveUnsafe writes records to disk (caller must hold the lock)
+func (s *OutboxStore) saveUnsafe() error {
+	if err := EnsureConfigDir(); err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(s.sortedUnsafe(), "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to serialize outbox: %w", err)
+	}
+
+	// Write with user-only read/write permissions (rw-------)
+	if err := os.WriteFile(s.path, data, 0600); err != nil {
+		return fmt.Errorf("failed to write outbox file: %w", err)
+	}
+	return nil
+}
+
+// copyRecord returns a deep copy of a record
+func copyRecord(r *OutgoingRecord) OutgoingRecord {
+	c := *r
+	c.To = append([]string(nil), r.To...)
+	c.History = append([]DeliveryTransition(nil), r.History...)
+	if r.Recipients != nil {
+		c.Recipients = make(map[string]RecipientDelivery, len(r.Recipients))
+		for addr, d := range r.Recipients {
+			c.Recipients[addr] = d
+		}
+	}
+	return c
+}
+
+// normalizeRecipients lowercases, trims and sorts recipient addresses
+func normalizeRecipients(to []string) []string {
+	result := make([]string, 0, len(to))
+	for _, addr := range to {
+		if addr = NormalizeMailAddress(ExtractMailAddress(addr)); addr != "" {
+			result = append(result, addr)
+		}
+	}
+	sort.Strings(result)
+	return result
+}
+
+// RefreshOutbox updates pending records from the local IMAP server
+// yggmail keeps a message in Outbox while the queue retries it and moves it to
+// Sent once the recipient accepts it; a message in neither was dropped from the queue.
+// Returns the number of records that changed state
+// Thread-safe
+func (sm *ServiceManager) RefreshOutbox(outbox *OutboxStore) (int, error) {
+	if outbox == nil {
+		return 0, fmt.Errorf("outbox not initialized")
+	}
+
+	pending := outbox.Pending()
+	if len(pending) == 0 {
+		return 0, nil
+	}
+
+	want := make(map[string]bool, len(pending))
+	for _, r := range pending {
+		want[r.MessageID] = true
+	}
+
+	var queued, sent map[string]bool
+	err := sm.withIMAPSession(func(s *mailclient.IMAPSession) error {
+		var err error
+		if queued, err = findMessageIDs(s, outboxMailbox, want); err != nil {
+			return err
+		}
+		sent, err = findMessageIDs(s, sentMailbox, want)
+		return err
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	changed := 0
+	now := time.Now().UTC()
+	for _, r := range pending {
+		state, reason := r.State, r.Error
+		switch {
+		case sent[r.MessageID]:
+			state, reason = DeliveryStateDelivered, ""
+		case queued[r.MessageID]:
+			if r.State == DeliveryStateSending || r.State == DeliveryStateFailed {
+				state, reason = DeliveryStateQueued, ""
+			}
+		case r.State == DeliveryStateQueued && now.Sub(r.UpdatedAt) > outboxMissingGrace:
+			state, reason = DeliveryStateFailed, "Message left the outbox without being delivered (expired or deleted)"
+		}
+
+		if state == r.State && reason == r.Error {
+			continue
+		}
+		if err := outbox.Transition(r.Key, state, reason); err != nil {
+			return changed, err
+		}
+		changed++
+	}
+	return changed, nil
+}
+
+// findMessageIDs returns which of the wanted Message-IDs are in a mailbox
+// Pages through the whole mailbox, newest first, stopping once all are found.
+// The local server ignores SEARCH criteria, so headers are compared here
+func findMessageIDs(s *mailclient.IMAPSession, mailbox string, want map[string]bool) (map[string]bool, error) {
+	found := make(map[string]bool)
+	for page := 0; len(found) < len(want); page++ {
+		result, err := s.ListMessages(mailbox, page, mailclient.MaxPageSize)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list %s: %w", mailbox, err)
+		}
+
+		for _, m := range result.Messages {
+			if id := strings.Trim(strings.TrimSpace(m.MessageID), "<>"); want[id] {
+				found[id] = true
+			}
+		}
+		if len(result.Messages) == 0 || uint64(page+1)*uint64(result.PageSize) >= uint64(result.Total) {
+			break
+		}
+	}
+	return found, nil
+}
@@ -0,0 +1,268 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+const (
+	// masterPasswordFile is the fallback password file encrypted with the master passphrase
+	masterPasswordFile = ".password.master"
+
+	// MinMasterPassphraseLength is the minimum length of the fallback store master passphrase
+	MinMasterPassphraseLength = 8
+)
+
+var (
+	// ErrPasswordStoreLocked is returned while the master passphrase has not been entered
+	ErrPasswordStoreLocked = errors.New("password store is locked")
+
+	// ErrWrongMasterPassphrase is returned when the master passphrase doesn't decrypt the store
+	ErrWrongMasterPassphrase = errors.New("wrong master passphrase")
+)
+
+// passwordStore holds the unlocked master-passphrase store for this session
+// Nothing is written to disk in plaintext; the values are cleared on lock
+var passwordStore struct {
+	mu         sync.RWMutex
+	unlocked   bool
+	passphrase string
+	password   string
+}
+
+// PasswordStoreStatus describes the fallback password file
+type PasswordStoreStatus struct {
+	// InUse indicates the password is kept in a file instead of the OS keyring
+	InUse bool
+
+	// MasterPassphrase indicates the file is encrypted with a master passphrase
+	// instead of the machine ID
+	MasterPassphrase bool
+
+	// Unlocked indicates the master passphrase was entered this session
+	Unlocked bool
+}
+
+// GetPasswordStoreStatus reports how the fallback password file is protected
+func GetPasswordStoreStatus() PasswordStoreStatus {
+	master := IsMasterPassphraseEnabled()
+
+	passwordStore.mu.RLock()
+	unlocked := passwordStore.unlocked
+	passwordStore.mu.RUnlock()
+
+	return PasswordStoreStatus{
+		InUse:            master || fileExists(passwordFilePath(fallbackPasswordFile)),
+		MasterPassphrase: master,
+		Unlocked:         master && unlocked,
+	}
+}
+
+// IsMasterPassphraseEnabled returns true if the fallback password file is
+// encrypted with a master passphrase
+func IsMasterPassphraseEnabled() bool {
+	return fileExists(passwordFilePath(masterPasswordFile))
+}
+
+// UnlockPasswordStore decrypts the fallback password file with the master passphrase
+// The password is kept in memory until LockPasswordStore is called
+func UnlockPasswordStore(passphrase string) error {
+	if !IsMasterPassphraseEnabled() {
+		return fmt.Errorf("master passphrase is not enabled")
+	}
+
+	password, err := readMasterPasswordFile(passphrase)
+	if err != nil {
+		return err
+	}
+
+	passwordStore.mu.Lock()
+	passwordStore.unlocked = true
+	passwordStore.passphrase = passphrase
+	passwordStore.password = password
+	passwordStore.mu.Unlock()
+	return nil
+}
+
+// LockPasswordStore forgets the master passphrase and password for this session
+func LockPasswordStore() {
+	passwordStore.mu.Lock()
+	defer passwordStore.mu.Unlock()
+	passwordStore.unlocked = false
+	passwordStore.passphrase = ""
+	passwordStore.password = ""
+}
+
+// EnableMasterPassphrase re-encrypts the fallback password file with a master passphrase
+// The existing machine-ID encrypted file is migrated and removed
+func EnableMasterPassphrase(passphrase string) error {
+	if IsMasterPassphraseEnabled() {
+		return fmt.Errorf("master passphrase is already enabled")
+	}
+	if len(passphrase) < MinMasterPassphraseLength {
+		return fmt.Errorf("master passphrase must be at least %d characters", MinMasterPassphraseLength)
+	}
+	if !fileExists(passwordFilePath(fallbackPasswordFile)) {
+		return fmt.Errorf("password is stored in the OS keyring, no fallback file to protect")
+	}
+
+	password, err := getFallbackPassword(KeyringService, KeyringUsername)
+	if err != nil {
+		return err
+	}
+	if err := writeMasterPasswordFile(password, passphrase); err != nil {
+		return err
+	}
+
+	// Verify the new file before removing the old one
+	if _, err := readMasterPasswordFile(passphrase); err != nil {
+		os.Remove(passwordFilePath(masterPasswordFile))
+		return fmt.Errorf("failed to verify master passphrase file: %w", err)
+	}
+	if err := os.Remove(passwordFilePath(fallbackPasswordFile)); err != nil && !os.IsNotExist(err) {
+		log.Printf("[Security] Warning: failed to remove machine-ID password file: %v", err)
+	}
+
+	passwordStore.mu.Lock()
+	passwordStore.unlocked = true
+	passwordStore.passphrase = passphrase
+	passwordStore.password = password
+	passwordStore.mu.Unlock()
+	return nil
+}
+
+// DisableMasterPassphrase moves the password back to the machine-ID encrypted file
+func DisableMasterPassphrase(passphrase string) error {
+	if !IsMasterPassphraseEnabled() {
+		return fmt.Errorf("master passphrase is not enabled")
+	}
+
+	password, err := readMasterPasswordFile(passphrase)
+	if err != nil {
+		return err
+	}
+	if err := saveMachineIDPassword(password); err != nil {
+		return err
+	}
+	if err := os.Remove(passwordFilePath(masterPasswordFile)); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to remove master passphrase file: %w", err)
+	}
+
+	LockPasswordStore()
+	return nil
+}
+
+// ChangeMasterPassphrase re-encrypts the fallback password file with a new master passphrase
+func ChangeMasterPassphrase(currentPassphrase, newPassphrase string) error {
+	if !IsMasterPassphraseEnabled() {
+		return fmt.Errorf("master passphrase is not enabled")
+	}
+	if len(newPassphrase) < MinMasterPassphraseLength {
+		return fmt.Errorf("master passphrase must be at least %d characters", MinMasterPassphraseLength)
+	}
+
+	password, err := readMasterPasswordFile(currentPassphrase)
+	if err != nil {
+		return err
+	}
+	if err := writeMasterPasswordFile(password, newPassphrase); err != nil {
+		return err
+	}
+
+	passwordStore.mu.Lock()
+	passwordStore.unlocked = true
+	passwordStore.passphrase = newPassphrase
+	passwordStore.password = password
+	passwordStore.mu.Unlock()
+	return nil
+}
+
+// saveMasterPassword encrypts a new password with the session's master passphrase
+func saveMasterPassword(password string) error {
+	passwordStore.mu.Lock()
+	defer passwordStore.mu.Unlock()
+
+	if !passwordStore.unlocked {
+		return ErrPasswordStoreLocked
+	}
+	if err := writeMasterPasswordFile(password, passwordStore.passphrase); err != nil {
+		return err
+	}
+	passwordStore.password = password
+	return nil
+}
+
+// getMasterPassword returns the password unlocked this session
+func getMasterPassword() (string, error) {
+	passwordStore.mu.RLock()
+	defer passwordStore.mu.RUnlock()
+
+	if !passwordStore.unlocked {
+		return "", ErrPasswordStoreLocked
+	}
+	return passwordStore.password, nil
+}
+
+// readMasterPasswordFile decrypts the master passphrase file
+func readMasterPasswordFile(passphrase string) (string, error) {
+	path := passwordFilePath(masterPasswordFile)
+	encrypted, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read master passphrase file: %w", err)
+	}
+
+	password, err := DecryptAESGCM(encrypted, passphrase)
+	if err != nil {
+		return "", ErrWrongMasterPassphrase
+	}
+
+	// Re-encrypt files written with a weaker key derivation
+	if upgraded, ok, err := UpgradeEncryption(encrypted, passphrase); err == nil && ok {
+		if err := os.WriteFile(path, upgraded, 0600); err != nil {
+			log.Printf("[Security] Warning: failed to write upgraded master passphrase file: %v", err)
+		}
+	}
+	return password, nil
+}
+
+// writeMasterPasswordFile encrypts the password with the master passphrase
+func writeMasterPasswordFile(password, passphrase string) error {
+	if err := EnsureConfigDir(); err != nil {
+		return fmt.Errorf("failed to create config directory: %w", err)
+	}
+
+	encrypted, err := EncryptAESGCM(password, passphrase)
+	if err != nil {
+		return fmt.Errorf("failed to encrypt password for fallback storage: %w", err)
+	}
+
+	path := passwordFilePath(masterPasswordFile)
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, encrypted, 0600); err != nil {
+		return fmt.Errorf("failed to write master passphrase file: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("failed to write master passphrase file: %w", err)
+	}
+	return nil
+}
+
+// passwordFilePath returns the path of a fallback password file in the config directory
+func passwordFilePath(name string) string {
+	configDir, err := GetConfigDir()
+	if err != nil {
+		return name
+	}
+	return filepath.Join(configDir, name)
+}
+
+// fileExists returns true if path exists
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
@@ -0,0 +1,131 @@
+package core
+
+import (
+	"errors"
+	"os"
+	"testing"
+)
+
+// resetPasswordFiles starts the test with a machine-ID password file holding password
+// Skips where there is no machine ID to encrypt it with
+func resetPasswordFiles(t *testing.T, password string) {
+	t.Helper()
+	useFastKDF(t)
+
+	if _, err := getMachineID(); err != nil {
+		t.Skipf("no machine ID: %v", err)
+	}
+	reset := func() {
+		os.Remove(passwordFilePath(masterPasswordFile))
+		os.Remove(passwordFilePath(fallbackPasswordFile))
+		cacheMachineIDPassword(nil, "")
+		LockPasswordStore()
+	}
+	reset()
+	t.Cleanup(reset)
+
+	if err := saveMachineIDPassword(password); err != nil {
+		t.Fatalf("saveMachineIDPassword: %v", err)
+	}
+}
+
+func TestMasterPassphraseLifecycle(t *testing.T) {
+	resetPasswordFiles(t, "mail password")
+
+	if err := EnableMasterPassphrase("short"); err == nil {
+		t.Error("EnableMasterPassphrase accepted a short passphrase")
+	}
+	if err := EnableMasterPassphrase("master passphrase"); err != nil {
+		t.Fatalf("EnableMasterPassphrase: %v", err)
+	}
+	if fileExists(passwordFilePath(fallbackPasswordFile)) {
+		t.Error("machine-ID password file kept after enabling the master passphrase")
+	}
+	if status := GetPasswordStoreStatus(); !status.InUse || !status.MasterPassphrase || !status.Unlocked {
+		t.Errorf("status after enabling = %+v, want in use, master passphrase, unlocked", status)
+	}
+
+	LockPasswordStore()
+	if _, err := getFallbackPassword(KeyringService, KeyringUsername); !errors.Is(err, ErrPasswordStoreLocked) {
+		t.Errorf("getFallbackPassword while locked = %v, want ErrPasswordStoreLocked", err)
+	}
+	if err := UnlockPasswordStore("wrong passphrase"); !errors.Is(err, ErrWrongMasterPassphrase) {
+		t.Errorf("UnlockPasswordStore with wrong passphrase = %v, want ErrWrongMasterPassphrase", err)
+	}
+	if err := UnlockPasswordStore("master passphrase"); err != nil {
+		t.Fatalf("UnlockPasswordStore: %v", err)
+	}
+	if password, err := getFallbackPassword(KeyringService, KeyringUsername); err != nil || password != "mail password" {
+		t.Errorf("getFallbackPassword = %q, %v, want %q", password, err, "mail password")
+	}
+
+	if err := ChangeMasterPassphrase("wrong passphrase", "new passphrase"); !errors.Is(err, ErrWrongMasterPassphrase) {
+		t.Errorf("ChangeMasterPassphrase with wrong passphrase = %v, want ErrWrongMasterPassphrase", err)
+	}
+	if err := ChangeMasterPassphrase("master passphrase", "new passphrase"); err != nil {
+		t.Fatalf("ChangeMasterPassphrase: %v", err)
+	}
+	LockPasswordStore()
+	if err := UnlockPasswordStore("master passphrase"); !errors.Is(err, ErrWrongMasterPassphrase) {
+		t.Error("old master passphrase still unlocks the store")
+	}
+
+	if err := DisableMasterPassphrase("new passphrase"); err != nil {
+		t.Fatalf("DisableMasterPassphrase: %v", err)
+	}
+	if IsMasterPassphraseEnabled() {
+		t.Error("master passphrase file kept after disabling")
+	}
+	if password, err := getFallbackPassword(KeyringService, KeyringUsername); err != nil || password != "mail password" {
+		t.Errorf("getFallbackPassword after disabling = %q, %v, want %q", password, err, "mail password")
+	}
+}
+
+func TestSaveMasterPasswordRequiresUnlock(t *testing.T) {
+	resetPasswordFiles(t, "mail password")
+
+	if err := EnableMasterPassphrase("master passphrase"); err != nil {
+		t.Fatalf("EnableMasterPassphrase: %v", err)
+	}
+	LockPasswordStore()
+	if err := saveFallbackPassword(KeyringService, KeyringUsername, "changed"); !errors.Is(err, ErrPasswordStoreLocked) {
+		t.Errorf("saveFallbackPassword while locked = %v, want ErrPasswordStoreLocked", err)
+	}
+
+	UnlockPasswordStore("master passphrase")
+	if err := saveFallbackPassword(KeyringService, KeyringUsername, "changed"); err != nil {
+		t.Fatalf("saveFallbackPassword: %v", err)
+	}
+	LockPasswordStore()
+	UnlockPasswordStore("master passphrase")
+	if password, _ := getFallbackPassword(KeyringService, KeyringUsername); password != "changed" {
+		t.Errorf("getFallbackPassword = %q, want %q", password, "changed")
+	}
+}
+
+func TestMachineIDPasswordUpgradedOnce(t *testing.T) {
+	resetPasswordFiles(t, "mail password")
+
+	machineID, _ := getMachineID()
+	legacy := encryptLegacy(t, "mail password", machineID)
+	if err := os.WriteFile(passwordFilePath(fallbackPasswordFile), legacy, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	if password, err := getFallbackPassword(KeyringService, KeyringUsername); err != nil || password != "mail password" {
+		t.Fatalf("getFallbackPassword = %q, %v, want %q", password, err, "mail password")
+	}
+	upgraded, _ := os.ReadFile(passwordFilePath(fallbackPasswordFile))
+	if NeedsEncryptionUpgrade(upgraded) {
+		t.Fatal("legacy password file wasn't upgraded on first read")
+	}
+
+	// Later reads use the cache and leave the file alone
+	if password, err := getFallbackPassword(KeyringService, KeyringUsername); err != nil || password != "mail password" {
+		t.Fatalf("cached getFallbackPassword = %q, %v, want %q", password, err, "mail password")
+	}
+	again, _ := os.ReadFile(passwordFilePath(fallbackPasswordFile))
+	if string(again) != string(upgraded) {
+		t.Error("password file rewritten on a later read")
+	}
+}
@@ -0,0 +1,120 @@
+package core
+
+import (
+	"fmt"
+	"io"
+	"net"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// proxyHostRegex validates the host name part of a SOCKS5 proxy address
+var proxyHostRegex = regexp.MustCompile(`^[a-zA-Z0-9.-]+$`)
+
+// proxySchemes maps direct peer schemes to their SOCKS equivalents
+// Only these protocols can be tunneled through a SOCKS5 proxy by Yggdrasil
+var proxySchemes = map[string]string{
+	"tcp": "socks",
+	"tls": "sockstls",
+}
+
+// ProxyTestTimeout is the maximum time allowed for a proxy connectivity test
+const ProxyTestTimeout = 5 * time.Second
+
+// ProxyTestResult contains the outcome of a proxy connectivity test
+type ProxyTestResult struct {
+	// Reachable indicates if the proxy accepted a SOCKS5 handshake
+	Reachable bool
+
+	// LatencyMs is the handshake round-trip time in milliseconds
+	LatencyMs int64
+
+	// ErrorMessage contains error details if Reachable is false
+	ErrorMessage string
+}
+
+// ProxyPeerAddress rewrites a peer URI to be dialed through the given SOCKS5 proxy
+// tcp://host:port becomes socks://proxy/host:port and tls://host:port becomes
+// sockstls://proxy/host:port. Other protocols are returned unchanged.
+// Returns the resulting address and whether it was rewritten
+func ProxyPeerAddress(address, proxyAddress string) (string, bool) {
+	scheme, rest, found := strings.Cut(address, "://")
+	if !found {
+		return address, false
+	}
+
+	proxyScheme, ok := proxySchemes[scheme]
+	if !ok {
+		return address, false
+	}
+
+	return fmt.Sprintf("%s://%s/%s", proxyScheme, proxyAddress, rest), true
+}
+
+// isValidProxyAddress checks a SOCKS5 proxy address in host:port format
+// IPv6 hosts must be bracketed, e.g. [::1]:9050
+func isValidProxyAddress(address string) bool {
+	host, port, err := net.SplitHostPort(address)
+	if err != nil || host == "" {
+		return false
+	}
+	portNum, err := strconv.Atoi(port)
+	if err != nil || portNum < 1 || portNum > 65535 {
+		return false
+	}
+	return net.ParseIP(host) != nil || proxyHostRegex.MatchString(host)
+}
+
+// IsProxyableAddress returns true if the peer URI can be routed through the proxy
+func IsProxyableAddress(address string) bool {
+	scheme, _, found := strings.Cut(address, "://")
+	if !found {
+		return false
+	}
+	_, ok := proxySchemes[scheme]
+	return ok
+}
+
+// TestProxy checks that a SOCKS5 proxy is reachable and accepts unauthenticated clients
+// Performs only the SOCKS5 method negotiation, no outgoing connection is requested
+func TestProxy(proxyAddress string) ProxyTestResult {
+	if !isValidProxyAddress(proxyAddress) {
+		return ProxyTestResult{ErrorMessage: "invalid proxy address format, expected host:port"}
+	}
+
+	start := time.Now()
+
+	conn, err := net.DialTimeout("tcp", proxyAddress, ProxyTestTimeout)
+	if err != nil {
+		return ProxyTestResult{ErrorMessage: fmt.Sprintf("failed to connect to proxy: %v", err)}
+	}
+	defer conn.Close()
+
+	if err := conn.SetDeadline(time.Now().Add(ProxyTestTimeout)); err != nil {
+		return ProxyTestResult{ErrorMessage: fmt.Sprintf("failed to set deadline: %v", err)}
+	}
+
+	// SOCKS5 greeting: version 5, one method, "no authentication required"
+	if _, err := conn.Write([]byte{0x05, 0x01, 0x00}); err != nil {
+		return ProxyTestResult{ErrorMessage: fmt.Sprintf("failed to send SOCKS5 greeting: %v", err)}
+	}
+
+	reply := make([]byte, 2)
+	if _, err := io.ReadFull(conn, reply); err != nil {
+		return ProxyTestResult{ErrorMessage: fmt.Sprintf("no SOCKS5 response from proxy: %v", err)}
+	}
+
+	if reply[0] != 0x05 {
+		return ProxyTestResult{ErrorMessage: "proxy did not respond with SOCKS5 protocol version"}
+	}
+	if reply[1] != 0x00 {
+		return ProxyTestResult{ErrorMessage: "proxy requires authentication, which is not supported"}
+	}
+
+	return ProxyTestResult{
+		Reachable: true,
+		LatencyMs: time.Since(start).Milliseconds(),
+	}
+}
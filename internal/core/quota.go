@@ -0,0 +1,364 @@
+package core
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/JB-SelfCompany/Tyr-Desktop/internal/mailclient"
+)
+
+// Storage quota states
+const (
+	// QuotaStateOK means usage is below every warning threshold
+	QuotaStateOK = "ok"
+
+	// QuotaStateWarning means usage crossed a warning threshold
+	QuotaStateWarning = "warning"
+
+	// QuotaStateExceeded means usage reached the storage budget
+	QuotaStateExceeded = "exceeded"
+)
+
+const (
+	// DefaultQuotaRestrictedMessageSizeMB is the incoming message size limit applied past the budget
+	DefaultQuotaRestrictedMessageSizeMB = 1
+
+	// DefaultLargestMessagesCount is the number of largest messages returned by GetStorageUsage
+	DefaultLargestMessagesCount = 20
+)
+
+// DefaultQuotaWarningThresholds are the default warning levels in percent of the budget
+var DefaultQuotaWarningThresholds = []int{80, 95}
+
+// QuotaSettings contains the storage budget configuration
+type QuotaSettings struct {
+	// Enabled turns on quota monitoring
+	Enabled bool `toml:"enabled"`
+
+	// BudgetMB is the storage budget for StorageStats.TotalSizeMB
+	BudgetMB int64 `toml:"budget_mb"`
+
+	// WarningThresholds are warning levels in percent of the budget (ascending)
+	WarningThresholds []int `toml:"warning_thresholds"`
+
+	// EnforceHardLimit lowers the accepted incoming message size once the budget is reached
+	EnforceHardLimit bool `toml:"enforce_hard_limit"`
+
+	// RestrictedMessageSizeMB is the incoming message size limit while over budget
+	RestrictedMessageSizeMB int64 `toml:"restricted_message_size_mb"`
+}
+
+// QuotaStatus describes storage usage against the budget
+type QuotaStatus struct {
+	// Enabled indicates if quota monitoring is on
+	Enabled bool
+
+	// UsedMB is the current total storage usage
+	UsedMB float64
+
+	// BudgetMB is the configured budget
+	BudgetMB int64
+
+	// Percent is the usage in percent of the budget
+	Percent float64
+
+	// State is "ok", "warning" or "exceeded"
+	State string
+
+	// Threshold is the highest warning threshold crossed (0 if none)
+	Threshold int
+
+	// Restricted indicates the incoming message size is currently lowered
+	Restricted bool
+
+	// CheckedAt is when usage was measured
+	CheckedAt time.Time
+}
+
+// MailboxUsage contains storage used by one mailbox
+type MailboxUsage struct {
+	// Name is the mailbox name
+	Name string
+
+	// Messages is the number of messages
+	Messages int
+
+	// SizeBytes is the total size of the messages
+	SizeBytes int64
+}
+
+// LargeMessage describes one of the largest stored messages
+type LargeMessage struct {
+	// Mailbox is the mailbox containing the message
+	Mailbox string
+
+	// UID is the message UID
+	UID uint32
+
+	// Subject is the message subject
+	Subject string
+
+	// From is the sender address
+	From string
+
+	// Date is when the message was received
+	Date time.Time
+
+	// SizeBytes is the message size
+	SizeBytes int64
+}
+
+// StorageUsage breaks storage down by mailbox and largest messages
+type StorageUsage struct {
+	// Mailboxes is sorted by size, largest first
+	Mailboxes []MailboxUsage
+
+	// Largest is the list of largest messages across all mailboxes, largest first
+	Largest []LargeMessage
+}
+
+// applyDefaults fills in missing quota values
+func (q *QuotaSettings) applyDefaults() {
+	if len(q.WarningThresholds) == 0 {
+		q.WarningThresholds = append([]int(nil), DefaultQuotaWarningThresholds...)
+	}
+	if q.RestrictedMessageSizeMB <= 0 {
+		q.RestrictedMessageSizeMB = DefaultQuotaRestrictedMessageSizeMB
+	}
+	sort.Ints(q.WarningThresholds)
+}
+
+// Validate checks the quota settings for errors
+func (q *QuotaSettings) Validate() error {
+	if q.Enabled && q.BudgetMB <= 0 {
+		return fmt.Errorf("storage budget must be greater than 0 MB")
+	}
+	for i, t := range q.WarningThresholds {
+		if t < 1 || t > 100 {
+			return fmt.Errorf("warning threshold must be between 1 and 100 percent")
+		}
+		if i > 0 && t == q.WarningThresholds[i-1] {
+			return fmt.Errorf("duplicate warning threshold: %d%%", t)
+		}
+	}
+	if q.RestrictedMessageSizeMB < 0 {
+		return fmt.Errorf("restricted message size cannot be negative")
+	}
+	return nil
+}
+
+// Evaluate computes the quota status for the given usage
+func (q *QuotaSettings) Evaluate(usedMB float64) QuotaStatus {
+	status := QuotaStatus{
+		Enabled:   q.Enabled,
+		UsedMB:    usedMB,
+		BudgetMB:  q.BudgetMB,
+		State:     QuotaStateOK,
+		CheckedAt: time.Now(),
+	}
+	if !q.Enabled || q.BudgetMB <= 0 {
+		return status
+	}
+
+	status.Percent = usedMB / float64(q.BudgetMB) * 100
+	for _, t := range q.WarningThresholds {
+		if status.Percent >= float64(t) {
+			status.Threshold = t
+			status.State = QuotaStateWarning
+		}
+	}
+	if status.Percent >= 100 {
+		status.State = QuotaStateExceeded
+	}
+	return status
+}
+
+// GetQuotaSettings returns a copy of the storage quota settings
+// Thread-safe with read lock
+func (c *Config) GetQuotaSettings() QuotaSettings {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	settings := c.StorageQuota
+	settings.WarningThresholds = append([]int(nil), c.StorageQuota.WarningThresholds...)
+	return settings
+}
+
+// SetQuotaSettings validates and replaces the storage quota settings
+// Thread-safe with write lock
+func (c *Config) SetQuotaSettings(settings QuotaSettings) error {
+	settings.applyDefaults()
+	if err := settings.Validate(); err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.StorageQuota = settings
+	return nil
+}
+
+// QuotaMonitor checks storage usage against the budget and enforces the hard limit
+// It remembers the last state so callers only report changes
+type QuotaMonitor struct {
+	config *Config
+
+	// last is the most recent status
+	last QuotaStatus
+
+	// restricted indicates the lowered message size is applied
+	restricted bool
+
+	// Mutex for thread-safe access to state
+	mu sync.Mutex
+}
+
+// NewQuotaMonitor creates a quota monitor for the given configuration
+func NewQuotaMonitor(config *Config) *QuotaMonitor {
+	return &QuotaMonitor{
+		config: config,
+		last:   QuotaStatus{State: QuotaStateOK},
+	}
+}
+
+// Check measures storage usage, applies or lifts the hard limit on sm (may be nil)
+// and returns the status. changed is true if the state or crossed threshold differs
+// from the previous check
+// Thread-safe
+func (m *QuotaMonitor) Check(sm *ServiceManager) (QuotaStatus, bool, error) {
+	stats, err := GetStorageStats(m.config)
+	if err != nil {
+		return QuotaStatus{}, false, err
+	}
+
+	settings := m.config.GetQuotaSettings()
+	status := settings.Evaluate(stats.TotalSizeMB)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	wantRestricted := settings.EnforceHardLimit && status.State == QuotaStateExceeded
+	if sm != nil && sm.IsRunning() {
+		// Read the applied limit back: a service restart resets it to the configured size
+		configured := m.config.GetMaxMessageSizeMB()
+		if current, err := sm.GetMaxMessageSizeMB(); err == nil {
+			m.restricted = current == settings.RestrictedMessageSizeMB && (current != configured || m.restricted)
+		}
+
+		if wantRestricted != m.restricted {
+			sizeMB := configured
+			if wantRestricted {
+				sizeMB = settings.RestrictedMessageSizeMB
+			}
+			if err := sm.HotReloadMaxMessageSize(sizeMB); err != nil {
+				log.Printf("[Quota] Failed to apply message size limit of %d MB: %v", sizeMB, err)
+			} else {
+				m.restricted = wantRestricted
+				log.Printf("[Quota] Incoming message size limit set to %d MB (over budget: %v)", sizeMB, wantRestricted)
+			}
+		}
+	} else {
+		// A stopped service applies the configured size on start
+		m.restricted = false
+	}
+	status.Restricted = m.restricted
+
+	changed := status.State != m.last.State || status.Threshold != m.last.Threshold ||
+		status.Restricted != m.last.Restricted || status.Enabled != m.last.Enabled
+	m.last = status
+	return status, changed, nil
+}
+
+// Status returns the result of the last check
+// Thread-safe
+func (m *QuotaMonitor) Status() QuotaStatus {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.last
+}
+
+// IsRestricted returns true if the lowered message size is currently applied
+// Thread-safe
+func (m *QuotaMonitor) IsRestricted() bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.restricted
+}
+
+// GetStorageUsage breaks storage usage down by mailbox and lists the largest messages
+// A largest count of 0 or less uses DefaultLargestMessagesCount
+// Thread-safe
+func (sm *ServiceManager) GetStorageUsage(largest int) (*StorageUsage, error) {
+	if largest <= 0 {
+		largest = DefaultLargestMessagesCount
+	}
+
+	usage := &StorageUsage{
+		Mailboxes: []MailboxUsage{},
+		Largest:   []LargeMessage{},
+	}
+
+	err := sm.withIMAPSession(func(s *mailclient.IMAPSession) error {
+		mailboxes, err := s.ListMailboxes()
+		if err != nil {
+			return err
+		}
+
+		for _, mbox := range mailboxes {
+			if _, err := s.Examine(mbox.Name); err != nil {
+				return err
+			}
+			headers, err := s.AllHeaders()
+			if err != nil {
+				return err
+			}
+
+			mu := MailboxUsage{Name: mbox.Name, Messages: len(headers)}
+			for i := range headers {
+				h := &headers[i]
+				mu.SizeBytes += int64(h.Size)
+
+				msg := LargeMessage{
+					Mailbox:   mbox.Name,
+					UID:       h.UID,
+					Subject:   h.Subject,
+					Date:      messageTime(h),
+					SizeBytes: int64(h.Size),
+				}
+				if len(h.From) > 0 {
+					msg.From = h.From[0]
+				}
+				usage.Largest = insertLargest(usage.Largest, msg, largest)
+			}
+			usage.Mailboxes = append(usage.Mailboxes, mu)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(usage.Mailboxes, func(i, j int) bool {
+		return usage.Mailboxes[i].SizeBytes > usage.Mailboxes[j].SizeBytes
+	})
+	return usage, nil
+}
+
+// insertLargest keeps list sorted by size (largest first) and at most limit long
+func insertLargest(list []LargeMessage, msg LargeMessage, limit int) []LargeMessage {
+	if len(list) >= limit && msg.SizeBytes <= list[len(list)-1].SizeBytes {
+		return list
+	}
+	i := sort.Search(len(list), func(i int) bool { return list[i].SizeBytes < msg.SizeBytes })
+	list = append(list, LargeMessage{})
+	copy(list[i+1:], list[i:])
+	list[i] = msg
+	if len(list) > limit {
+		list = list[:limit]
+	}
+	return list
+}
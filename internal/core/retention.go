@@ -0,0 +1,583 @@
+package core
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/JB-SelfCompany/Tyr-Desktop/internal/mailclient"
+	"github.com/JB-SelfCompany/Tyr-Desktop/internal/platform"
+)
+
+// Retention actions
+const (
+	// RetentionActionDelete permanently deletes matching messages
+	RetentionActionDelete = "delete"
+
+	// RetentionActionMove moves matching messages to another mailbox
+	RetentionActionMove = "move"
+
+	// RetentionActionPurgeAttachments replaces large attachments with a short note
+	RetentionActionPurgeAttachments = "purge_attachments"
+)
+
+const (
+	// DefaultRetentionIntervalHours is how often scheduled retention runs by default
+	DefaultRetentionIntervalHours = 24
+
+	// MaxRetentionLogEntries is the number of retention runs kept in the log
+	MaxRetentionLogEntries = 100
+)
+
+// RetentionSettings contains mailbox retention policies and their schedule
+type RetentionSettings struct {
+	// Enabled turns on scheduled retention runs
+	Enabled bool `toml:"enabled"`
+
+	// IntervalHours is the time between scheduled runs
+	IntervalHours int `toml:"interval_hours"`
+
+	// LastRun is the Unix timestamp of the last scheduled or manual run
+	LastRun int64 `toml:"last_run,omitempty"`
+
+	// Policies is the list of per-mailbox policies
+	Policies []RetentionPolicy `toml:"policies"`
+}
+
+// RetentionPolicy describes what to clean up in one mailbox
+// Zero values disable the corresponding rule
+type RetentionPolicy struct {
+	// Mailbox is the mailbox the policy applies to (Outbox is not allowed)
+	Mailbox string `toml:"mailbox"`
+
+	// Enabled indicates if the policy is applied
+	Enabled bool `toml:"enabled"`
+
+	// MaxAgeDays removes messages older than this many days
+	MaxAgeDays int `toml:"max_age_days"`
+
+	// MaxMessages keeps at most this many of the newest messages
+	MaxMessages int `toml:"max_messages"`
+
+	// Action is what happens to removed messages ("delete" or "move")
+	Action string `toml:"action"`
+
+	// MoveTo is the destination mailbox for the "move" action
+	MoveTo string `toml:"move_to,omitempty"`
+
+	// KeepFlagged excludes flagged (starred) messages from the policy
+	KeepFlagged bool `toml:"keep_flagged"`
+
+	// PurgeAttachmentsOverKB removes attachments larger than this size
+	PurgeAttachmentsOverKB int `toml:"purge_attachments_over_kb"`
+
+	// PurgeAttachmentsAfterDays only purges attachments of messages older than this
+	PurgeAttachmentsAfterDays int `toml:"purge_attachments_after_days"`
+}
+
+// RetentionItem describes one message affected by a retention run
+type RetentionItem struct {
+	// Mailbox is the mailbox the message was in
+	Mailbox string `json:"mailbox"`
+
+	// UID is the message UID at the time of the run
+	UID uint32 `json:"uid"`
+
+	// MessageID is the Message-ID header
+	MessageID string `json:"message_id,omitempty"`
+
+	// Subject is the message subject
+	Subject string `json:"subject"`
+
+	// From is the sender address
+	From string `json:"from"`
+
+	// Date is when the message was received
+	Date time.Time `json:"date"`
+
+	// SizeBytes is the size of the message before the action
+	SizeBytes int64 `json:"size_bytes"`
+
+	// Action is the retention action applied (or planned in a dry run)
+	Action string `json:"action"`
+
+	// Target is the destination mailbox for moved messages
+	Target string `json:"target,omitempty"`
+
+	// Reason explains which rule matched
+	Reason string `json:"reason"`
+
+	// Attachments lists the names of purged attachments
+	Attachments []string `json:"attachments,omitempty"`
+
+	// FreedBytes is the storage released by the action
+	FreedBytes int64 `json:"freed_bytes"`
+
+	// Error is set if the action failed
+	Error string `json:"error,omitempty"`
+}
+
+// RetentionReport contains the outcome of a retention run
+type RetentionReport struct {
+	// StartedAt is when the run started
+	StartedAt time.Time `json:"started_at"`
+
+	// FinishedAt is when the run finished
+	FinishedAt time.Time `json:"finished_at"`
+
+	// DryRun indicates nothing was changed
+	DryRun bool `json:"dry_run"`
+
+	// Scheduled indicates the run was started by the scheduler
+	Scheduled bool `json:"scheduled"`
+
+	// Items lists the affected messages
+	Items []RetentionItem `json:"items"`
+
+	// Errors lists policy-level failures (e.g., a missing mailbox)
+	Errors []string `json:"errors,omitempty"`
+
+	// Processed is the number of messages successfully handled
+	Processed int `json:"processed"`
+
+	// FreedBytes is the total storage released
+	FreedBytes int64 `json:"freed_bytes"`
+}
+
+// applyDefaults fills in missing retention values
+func (r *RetentionSettings) applyDefaults() {
+	if r.IntervalHours <= 0 {
+		r.IntervalHours = DefaultRetentionIntervalHours
+	}
+	for i := range r.Policies {
+		r.Policies[i].Mailbox = strings.TrimSpace(r.Policies[i].Mailbox)
+		r.Policies[i].MoveTo = strings.TrimSpace(r.Policies[i].MoveTo)
+		if r.Policies[i].Action == "" {
+			r.Policies[i].Action = RetentionActionDelete
+		}
+	}
+}
+
+// Prepare fills in missing values and validates the settings
+// Use before running policies that were not saved through SetRetentionSettings
+func (r *RetentionSettings) Prepare() error {
+	r.applyDefaults()
+	return r.Validate()
+}
+
+// Validate checks the retention settings for errors
+func (r *RetentionSettings) Validate() error {
+	if r.IntervalHours < 1 {
+		return fmt.Errorf("retention interval must be at least 1 hour")
+	}
+
+	seen := make(map[string]bool)
+	for i, p := range r.Policies {
+		if err := p.Validate(); err != nil {
+			return fmt.Errorf("policy %d: %w", i+1, err)
+		}
+		key := strings.ToLower(p.Mailbox)
+		if seen[key] {
+			return fmt.Errorf("policy %d: mailbox %s already has a policy", i+1, p.Mailbox)
+		}
+		seen[key] = true
+	}
+	return nil
+}
+
+// Validate checks a single policy for errors
+func (p *RetentionPolicy) Validate() error {
+	if p.Mailbox == "" {
+		return fmt.Errorf("mailbox cannot be empty")
+	}
+	if strings.EqualFold(p.Mailbox, outboxMailbox) {
+		return fmt.Errorf("Outbox holds the delivery queue and cannot have a retention policy")
+	}
+	if p.MaxAgeDays < 0 || p.MaxMessages < 0 || p.PurgeAttachmentsOverKB < 0 || p.PurgeAttachmentsAfterDays < 0 {
+		return fmt.Errorf("retention limits cannot be negative")
+	}
+	if p.MaxAgeDays == 0 && p.MaxMessages == 0 && p.PurgeAttachmentsOverKB == 0 {
+		return fmt.Errorf("policy for %s has no rules", p.Mailbox)
+	}
+
+	switch p.Action {
+	case RetentionActionDelete:
+	case RetentionActionMove:
+		if p.MoveTo == "" {
+			return fmt.Errorf("destination mailbox is required for the move action")
+		}
+		if strings.EqualFold(p.MoveTo, p.Mailbox) {
+			return fmt.Errorf("cannot move messages into the same mailbox")
+		}
+		if strings.EqualFold(p.MoveTo, outboxMailbox) {
+			return fmt.Errorf("cannot move messages into Outbox")
+		}
+	default:
+		return fmt.Errorf("invalid retention action: %s", p.Action)
+	}
+	return nil
+}
+
+// GetRetentionSettings returns a copy of the retention settings
+// Thread-safe with read lock
+func (c *Config) GetRetentionSettings() RetentionSettings {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	settings := c.Retention
+	settings.Policies = append([]RetentionPolicy(nil), c.Retention.Policies...)
+	return settings
+}
+
+// SetRetentionSettings validates and replaces the retention settings
+// The last run time is kept
+// Thread-safe with write lock
+func (c *Config) SetRetentionSettings(settings RetentionSettings) error {
+	if err := settings.Prepare(); err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	settings.LastRun = c.Retention.LastRun
+	c.Retention = settings
+	return nil
+}
+
+// SetRetentionLastRun records when retention last ran
+// Thread-safe with write lock
+func (c *Config) SetRetentionLastRun(t time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.Retention.LastRun = t.Unix()
+}
+
+// IsRetentionDue returns true if scheduled retention is enabled and the interval has passed
+// Thread-safe with read lock
+func (c *Config) IsRetentionDue(now time.Time) bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if !c.Retention.Enabled || len(c.Retention.Policies) == 0 {
+		return false
+	}
+	interval := time.Duration(c.Retention.IntervalHours) * time.Hour
+	return now.Sub(time.Unix(c.Retention.LastRun, 0)) >= interval
+}
+
+// RunRetention applies retention policies through the local IMAP server
+// In a dry run the affected messages are listed but nothing is changed.
+// Only one run can be active at a time
+// Thread-safe
+func (sm *ServiceManager) RunRetention(policies []RetentionPolicy, dryRun bool) (*RetentionReport, error) {
+	if !sm.retentionMu.TryLock() {
+		return nil, fmt.Errorf("a retention run is already in progress")
+	}
+	defer sm.retentionMu.Unlock()
+
+	report := &RetentionReport{
+		StartedAt: time.Now().UTC(),
+		DryRun:    dryRun,
+		Items:     []RetentionItem{},
+	}
+
+	err := sm.withIMAPSession(func(s *mailclient.IMAPSession) error {
+		for _, policy := range policies {
+			if !policy.Enabled {
+				continue
+			}
+			if err := applyRetentionPolicy(s, policy, dryRun, report); err != nil {
+				log.Printf("[Retention] Policy for %s failed: %v", policy.Mailbox, err)
+				report.Errors = append(report.Errors, fmt.Sprintf("%s: %v", policy.Mailbox, err))
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, item := range report.Items {
+		if item.Error == "" {
+			report.Processed++
+			report.FreedBytes += item.FreedBytes
+		}
+	}
+	report.FinishedAt = time.Now().UTC()
+
+	log.Printf("[Retention] Run finished (dry run: %v): %d message(s), %d bytes freed, %d error(s)",
+		dryRun, report.Processed, report.FreedBytes, len(report.Errors))
+	return report, nil
+}
+
+// applyRetentionPolicy plans and, unless dryRun, executes one policy
+func applyRetentionPolicy(s *mailclient.IMAPSession, policy RetentionPolicy, dryRun bool, report *RetentionReport) error {
+	var err error
+	if dryRun {
+		_, err = s.Examine(policy.Mailbox)
+	} else {
+		_, err = s.Select(policy.Mailbox)
+	}
+	if err != nil {
+		return err
+	}
+
+	headers, err := s.AllHeaders()
+	if err != nil {
+		return err
+	}
+
+	// Newest first, so MaxMessages keeps the head of the list
+	sort.SliceStable(headers, func(i, j int) bool {
+		return messageTime(&headers[i]).After(messageTime(&headers[j]))
+	})
+
+	now := time.Now()
+	var removals, purges []RetentionItem
+	kept := 0
+	for i := range headers {
+		h := &headers[i]
+		if policy.KeepFlagged && h.HasFlag("\\Flagged") {
+			continue
+		}
+		age := now.Sub(messageTime(h))
+
+		reason := ""
+		if policy.MaxMessages > 0 && kept >= policy.MaxMessages {
+			reason = fmt.Sprintf("exceeds the %d most recent messages", policy.MaxMessages)
+		} else if policy.MaxAgeDays > 0 && age > time.Duration(policy.MaxAgeDays)*24*time.Hour {
+			reason = fmt.Sprintf("older than %d days", policy.MaxAgeDays)
+		}
+		if reason != "" {
+			item := newRetentionItem(policy.Mailbox, h, policy.Action, reason)
+			if policy.Action == RetentionActionMove {
+				item.Target = policy.MoveTo
+			} else {
+				item.FreedBytes = int64(h.Size)
+			}
+			removals = append(removals, item)
+			continue
+		}
+		kept++
+
+		threshold := policy.PurgeAttachmentsOverKB * 1024
+		if threshold > 0 && int(h.Size) > threshold && age >= time.Duration(policy.PurgeAttachmentsAfterDays)*24*time.Hour {
+			purges = append(purges, newRetentionItem(policy.Mailbox, h, RetentionActionPurgeAttachments,
+				fmt.Sprintf("attachments over %d KB after %d days", policy.PurgeAttachmentsOverKB, policy.PurgeAttachmentsAfterDays)))
+		}
+	}
+
+	// Attachments are purged one message at a time: the stripped copy is
+	// appended with the original flags and date, then the original is deleted
+	for _, item := range purges {
+		if purgeAttachments(s, policy, &item, dryRun) {
+			report.Items = append(report.Items, item)
+		}
+	}
+
+	if len(removals) > 0 && !dryRun {
+		uids := make([]uint32, 0, len(removals))
+		for _, item := range removals {
+			uids = append(uids, item.UID)
+		}
+
+		var err error
+		if policy.Action == RetentionActionMove {
+			if err = ensureMailbox(s, policy.MoveTo); err == nil {
+				err = s.MoveMessages(uids, policy.MoveTo)
+			}
+		} else {
+			err = s.DeleteMessages(uids)
+		}
+		if err != nil {
+			for i := range removals {
+				removals[i].Error = err.Error()
+				removals[i].FreedBytes = 0
+			}
+		}
+	}
+	report.Items = append(report.Items, removals...)
+	return nil
+}
+
+// purgeAttachments strips large attachments from one message
+// Returns false if the message has no attachment to purge
+func purgeAttachments(s *mailclient.IMAPSession, policy RetentionPolicy, item *RetentionItem, dryRun bool) bool {
+	header, raw, err := s.FetchRaw(item.UID)
+	if err != nil {
+		item.Error = err.Error()
+		return true
+	}
+
+	purgedAt := time.Now().UTC().Format("2006-01-02")
+	stripped, removed, err := mailclient.StripAttachments(raw, policy.PurgeAttachmentsOverKB*1024, func(att mailclient.StrippedAttachment) string {
+		name := att.Filename
+		if name == "" {
+			name = att.ContentType
+		}
+		return fmt.Sprintf("[Attachment %q (%d KB) was removed by a Tyr retention policy on %s]", name, att.Size/1024, purgedAt)
+	})
+	if err != nil {
+		item.Error = err.Error()
+		return true
+	}
+	if len(removed) == 0 {
+		return false
+	}
+
+	for _, att := range removed {
+		item.Attachments = append(item.Attachments, att.Filename)
+	}
+	item.FreedBytes = int64(len(raw) - len(stripped))
+
+	if dryRun {
+		return true
+	}
+
+	// The server stamps the copy with the current time, so the original date is
+	// kept in a header for age and order checks (see messageTime)
+	if header.OriginalDate.IsZero() && !header.InternalDate.IsZero() {
+		dateField := fmt.Sprintf("%s: %s\r\n", mailclient.OriginalDateHeader, header.InternalDate.Format(time.RFC1123Z))
+		stripped = append([]byte(dateField), stripped...)
+	}
+
+	if err := s.AppendMessage(policy.Mailbox, header.Flags, messageTime(header), stripped); err != nil {
+		item.Error = err.Error()
+		item.FreedBytes = 0
+		return true
+	}
+	if err := s.DeleteMessages([]uint32{item.UID}); err != nil {
+		// The stripped copy exists; the original stays until the next run
+		item.Error = fmt.Sprintf("stripped copy saved but original not removed: %v", err)
+		item.FreedBytes = 0
+	}
+	return true
+}
+
+// ensureMailbox creates a mailbox if it doesn't exist
+func ensureMailbox(s *mailclient.IMAPSession, name string) error {
+	mailboxes, err := s.ListMailboxes()
+	if err != nil {
+		return err
+	}
+	for _, m := range mailboxes {
+		if strings.EqualFold(m.Name, name) {
+			return nil
+		}
+	}
+	if err := s.Client().Create(name); err != nil {
+		return fmt.Errorf("failed to create mailbox %s: %w", name, err)
+	}
+	return nil
+}
+
+// newRetentionItem creates a report item from a message header
+func newRetentionItem(mailbox string, h *mailclient.MessageHeader, action, reason string) RetentionItem {
+	item := RetentionItem{
+		Mailbox:   mailbox,
+		UID:       h.UID,
+		MessageID: h.MessageID,
+		Subject:   h.Subject,
+		Date:      messageTime(h),
+		SizeBytes: int64(h.Size),
+		Action:    action,
+		Reason:    reason,
+	}
+	if len(h.From) > 0 {
+		item.From = h.From[0]
+	}
+	return item
+}
+
+// messageTime returns when a message was first stored, falling back to its Date header
+// Messages Tyr stored again carry their original internal date in a header
+func messageTime(h *mailclient.MessageHeader) time.Time {
+	if !h.OriginalDate.IsZero() {
+		return h.OriginalDate
+	}
+	if !h.InternalDate.IsZero() {
+		return h.InternalDate
+	}
+	return h.Date
+}
+
+// AppendRetentionLog adds a report to the retention log in the data directory
+// Only the last MaxRetentionLogEntries reports are kept
+func AppendRetentionLog(report *RetentionReport) error {
+	if report == nil {
+		return nil
+	}
+
+	reports, err := ReadRetentionLog(0)
+	if err != nil {
+		// A damaged log must not block cleanup, start over
+		log.Printf("[Retention] Warning: discarding unreadable retention log: %v", err)
+		reports = nil
+	}
+	reports = append(reports, *report)
+	if len(reports) > MaxRetentionLogEntries {
+		reports = reports[len(reports)-MaxRetentionLogEntries:]
+	}
+
+	if err := EnsureConfigDir(); err != nil {
+		return err
+	}
+
+	var buf strings.Builder
+	for i := range reports {
+		line, err := json.Marshal(&reports[i])
+		if err != nil {
+			return fmt.Errorf("failed to serialize retention report: %w", err)
+		}
+		buf.Write(line)
+		buf.WriteByte('\n')
+	}
+
+	// Write with user-only read/write permissions (rw-------)
+	if err := os.WriteFile(platform.GetRetentionLogPath(), []byte(buf.String()), 0600); err != nil {
+		return fmt.Errorf("failed to write retention log: %w", err)
+	}
+	return nil
+}
+
+// ReadRetentionLog returns logged retention reports, oldest first
+// A limit greater than 0 returns only the most recent reports
+func ReadRetentionLog(limit int) ([]RetentionReport, error) {
+	f, err := os.Open(platform.GetRetentionLogPath())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return []RetentionReport{}, nil
+		}
+		return nil, fmt.Errorf("failed to open retention log: %w", err)
+	}
+	defer f.Close()
+
+	reports := []RetentionReport{}
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		var report RetentionReport
+		if err := json.Unmarshal([]byte(line), &report); err != nil {
+			return nil, fmt.Errorf("failed to parse retention log: %w", err)
+		}
+		reports = append(reports, report)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read retention log: %w", err)
+	}
+
+	if limit > 0 && len(reports) > limit {
+		reports = reports[len(reports)-limit:]
+	}
+	return reports, nil
+}
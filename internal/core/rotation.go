@@ -0,0 +1,372 @@
+package core
+
+import (
+	"crypto/ed25519"
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/JB-SelfCompany/Tyr-Desktop/internal/mailclient"
+)
+
+// movedNoticeContext prefixes the signed statement so the signature can't be reused elsewhere
+const movedNoticeContext = "tyr-moved-v1"
+
+// MovedNotice announces that an identity moved to a new address
+// It is signed with the old identity key and sent from the old address before the
+// key is replaced; the signature lets contacts verify it wherever it is forwarded
+type MovedNotice struct {
+	// OldAddress is the retired mail address
+	OldAddress string
+
+	// NewAddress is the mail address the identity moved to
+	NewAddress string
+
+	// Date is the time of the move
+	Date time.Time
+
+	// Signature is the Ed25519 signature of Statement by the old key
+	Signature []byte
+}
+
+// SignMovedNotice creates a moved notice signed with the old identity key
+func SignMovedNotice(oldKey ed25519.PrivateKey, newAddress string, date time.Time) MovedNotice {
+	notice := MovedNotice{
+		OldAddress: IdentityAddress(oldKey),
+		NewAddress: NormalizeMailAddress(newAddress),
+		Date:       date.UTC().Truncate(time.Second),
+	}
+	notice.Signature = ed25519.Sign(oldKey, []byte(notice.Statement()))
+	return notice
+}
+
+// Statement returns the signed text of the notice
+func (n MovedNotice) Statement() string {
+	return fmt.Sprintf("%s\n%s\n%s\n%s", movedNoticeContext, n.OldAddress, n.NewAddress, n.Date.Format(time.RFC3339))
+}
+
+// Verify checks the signature against the public key in the old address
+func (n MovedNotice) Verify() bool {
+	local, _, _ := strings.Cut(n.OldAddress, "@")
+	pub, err := hex.DecodeString(local)
+	if err != nil || len(pub) != ed25519.PublicKeySize {
+		return false
+	}
+	return ed25519.Verify(ed25519.PublicKey(pub), []byte(n.Statement()), n.Signature)
+}
+
+// Message builds the notice mail for one recipient
+// The notice is carried in X-Tyr-Moved-* headers and repeated in the body
+func (n MovedNotice) Message(recipient string) *mailclient.OutgoingMessage {
+	signature := hex.EncodeToString(n.Signature)
+	date := n.Date.Format(time.RFC3339)
+
+	var body strings.Builder
+	fmt.Fprintf(&body, "I have moved to a new address:\n\n    %s\n\n", n.NewAddress)
+	fmt.Fprintf(&body, "My old address %s is no longer in use. Please update your address book.\n\n", n.OldAddress)
+	body.WriteString("This notice is signed with the key of my old address. The signed statement is:\n\n")
+	for _, line := range strings.Split(n.Statement(), "\n") {
+		fmt.Fprintf(&body, "    %s\n", line)
+	}
+	fmt.Fprintf(&body, "\nEd25519 signature: %s\n", signature)
+
+	return &mailclient.OutgoingMessage{
+		To:       []string{recipient},
+		Subject:  "I moved to " + n.NewAddress,
+		TextBody: body.String(),
+		Headers: map[string]string{
+			"Auto-Submitted":        "auto-generated",
+			"X-Tyr-Moved-From":      n.OldAddress,
+			"X-Tyr-Moved-To":        n.NewAddress,
+			"X-Tyr-Moved-Date":      date,
+			"X-Tyr-Moved-Signature": signature,
+		},
+	}
+}
+
+// rotatedDirName names the directory the old database and filestore are moved to
+// while mail is re-imported into the new database
+const rotatedDirName = "rotated"
+
+// PendingDelivery is a message still queued for delivery in the yggmail database
+type PendingDelivery struct {
+	// Recipient is the address the message is queued for
+	Recipient string
+
+	// Mailbox and ID identify the queued message (ID is its IMAP UID)
+	Mailbox string
+	ID      uint32
+}
+
+// RotateIdentityKey moves the database and filestore aside and creates a new
+// database holding newKey. Mail is not copied; export it over IMAP before and
+// import it after (see ExportMail and ImportMail).
+// Deliveries still queued in the old database are returned, since the new
+// identity can't send them. Call RestoreRotatedDatabase to undo, or
+// DiscardRotatedDatabase once the mail is imported.
+// The service must be closed
+func RotateIdentityKey(dbPath string, newKey ed25519.PrivateKey) ([]PendingDelivery, error) {
+	if _, err := ReadIdentityKey(dbPath); err != nil {
+		return nil, err
+	}
+	pending, err := PendingDeliveries(dbPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read outgoing queue: %w", err)
+	}
+
+	dir := filepath.Dir(dbPath)
+	aside := RotatedDatabaseDir(dbPath)
+	if err := os.RemoveAll(aside); err != nil {
+		return nil, fmt.Errorf("failed to clear %s: %w", aside, err)
+	}
+	if err := os.MkdirAll(aside, 0700); err != nil {
+		return nil, fmt.Errorf("failed to create %s: %w", aside, err)
+	}
+	if err := moveStorageEntries(dir, aside); err != nil {
+		moveStorageEntries(aside, dir)
+		return nil, fmt.Errorf("failed to move old database aside: %w", err)
+	}
+
+	if err := WriteIdentityKey(dbPath, newKey, false); err != nil {
+		RestoreRotatedDatabase(dbPath)
+		return nil, err
+	}
+	return pending, nil
+}
+
+// RestoreRotatedDatabase puts back the database and filestore moved aside by
+// RotateIdentityKey, discarding the new database. The service must be closed
+func RestoreRotatedDatabase(dbPath string) error {
+	dir := filepath.Dir(dbPath)
+	aside := RotatedDatabaseDir(dbPath)
+	if _, err := os.Stat(aside); err != nil {
+		return fmt.Errorf("no rotated database to restore: %w", err)
+	}
+
+	for _, name := range storageVaultEntries {
+		if err := os.RemoveAll(filepath.Join(dir, name)); err != nil {
+			return fmt.Errorf("failed to remove new %s: %w", name, err)
+		}
+	}
+	if err := moveStorageEntries(aside, dir); err != nil {
+		return fmt.Errorf("failed to restore old database: %w", err)
+	}
+	return os.RemoveAll(aside)
+}
+
+// RotatedDatabaseDir returns the directory RotateIdentityKey moves the old database to
+func RotatedDatabaseDir(dbPath string) string {
+	return filepath.Join(filepath.Dir(dbPath), rotatedDirName)
+}
+
+// DiscardRotatedDatabase removes the old database and filestore moved aside by RotateIdentityKey
+func DiscardRotatedDatabase(dbPath string) error {
+	return os.RemoveAll(RotatedDatabaseDir(dbPath))
+}
+
+// moveStorageEntries moves the database and filestore files from src to dst
+func moveStorageEntries(src, dst string) error {
+	for _, name := range storageVaultEntries {
+		from := filepath.Join(src, name)
+		if _, err := os.Stat(from); os.IsNotExist(err) {
+			continue
+		}
+		if err := os.Rename(from, filepath.Join(dst, name)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// PendingDeliveries lists the deliveries queued in the yggmail database
+// The database may be open by the running service; it is only read
+func PendingDeliveries(dbPath string) ([]PendingDelivery, error) {
+	db, err := sql.Open("sqlite3", "file:"+dbPath+"?mode=ro&_busy_timeout=5000")
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+
+	rows, err := db.Query(`SELECT rcpt, mailbox, id FROM queue ORDER BY mailbox, id`)
+	if err != nil {
+		if strings.Contains(err.Error(), "no such table") {
+			return []PendingDelivery{}, nil
+		}
+		return nil, err
+	}
+	defer rows.Close()
+
+	pending := []PendingDelivery{}
+	for rows.Next() {
+		var p PendingDelivery
+		if err := rows.Scan(&p.Recipient, &p.Mailbox, &p.ID); err != nil {
+			return nil, err
+		}
+		pending = append(pending, p)
+	}
+	return pending, rows.Err()
+}
+
+// WaitForQueue waits until the yggmail queue is empty or the timeout expires
+// Returns the deliveries still pending
+func WaitForQueue(dbPath string, timeout time.Duration) ([]PendingDelivery, error) {
+	deadline := time.Now().Add(timeout)
+	for {
+		pending, err := PendingDeliveries(dbPath)
+		if err != nil || len(pending) == 0 || time.Now().After(deadline) {
+			return pending, err
+		}
+		time.Sleep(time.Second)
+	}
+}
+
+// MailExport is mail exported over IMAP into a staging directory for re-import
+type MailExport struct {
+	// Dir holds one file per message
+	Dir string
+
+	// Mailboxes are the exported mailboxes in listing order
+	Mailboxes []ExportedMailbox
+}
+
+// ExportedMailbox is one exported mailbox
+type ExportedMailbox struct {
+	Name     string
+	Messages []ExportedMessage
+}
+
+// ExportedMessage is one exported message
+type ExportedMessage struct {
+	// File is the raw message path below the export directory
+	File string
+
+	// UID is the message UID in the exported database
+	UID uint32
+
+	// Subject is kept to report undelivered messages
+	Subject string
+
+	// Flags and Date are restored on import
+	Flags []string
+	Date  time.Time
+}
+
+// Count returns the number of exported messages
+func (e *MailExport) Count() int {
+	n := 0
+	for _, mbox := range e.Mailboxes {
+		n += len(mbox.Messages)
+	}
+	return n
+}
+
+// Subject returns the subject of an exported message, or "" if unknown
+func (e *MailExport) Subject(mailbox string, uid uint32) string {
+	for _, mbox := range e.Mailboxes {
+		if mbox.Name != mailbox {
+			continue
+		}
+		for _, msg := range mbox.Messages {
+			if msg.UID == uid {
+				return msg.Subject
+			}
+		}
+	}
+	return ""
+}
+
+// ExportMail copies every message of every mailbox into dir over IMAP
+// Messages are written one file each, so mailboxes of any size fit.
+// The dates are kept in the OriginalDateHeader, since the server stamps appended
+// copies with the current time
+// Thread-safe
+func (sm *ServiceManager) ExportMail(dir string) (*MailExport, error) {
+	if err := os.RemoveAll(dir); err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, err
+	}
+
+	export := &MailExport{Dir: dir}
+	err := sm.withIMAPSession(func(s *mailclient.IMAPSession) error {
+		mailboxes, err := s.ListMailboxes()
+		if err != nil {
+			return err
+		}
+
+		for i, info := range mailboxes {
+			if _, err := s.Examine(info.Name); err != nil {
+				return err
+			}
+			headers, err := s.AllHeaders()
+			if err != nil {
+				return err
+			}
+
+			mbox := ExportedMailbox{Name: info.Name}
+			for _, h := range headers {
+				header, raw, err := s.FetchRaw(h.UID)
+				if err != nil {
+					return fmt.Errorf("failed to export message %d from %s: %w", h.UID, info.Name, err)
+				}
+				if header.OriginalDate.IsZero() && !header.InternalDate.IsZero() {
+					dateField := fmt.Sprintf("%s: %s\r\n", mailclient.OriginalDateHeader, header.InternalDate.Format(time.RFC1123Z))
+					raw = append([]byte(dateField), raw...)
+				}
+
+				file := fmt.Sprintf("%d-%d.eml", i, header.UID)
+				if err := os.WriteFile(filepath.Join(dir, file), raw, 0600); err != nil {
+					return fmt.Errorf("failed to write exported message: %w", err)
+				}
+				mbox.Messages = append(mbox.Messages, ExportedMessage{
+					File:    file,
+					UID:     header.UID,
+					Subject: header.Subject,
+					Flags:   header.Flags,
+					Date:    messageTime(header),
+				})
+			}
+			export.Mailboxes = append(export.Mailboxes, mbox)
+		}
+		return nil
+	})
+	if err != nil {
+		os.RemoveAll(dir)
+		return nil, fmt.Errorf("failed to export mail: %w", err)
+	}
+	return export, nil
+}
+
+// ImportMail appends exported mail into the mailboxes of the running service,
+// creating missing mailboxes. Returns the number of messages imported
+// Thread-safe
+func (sm *ServiceManager) ImportMail(export *MailExport) (int, error) {
+	imported := 0
+	err := sm.withIMAPSession(func(s *mailclient.IMAPSession) error {
+		for _, mbox := range export.Mailboxes {
+			if err := ensureMailbox(s, mbox.Name); err != nil {
+				return err
+			}
+			for _, msg := range mbox.Messages {
+				raw, err := os.ReadFile(filepath.Join(export.Dir, msg.File))
+				if err != nil {
+					return fmt.Errorf("failed to read exported message: %w", err)
+				}
+				if err := s.AppendMessage(mbox.Name, msg.Flags, msg.Date, raw); err != nil {
+					return err
+				}
+				imported++
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return imported, fmt.Errorf("failed to import mail: %w", err)
+	}
+	return imported, nil
+}
@@ -1,15 +1,14 @@
 package core
 
 import (
-	"crypto/aes"
-	"crypto/cipher"
-	"crypto/rand"
+	"bytes"
 	"crypto/sha256"
 	"fmt"
-	"io"
+	"log"
 	"os"
 	"path/filepath"
 	"runtime"
+	"sync"
 
 	"github.com/zalando/go-keyring"
 	"golang.org/x/crypto/pbkdf2"
@@ -142,9 +141,9 @@ func DeriveKey(password string, salt []byte, iterations int) []byte {
 	return pbkdf2.Key([]byte(password), salt, iterations, AESKeySize, sha256.New)
 }
 
-// EncryptAESGCM encrypts plaintext using AES-256-GCM with PBKDF2 key derivation
-// Returns ciphertext in format: [32-byte salt] + [12-byte nonce] + [encrypted data + 16-byte tag]
-// Uses 100,000 PBKDF2 iterations for strong key derivation
+// EncryptAESGCM encrypts plaintext using AES-256-GCM with a password-derived key
+// Returns data in the versioned container format (see encryptContainer) using
+// Argon2id with the current parameters (see SetKDFParams)
 // Thread-safe and cryptographically secure
 func EncryptAESGCM(plaintext, password string) ([]byte, error) {
 	// Input validation
@@ -155,58 +154,40 @@ func EncryptAESGCM(plaintext, password string) ([]byte, error) {
 		return nil, fmt.Errorf("password cannot be empty")
 	}
 
-	// Generate random salt (32 bytes)
-	salt := make([]byte, SaltSize)
-	if _, err := io.ReadFull(rand.Reader, salt); err != nil {
-		return nil, fmt.Errorf("failed to generate salt: %w", err)
-	}
-
-	// Derive encryption key from password using PBKDF2
-	key := DeriveKey(password, salt, PBKDF2Iterations)
-
-	// Create AES cipher
-	block, err := aes.NewCipher(key)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create AES cipher: %w", err)
-	}
+	return encryptContainer([]byte(plaintext), password, CurrentKDFParams())
+}
 
-	// Create GCM mode
-	gcm, err := cipher.NewGCM(block)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create GCM: %w", err)
+// DecryptAESGCM decrypts data produced by EncryptAESGCM
+// Reads both the versioned container format and the legacy headerless format:
+// [32-byte salt] + [12-byte nonce] + [encrypted data + 16-byte tag] with PBKDF2
+// Thread-safe and validates authentication tag
+func DecryptAESGCM(ciphertext []byte, password string) (string, error) {
+	if password == "" {
+		return "", fmt.Errorf("password cannot be empty")
 	}
 
-	// Generate random nonce (12 bytes for GCM)
-	nonce := make([]byte, GCMNonceSize)
-	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
-		return nil, fmt.Errorf("failed to generate nonce: %w", err)
+	if isContainer(ciphertext) {
+		plaintext, _, err := decryptContainer(ciphertext, password)
+		if err == nil {
+			return string(plaintext), nil
+		}
+		// A legacy file may start with the magic bytes by chance
+		if legacy, legacyErr := decryptLegacyAESGCM(ciphertext, password); legacyErr == nil {
+			return legacy, nil
+		}
+		return "", err
 	}
-
-	// Encrypt plaintext
-	// GCM automatically appends 16-byte authentication tag
-	ciphertext := gcm.Seal(nil, nonce, []byte(plaintext), nil)
-
-	// Construct final output: salt + nonce + ciphertext
-	result := make([]byte, 0, SaltSize+GCMNonceSize+len(ciphertext))
-	result = append(result, salt...)
-	result = append(result, nonce...)
-	result = append(result, ciphertext...)
-
-	return result, nil
+	return decryptLegacyAESGCM(ciphertext, password)
 }
 
-// DecryptAESGCM decrypts ciphertext using AES-256-GCM with PBKDF2 key derivation
+// decryptLegacyAESGCM decrypts the headerless format written before versioned containers
 // Expects ciphertext in format: [32-byte salt] + [12-byte nonce] + [encrypted data + 16-byte tag]
 // Uses 100,000 PBKDF2 iterations to derive key from password
-// Thread-safe and validates authentication tag
-func DecryptAESGCM(ciphertext []byte, password string) (string, error) {
+func decryptLegacyAESGCM(ciphertext []byte, password string) (string, error) {
 	// Input validation
 	if len(ciphertext) < SaltSize+GCMNonceSize+16 {
 		return "", fmt.Errorf("ciphertext too small (corrupted or invalid)")
 	}
-	if password == "" {
-		return "", fmt.Errorf("password cannot be empty")
-	}
 
 	// Extract salt, nonce, and encrypted data
 	salt := ciphertext[:SaltSize]
@@ -216,16 +197,9 @@ func DecryptAESGCM(ciphertext []byte, password string) (string, error) {
 	// Derive decryption key from password using PBKDF2
 	key := DeriveKey(password, salt, PBKDF2Iterations)
 
-	// Create AES cipher
-	block, err := aes.NewCipher(key)
+	gcm, err := newGCM(key)
 	if err != nil {
-		return "", fmt.Errorf("failed to create AES cipher: %w", err)
-	}
-
-	// Create GCM mode
-	gcm, err := cipher.NewGCM(block)
-	if err != nil {
-		return "", fmt.Errorf("failed to create GCM: %w", err)
+		return "", err
 	}
 
 	// Decrypt and verify authentication tag
@@ -257,10 +231,37 @@ func getMachineID() (string, error) {
 	return "", fmt.Errorf("failed to read machine ID (not found in /etc/machine-id or /var/lib/dbus/machine-id)")
 }
 
+// machineIDPassword caches the decrypted machine ID password file
+// The file is decrypted (and upgraded if needed) when its contents change,
+// not on every read: the key derivation is deliberately slow
+var machineIDPassword struct {
+	mu        sync.Mutex
+	encrypted []byte
+	password  string
+}
+
+// cacheMachineIDPassword remembers the decrypted password for the file contents
+func cacheMachineIDPassword(encrypted []byte, password string) {
+	machineIDPassword.mu.Lock()
+	defer machineIDPassword.mu.Unlock()
+
+	machineIDPassword.encrypted = encrypted
+	machineIDPassword.password = password
+}
+
 // saveFallbackPassword stores password in encrypted file (Linux keyring fallback)
+// Uses the master passphrase if enabled, the machine ID otherwise
+func saveFallbackPassword(service, username, password string) error {
+	if IsMasterPassphraseEnabled() {
+		return saveMasterPassword(password)
+	}
+	return saveMachineIDPassword(password)
+}
+
+// saveMachineIDPassword stores password in a file encrypted with the machine ID
 // Uses machine ID as encryption key to prevent plaintext storage
 // File is stored in config directory with 0600 permissions
-func saveFallbackPassword(service, username, password string) error {
+func saveMachineIDPassword(password string) error {
 	// Get machine ID for encryption key
 	machineID, err := getMachineID()
 	if err != nil {
@@ -290,12 +291,17 @@ func saveFallbackPassword(service, username, password string) error {
 		return fmt.Errorf("failed to write fallback password file: %w", err)
 	}
 
+	cacheMachineIDPassword(encrypted, password)
 	return nil
 }
 
 // getFallbackPassword retrieves password from encrypted file (Linux keyring fallback)
-// Uses machine ID as decryption key
+// Uses the master passphrase unlocked this session if enabled, the machine ID otherwise
 func getFallbackPassword(service, username string) (string, error) {
+	if IsMasterPassphraseEnabled() {
+		return getMasterPassword()
+	}
+
 	// Get machine ID for decryption key
 	machineID, err := getMachineID()
 	if err != nil {
@@ -318,12 +324,32 @@ func getFallbackPassword(service, username string) (string, error) {
 		return "", fmt.Errorf("failed to read fallback password file: %w", err)
 	}
 
+	machineIDPassword.mu.Lock()
+	defer machineIDPassword.mu.Unlock()
+	if machineIDPassword.encrypted != nil && bytes.Equal(encrypted, machineIDPassword.encrypted) {
+		return machineIDPassword.password, nil
+	}
+
 	// Decrypt password using machine ID as key
 	password, err := DecryptAESGCM(encrypted, machineID)
 	if err != nil {
 		return "", fmt.Errorf("failed to decrypt fallback password: %w", err)
 	}
 
+	// Re-encrypt files written with the legacy format or a weaker key derivation
+	if NeedsEncryptionUpgrade(encrypted) {
+		if upgraded, err := EncryptAESGCM(password, machineID); err != nil {
+			log.Printf("[Security] Warning: failed to upgrade fallback password encryption: %v", err)
+		} else if err := os.WriteFile(fallbackPath, upgraded, 0600); err != nil {
+			log.Printf("[Security] Warning: failed to write upgraded fallback password file: %v", err)
+		} else {
+			encrypted = upgraded
+			log.Printf("[Security] Upgraded fallback password file encryption")
+		}
+	}
+
+	machineIDPassword.encrypted = encrypted
+	machineIDPassword.password = password
 	return password, nil
 }
 
@@ -335,7 +361,14 @@ func deleteFallbackPassword(service, username string) error {
 		return fmt.Errorf("failed to get config directory: %w", err)
 	}
 
+	// Delete master passphrase file
+	LockPasswordStore()
+	if err := os.Remove(filepath.Join(configDir, masterPasswordFile)); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to delete master passphrase file: %w", err)
+	}
+
 	// Delete fallback file
+	cacheMachineIDPassword(nil, "")
 	fallbackPath := filepath.Join(configDir, fallbackPasswordFile)
 	if err := os.Remove(fallbackPath); err != nil {
 		if os.IsNotExist(err) {
@@ -0,0 +1,525 @@
+package core
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/JB-SelfCompany/Tyr-Desktop/internal/mailclient"
+	"github.com/JB-SelfCompany/Tyr-Desktop/internal/platform"
+)
+
+// Sender filter actions
+const (
+	// SenderActionDeliver leaves the message in the inbox
+	SenderActionDeliver = "deliver"
+
+	// SenderActionJunk moves the message to the junk mailbox
+	SenderActionJunk = "junk"
+
+	// SenderActionDelete deletes the message
+	SenderActionDelete = "delete"
+
+	// SenderActionQuarantine moves the message to the quarantine mailbox
+	SenderActionQuarantine = "quarantine"
+
+	// SenderActionRestore moves a filed message back to the inbox
+	SenderActionRestore = "restore"
+)
+
+// Sender list names used by SetSenderListing
+const (
+	// SenderListBlocked marks a sender as blocked
+	SenderListBlocked = "blocked"
+
+	// SenderListAllowed marks a sender as allowed
+	SenderListAllowed = "allowed"
+
+	// SenderListNone removes a sender from both lists
+	SenderListNone = ""
+)
+
+const (
+	// DefaultJunkMailbox is where messages from blocked senders are moved
+	DefaultJunkMailbox = "Junk"
+
+	// DefaultQuarantineMailbox is where messages from unknown or flooding senders are moved
+	DefaultQuarantineMailbox = "Quarantine"
+
+	// DefaultSenderRateWindowMinutes is the default per-sender rate limit window
+	DefaultSenderRateWindowMinutes = 60
+
+	// maxSenderRateWindowMinutes is the longest allowed rate limit window (1 day)
+	maxSenderRateWindowMinutes = 24 * 60
+
+	// maxSenderFilterLogSize is the size at which the decision log is trimmed to its newer half
+	maxSenderFilterLogSize = 512 * 1024
+)
+
+// senderKeyRegex matches a sender key (hex-encoded ed25519 public key)
+var senderKeyRegex = regexp.MustCompile(`^[0-9a-f]{64}$`)
+
+// SenderKey returns the public key of a yggmail address, or "" if it isn't one
+// Accepts a bare key, an address, or "Name <address>"
+func SenderKey(address string) string {
+	address = NormalizeMailAddress(ExtractMailAddress(address))
+	key, _, _ := strings.Cut(address, "@")
+	if !senderKeyRegex.MatchString(key) {
+		return ""
+	}
+	return key
+}
+
+// SenderFilterSettings contains sender filtering keyed on the sender's public key
+// Allowed senders always reach the inbox; blocked senders are moved to junk or deleted.
+// In allowlist-only mode, senders that are neither allowed nor contacts are quarantined
+type SenderFilterSettings struct {
+	// Enabled turns on sender filtering
+	Enabled bool `toml:"enabled"`
+
+	// AllowlistOnly quarantines mail from senders not in the allowlist or contacts
+	AllowlistOnly bool `toml:"allowlist_only"`
+
+	// BlockAction is what happens to mail from blocked senders (junk or delete)
+	BlockAction string `toml:"block_action"`
+
+	// JunkMailbox receives mail from blocked senders
+	JunkMailbox string `toml:"junk_mailbox"`
+
+	// QuarantineMailbox receives mail from unknown or rate-limited senders
+	QuarantineMailbox string `toml:"quarantine_mailbox"`
+
+	// RateLimit is the maximum number of messages per sender per window (0 for unlimited)
+	RateLimit int `toml:"rate_limit"`
+
+	// RateWindowMinutes is the rate limit window
+	RateWindowMinutes int `toml:"rate_window_minutes"`
+
+	// Blocked contains the public keys of blocked senders
+	Blocked []string `toml:"blocked,omitempty"`
+
+	// Allowed contains the public keys of allowed senders
+	Allowed []string `toml:"allowed,omitempty"`
+}
+
+// applyDefaults fills in missing sender filter values
+func (s *SenderFilterSettings) applyDefaults() {
+	s.BlockAction = strings.ToLower(strings.TrimSpace(s.BlockAction))
+	if s.BlockAction == "" {
+		s.BlockAction = SenderActionJunk
+	}
+	s.JunkMailbox = strings.TrimSpace(s.JunkMailbox)
+	if s.JunkMailbox == "" {
+		s.JunkMailbox = DefaultJunkMailbox
+	}
+	s.QuarantineMailbox = strings.TrimSpace(s.QuarantineMailbox)
+	if s.QuarantineMailbox == "" {
+		s.QuarantineMailbox = DefaultQuarantineMailbox
+	}
+	if s.RateWindowMinutes <= 0 {
+		s.RateWindowMinutes = DefaultSenderRateWindowMinutes
+	}
+	s.Blocked = normalizeSenderKeys(s.Blocked)
+	s.Allowed = normalizeSenderKeys(s.Allowed)
+}
+
+// normalizeSenderKeys converts addresses to keys and drops duplicates
+// Entries that aren't keys are kept as-is so Validate can report them
+func normalizeSenderKeys(entries []string) []string {
+	seen := make(map[string]bool, len(entries))
+	keys := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		key := SenderKey(entry)
+		if key == "" {
+			key = strings.TrimSpace(entry)
+		}
+		if key == "" || seen[key] {
+			continue
+		}
+		seen[key] = true
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+// Validate checks the sender filter settings for errors
+func (s *SenderFilterSettings) Validate() error {
+	if s.BlockAction != SenderActionJunk && s.BlockAction != SenderActionDelete {
+		return fmt.Errorf("invalid block action %q (expected junk or delete)", s.BlockAction)
+	}
+	for _, name := range []string{s.JunkMailbox, s.QuarantineMailbox} {
+		if strings.EqualFold(name, "INBOX") || strings.EqualFold(name, outboxMailbox) {
+			return fmt.Errorf("mailbox %s can't be used for filtered mail", name)
+		}
+	}
+	if s.RateLimit < 0 {
+		return fmt.Errorf("rate limit must not be negative")
+	}
+	if s.RateWindowMinutes < 1 || s.RateWindowMinutes > maxSenderRateWindowMinutes {
+		return fmt.Errorf("rate limit window must be between 1 and %d minutes", maxSenderRateWindowMinutes)
+	}
+
+	blocked := make(map[string]bool, len(s.Blocked))
+	for _, key := range s.Blocked {
+		if !senderKeyRegex.MatchString(key) {
+			return fmt.Errorf("invalid blocked sender: %s", key)
+		}
+		blocked[key] = true
+	}
+	for _, key := range s.Allowed {
+		if !senderKeyRegex.MatchString(key) {
+			return fmt.Errorf("invalid allowed sender: %s", key)
+		}
+		if blocked[key] {
+			return fmt.Errorf("sender %s is both blocked and allowed", ShortMailAddress(key))
+		}
+	}
+	return nil
+}
+
+// GetSenderFilterSettings returns a copy of the sender filter settings
+// Thread-safe with read lock
+func (c *Config) GetSenderFilterSettings() SenderFilterSettings {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	settings := c.SenderFilter
+	settings.Blocked = append([]string(nil), c.SenderFilter.Blocked...)
+	settings.Allowed = append([]string(nil), c.SenderFilter.Allowed...)
+	return settings
+}
+
+// SetSenderFilterSettings validates and replaces the sender filter settings
+// Thread-safe with write lock
+func (c *Config) SetSenderFilterSettings(settings SenderFilterSettings) error {
+	settings.Blocked = append([]string(nil), settings.Blocked...)
+	settings.Allowed = append([]string(nil), settings.Allowed...)
+	settings.applyDefaults()
+	if err := settings.Validate(); err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.SenderFilter = settings
+	return nil
+}
+
+// SetSenderListing puts a sender on the blocklist or allowlist, or removes it from both
+// Returns the sender key
+// Thread-safe with write lock
+func (c *Config) SetSenderListing(address, list string) (string, error) {
+	key := SenderKey(address)
+	if key == "" {
+		return "", fmt.Errorf("invalid sender address: %s", address)
+	}
+	if list != SenderListBlocked && list != SenderListAllowed && list != SenderListNone {
+		return "", fmt.Errorf("invalid sender list %q", list)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	remove := func(keys []string) []string {
+		kept := keys[:0]
+		for _, k := range keys {
+			if k != key {
+				kept = append(kept, k)
+			}
+		}
+		return kept
+	}
+	c.SenderFilter.Blocked = remove(c.SenderFilter.Blocked)
+	c.SenderFilter.Allowed = remove(c.SenderFilter.Allowed)
+
+	switch list {
+	case SenderListBlocked:
+		c.SenderFilter.Blocked = append(c.SenderFilter.Blocked, key)
+	case SenderListAllowed:
+		c.SenderFilter.Allowed = append(c.SenderFilter.Allowed, key)
+	}
+	return key, nil
+}
+
+// SenderDecision describes what the sender filter does with a message
+type SenderDecision struct {
+	// Sender is the sender address
+	Sender string
+
+	// Key is the sender's public key
+	Key string
+
+	// Action is deliver, junk, delete, quarantine or restore
+	Action string
+
+	// Reason explains the decision
+	Reason string
+
+	// Target is the mailbox the message is moved to (empty for deliver and delete)
+	Target string
+}
+
+// Filed returns true if the message is moved out of the inbox or deleted
+func (d SenderDecision) Filed() bool {
+	return d.Action != SenderActionDeliver && d.Action != SenderActionRestore
+}
+
+// SenderFilterLogEntry is one logged filtering decision
+type SenderFilterLogEntry struct {
+	Time    time.Time `json:"time"`
+	Sender  string    `json:"sender"`
+	Mailbox string    `json:"mailbox"`
+	UID     uint32    `json:"uid"`
+	Subject string    `json:"subject,omitempty"`
+	Action  string    `json:"action"`
+	Reason  string    `json:"reason"`
+	Target  string    `json:"target,omitempty"`
+	Error   string    `json:"error,omitempty"`
+}
+
+// SenderFilter files new mail according to the sender block and allow lists
+// Keeps recent arrival times per sender in memory for rate limiting
+type SenderFilter struct {
+	config   *Config
+	contacts *ContactStore
+
+	// recent maps sender key to arrival times within the rate window
+	recent map[string][]time.Time
+
+	// Mutex protects recent
+	mu sync.Mutex
+
+	// logMu serializes writes to the decision log
+	logMu sync.Mutex
+}
+
+// NewSenderFilter creates a sender filter
+// contacts may be nil; allowlist-only mode then accepts only allowed senders
+func NewSenderFilter(config *Config, contacts *ContactStore) *SenderFilter {
+	return &SenderFilter{
+		config:   config,
+		contacts: contacts,
+		recent:   make(map[string][]time.Time),
+	}
+}
+
+// Enabled returns true if sender filtering is turned on
+func (f *SenderFilter) Enabled() bool {
+	return f.config.GetSenderFilterSettings().Enabled
+}
+
+// Evaluate decides what to do with a new message from a sender and records it for rate limiting
+// Returns a deliver decision when filtering is disabled
+// Thread-safe
+func (f *SenderFilter) Evaluate(from string) SenderDecision {
+	settings := f.config.GetSenderFilterSettings()
+	sender := NormalizeMailAddress(ExtractMailAddress(from))
+	decision := SenderDecision{Sender: sender, Key: SenderKey(sender), Action: SenderActionDeliver}
+
+	if !settings.Enabled {
+		decision.Reason = "filtering is disabled"
+		return decision
+	}
+	if decision.Key == "" {
+		decision.Reason = "sender has no public key"
+		return decision
+	}
+
+	for _, key := range settings.Allowed {
+		if key == decision.Key {
+			decision.Reason = "sender is allowed"
+			return decision
+		}
+	}
+	for _, key := range settings.Blocked {
+		if key == decision.Key {
+			decision.Action = settings.BlockAction
+			decision.Reason = "sender is blocked"
+			if decision.Action == SenderActionJunk {
+				decision.Target = settings.JunkMailbox
+			}
+			return decision
+		}
+	}
+
+	if settings.RateLimit > 0 && f.overRateLimit(decision.Key, settings, time.Now()) {
+		decision.Action = SenderActionQuarantine
+		decision.Reason = fmt.Sprintf("sender exceeded %d messages in %d minutes", settings.RateLimit, settings.RateWindowMinutes)
+		decision.Target = settings.QuarantineMailbox
+		return decision
+	}
+
+	if settings.AllowlistOnly {
+		if f.contacts != nil {
+			if _, ok := f.contacts.Get(sender); ok {
+				decision.Reason = "sender is a contact"
+				return decision
+			}
+		}
+		decision.Action = SenderActionQuarantine
+		decision.Reason = "sender is not in the allowlist"
+		decision.Target = settings.QuarantineMailbox
+		return decision
+	}
+
+	decision.Reason = "no rule matched"
+	return decision
+}
+
+// overRateLimit records an arrival and reports whether the sender exceeded the limit
+func (f *SenderFilter) overRateLimit(key string, settings SenderFilterSettings, now time.Time) bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	window := time.Duration(settings.RateWindowMinutes) * time.Minute
+	prune := func(times []time.Time) []time.Time {
+		kept := times[:0]
+		for _, t := range times {
+			if now.Sub(t) < window {
+				kept = append(kept, t)
+			}
+		}
+		return kept
+	}
+
+	// Forget idle senders once the map grows
+	if len(f.recent) > 1000 {
+		for k, times := range f.recent {
+			if times = prune(times); len(times) == 0 {
+				delete(f.recent, k)
+			} else {
+				f.recent[k] = times
+			}
+		}
+	}
+
+	times := append(prune(f.recent[key]), now)
+	f.recent[key] = times
+	return len(times) > settings.RateLimit
+}
+
+// Apply carries out a decision for a message over local IMAP and logs it
+// Deliver decisions are only logged
+// Thread-safe
+func (f *SenderFilter) Apply(sm *ServiceManager, decision SenderDecision, mailbox string, uid uint32) error {
+	entry := SenderFilterLogEntry{
+		Time:    time.Now(),
+		Sender:  decision.Sender,
+		Mailbox: mailbox,
+		UID:     uid,
+		Action:  decision.Action,
+		Reason:  decision.Reason,
+		Target:  decision.Target,
+	}
+
+	err := sm.withIMAPSession(func(s *mailclient.IMAPSession) error {
+		if _, err := s.Select(mailbox); err != nil {
+			return err
+		}
+		if headers, err := s.FetchHeaders([]uint32{uid}); err == nil && len(headers) > 0 {
+			entry.Subject = headers[0].Subject
+		}
+
+		switch decision.Action {
+		case SenderActionDeliver:
+			return nil
+		case SenderActionDelete:
+			return s.DeleteMessages([]uint32{uid})
+		default:
+			if decision.Target == "" || strings.EqualFold(decision.Target, mailbox) {
+				return nil
+			}
+			if err := ensureMailbox(s, decision.Target); err != nil {
+				return err
+			}
+			return s.MoveMessages([]uint32{uid}, decision.Target)
+		}
+	})
+	if err != nil {
+		entry.Error = err.Error()
+	}
+
+	f.appendLog(entry)
+	if decision.Action != SenderActionDeliver {
+		log.Printf("[SenderFilter] %s message %d from %s: %s", decision.Action, uid, ShortMailAddress(decision.Sender), decision.Reason)
+	}
+	return err
+}
+
+// appendLog adds a decision to the log, trimming it when it grows too large
+func (f *SenderFilter) appendLog(entry SenderFilterLogEntry) {
+	f.logMu.Lock()
+	defer f.logMu.Unlock()
+
+	line, err := json.Marshal(&entry)
+	if err != nil {
+		log.Printf("[SenderFilter] Failed to serialize log entry: %v", err)
+		return
+	}
+	if err := EnsureConfigDir(); err != nil {
+		log.Printf("[SenderFilter] Failed to write log: %v", err)
+		return
+	}
+
+	path := platform.GetSenderFilterLogPath()
+	if info, err := os.Stat(path); err == nil && info.Size() > maxSenderFilterLogSize {
+		if data, err := os.ReadFile(path); err == nil {
+			data = data[len(data)/2:]
+			if i := strings.IndexByte(string(data), '\n'); i >= 0 {
+				data = data[i+1:]
+			}
+			_ = os.WriteFile(path, data, 0600)
+		}
+	}
+
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0600)
+	if err != nil {
+		log.Printf("[SenderFilter] Failed to open log: %v", err)
+		return
+	}
+	defer file.Close()
+	file.Write(append(line, '\n'))
+}
+
+// ReadSenderFilterLog returns logged filtering decisions, oldest first
+// A limit greater than 0 returns only the most recent decisions
+func ReadSenderFilterLog(limit int) ([]SenderFilterLogEntry, error) {
+	file, err := os.Open(platform.GetSenderFilterLogPath())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return []SenderFilterLogEntry{}, nil
+		}
+		return nil, fmt.Errorf("failed to open sender filter log: %w", err)
+	}
+	defer file.Close()
+
+	entries := []SenderFilterLogEntry{}
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		var entry SenderFilterLogEntry
+		if err := json.Unmarshal([]byte(line), &entry); err != nil {
+			// Skip a line cut short by trimming or a crash
+			continue
+		}
+		entries = append(entries, entry)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read sender filter log: %w", err)
+	}
+
+	if limit > 0 && len(entries) > limit {
+		entries = entries[len(entries)-limit:]
+	}
+	return entries, nil
+}
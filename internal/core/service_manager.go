@@ -20,6 +20,9 @@ type ServiceManager struct {
 	// Autoconfiguration server
 	autoconfigServer *autoconfig.Server
 
+	// TLS listeners serving the mail servers to the LAN (nil when disabled)
+	lanProxy *LANProxy
+
 	// Configuration reference
 	config *Config
 
@@ -43,6 +46,15 @@ type ServiceManager struct {
 	maxRestartCount  int
 	restartResetTime time.Duration
 	lastRestart      time.Time
+
+	// retentionMu prevents overlapping retention runs
+	retentionMu sync.Mutex
+
+	// archiveMu prevents overlapping mailbox exports and imports
+	archiveMu sync.Mutex
+
+	// vault seals encrypted storage whenever the database is released (may be nil)
+	vault *StorageVault
 }
 
 // ServiceManagerOptions contains optional configuration for the service manager
@@ -132,6 +144,7 @@ func (sm *ServiceManager) Initialize() error {
 			log.Printf("Warning: failed to stop existing autoconfig server: %v", err)
 		}
 	}
+	sm.stopLANProxy()
 
 	// Create new yggmail service
 	service, err := yggmail.New(
@@ -189,8 +202,15 @@ func (sm *ServiceManager) Initialize() error {
 	sm.yggmailService = service
 	sm.eventChans = service.GetEventChannels()
 
+	// Start the LAN TLS listeners before autoconfig so it advertises them
+	// The service stays usable on loopback if they fail
+	if err := sm.startLANProxy(); err != nil {
+		log.Printf("Warning: failed to start LAN access: %v", err)
+	}
+
 	// Initialize and start autoconfiguration server
 	if err := sm.startAutoconfigServer(); err != nil {
+		sm.stopLANProxy()
 		service.Close()
 		return fmt.Errorf("failed to start autoconfig server: %w", err)
 	}
@@ -221,8 +241,8 @@ func (sm *ServiceManager) Start() error {
 		return fmt.Errorf("service not initialized, call Initialize() first")
 	}
 
-	// Get enabled peers from configuration
-	peers := sm.config.GetEnabledPeers()
+	// Get enabled peers from configuration (rewritten for the proxy if enabled)
+	peers := sm.config.GetConnectionPeers()
 
 	// Allow starting with no peers - service can run locally without network peers
 	// This is useful for testing or when user wants to configure peers after start
@@ -290,6 +310,7 @@ func (sm *ServiceManager) Stop() error {
 			log.Printf("Warning: failed to stop autoconfig server: %v", err)
 		}
 	}
+	sm.stopLANProxy()
 	sm.running = false
 	sm.mu.Unlock()
 
@@ -337,6 +358,7 @@ func (sm *ServiceManager) SoftStop() error {
 			log.Printf("Warning: failed to stop autoconfig server: %v", err)
 		}
 	}
+	sm.stopLANProxy()
 	sm.running = false
 	sm.mu.Unlock()
 
@@ -397,6 +419,7 @@ func (sm *ServiceManager) CloseService() error {
 		}
 		sm.autoconfigServer = nil
 	}
+	sm.stopLANProxy()
 
 	// Close yggmail service to release database
 	if sm.yggmailService != nil {
@@ -406,9 +429,24 @@ func (sm *ServiceManager) CloseService() error {
 		sm.yggmailService = nil
 	}
 
+	// The database is consistent now, save it to encrypted storage
+	if sm.vault != nil && sm.vault.IsUnlocked() {
+		if err := sm.vault.Seal(); err != nil {
+			log.Printf("Warning: failed to seal encrypted storage: %v", err)
+		}
+	}
+
 	return nil
 }
 
+// SetStorageVault sets the encrypted storage sealed when the service is closed
+// Thread-safe with write lock
+func (sm *ServiceManager) SetStorageVault(vault *StorageVault) {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+	sm.vault = vault
+}
+
 // Shutdown performs a complete shutdown of the service manager
 // Stops monitoring, closes service, and waits for all goroutines
 // Uses SoftStop to prevent ErrClosed errors in logs
@@ -459,6 +497,9 @@ func (sm *ServiceManager) Shutdown() error {
 		sm.autoconfigServer = nil
 	}
 
+	// Stop LAN TLS listeners
+	sm.stopLANProxy()
+
 	// Close service
 	if sm.yggmailService != nil {
 		if err := sm.yggmailService.Close(); err != nil {
@@ -724,21 +765,36 @@ func (sm *ServiceManager) handleServiceError() {
 }
 
 // startAutoconfigServer initializes and starts the autoconfiguration HTTP server
+// Caller must hold sm.mu
 func (sm *ServiceManager) startAutoconfigServer() error {
-	// Parse SMTP and IMAP addresses
-	smtpHost, smtpPort := autoconfig.ParseSMTPAddress(sm.config.ServiceSettings.SMTPAddress)
-	imapHost, imapPort := autoconfig.ParseIMAPAddress(sm.config.ServiceSettings.IMAPAddress)
+	// Advertise the LAN TLS listeners if running, otherwise the loopback servers
+	endpoints := sm.clientEndpoints()
+
+	// Optionally serve the mail address QR code on the autoconfig page
+	qrAddress := ""
+	if sm.config.ServiceSettings.AutoconfigServeQR && sm.yggmailService != nil {
+		qrAddress = sm.yggmailService.GetMailAddress()
+	}
+
+	// A random port makes the server harder to find for other local software
+	listenAddr := autoconfig.DefaultListenAddr
+	if sm.config.ServiceSettings.AutoconfigRandomPort {
+		listenAddr = autoconfig.RandomPortListenAddr
+	}
 
 	// Create autoconfig server
 	server, err := autoconfig.NewServer(autoconfig.ServerConfig{
 		MailDomain:  "yggmail",
-		SMTPHost:    smtpHost,
-		SMTPPort:    smtpPort,
-		IMAPHost:    imapHost,
-		IMAPPort:    imapPort,
-		ListenAddr:  "127.0.0.1:8080",
+		SMTPHost:    endpoints.SMTPHost,
+		SMTPPort:    endpoints.SMTPPort,
+		IMAPHost:    endpoints.IMAPHost,
+		IMAPPort:    endpoints.IMAPPort,
+		SocketType:  endpoints.AutoconfigSocketType(),
+		Fingerprint: endpoints.Fingerprint,
+		ListenAddr:  listenAddr,
 		DisplayName: "Yggmail",
 		ShortName:   "Yggmail",
+		QRAddress:   qrAddress,
 	})
 	if err != nil {
 		return fmt.Errorf("failed to create autoconfig server: %w", err)
@@ -753,17 +809,139 @@ func (sm *ServiceManager) startAutoconfigServer() error {
 	return nil
 }
 
+// startLANProxy starts the LAN TLS listeners if LAN access is enabled
+// Caller must hold sm.mu
+func (sm *ServiceManager) startLANProxy() error {
+	settings := sm.config.GetLANAccessSettings()
+	if !settings.Enabled {
+		return nil
+	}
+
+	proxy, err := NewLANProxy(settings, sm.config.ServiceSettings.SMTPAddress, sm.config.ServiceSettings.IMAPAddress)
+	if err != nil {
+		return err
+	}
+	if err := proxy.Start(); err != nil {
+		return err
+	}
+
+	sm.lanProxy = proxy
+	log.Printf("[LANAccess] Certificate fingerprint (SHA-256): %s", proxy.Endpoints().Fingerprint)
+	return nil
+}
+
+// stopLANProxy stops the LAN TLS listeners if running
+// Caller must hold sm.mu
+func (sm *ServiceManager) stopLANProxy() {
+	if sm.lanProxy == nil {
+		return
+	}
+	if err := sm.lanProxy.Stop(); err != nil {
+		log.Printf("Warning: failed to stop LAN access: %v", err)
+	}
+	sm.lanProxy = nil
+}
+
+// clientEndpoints returns the endpoints mail clients should use
+// Caller must hold sm.mu
+func (sm *ServiceManager) clientEndpoints() ClientEndpoints {
+	if sm.lanProxy != nil && sm.lanProxy.IsRunning() {
+		return sm.lanProxy.Endpoints()
+	}
+	return loopbackEndpoints(sm.config.ServiceSettings)
+}
+
+// GetClientEndpoints returns the IMAP/SMTP endpoints, security and certificate
+// fingerprint mail clients should use
+// Thread-safe with read lock
+func (sm *ServiceManager) GetClientEndpoints() ClientEndpoints {
+	sm.mu.RLock()
+	defer sm.mu.RUnlock()
+	return sm.clientEndpoints()
+}
+
+// IsLANAccessRunning returns true if the LAN TLS listeners are open
+func (sm *ServiceManager) IsLANAccessRunning() bool {
+	sm.mu.RLock()
+	defer sm.mu.RUnlock()
+	return sm.lanProxy != nil && sm.lanProxy.IsRunning()
+}
+
+// ApplyLANAccessSettings restarts the LAN TLS listeners with the current settings
+// and refreshes the autoconfig server so it advertises them
+// Does nothing until the service is initialized
+// Thread-safe with write lock
+func (sm *ServiceManager) ApplyLANAccessSettings() error {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+
+	if sm.yggmailService == nil {
+		return nil
+	}
+
+	sm.stopLANProxy()
+	lanErr := sm.startLANProxy()
+
+	if sm.autoconfigServer != nil && sm.autoconfigServer.IsRunning() {
+		if err := sm.autoconfigServer.Stop(); err != nil {
+			log.Printf("Warning: failed to stop autoconfig server: %v", err)
+		}
+		if err := sm.startAutoconfigServer(); err != nil {
+			log.Printf("Warning: failed to restart autoconfig server: %v", err)
+		}
+	}
+	return lanErr
+}
+
 // GetAutoconfigURL returns the URL for the autoconfiguration server
 // Returns empty string if autoconfig server is not running
 func (sm *ServiceManager) GetAutoconfigURL() string {
 	sm.mu.RLock()
 	defer sm.mu.RUnlock()
 
-	if sm.autoconfigServer == nil || !sm.autoconfigServer.IsRunning() {
+	if sm.autoconfigServer == nil {
 		return ""
 	}
 
-	return "http://" + sm.autoconfigServer.GetListenAddr()
+	return sm.autoconfigServer.GetURL()
+}
+
+// SetAutoconfigServeQR enables or disables the mail address QR code on the autoconfig page
+// Applies to the running autoconfig server immediately
+// Thread-safe with read lock
+func (sm *ServiceManager) SetAutoconfigServeQR(enabled bool) {
+	sm.config.SetAutoconfigServeQR(enabled)
+
+	sm.mu.RLock()
+	defer sm.mu.RUnlock()
+
+	if sm.autoconfigServer == nil || sm.yggmailService == nil {
+		return
+	}
+
+	if enabled {
+		sm.autoconfigServer.SetQRAddress(sm.yggmailService.GetMailAddress())
+	} else {
+		sm.autoconfigServer.SetQRAddress("")
+	}
+}
+
+// SetAutoconfigRandomPort switches the autoconfig server between 127.0.0.1:8080
+// and a random port. A running server is restarted on the new address
+// Thread-safe with write lock
+func (sm *ServiceManager) SetAutoconfigRandomPort(enabled bool) error {
+	sm.config.SetAutoconfigRandomPort(enabled)
+
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+
+	if sm.autoconfigServer == nil || !sm.autoconfigServer.IsRunning() {
+		return nil
+	}
+	if err := sm.autoconfigServer.Stop(); err != nil {
+		return fmt.Errorf("failed to stop autoconfig server: %w", err)
+	}
+	return sm.startAutoconfigServer()
 }
 
 // IsAutoconfigRunning returns true if the autoconfig server is running
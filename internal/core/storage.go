@@ -5,6 +5,8 @@ import (
 	"io/fs"
 	"os"
 	"path/filepath"
+
+	"github.com/JB-SelfCompany/Tyr-Desktop/internal/platform"
 )
 
 // StorageStats contains information about storage usage
@@ -14,7 +16,12 @@ type StorageStats struct {
 	// FilesSizeMB is the total size of all stored message files in megabytes
 	FilesSizeMB float64
 	// TotalSizeMB is the total storage usage in megabytes
+	// While encrypted storage is locked this is the size of the encrypted archive
 	TotalSizeMB float64
+	// Encrypted indicates the database and filestore are encrypted at rest
+	Encrypted bool
+	// EncryptedSizeMB is the size of the encrypted archive in megabytes
+	EncryptedSizeMB float64
 }
 
 // GetStorageStats returns storage usage statistics
@@ -71,5 +78,14 @@ func GetStorageStats(config *Config) (*StorageStats, error) {
 	stats.FilesSizeMB = float64(totalFilesSize) / (1024 * 1024)
 	stats.TotalSizeMB = stats.DatabaseSizeMB + stats.FilesSizeMB
 
+	// The working copy only exists while encrypted storage is unlocked
+	if info, err := os.Stat(platform.GetStorageVaultPath()); err == nil {
+		stats.Encrypted = true
+		stats.EncryptedSizeMB = float64(info.Size()) / (1024 * 1024)
+		if stats.TotalSizeMB == 0 {
+			stats.TotalSizeMB = stats.EncryptedSizeMB
+		}
+	}
+
 	return stats, nil
 }
@@ -0,0 +1,1042 @@
+package core
+
+import (
+	"archive/tar"
+	"bufio"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"database/sql"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/JB-SelfCompany/Tyr-Desktop/internal/platform"
+)
+
+const (
+	// MinStoragePassphraseLength is the minimum passphrase length for encrypted storage
+	MinStoragePassphraseLength = 8
+
+	// storageVaultMagic identifies an encrypted storage file
+	storageVaultMagic = "TYRVAULT"
+
+	// storageVaultVersion is the current encrypted storage format version
+	storageVaultVersion = 1
+
+	// storageVaultChunkSize is the plaintext size of one encrypted chunk
+	storageVaultChunkSize = 64 * 1024
+
+	// storageVaultFinalChunk marks the last chunk in its length prefix
+	storageVaultFinalChunk = 1 << 31
+
+	// storageSnapshotSuffix names the directory a checkpoint snapshot is staged in,
+	// next to the working copy
+	storageSnapshotSuffix = "-snapshot"
+)
+
+// storageVaultEntries are the database and filestore files moved into encrypted storage
+var storageVaultEntries = []string{"yggmail.db", "yggmail.db-wal", "yggmail.db-shm", "yggmail.db-journal", "filestore"}
+
+var (
+	// ErrStorageLocked is returned when encrypted storage is needed but not unlocked
+	ErrStorageLocked = errors.New("encrypted storage is locked")
+
+	// ErrStorageWorkDirOnDisk is returned when the working copy would be written to
+	// persistent disk and the user hasn't allowed it
+	ErrStorageWorkDirOnDisk = errors.New("decrypted working copy would be stored on disk")
+
+	// ErrWrongStoragePassphrase is returned when the storage passphrase is incorrect
+	ErrWrongStoragePassphrase = errors.New("incorrect storage passphrase")
+)
+
+// storageVaultHeader describes how the data key is derived and wrapped
+// The data key encrypts the archive; the passphrase only wraps the data key,
+// so changing the passphrase rewrites the header but not the archive
+type storageVaultHeader struct {
+	KDFParams
+	Salt       []byte `json:"salt"`
+	KeyNonce   []byte `json:"key_nonce"`
+	WrappedKey []byte `json:"wrapped_key"`
+}
+
+// StorageVault keeps the mail database and filestore encrypted at rest
+//
+// The database and filestore are stored as one encrypted archive in the data directory.
+// Unlocking decrypts them into a private working directory that the service uses
+// while the application runs; sealing writes the working copy back to the archive.
+// The working copy is plaintext, so it is placed in the per-user runtime directory
+// where available and removed on Lock. While the service runs, Checkpoint seals a
+// snapshot periodically and after mail changes, so a crash loses little. A working
+// copy left behind by a crash is removed on the next start
+type StorageVault struct {
+	// path is the encrypted archive location
+	path string
+
+	// dataDir holds the plaintext database when encryption is off
+	dataDir string
+
+	// workDir holds the decrypted working copy while unlocked
+	workDir string
+
+	// key is the data key (nil while locked)
+	key []byte
+
+	// header is the current archive header
+	header storageVaultHeader
+
+	// sealedAt is when the archive was last written from the working copy
+	sealedAt time.Time
+
+	// changes signals Checkpoint schedulers that mail changed
+	changes chan struct{}
+
+	// Mutex serializes sealing, unlocking and migration
+	mu sync.Mutex
+}
+
+// NewStorageVault creates a storage vault for the data directory
+func NewStorageVault() *StorageVault {
+	return &StorageVault{
+		path:    platform.GetStorageVaultPath(),
+		dataDir: platform.GetDataDir(),
+		workDir: platform.GetStorageWorkDir(),
+		changes: make(chan struct{}, 1),
+	}
+}
+
+// IsEnabled returns true if the database and filestore are encrypted at rest
+func (v *StorageVault) IsEnabled() bool {
+	_, err := os.Stat(v.path)
+	return err == nil
+}
+
+// IsUnlocked returns true if the working copy is decrypted and in use
+// Thread-safe
+func (v *StorageVault) IsUnlocked() bool {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	return v.key != nil
+}
+
+// Size returns the size of the encrypted archive in bytes (0 if encryption is off)
+func (v *StorageVault) Size() int64 {
+	info, err := os.Stat(v.path)
+	if err != nil {
+		return 0
+	}
+	return info.Size()
+}
+
+// WorkDir returns the directory holding the decrypted working copy
+func (v *StorageVault) WorkDir() string {
+	return v.workDir
+}
+
+// WorkDirOnDisk returns true if the decrypted working copy is written to persistent
+// disk (Windows and macOS). Plaintext mail and the identity key then stay on disk
+// while unlocked, and after a crash until the next start
+func (v *StorageVault) WorkDirOnDisk() bool {
+	return !platform.IsStorageWorkDirInMemory()
+}
+
+// RemoveStaleWorkDir deletes a plaintext working copy left behind by a crash
+// Changes made after the last checkpoint are lost; the archive holds the rest.
+// Called on startup, before storage is unlocked
+// Thread-safe
+func (v *StorageVault) RemoveStaleWorkDir() {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	if v.key != nil {
+		return
+	}
+	for _, dir := range []string{v.workDir, v.workDir + storageSnapshotSuffix} {
+		if _, err := os.Stat(dir); err != nil {
+			continue
+		}
+		log.Printf("[Storage] Removing plaintext working copy left by an unclean shutdown: %s", dir)
+		if err := os.RemoveAll(dir); err != nil {
+			log.Printf("[Storage] Warning: failed to remove stale working copy: %v", err)
+		}
+	}
+}
+
+// Unlock decrypts the database and filestore into the working directory
+// and points the database path there. allowOnDisk must be true where the
+// working copy can't be kept in memory, otherwise ErrStorageWorkDirOnDisk is returned
+// Thread-safe
+func (v *StorageVault) Unlock(passphrase string, allowOnDisk bool) error {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	if v.key != nil {
+		return nil
+	}
+	if v.WorkDirOnDisk() && !allowOnDisk {
+		return ErrStorageWorkDirOnDisk
+	}
+
+	header, _, err := readStorageVaultHeader(v.path)
+	if err != nil {
+		return err
+	}
+	key, err := header.unwrap(passphrase)
+	if err != nil {
+		return err
+	}
+
+	if err := v.extract(key, v.workDir); err != nil {
+		os.RemoveAll(v.workDir)
+		return err
+	}
+
+	v.key = key
+	v.header = *header
+	v.sealedAt = time.Now()
+	platform.SetDatabaseDir(v.workDir)
+	if v.WorkDirOnDisk() {
+		log.Printf("[Storage] Warning: decrypted working copy is on disk at %s while unlocked", v.workDir)
+	}
+	log.Printf("[Storage] Encrypted storage unlocked")
+	return nil
+}
+
+// MarkChanged tells the checkpoint scheduler that mail changed
+// Never blocks; repeated calls before the next checkpoint are merged
+func (v *StorageVault) MarkChanged() {
+	select {
+	case v.changes <- struct{}{}:
+	default:
+	}
+}
+
+// Changes returns the channel signalled by MarkChanged
+func (v *StorageVault) Changes() <-chan struct{} {
+	return v.changes
+}
+
+// Checkpoint seals a snapshot of the working copy while the service may be running
+// The database is copied with VACUUM INTO, which gives a consistent snapshot even
+// while it is being written, and the archive is replaced atomically.
+// Does nothing if the working copy hasn't changed since the last seal
+// Thread-safe
+func (v *StorageVault) Checkpoint() error {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	if v.key == nil {
+		return ErrStorageLocked
+	}
+	if !v.changedSinceSealUnsafe() {
+		return nil
+	}
+
+	// Writes that land while the snapshot is taken are picked up by the next checkpoint
+	started := time.Now()
+	snapshot := v.workDir + storageSnapshotSuffix
+	defer os.RemoveAll(snapshot)
+
+	if err := snapshotWorkDir(v.workDir, snapshot); err != nil {
+		return fmt.Errorf("failed to snapshot working copy: %w", err)
+	}
+	if err := writeStorageVault(v.path, &v.header, v.key, snapshot); err != nil {
+		return err
+	}
+	v.sealedAt = started
+	return nil
+}
+
+// Seal encrypts the working copy into the archive
+// The database must not be open, call it after the service is closed
+// Thread-safe
+func (v *StorageVault) Seal() error {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	if v.key == nil {
+		return ErrStorageLocked
+	}
+	return v.sealUnsafe()
+}
+
+// Lock seals the working copy, removes it and forgets the data key
+// The database must not be open, call it after the service is closed
+// Thread-safe
+func (v *StorageVault) Lock() error {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	if v.key == nil {
+		return nil
+	}
+	if err := v.sealUnsafe(); err != nil {
+		return err
+	}
+	if err := os.RemoveAll(v.workDir); err != nil {
+		log.Printf("[Storage] Warning: failed to remove working copy: %v", err)
+	}
+
+	v.forgetKeyUnsafe()
+	platform.SetDatabaseDir("")
+	log.Printf("[Storage] Encrypted storage locked")
+	return nil
+}
+
+// Enable encrypts the plaintext database and filestore with a passphrase
+// The archive is verified by decrypting it into the working directory before
+// the plaintext files are deleted; storage is left unlocked.
+// The service must be closed. Note that deleted plaintext may remain recoverable on disk.
+// allowOnDisk must be true where the working copy can't be kept in memory
+// Thread-safe
+func (v *StorageVault) Enable(passphrase string, allowOnDisk bool) error {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	if v.IsEnabled() {
+		return fmt.Errorf("storage is already encrypted")
+	}
+	if len(passphrase) < MinStoragePassphraseLength {
+		return fmt.Errorf("passphrase must be at least %d characters", MinStoragePassphraseLength)
+	}
+	if v.WorkDirOnDisk() && !allowOnDisk {
+		return ErrStorageWorkDirOnDisk
+	}
+
+	key := make([]byte, AESKeySize)
+	if _, err := io.ReadFull(rand.Reader, key); err != nil {
+		return fmt.Errorf("failed to generate data key: %w", err)
+	}
+	header, err := newStorageVaultHeader(passphrase, key)
+	if err != nil {
+		return err
+	}
+
+	if err := writeStorageVault(v.path, header, key, v.dataDir); err != nil {
+		return err
+	}
+	if err := v.extract(key, v.workDir); err != nil {
+		os.Remove(v.path)
+		os.RemoveAll(v.workDir)
+		return fmt.Errorf("failed to verify encrypted storage: %w", err)
+	}
+
+	for _, name := range storageVaultEntries {
+		if err := os.RemoveAll(filepath.Join(v.dataDir, name)); err != nil {
+			log.Printf("[Storage] Warning: failed to remove plaintext %s: %v", name, err)
+		}
+	}
+
+	v.key = key
+	v.header = *header
+	v.sealedAt = time.Now()
+	platform.SetDatabaseDir(v.workDir)
+	log.Printf("[Storage] Encrypted storage enabled")
+	return nil
+}
+
+// Disable decrypts storage back into the data directory and removes the archive
+// Storage must be unlocked and the service must be closed
+// Thread-safe
+func (v *StorageVault) Disable(passphrase string) error {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	if v.key == nil {
+		return ErrStorageLocked
+	}
+	if _, err := v.header.unwrap(passphrase); err != nil {
+		return err
+	}
+
+	for _, name := range storageVaultEntries {
+		src := filepath.Join(v.workDir, name)
+		if _, err := os.Stat(src); os.IsNotExist(err) {
+			continue
+		}
+		if err := copyTree(src, filepath.Join(v.dataDir, name)); err != nil {
+			return fmt.Errorf("failed to decrypt %s: %w", name, err)
+		}
+	}
+
+	if err := os.Remove(v.path); err != nil {
+		return fmt.Errorf("failed to remove encrypted storage: %w", err)
+	}
+	if err := os.RemoveAll(v.workDir); err != nil {
+		log.Printf("[Storage] Warning: failed to remove working copy: %v", err)
+	}
+
+	v.forgetKeyUnsafe()
+	platform.SetDatabaseDir("")
+	log.Printf("[Storage] Encrypted storage disabled")
+	return nil
+}
+
+// ChangePassphrase re-wraps the data key with a new passphrase
+// Only the header is rewritten; the service may keep running
+// Thread-safe
+func (v *StorageVault) ChangePassphrase(oldPassphrase, newPassphrase string) error {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	if len(newPassphrase) < MinStoragePassphraseLength {
+		return fmt.Errorf("passphrase must be at least %d characters", MinStoragePassphraseLength)
+	}
+
+	header, offset, err := readStorageVaultHeader(v.path)
+	if err != nil {
+		return err
+	}
+	key, err := header.unwrap(oldPassphrase)
+	if err != nil {
+		return err
+	}
+	newHeader, err := newStorageVaultHeader(newPassphrase, key)
+	if err != nil {
+		return err
+	}
+
+	src, err := os.Open(v.path)
+	if err != nil {
+		return fmt.Errorf("failed to open encrypted storage: %w", err)
+	}
+	defer src.Close()
+	if _, err := src.Seek(offset, io.SeekStart); err != nil {
+		return fmt.Errorf("failed to read encrypted storage: %w", err)
+	}
+
+	err = writeFileAtomic(v.path, func(w io.Writer) error {
+		if err := writeStorageVaultHeader(w, newHeader); err != nil {
+			return err
+		}
+		_, err := io.Copy(w, src)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	if v.key != nil {
+		v.header = *newHeader
+	}
+	log.Printf("[Storage] Storage passphrase changed")
+	return nil
+}
+
+// sealUnsafe writes the working copy to the archive (caller must hold the lock)
+func (v *StorageVault) sealUnsafe() error {
+	started := time.Now()
+	if err := writeStorageVault(v.path, &v.header, v.key, v.workDir); err != nil {
+		return err
+	}
+	v.sealedAt = started
+	return nil
+}
+
+// changedSinceSealUnsafe reports whether any database or filestore entry in the
+// working copy was modified since the last seal (caller must hold the lock)
+func (v *StorageVault) changedSinceSealUnsafe() bool {
+	changed := false
+	for _, name := range storageVaultEntries {
+		filepath.WalkDir(filepath.Join(v.workDir, name), func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return nil
+			}
+			if info, err := d.Info(); err == nil && !info.ModTime().Before(v.sealedAt) {
+				changed = true
+				return filepath.SkipAll
+			}
+			return nil
+		})
+		if changed {
+			return true
+		}
+	}
+	return false
+}
+
+// forgetKeyUnsafe clears the data key from memory (caller must hold the lock)
+func (v *StorageVault) forgetKeyUnsafe() {
+	for i := range v.key {
+		v.key[i] = 0
+	}
+	v.key = nil
+	v.header = storageVaultHeader{}
+	v.sealedAt = time.Time{}
+}
+
+// extract decrypts the archive into dir, replacing its contents
+func (v *StorageVault) extract(key []byte, dir string) error {
+	if err := os.RemoveAll(dir); err != nil {
+		return fmt.Errorf("failed to clear working directory: %w", err)
+	}
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return fmt.Errorf("failed to create working directory: %w", err)
+	}
+
+	f, err := os.Open(v.path)
+	if err != nil {
+		return fmt.Errorf("failed to open encrypted storage: %w", err)
+	}
+	defer f.Close()
+
+	br := bufio.NewReader(f)
+	if _, _, err := readStorageVaultHeaderFrom(br); err != nil {
+		return err
+	}
+	cr, err := newVaultChunkReader(br, key)
+	if err != nil {
+		return err
+	}
+
+	tr := tar.NewReader(cr)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read encrypted storage: %w", err)
+		}
+
+		name := filepath.Clean(filepath.FromSlash(hdr.Name))
+		if filepath.IsAbs(name) || name == ".." || strings.HasPrefix(name, ".."+string(filepath.Separator)) {
+			return fmt.Errorf("invalid path in encrypted storage: %s", hdr.Name)
+		}
+		target := filepath.Join(dir, name)
+
+		switch hdr.Typeflag {
+		case tar.TypeDir:
+			if err := os.MkdirAll(target, 0700); err != nil {
+				return err
+			}
+		case tar.TypeReg:
+			if err := os.MkdirAll(filepath.Dir(target), 0700); err != nil {
+				return err
+			}
+			out, err := os.OpenFile(target, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0600)
+			if err != nil {
+				return err
+			}
+			_, err = io.Copy(out, tr)
+			if cerr := out.Close(); err == nil {
+				err = cerr
+			}
+			if err != nil {
+				return fmt.Errorf("failed to decrypt %s: %w", hdr.Name, err)
+			}
+			os.Chtimes(target, hdr.ModTime, hdr.ModTime)
+		}
+	}
+
+	// Reading to the end verifies the final chunk, catching a truncated archive
+	if _, err := io.Copy(io.Discard, cr); err != nil {
+		return fmt.Errorf("failed to read encrypted storage: %w", err)
+	}
+	return nil
+}
+
+// newStorageVaultHeader derives a key from the passphrase with the current
+// key derivation parameters and wraps the data key with it
+func newStorageVaultHeader(passphrase string, key []byte) (*storageVaultHeader, error) {
+	header := &storageVaultHeader{
+		KDFParams: CurrentKDFParams(),
+		Salt:      make([]byte, SaltSize),
+		KeyNonce:  make([]byte, GCMNonceSize),
+	}
+	if _, err := io.ReadFull(rand.Reader, header.Salt); err != nil {
+		return nil, fmt.Errorf("failed to generate salt: %w", err)
+	}
+	if _, err := io.ReadFull(rand.Reader, header.KeyNonce); err != nil {
+		return nil, fmt.Errorf("failed to generate nonce: %w", err)
+	}
+
+	kek, err := header.deriveKey(passphrase, header.Salt)
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := newGCM(kek)
+	if err != nil {
+		return nil, err
+	}
+	header.WrappedKey = gcm.Seal(nil, header.KeyNonce, key, []byte(storageVaultMagic))
+	return header, nil
+}
+
+// unwrap derives the passphrase key and decrypts the data key
+func (h *storageVaultHeader) unwrap(passphrase string) ([]byte, error) {
+	if err := h.checkBounds(); err != nil {
+		return nil, err
+	}
+	kek, err := h.deriveKey(passphrase, h.Salt)
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := newGCM(kek)
+	if err != nil {
+		return nil, err
+	}
+	if len(h.KeyNonce) != gcm.NonceSize() {
+		return nil, fmt.Errorf("invalid encrypted storage header")
+	}
+	key, err := gcm.Open(nil, h.KeyNonce, h.WrappedKey, []byte(storageVaultMagic))
+	if err != nil {
+		return nil, ErrWrongStoragePassphrase
+	}
+	return key, nil
+}
+
+// newGCM creates an AES-256-GCM cipher for a key
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create AES cipher: %w", err)
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create GCM: %w", err)
+	}
+	return gcm, nil
+}
+
+// readStorageVaultHeader reads the header of an archive file
+// Returns the header and the offset of the encrypted body
+func readStorageVaultHeader(path string) (*storageVaultHeader, int64, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, 0, fmt.Errorf("storage is not encrypted")
+		}
+		return nil, 0, fmt.Errorf("failed to open encrypted storage: %w", err)
+	}
+	defer f.Close()
+
+	return readStorageVaultHeaderFrom(f)
+}
+
+// readStorageVaultHeaderFrom reads and validates the magic, version and header
+// Returns the header and the number of bytes read
+func readStorageVaultHeaderFrom(r io.Reader) (*storageVaultHeader, int64, error) {
+	prefix := make([]byte, len(storageVaultMagic)+1+4)
+	if _, err := io.ReadFull(r, prefix); err != nil {
+		return nil, 0, fmt.Errorf("failed to read encrypted storage header: %w", err)
+	}
+	if string(prefix[:len(storageVaultMagic)]) != storageVaultMagic {
+		return nil, 0, fmt.Errorf("not an encrypted storage file")
+	}
+	if version := prefix[len(storageVaultMagic)]; version != storageVaultVersion {
+		return nil, 0, fmt.Errorf("unsupported encrypted storage version %d", version)
+	}
+
+	size := binary.BigEndian.Uint32(prefix[len(storageVaultMagic)+1:])
+	if size == 0 || size > 64*1024 {
+		return nil, 0, fmt.Errorf("invalid encrypted storage header")
+	}
+	data := make([]byte, size)
+	if _, err := io.ReadFull(r, data); err != nil {
+		return nil, 0, fmt.Errorf("failed to read encrypted storage header: %w", err)
+	}
+
+	var header storageVaultHeader
+	if err := json.Unmarshal(data, &header); err != nil {
+		return nil, 0, fmt.Errorf("invalid encrypted storage header: %w", err)
+	}
+	return &header, int64(len(prefix)) + int64(size), nil
+}
+
+// writeStorageVaultHeader writes the magic, version and header
+func writeStorageVaultHeader(w io.Writer, h *storageVaultHeader) error {
+	data, err := json.Marshal(h)
+	if err != nil {
+		return fmt.Errorf("failed to serialize encrypted storage header: %w", err)
+	}
+	prefix := make([]byte, len(storageVaultMagic)+1+4)
+	copy(prefix, storageVaultMagic)
+	prefix[len(storageVaultMagic)] = storageVaultVersion
+	binary.BigEndian.PutUint32(prefix[len(storageVaultMagic)+1:], uint32(len(data)))
+	if _, err := w.Write(prefix); err != nil {
+		return err
+	}
+	_, err = w.Write(data)
+	return err
+}
+
+// writeStorageVault encrypts the database and filestore under root into an archive
+func writeStorageVault(path string, header *storageVaultHeader, key []byte, root string) error {
+	if err := EnsureConfigDir(); err != nil {
+		return err
+	}
+
+	err := writeFileAtomic(path, func(w io.Writer) error {
+		if err := writeStorageVaultHeader(w, header); err != nil {
+			return err
+		}
+		cw, err := newVaultChunkWriter(w, key)
+		if err != nil {
+			return err
+		}
+		tw := tar.NewWriter(cw)
+		for _, name := range storageVaultEntries {
+			if err := addTarTree(tw, root, name); err != nil {
+				return err
+			}
+		}
+		if err := tw.Close(); err != nil {
+			return err
+		}
+		return cw.Close()
+	})
+	if err != nil {
+		return fmt.Errorf("failed to write encrypted storage: %w", err)
+	}
+	return nil
+}
+
+// addTarTree adds a file or directory below root to the archive (missing entries are skipped)
+func addTarTree(tw *tar.Writer, root, name string) error {
+	return filepath.WalkDir(filepath.Join(root, name), func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil
+			}
+			return err
+		}
+		if !d.IsDir() && !d.Type().IsRegular() {
+			return nil
+		}
+
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			return err
+		}
+		hdr, err := tar.FileInfoHeader(info, "")
+		if err != nil {
+			return err
+		}
+		hdr.Name = filepath.ToSlash(rel)
+		if err := tw.WriteHeader(hdr); err != nil {
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+
+		f, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		_, err = io.CopyN(tw, f, info.Size())
+		return err
+	})
+}
+
+// writeFileAtomic writes a file through a temporary file that replaces it when complete
+func writeFileAtomic(path string, write func(w io.Writer) error) error {
+	tmp := path + ".tmp"
+	f, err := os.OpenFile(tmp, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+
+	bw := bufio.NewWriterSize(f, storageVaultChunkSize)
+	err = write(bw)
+	if err == nil {
+		err = bw.Flush()
+	}
+	if err == nil {
+		err = f.Sync()
+	}
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// snapshotWorkDir copies a consistent snapshot of the database and filestore in src to dst
+// Filestore files are copied before and after the database snapshot, so files the
+// snapshot references are present even if they were added or removed meanwhile
+func snapshotWorkDir(src, dst string) error {
+	if err := os.RemoveAll(dst); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(dst, 0700); err != nil {
+		return err
+	}
+
+	filestore := filepath.Join(src, "filestore")
+	if err := copyMissing(filestore, filepath.Join(dst, "filestore")); err != nil {
+		return err
+	}
+	db := filepath.Join(src, "yggmail.db")
+	if _, err := os.Stat(db); err == nil {
+		if err := vacuumInto(db, filepath.Join(dst, "yggmail.db")); err != nil {
+			return err
+		}
+	}
+	return copyMissing(filestore, filepath.Join(dst, "filestore"))
+}
+
+// vacuumInto writes a consistent copy of an SQLite database that may be in use
+func vacuumInto(src, dst string) error {
+	db, err := sql.Open("sqlite3", "file:"+src+"?mode=ro&_busy_timeout=5000")
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	if _, err := db.Exec(`VACUUM INTO ?`, dst); err != nil {
+		return fmt.Errorf("failed to copy database: %w", err)
+	}
+	return nil
+}
+
+// copyMissing copies the files under src that don't exist under dst yet
+// Filestore files are never modified in place, so existing copies are current
+func copyMissing(src, dst string) error {
+	return filepath.WalkDir(src, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			// Missing source, or a file removed while walking
+			if os.IsNotExist(err) {
+				return nil
+			}
+			return err
+		}
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dst, rel)
+		if d.IsDir() {
+			return os.MkdirAll(target, 0700)
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		if _, err := os.Stat(target); err == nil {
+			return nil
+		}
+		if err := copyTree(path, target); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return nil
+	})
+}
+
+// copyTree copies a file or directory, replacing the destination
+func copyTree(src, dst string) error {
+	if err := os.RemoveAll(dst); err != nil {
+		return err
+	}
+	return filepath.WalkDir(src, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dst, rel)
+		if d.IsDir() {
+			return os.MkdirAll(target, 0700)
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+
+		in, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer in.Close()
+		out, err := os.OpenFile(target, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0600)
+		if err != nil {
+			return err
+		}
+		if _, err := io.Copy(out, in); err != nil {
+			out.Close()
+			return err
+		}
+		return out.Close()
+	})
+}
+
+// vaultChunkWriter encrypts a stream in fixed-size AES-GCM chunks
+// Each chunk is [4-byte length | final flag][12-byte nonce][ciphertext]; the chunk
+// index and final flag are authenticated so chunks can't be reordered or dropped
+type vaultChunkWriter struct {
+	w     io.Writer
+	gcm   cipher.AEAD
+	buf   []byte
+	index uint64
+}
+
+// newVaultChunkWriter creates a chunk writer with the data key
+func newVaultChunkWriter(w io.Writer, key []byte) (*vaultChunkWriter, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	return &vaultChunkWriter{w: w, gcm: gcm, buf: make([]byte, 0, storageVaultChunkSize)}, nil
+}
+
+// Write buffers data and encrypts every full chunk
+func (c *vaultChunkWriter) Write(p []byte) (int, error) {
+	written := 0
+	for len(p) > 0 {
+		n := copy(c.buf[len(c.buf):cap(c.buf)], p)
+		c.buf = c.buf[:len(c.buf)+n]
+		p = p[n:]
+		written += n
+		if len(c.buf) == cap(c.buf) {
+			if err := c.flush(false); err != nil {
+				return written, err
+			}
+		}
+	}
+	return written, nil
+}
+
+// Close encrypts the remaining data as the final chunk
+func (c *vaultChunkWriter) Close() error {
+	return c.flush(true)
+}
+
+// flush encrypts and writes the buffered chunk
+func (c *vaultChunkWriter) flush(final bool) error {
+	nonce := make([]byte, c.gcm.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return fmt.Errorf("failed to generate nonce: %w", err)
+	}
+	sealed := c.gcm.Seal(nil, nonce, c.buf, vaultChunkAAD(c.index, final))
+
+	length := uint32(len(sealed))
+	if final {
+		length |= storageVaultFinalChunk
+	}
+	var prefix [4]byte
+	binary.BigEndian.PutUint32(prefix[:], length)
+	for _, part := range [][]byte{prefix[:], nonce, sealed} {
+		if _, err := c.w.Write(part); err != nil {
+			return err
+		}
+	}
+
+	c.index++
+	c.buf = c.buf[:0]
+	return nil
+}
+
+// vaultChunkReader decrypts a stream written by vaultChunkWriter
+type vaultChunkReader struct {
+	r     io.Reader
+	gcm   cipher.AEAD
+	buf   []byte
+	index uint64
+	done  bool
+}
+
+// newVaultChunkReader creates a chunk reader with the data key
+func newVaultChunkReader(r io.Reader, key []byte) (*vaultChunkReader, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	return &vaultChunkReader{r: r, gcm: gcm}, nil
+}
+
+// Read returns decrypted data, failing if the stream ends before the final chunk
+func (c *vaultChunkReader) Read(p []byte) (int, error) {
+	for len(c.buf) == 0 {
+		if c.done {
+			return 0, io.EOF
+		}
+		if err := c.next(); err != nil {
+			return 0, err
+		}
+	}
+	n := copy(p, c.buf)
+	c.buf = c.buf[n:]
+	return n, nil
+}
+
+// next reads and decrypts one chunk
+func (c *vaultChunkReader) next() error {
+	var prefix [4]byte
+	if _, err := io.ReadFull(c.r, prefix[:]); err != nil {
+		return fmt.Errorf("encrypted storage is truncated: %w", err)
+	}
+	length := binary.BigEndian.Uint32(prefix[:])
+	final := length&storageVaultFinalChunk != 0
+	length &^= storageVaultFinalChunk
+	if int(length) > storageVaultChunkSize+c.gcm.Overhead() {
+		return fmt.Errorf("encrypted storage is corrupted")
+	}
+
+	data := make([]byte, c.gcm.NonceSize()+int(length))
+	if _, err := io.ReadFull(c.r, data); err != nil {
+		return fmt.Errorf("encrypted storage is truncated: %w", err)
+	}
+	plain, err := c.gcm.Open(nil, data[:c.gcm.NonceSize()], data[c.gcm.NonceSize():], vaultChunkAAD(c.index, final))
+	if err != nil {
+		return fmt.Errorf("encrypted storage is corrupted")
+	}
+
+	c.index++
+	c.buf = plain
+	c.done = final
+	return nil
+}
+
+// vaultChunkAAD returns the authenticated data for a chunk
+func vaultChunkAAD(index uint64, final bool) []byte {
+	aad := make([]byte, len(storageVaultMagic)+9)
+	copy(aad, storageVaultMagic)
+	binary.BigEndian.PutUint64(aad[len(storageVaultMagic):], index)
+	if final {
+		aad[len(aad)-1] = 1
+	}
+	return aad
+}
+
+// IsStorageWorkDirOnDiskAllowed returns true if the user accepted a decrypted
+// working copy on disk where it can't be kept in memory
+func (c *Config) IsStorageWorkDirOnDiskAllowed() bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.Encryption.AllowWorkDirOnDisk
+}
+
+// SetStorageWorkDirOnDiskAllowed records whether a decrypted working copy on disk is accepted
+// Thread-safe with write lock
+func (c *Config) SetStorageWorkDirOnDiskAllowed(allowed bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.Encryption.AllowWorkDirOnDisk = allowed
+}
+
+// ResetDatabasePath points the database path at the current platform location
+// Called after encrypted storage is unlocked, enabled or disabled
+// Thread-safe with write lock
+func (c *Config) ResetDatabasePath() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.ServiceSettings.DatabasePath = platform.GetDatabasePath()
+}
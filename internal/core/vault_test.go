@@ -0,0 +1,244 @@
+package core
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// newTestVaultKey returns a random data key
+func newTestVaultKey(t *testing.T) []byte {
+	t.Helper()
+
+	key := make([]byte, AESKeySize)
+	if _, err := io.ReadFull(rand.Reader, key); err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	return key
+}
+
+// sealChunks encrypts plaintext with a chunk writer
+func sealChunks(t *testing.T, key, plaintext []byte) []byte {
+	t.Helper()
+
+	var out bytes.Buffer
+	cw, err := newVaultChunkWriter(&out, key)
+	if err != nil {
+		t.Fatalf("newVaultChunkWriter: %v", err)
+	}
+	if _, err := cw.Write(plaintext); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	if err := cw.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	return out.Bytes()
+}
+
+// openChunks decrypts a chunk stream
+func openChunks(key, data []byte) ([]byte, error) {
+	cr, err := newVaultChunkReader(bytes.NewReader(data), key)
+	if err != nil {
+		return nil, err
+	}
+	return io.ReadAll(cr)
+}
+
+// splitChunks splits a chunk stream into its chunks, prefix included
+func splitChunks(t *testing.T, data []byte) [][]byte {
+	t.Helper()
+
+	var chunks [][]byte
+	for len(data) > 0 {
+		length := binary.BigEndian.Uint32(data) &^ storageVaultFinalChunk
+		size := 4 + GCMNonceSize + int(length)
+		if size > len(data) {
+			t.Fatalf("chunk runs past the end of the stream")
+		}
+		chunks = append(chunks, data[:size:size])
+		data = data[size:]
+	}
+	return chunks
+}
+
+func TestVaultChunkRoundTrip(t *testing.T) {
+	tests := []struct {
+		name   string
+		size   int
+		chunks int
+	}{
+		{"empty", 0, 1},
+		{"one byte", 1, 1},
+		{"just under a chunk", storageVaultChunkSize - 1, 1},
+		{"exactly one chunk", storageVaultChunkSize, 2},
+		{"several chunks", 3*storageVaultChunkSize + 5, 4},
+	}
+
+	key := newTestVaultKey(t)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plaintext := make([]byte, tt.size)
+			io.ReadFull(rand.Reader, plaintext)
+
+			data := sealChunks(t, key, plaintext)
+			if got := len(splitChunks(t, data)); got != tt.chunks {
+				t.Errorf("chunks = %d, want %d", got, tt.chunks)
+			}
+
+			got, err := openChunks(key, data)
+			if err != nil {
+				t.Fatalf("openChunks: %v", err)
+			}
+			if !bytes.Equal(got, plaintext) {
+				t.Errorf("decrypted %d bytes don't match the %d written", len(got), len(plaintext))
+			}
+		})
+	}
+}
+
+func TestVaultChunkRejectsTampering(t *testing.T) {
+	key := newTestVaultKey(t)
+	plaintext := make([]byte, 2*storageVaultChunkSize+100)
+	io.ReadFull(rand.Reader, plaintext)
+	valid := sealChunks(t, key, plaintext)
+
+	join := func(chunks ...[]byte) []byte {
+		return bytes.Join(chunks, nil)
+	}
+
+	tests := []struct {
+		name   string
+		key    []byte
+		mutate func(chunks [][]byte) []byte
+	}{
+		{"flipped ciphertext byte", key, func(chunks [][]byte) []byte {
+			data := join(chunks...)
+			data[4+GCMNonceSize+10] ^= 0x01
+			return data
+		}},
+		{"flipped nonce byte", key, func(chunks [][]byte) []byte {
+			data := join(chunks...)
+			data[4] ^= 0x01
+			return data
+		}},
+		{"final chunk dropped", key, func(chunks [][]byte) []byte {
+			return join(chunks[:len(chunks)-1]...)
+		}},
+		{"final chunk truncated", key, func(chunks [][]byte) []byte {
+			data := join(chunks...)
+			return data[:len(data)-1]
+		}},
+		{"chunks reordered", key, func(chunks [][]byte) []byte {
+			return join(chunks[1], chunks[0], chunks[2])
+		}},
+		{"chunk dropped", key, func(chunks [][]byte) []byte {
+			return join(chunks[0], chunks[2])
+		}},
+		{"early chunk marked final", key, func(chunks [][]byte) []byte {
+			first := append([]byte{}, chunks[0]...)
+			first[0] |= 0x80
+			return first
+		}},
+		{"wrong key", newTestVaultKey(t), func(chunks [][]byte) []byte {
+			return join(chunks...)
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := tt.mutate(splitChunks(t, append([]byte{}, valid...)))
+			if _, err := openChunks(tt.key, data); err == nil {
+				t.Error("tampered stream decrypted without error")
+			}
+		})
+	}
+}
+
+func TestStorageVaultHeaderRoundTrip(t *testing.T) {
+	useFastKDF(t)
+
+	key := newTestVaultKey(t)
+	header, err := newStorageVaultHeader("correct horse", key)
+	if err != nil {
+		t.Fatalf("newStorageVaultHeader: %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := writeStorageVaultHeader(&buf, header); err != nil {
+		t.Fatalf("writeStorageVaultHeader: %v", err)
+	}
+	written := buf.Len()
+	read, n, err := readStorageVaultHeaderFrom(&buf)
+	if err != nil {
+		t.Fatalf("readStorageVaultHeaderFrom: %v", err)
+	}
+	if n != int64(written) {
+		t.Errorf("header length = %d, want %d", n, written)
+	}
+
+	got, err := read.unwrap("correct horse")
+	if err != nil || !bytes.Equal(got, key) {
+		t.Errorf("unwrap = %x, %v, want the data key", got, err)
+	}
+	if _, err := read.unwrap("wrong horse"); !errors.Is(err, ErrWrongStoragePassphrase) {
+		t.Errorf("unwrap with wrong passphrase = %v, want ErrWrongStoragePassphrase", err)
+	}
+}
+
+func TestStorageVaultRoundTrip(t *testing.T) {
+	useFastKDF(t)
+
+	src := t.TempDir()
+	files := map[string]string{
+		"yggmail.db":           "database",
+		"yggmail.db-wal":       "write-ahead log",
+		"filestore/1/message1": "first message",
+		"filestore/2/message2": "second message",
+	}
+	for name, content := range files {
+		path := filepath.Join(src, filepath.FromSlash(name))
+		os.MkdirAll(filepath.Dir(path), 0700)
+		if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// Not a storage entry, so it must stay out of the archive
+	os.WriteFile(filepath.Join(src, "config.toml"), []byte("config"), 0600)
+
+	key := newTestVaultKey(t)
+	header, err := newStorageVaultHeader("correct horse", key)
+	if err != nil {
+		t.Fatalf("newStorageVaultHeader: %v", err)
+	}
+	vault := &StorageVault{path: filepath.Join(t.TempDir(), "storage.vault")}
+	if err := writeStorageVault(vault.path, header, key, src); err != nil {
+		t.Fatalf("writeStorageVault: %v", err)
+	}
+
+	dst := filepath.Join(t.TempDir(), "work")
+	if err := vault.extract(key, dst); err != nil {
+		t.Fatalf("extract: %v", err)
+	}
+	for name, content := range files {
+		got, err := os.ReadFile(filepath.Join(dst, filepath.FromSlash(name)))
+		if err != nil || string(got) != content {
+			t.Errorf("%s = %q, %v, want %q", name, got, err, content)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(dst, "config.toml")); !os.IsNotExist(err) {
+		t.Error("config.toml was archived")
+	}
+
+	// A flipped byte in the body must fail the extraction, not yield partial data
+	data, _ := os.ReadFile(vault.path)
+	data[len(data)-20] ^= 0x01
+	os.WriteFile(vault.path, data, 0600)
+	if err := vault.extract(key, dst); err == nil {
+		t.Error("extract accepted a tampered archive")
+	}
+}
@@ -0,0 +1,215 @@
+package core
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// vCard property names used for Tyr-specific contact fields
+const (
+	vcardPropVerified  = "X-TYR-VERIFIED"
+	vcardPropDateAdded = "X-TYR-ADDED"
+
+	// vcardMaxLineLength is the maximum line length before folding (RFC 6350 section 3.2)
+	vcardMaxLineLength = 75
+)
+
+// EncodeVCards serializes contacts as vCard 4.0 (RFC 6350)
+// Tyr-specific fields (verified flag, date added) use X- properties
+func EncodeVCards(contacts []Contact) []byte {
+	var buf bytes.Buffer
+	for _, c := range contacts {
+		writeVCardLine(&buf, "BEGIN:VCARD")
+		writeVCardLine(&buf, "VERSION:4.0")
+		writeVCardLine(&buf, "FN:"+escapeVCardValue(c.DisplayName))
+		writeVCardLine(&buf, "EMAIL;TYPE=internet:"+escapeVCardValue(c.Address))
+		if c.Notes != "" {
+			writeVCardLine(&buf, "NOTE:"+escapeVCardValue(c.Notes))
+		}
+		if !c.DateAdded.IsZero() {
+			writeVCardLine(&buf, vcardPropDateAdded+":"+c.DateAdded.UTC().Format(time.RFC3339))
+		}
+		if c.Verified {
+			writeVCardLine(&buf, vcardPropVerified+":TRUE")
+		}
+		writeVCardLine(&buf, "END:VCARD")
+	}
+	return buf.Bytes()
+}
+
+// DecodeVCards parses vCard data (versions 3.0 and 4.0) into contacts
+// Only cards with an EMAIL property are returned; the first yggmail address
+// is preferred when a card has several
+func DecodeVCards(data []byte) ([]Contact, error) {
+	lines, err := unfoldVCardLines(data)
+	if err != nil {
+		return nil, err
+	}
+
+	var contacts []Contact
+	var current *Contact
+	var emails []string
+
+	for lineNum, line := range lines {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+
+		name, value, ok := parseVCardLine(line)
+		if !ok {
+			return nil, fmt.Errorf("invalid vCard line %d", lineNum+1)
+		}
+
+		switch name {
+		case "BEGIN":
+			if !strings.EqualFold(value, "VCARD") {
+				continue
+			}
+			if current != nil {
+				return nil, fmt.Errorf("nested vCard at line %d", lineNum+1)
+			}
+			current = &Contact{}
+			emails = nil
+
+		case "END":
+			if !strings.EqualFold(value, "VCARD") || current == nil {
+				continue
+			}
+			if address := pickVCardEmail(emails); address != "" {
+				current.Address = address
+				contacts = append(contacts, *current)
+			}
+			current = nil
+
+		default:
+			if current == nil {
+				continue
+			}
+			switch name {
+			case "FN":
+				current.DisplayName = unescapeVCardValue(value)
+			case "EMAIL":
+				emails = append(emails, unescapeVCardValue(value))
+			case "NOTE":
+				current.Notes = unescapeVCardValue(value)
+			case vcardPropDateAdded:
+				if t, err := time.Parse(time.RFC3339, value); err == nil {
+					current.DateAdded = t
+				}
+			case vcardPropVerified:
+				current.Verified = strings.EqualFold(value, "TRUE")
+			}
+		}
+	}
+
+	if current != nil {
+		return nil, fmt.Errorf("unterminated vCard")
+	}
+
+	return contacts, nil
+}
+
+// writeVCardLine writes a content line folded at 75 octets with CRLF endings
+// Folding never splits a multi-byte UTF-8 sequence
+func writeVCardLine(buf *bytes.Buffer, line string) {
+	limit := vcardMaxLineLength
+	for len(line) > limit {
+		cut := limit
+		for cut > 0 && !isUTF8Boundary(line, cut) {
+			cut--
+		}
+		buf.WriteString(line[:cut])
+		buf.WriteString("\r\n ")
+		line = line[cut:]
+		// Continuation lines start with a space, which counts toward the limit
+		limit = vcardMaxLineLength - 1
+	}
+	buf.WriteString(line)
+	buf.WriteString("\r\n")
+}
+
+// isUTF8Boundary reports whether index i starts a UTF-8 sequence
+func isUTF8Boundary(s string, i int) bool {
+	return i >= len(s) || s[i]&0xC0 != 0x80
+}
+
+// unfoldVCardLines splits data into logical lines, joining folded continuations
+func unfoldVCardLines(data []byte) ([]string, error) {
+	var lines []string
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")) && len(lines) > 0 {
+			lines[len(lines)-1] += line[1:]
+			continue
+		}
+		lines = append(lines, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read vCard data: %w", err)
+	}
+	return lines, nil
+}
+
+// parseVCardLine splits "group.NAME;PARAM=x:value" into the upper-case name and value
+// Parameters are ignored; Tyr only reads plain text properties
+func parseVCardLine(line string) (name string, value string, ok bool) {
+	colon := strings.Index(line, ":")
+	if colon <= 0 {
+		return "", "", false
+	}
+
+	name, _, _ = strings.Cut(strings.ToUpper(line[:colon]), ";")
+	if dot := strings.LastIndex(name, "."); dot >= 0 {
+		name = name[dot+1:]
+	}
+	return name, line[colon+1:], true
+}
+
+// pickVCardEmail returns the first yggmail address, or the first address if none match
+func pickVCardEmail(emails []string) string {
+	for _, e := range emails {
+		if IsValidMailAddress(e) {
+			return NormalizeMailAddress(e)
+		}
+	}
+	if len(emails) > 0 {
+		return strings.TrimSpace(emails[0])
+	}
+	return ""
+}
+
+// escapeVCardValue escapes text per RFC 6350 section 3.4
+func escapeVCardValue(s string) string {
+	r := strings.NewReplacer(
+		`\`, `\\`,
+		",", `\,`,
+		";", `\;`,
+		"\r\n", `\n`,
+		"\n", `\n`,
+	)
+	return r.Replace(s)
+}
+
+// unescapeVCardValue reverses escapeVCardValue
+func unescapeVCardValue(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] != '\\' || i+1 >= len(s) {
+			b.WriteByte(s[i])
+			continue
+		}
+		i++
+		switch s[i] {
+		case 'n', 'N':
+			b.WriteByte('\n')
+		default:
+			b.WriteByte(s[i])
+		}
+	}
+	return b.String()
+}
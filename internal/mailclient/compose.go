@@ -0,0 +1,268 @@
+// Package mailclient provides client-side access to the local yggmail
+// SMTP and IMAP listeners: composing and submitting outgoing messages,
+// and reading stored mail.
+package mailclient
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"mime"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/emersion/go-message/mail"
+)
+
+// Attachment represents a file attached to an outgoing message
+type Attachment struct {
+	// Filename is the name presented to the recipient
+	Filename string
+
+	// ContentType is the MIME type (detected from the filename if empty)
+	ContentType string
+
+	// Data is the attachment content
+	Data []byte
+}
+
+// OutgoingMessage represents a message composed for sending
+type OutgoingMessage struct {
+	// From is the sender address (must be the local yggmail address)
+	From string
+
+	// To is the list of primary recipients
+	To []string
+
+	// Cc is the list of carbon-copy recipients
+	Cc []string
+
+	// Subject is the message subject
+	Subject string
+
+	// TextBody is the plain-text body
+	TextBody string
+
+	// HTMLBody is the optional HTML alternative of the body
+	HTMLBody string
+
+	// Attachments is the list of attached files
+	Attachments []Attachment
+
+	// Headers contains additional raw headers (e.g., In-Reply-To, Auto-Submitted)
+	Headers map[string]string
+}
+
+// Recipients returns all envelope recipients (To and Cc) without duplicates
+func (m *OutgoingMessage) Recipients() []string {
+	seen := make(map[string]bool)
+	result := make([]string, 0, len(m.To)+len(m.Cc))
+	for _, addr := range append(append([]string{}, m.To...), m.Cc...) {
+		addr = strings.TrimSpace(addr)
+		if addr == "" || seen[strings.ToLower(addr)] {
+			continue
+		}
+		seen[strings.ToLower(addr)] = true
+		result = append(result, addr)
+	}
+	return result
+}
+
+// Validate checks that the message has a sender, recipients and content
+func (m *OutgoingMessage) Validate() error {
+	if m.From == "" {
+		return fmt.Errorf("sender address cannot be empty")
+	}
+	if len(m.Recipients()) == 0 {
+		return fmt.Errorf("at least one recipient is required")
+	}
+	if m.TextBody == "" && m.HTMLBody == "" && len(m.Attachments) == 0 {
+		return fmt.Errorf("message has no content")
+	}
+	for _, att := range m.Attachments {
+		if att.Filename == "" {
+			return fmt.Errorf("attachment filename cannot be empty")
+		}
+	}
+	return nil
+}
+
+// LoadAttachment reads a file from disk as an attachment
+// The content type is detected from the file extension
+func LoadAttachment(path string) (Attachment, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return Attachment{}, fmt.Errorf("failed to access attachment: %w", err)
+	}
+	if !info.Mode().IsRegular() {
+		return Attachment{}, fmt.Errorf("attachment is not a regular file: %s", path)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Attachment{}, fmt.Errorf("failed to read attachment: %w", err)
+	}
+
+	return Attachment{
+		Filename: filepath.Base(path),
+		Data:     data,
+	}, nil
+}
+
+// BuildMessage renders the message as RFC 5322 bytes
+// Produces a single text/plain part when there is no HTML body and no attachments,
+// otherwise a multipart message. Returns the raw message and its Message-ID
+func BuildMessage(m *OutgoingMessage) ([]byte, string, error) {
+	if err := m.Validate(); err != nil {
+		return nil, "", err
+	}
+
+	var h mail.Header
+	h.SetDate(time.Now())
+	h.SetSubject(m.Subject)
+	h.SetAddressList("From", []*mail.Address{{Address: m.From}})
+	h.SetAddressList("To", toAddressList(m.To))
+	if len(m.Cc) > 0 {
+		h.SetAddressList("Cc", toAddressList(m.Cc))
+	}
+	if err := h.GenerateMessageIDWithHostname(domainOf(m.From)); err != nil {
+		return nil, "", fmt.Errorf("failed to generate Message-ID: %w", err)
+	}
+	for key, value := range m.Headers {
+		h.Set(key, value)
+	}
+
+	messageID, err := h.MessageID()
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to read Message-ID: %w", err)
+	}
+
+	var buf bytes.Buffer
+	if m.HTMLBody == "" && len(m.Attachments) == 0 {
+		h.SetContentType("text/plain", map[string]string{"charset": "utf-8"})
+		w, err := mail.CreateSingleInlineWriter(&buf, h)
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to create message writer: %w", err)
+		}
+		if _, err := io.WriteString(w, m.TextBody); err != nil {
+			return nil, "", fmt.Errorf("failed to write message body: %w", err)
+		}
+		if err := w.Close(); err != nil {
+			return nil, "", fmt.Errorf("failed to finalize message: %w", err)
+		}
+		return buf.Bytes(), messageID, nil
+	}
+
+	mw, err := mail.CreateWriter(&buf, h)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to create message writer: %w", err)
+	}
+
+	if err := writeBody(mw, m); err != nil {
+		return nil, "", err
+	}
+
+	for _, att := range m.Attachments {
+		if err := writeAttachment(mw, att); err != nil {
+			return nil, "", err
+		}
+	}
+
+	if err := mw.Close(); err != nil {
+		return nil, "", fmt.Errorf("failed to finalize message: %w", err)
+	}
+
+	return buf.Bytes(), messageID, nil
+}
+
+// writeBody writes the text and optional HTML body as a multipart/alternative part
+func writeBody(mw *mail.Writer, m *OutgoingMessage) error {
+	if m.TextBody == "" && m.HTMLBody == "" {
+		return nil
+	}
+
+	iw, err := mw.CreateInline()
+	if err != nil {
+		return fmt.Errorf("failed to create body part: %w", err)
+	}
+
+	parts := []struct {
+		contentType string
+		body        string
+	}{
+		{"text/plain", m.TextBody},
+		{"text/html", m.HTMLBody},
+	}
+	for _, part := range parts {
+		if part.body == "" {
+			continue
+		}
+
+		var ph mail.InlineHeader
+		ph.SetContentType(part.contentType, map[string]string{"charset": "utf-8"})
+		pw, err := iw.CreatePart(ph)
+		if err != nil {
+			return fmt.Errorf("failed to create %s part: %w", part.contentType, err)
+		}
+		if _, err := io.WriteString(pw, part.body); err != nil {
+			return fmt.Errorf("failed to write %s part: %w", part.contentType, err)
+		}
+		if err := pw.Close(); err != nil {
+			return fmt.Errorf("failed to finalize %s part: %w", part.contentType, err)
+		}
+	}
+
+	return iw.Close()
+}
+
+// writeAttachment writes a single attachment part
+func writeAttachment(mw *mail.Writer, att Attachment) error {
+	contentType := att.ContentType
+	if contentType == "" {
+		contentType = mime.TypeByExtension(filepath.Ext(att.Filename))
+	}
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+
+	// TypeByExtension may include parameters (e.g., "text/plain; charset=utf-8")
+	mediaType, params, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		mediaType, params = "application/octet-stream", nil
+	}
+
+	var ah mail.AttachmentHeader
+	ah.SetContentType(mediaType, params)
+	ah.SetFilename(att.Filename)
+
+	w, err := mw.CreateAttachment(ah)
+	if err != nil {
+		return fmt.Errorf("failed to create attachment %s: %w", att.Filename, err)
+	}
+	if _, err := w.Write(att.Data); err != nil {
+		return fmt.Errorf("failed to write attachment %s: %w", att.Filename, err)
+	}
+	return w.Close()
+}
+
+// toAddressList converts plain addresses to mail.Address values
+func toAddressList(addrs []string) []*mail.Address {
+	list := make([]*mail.Address, 0, len(addrs))
+	for _, addr := range addrs {
+		addr = strings.TrimSpace(addr)
+		if addr != "" {
+			list = append(list, &mail.Address{Address: addr})
+		}
+	}
+	return list
+}
+
+// domainOf returns the domain part of an address (e.g., "yggmail")
+func domainOf(addr string) string {
+	if i := strings.LastIndex(addr, "@"); i >= 0 && i < len(addr)-1 {
+		return addr[i+1:]
+	}
+	return "yggmail"
+}
@@ -0,0 +1,762 @@
+package mailclient
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"net"
+	netmail "net/mail"
+	"strings"
+	"time"
+
+	"github.com/emersion/go-imap"
+	"github.com/emersion/go-imap/client"
+	"github.com/emersion/go-message"
+	_ "github.com/emersion/go-message/charset" // register non-UTF-8 charsets for decoding
+	"github.com/emersion/go-message/mail"
+	"github.com/emersion/go-message/textproto"
+)
+
+const (
+	// IMAPDialTimeout is the maximum time allowed to connect to the local IMAP listener
+	IMAPDialTimeout = 10 * time.Second
+
+	// IMAPCommandTimeout is the maximum time allowed for a single IMAP command
+	IMAPCommandTimeout = 60 * time.Second
+
+	// DefaultPageSize is the number of message headers returned per page
+	DefaultPageSize = 50
+
+	// MaxPageSize is the largest allowed page size
+	MaxPageSize = 500
+
+	// OriginalDateHeader carries the original internal date of a message Tyr
+	// stored again (e.g., after stripping attachments). The local server stamps
+	// appended messages with the current time and ignores the APPEND date
+	OriginalDateHeader = "X-Tyr-Original-Date"
+)
+
+// IMAPCredentials contains the address and login for the local IMAP listener
+type IMAPCredentials struct {
+	// Address is the IMAP listener address (e.g., "127.0.0.1:1143")
+	Address string
+
+	// Username is the login name (the full yggmail address)
+	Username string
+
+	// Password is the yggmail password
+	Password string
+}
+
+// MailboxInfo contains summary information about a mailbox
+type MailboxInfo struct {
+	// Name is the mailbox name (e.g., "INBOX")
+	Name string
+
+	// Messages is the total number of messages
+	Messages uint32
+
+	// Unseen is the number of messages without the \Seen flag
+	Unseen uint32
+}
+
+// MessageHeader contains the envelope information of a stored message
+type MessageHeader struct {
+	// UID is the unique identifier of the message within its mailbox
+	UID uint32
+
+	// MessageID is the Message-ID header value
+	MessageID string
+
+	// Subject is the decoded message subject
+	Subject string
+
+	// From is the list of sender addresses
+	From []string
+
+	// To is the list of primary recipient addresses
+	To []string
+
+	// Cc is the list of carbon-copy recipient addresses
+	Cc []string
+
+	// Date is the date from the message header
+	Date time.Time
+
+	// InternalDate is the time the message was stored on the server
+	InternalDate time.Time
+
+	// OriginalDate is the internal date before Tyr stored the message again,
+	// from the OriginalDateHeader (zero for messages stored only once)
+	OriginalDate time.Time
+
+	// Size is the size of the raw message in bytes
+	Size uint32
+
+	// Flags is the list of IMAP flags (e.g., \Seen, \Flagged)
+	Flags []string
+}
+
+// Seen returns true if the message has the \Seen flag
+func (h *MessageHeader) Seen() bool {
+	return h.HasFlag(imap.SeenFlag)
+}
+
+// HasFlag returns true if the message has the given flag
+func (h *MessageHeader) HasFlag(flag string) bool {
+	for _, f := range h.Flags {
+		if strings.EqualFold(f, flag) {
+			return true
+		}
+	}
+	return false
+}
+
+// MessagePage contains one page of message headers, newest first
+type MessagePage struct {
+	// Mailbox is the mailbox the page was read from
+	Mailbox string
+
+	// Total is the number of messages in the mailbox
+	Total uint32
+
+	// Page is the zero-based page index
+	Page int
+
+	// PageSize is the maximum number of messages per page
+	PageSize int
+
+	// Messages is the list of headers on this page
+	Messages []MessageHeader
+}
+
+// MessagePart describes a node in the MIME tree of a message
+type MessagePart struct {
+	// ID is the IMAP-style part path (e.g., "1", "2.1")
+	ID string
+
+	// ContentType is the media type (e.g., "text/plain", "multipart/mixed")
+	ContentType string
+
+	// Filename is the attachment filename, if any
+	Filename string
+
+	// Disposition is the Content-Disposition value ("inline", "attachment" or empty)
+	Disposition string
+
+	// Size is the decoded size of the part in bytes (0 for multipart nodes)
+	Size int
+
+	// Children contains the sub-parts of a multipart node
+	Children []MessagePart
+}
+
+// IsAttachment returns true if the part should be offered as a download
+func (p *MessagePart) IsAttachment() bool {
+	if len(p.Children) > 0 || strings.HasPrefix(p.ContentType, "multipart/") {
+		return false
+	}
+	return p.Disposition == "attachment" || p.Filename != "" || !strings.HasPrefix(p.ContentType, "text/")
+}
+
+// Message contains a fully fetched message
+type Message struct {
+	// Header contains the envelope information
+	Header MessageHeader
+
+	// TextBody is the first plain-text body part
+	TextBody string
+
+	// HTMLBody is the first HTML body part
+	HTMLBody string
+
+	// Structure is the root of the MIME tree
+	Structure MessagePart
+
+	// Attachments is the flat list of attachment parts
+	Attachments []MessagePart
+}
+
+// IMAPSession is an authenticated connection to the local IMAP listener
+// A session is not safe for concurrent use
+type IMAPSession struct {
+	c *client.Client
+}
+
+// DialIMAP connects and logs into the local IMAP listener
+// The caller must Close the session when done
+func DialIMAP(creds IMAPCredentials) (*IMAPSession, error) {
+	if creds.Address == "" {
+		return nil, fmt.Errorf("IMAP address cannot be empty")
+	}
+	if creds.Password == "" {
+		return nil, fmt.Errorf("IMAP password cannot be empty")
+	}
+
+	conn, err := net.DialTimeout("tcp", creds.Address, IMAPDialTimeout)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to IMAP listener: %w", err)
+	}
+
+	c, err := client.New(conn)
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to create IMAP client: %w", err)
+	}
+	c.Timeout = IMAPCommandTimeout
+
+	// The local listener is plain text on loopback, LOGIN is expected
+	if err := c.Login(creds.Username, creds.Password); err != nil {
+		c.Logout()
+		return nil, fmt.Errorf("IMAP authentication failed: %w", err)
+	}
+
+	return &IMAPSession{c: c}, nil
+}
+
+// Client returns the underlying go-imap client for operations not covered here
+func (s *IMAPSession) Client() *client.Client {
+	return s.c
+}
+
+// Close logs out and closes the connection
+func (s *IMAPSession) Close() error {
+	if s.c == nil {
+		return nil
+	}
+	err := s.c.Logout()
+	s.c = nil
+	return err
+}
+
+// ListMailboxes returns all mailboxes with message and unseen counts
+func (s *IMAPSession) ListMailboxes() ([]MailboxInfo, error) {
+	ch := make(chan *imap.MailboxInfo, 16)
+	done := make(chan error, 1)
+	go func() {
+		done <- s.c.List("", "*", ch)
+	}()
+
+	var names []string
+	for info := range ch {
+		names = append(names, info.Name)
+	}
+	if err := <-done; err != nil {
+		return nil, fmt.Errorf("failed to list mailboxes: %w", err)
+	}
+
+	result := make([]MailboxInfo, 0, len(names))
+	for _, name := range names {
+		status, err := s.c.Status(name, []imap.StatusItem{imap.StatusMessages, imap.StatusUnseen})
+		if err != nil {
+			return nil, fmt.Errorf("failed to get status of %s: %w", name, err)
+		}
+		result = append(result, MailboxInfo{
+			Name:     name,
+			Messages: status.Messages,
+			Unseen:   status.Unseen,
+		})
+	}
+
+	return result, nil
+}
+
+// Examine opens a mailbox read-only and returns its status
+func (s *IMAPSession) Examine(mailbox string) (*imap.MailboxStatus, error) {
+	if mailbox == "" {
+		mailbox = "INBOX"
+	}
+	status, err := s.c.Select(mailbox, true)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open mailbox %s: %w", mailbox, err)
+	}
+	return status, nil
+}
+
+// Select opens a mailbox read-write and returns its status
+// Required before DeleteMessages and MoveMessages
+func (s *IMAPSession) Select(mailbox string) (*imap.MailboxStatus, error) {
+	if mailbox == "" {
+		mailbox = "INBOX"
+	}
+	status, err := s.c.Select(mailbox, false)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open mailbox %s: %w", mailbox, err)
+	}
+	return status, nil
+}
+
+// AllHeaders returns headers of every message in the currently opened mailbox, oldest first
+func (s *IMAPSession) AllHeaders() ([]MessageHeader, error) {
+	if mbox := s.c.Mailbox(); mbox == nil || mbox.Messages == 0 {
+		return []MessageHeader{}, nil
+	}
+	seqSet := new(imap.SeqSet)
+	seqSet.AddRange(1, 0)
+	return s.fetchHeaders(seqSet, false)
+}
+
+// DeleteMessages flags messages as deleted and expunges the currently opened mailbox
+// The mailbox must be opened with Select
+func (s *IMAPSession) DeleteMessages(uids []uint32) error {
+	if len(uids) == 0 {
+		return nil
+	}
+	seqSet := new(imap.SeqSet)
+	seqSet.AddNum(uids...)
+
+	item := imap.FormatFlagsOp(imap.AddFlags, true)
+	if err := s.c.UidStore(seqSet, item, []interface{}{imap.DeletedFlag}, nil); err != nil {
+		return fmt.Errorf("failed to flag messages as deleted: %w", err)
+	}
+	if err := s.c.Expunge(nil); err != nil {
+		return fmt.Errorf("failed to expunge mailbox: %w", err)
+	}
+	return nil
+}
+
+// MoveMessages moves messages from the currently opened mailbox to another mailbox
+// The mailbox must be opened with Select
+func (s *IMAPSession) MoveMessages(uids []uint32, dest string) error {
+	if len(uids) == 0 {
+		return nil
+	}
+	seqSet := new(imap.SeqSet)
+	seqSet.AddNum(uids...)
+
+	if err := s.c.UidMove(seqSet, dest); err != nil {
+		return fmt.Errorf("failed to move messages to %s: %w", dest, err)
+	}
+	return nil
+}
+
+// AppendMessage stores a raw message in a mailbox with the given flags
+// The date is sent as the internal date, but the local server ignores it and
+// uses the current time; see OriginalDateHeader
+func (s *IMAPSession) AppendMessage(mailbox string, flags []string, date time.Time, raw []byte) error {
+	// \Recent is server-managed and can't be set by clients
+	kept := make([]string, 0, len(flags))
+	for _, f := range flags {
+		if !strings.EqualFold(f, imap.RecentFlag) {
+			kept = append(kept, f)
+		}
+	}
+
+	if err := s.c.Append(mailbox, kept, date, bytes.NewReader(raw)); err != nil {
+		return fmt.Errorf("failed to append message to %s: %w", mailbox, err)
+	}
+	return nil
+}
+
+// ListMessages returns one page of message headers, newest first
+// Page is zero-based; pageSize is clamped to [1, MaxPageSize]
+func (s *IMAPSession) ListMessages(mailbox string, page, pageSize int) (*MessagePage, error) {
+	if page < 0 {
+		page = 0
+	}
+	if pageSize <= 0 {
+		pageSize = DefaultPageSize
+	}
+	if pageSize > MaxPageSize {
+		pageSize = MaxPageSize
+	}
+
+	status, err := s.Examine(mailbox)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &MessagePage{
+		Mailbox:  status.Name,
+		Total:    status.Messages,
+		Page:     page,
+		PageSize: pageSize,
+		Messages: []MessageHeader{},
+	}
+
+	// Sequence numbers grow with arrival order, so the newest page is at the end
+	offset := uint64(page) * uint64(pageSize)
+	if offset >= uint64(status.Messages) {
+		return result, nil
+	}
+	stop := uint32(uint64(status.Messages) - offset)
+	start := uint32(1)
+	if stop > uint32(pageSize) {
+		start = stop - uint32(pageSize) + 1
+	}
+
+	seqSet := new(imap.SeqSet)
+	seqSet.AddRange(start, stop)
+
+	headers, err := s.fetchHeaders(seqSet, false)
+	if err != nil {
+		return nil, err
+	}
+
+	// Reverse to newest first
+	for i := len(headers) - 1; i >= 0; i-- {
+		result.Messages = append(result.Messages, headers[i])
+	}
+
+	return result, nil
+}
+
+// FetchHeaders returns headers for the given UIDs in the currently opened mailbox
+func (s *IMAPSession) FetchHeaders(uids []uint32) ([]MessageHeader, error) {
+	if len(uids) == 0 {
+		return []MessageHeader{}, nil
+	}
+	seqSet := new(imap.SeqSet)
+	seqSet.AddNum(uids...)
+	return s.fetchHeaders(seqSet, true)
+}
+
+// originalDateSection fetches only the OriginalDateHeader field
+var originalDateSection = &imap.BodySectionName{
+	BodyPartName: imap.BodyPartName{
+		Specifier: imap.HeaderSpecifier,
+		Fields:    []string{OriginalDateHeader},
+	},
+	Peek: true,
+}
+
+// fetchHeaders fetches envelope, flags and size for a sequence or UID set
+func (s *IMAPSession) fetchHeaders(seqSet *imap.SeqSet, uid bool) ([]MessageHeader, error) {
+	items := []imap.FetchItem{
+		imap.FetchUid,
+		imap.FetchEnvelope,
+		imap.FetchFlags,
+		imap.FetchInternalDate,
+		imap.FetchRFC822Size,
+		originalDateSection.FetchItem(),
+	}
+
+	ch := make(chan *imap.Message, 32)
+	done := make(chan error, 1)
+	go func() {
+		if uid {
+			done <- s.c.UidFetch(seqSet, items, ch)
+		} else {
+			done <- s.c.Fetch(seqSet, items, ch)
+		}
+	}()
+
+	var headers []MessageHeader
+	for msg := range ch {
+		headers = append(headers, headerFromIMAP(msg))
+	}
+	if err := <-done; err != nil {
+		return nil, fmt.Errorf("failed to fetch message headers: %w", err)
+	}
+
+	return headers, nil
+}
+
+// FetchRaw returns the raw RFC 5322 bytes of a message without marking it as seen
+// The mailbox must already be opened with Examine
+func (s *IMAPSession) FetchRaw(uid uint32) (*MessageHeader, []byte, error) {
+	section := &imap.BodySectionName{Peek: true}
+	items := []imap.FetchItem{
+		imap.FetchUid,
+		imap.FetchEnvelope,
+		imap.FetchFlags,
+		imap.FetchInternalDate,
+		imap.FetchRFC822Size,
+		originalDateSection.FetchItem(),
+		section.FetchItem(),
+	}
+
+	seqSet := new(imap.SeqSet)
+	seqSet.AddNum(uid)
+
+	ch := make(chan *imap.Message, 1)
+	done := make(chan error, 1)
+	go func() {
+		done <- s.c.UidFetch(seqSet, items, ch)
+	}()
+
+	var header *MessageHeader
+	var raw []byte
+	var readErr error
+	for msg := range ch {
+		h := headerFromIMAP(msg)
+		header = &h
+		if body := msg.GetBody(section); body != nil {
+			raw, readErr = io.ReadAll(body)
+		}
+	}
+	if err := <-done; err != nil {
+		return nil, nil, fmt.Errorf("failed to fetch message: %w", err)
+	}
+	if readErr != nil {
+		return nil, nil, fmt.Errorf("failed to read message body: %w", readErr)
+	}
+	if header == nil || raw == nil {
+		return nil, nil, fmt.Errorf("message %d not found", uid)
+	}
+
+	return header, raw, nil
+}
+
+// FetchRawHeader returns the raw header section of a message without marking it as seen
+// The mailbox must already be opened with Examine
+func (s *IMAPSession) FetchRawHeader(uid uint32) ([]byte, error) {
+	section := &imap.BodySectionName{
+		BodyPartName: imap.BodyPartName{Specifier: imap.HeaderSpecifier},
+		Peek:         true,
+	}
+
+	seqSet := new(imap.SeqSet)
+	seqSet.AddNum(uid)
+
+	ch := make(chan *imap.Message, 1)
+	done := make(chan error, 1)
+	go func() {
+		done <- s.c.UidFetch(seqSet, []imap.FetchItem{section.FetchItem()}, ch)
+	}()
+
+	var raw []byte
+	var readErr error
+	for msg := range ch {
+		if body := msg.GetBody(section); body != nil {
+			raw, readErr = io.ReadAll(body)
+		}
+	}
+	if err := <-done; err != nil {
+		return nil, fmt.Errorf("failed to fetch message header: %w", err)
+	}
+	if readErr != nil {
+		return nil, fmt.Errorf("failed to read message header: %w", readErr)
+	}
+	if raw == nil {
+		return nil, fmt.Errorf("message %d not found", uid)
+	}
+	return raw, nil
+}
+
+// FetchMessage returns a message with its bodies and MIME tree
+// The message is read with BODY.PEEK and is not marked as seen
+func (s *IMAPSession) FetchMessage(mailbox string, uid uint32) (*Message, error) {
+	if _, err := s.Examine(mailbox); err != nil {
+		return nil, err
+	}
+
+	header, raw, err := s.FetchRaw(uid)
+	if err != nil {
+		return nil, err
+	}
+
+	msg, err := ParseMessage(raw)
+	if err != nil {
+		return nil, err
+	}
+	msg.Header = *header
+
+	return msg, nil
+}
+
+// FetchAttachment returns the decoded content of a single message part
+func (s *IMAPSession) FetchAttachment(mailbox string, uid uint32, partID string) (*Attachment, error) {
+	if _, err := s.Examine(mailbox); err != nil {
+		return nil, err
+	}
+
+	_, raw, err := s.FetchRaw(uid)
+	if err != nil {
+		return nil, err
+	}
+
+	return ExtractPart(raw, partID)
+}
+
+// ParseMessage parses raw message bytes into bodies and a MIME tree
+// The returned Header only contains fields available from the message headers
+func ParseMessage(raw []byte) (*Message, error) {
+	msg := &Message{}
+	err := walkParts(raw, func(part *MessagePart, contentType string, body []byte) bool {
+		if part.IsAttachment() {
+			msg.Attachments = append(msg.Attachments, *part)
+			return true
+		}
+		switch {
+		case contentType == "text/plain" && msg.TextBody == "":
+			msg.TextBody = string(body)
+		case contentType == "text/html" && msg.HTMLBody == "":
+			msg.HTMLBody = string(body)
+		}
+		return true
+	}, &msg.Structure)
+	if err != nil {
+		return nil, err
+	}
+	if msg.Attachments == nil {
+		msg.Attachments = []MessagePart{}
+	}
+
+	return msg, nil
+}
+
+// ExtractPart returns the decoded content of the part with the given ID
+func ExtractPart(raw []byte, partID string) (*Attachment, error) {
+	var found *Attachment
+	err := walkParts(raw, func(part *MessagePart, contentType string, body []byte) bool {
+		if part.ID != partID {
+			return true
+		}
+		found = &Attachment{
+			Filename:    part.Filename,
+			ContentType: contentType,
+			Data:        body,
+		}
+		return false
+	}, nil)
+	if err != nil {
+		return nil, err
+	}
+	if found == nil {
+		return nil, fmt.Errorf("part %s not found", partID)
+	}
+	if found.Filename == "" {
+		found.Filename = "part-" + strings.ReplaceAll(partID, ".", "-")
+	}
+
+	return found, nil
+}
+
+// walkParts visits every leaf part of a message in order
+// The visit callback returns false to stop walking. If root is not nil,
+// the full MIME tree is stored in it
+func walkParts(raw []byte, visit func(part *MessagePart, contentType string, body []byte) bool, root *MessagePart) error {
+	e, err := message.Read(bytes.NewReader(raw))
+	if err != nil && !message.IsUnknownCharset(err) {
+		return fmt.Errorf("failed to parse message: %w", err)
+	}
+
+	tree, _, err := walkEntity(e, "", visit)
+	if err != nil {
+		return err
+	}
+	if root != nil {
+		*root = tree
+	}
+	return nil
+}
+
+// walkEntity recursively visits an entity and its children, assigning
+// IMAP part paths. Returns the part node and whether walking should continue
+func walkEntity(e *message.Entity, id string, visit func(part *MessagePart, contentType string, body []byte) bool) (MessagePart, bool, error) {
+	contentType, _, _ := e.Header.ContentType()
+	if contentType == "" {
+		contentType = "text/plain"
+	}
+
+	part := MessagePart{ID: id, ContentType: contentType}
+	if disp, _, err := e.Header.ContentDisposition(); err == nil {
+		part.Disposition = disp
+	}
+	// Filename handles both the disposition "filename" and the legacy "name" parameter
+	part.Filename, _ = (&mail.AttachmentHeader{Header: e.Header}).Filename()
+
+	if mr := e.MultipartReader(); mr != nil {
+		for i := 1; ; i++ {
+			child, err := mr.NextPart()
+			if err == io.EOF {
+				break
+			}
+			if err != nil && !message.IsUnknownCharset(err) {
+				return part, false, fmt.Errorf("failed to read message part: %w", err)
+			}
+
+			childID := fmt.Sprintf("%d", i)
+			if id != "" {
+				childID = id + "." + childID
+			}
+
+			childPart, cont, err := walkEntity(child, childID, visit)
+			if err != nil {
+				return part, false, err
+			}
+			part.Children = append(part.Children, childPart)
+			if !cont {
+				return part, false, nil
+			}
+		}
+		return part, true, nil
+	}
+
+	// The body of a single-part message is part "1"
+	if part.ID == "" {
+		part.ID = "1"
+	}
+
+	body, err := io.ReadAll(e.Body)
+	if err != nil {
+		return part, false, fmt.Errorf("failed to read part %s: %w", part.ID, err)
+	}
+	part.Size = len(body)
+
+	return part, visit(&part, contentType, body), nil
+}
+
+// headerFromIMAP converts a fetched IMAP message into a MessageHeader
+func headerFromIMAP(msg *imap.Message) MessageHeader {
+	h := MessageHeader{
+		UID:          msg.Uid,
+		InternalDate: msg.InternalDate,
+		Size:         msg.Size,
+		Flags:        msg.Flags,
+		From:         []string{},
+		To:           []string{},
+		Cc:           []string{},
+	}
+	if h.Flags == nil {
+		h.Flags = []string{}
+	}
+	if body := msg.GetBody(originalDateSection); body != nil {
+		h.OriginalDate = parseOriginalDate(body)
+	}
+	if env := msg.Envelope; env != nil {
+		h.MessageID = env.MessageId
+		h.Subject = env.Subject
+		h.Date = env.Date
+		h.From = formatAddresses(env.From)
+		h.To = formatAddresses(env.To)
+		h.Cc = formatAddresses(env.Cc)
+	}
+	return h
+}
+
+// parseOriginalDate reads the OriginalDateHeader from a fetched header section
+// Returns the zero time if the header is missing or invalid
+func parseOriginalDate(r io.Reader) time.Time {
+	header, err := textproto.ReadHeader(bufio.NewReader(r))
+	if err != nil {
+		return time.Time{}
+	}
+	value := header.Get(OriginalDateHeader)
+	if value == "" {
+		return time.Time{}
+	}
+	t, err := netmail.ParseDate(value)
+	if err != nil {
+		return time.Time{}
+	}
+	return t
+}
+
+// formatAddresses converts IMAP addresses to "Name <addr>" or plain address strings
+func formatAddresses(addrs []*imap.Address) []string {
+	result := make([]string, 0, len(addrs))
+	for _, a := range addrs {
+		if a == nil || a.MailboxName == "" {
+			continue
+		}
+		addr := a.Address()
+		if a.PersonalName != "" {
+			addr = (&mail.Address{Name: a.PersonalName, Address: addr}).String()
+		}
+		result = append(result, addr)
+	}
+	return result
+}
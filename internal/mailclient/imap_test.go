@@ -0,0 +1,130 @@
+package mailclient
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/emersion/go-imap/backend/memory"
+	"github.com/emersion/go-imap/server"
+)
+
+// startTestIMAPServer serves an in-memory mailbox on a random loopback port
+// The backend has one user ("username"/"password") with one message in INBOX
+func startTestIMAPServer(t *testing.T) IMAPCredentials {
+	t.Helper()
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+
+	srv := server.New(memory.New())
+	srv.AllowInsecureAuth = true
+	go srv.Serve(ln)
+	t.Cleanup(func() { srv.Close() })
+
+	return IMAPCredentials{Address: ln.Addr().String(), Username: "username", Password: "password"}
+}
+
+func TestIMAPSessionListAndFetch(t *testing.T) {
+	creds := startTestIMAPServer(t)
+
+	session, err := DialIMAP(creds)
+	if err != nil {
+		t.Fatalf("DialIMAP: %v", err)
+	}
+	defer session.Close()
+
+	raw, messageID, err := BuildMessage(&OutgoingMessage{
+		From:     "sender@example.org",
+		To:       []string{"rcpt@example.org"},
+		Subject:  "Test subject",
+		TextBody: "Hello from the test",
+		Attachments: []Attachment{
+			{Filename: "notes.bin", ContentType: "application/octet-stream", Data: []byte{1, 2, 3, 4}},
+		},
+	})
+	if err != nil {
+		t.Fatalf("BuildMessage: %v", err)
+	}
+	if err := session.AppendMessage("INBOX", nil, time.Now(), raw); err != nil {
+		t.Fatalf("AppendMessage: %v", err)
+	}
+
+	mailboxes, err := session.ListMailboxes()
+	if err != nil {
+		t.Fatalf("ListMailboxes: %v", err)
+	}
+	if len(mailboxes) != 1 || mailboxes[0].Name != "INBOX" || mailboxes[0].Messages != 2 {
+		t.Fatalf("ListMailboxes = %+v, want INBOX with 2 messages", mailboxes)
+	}
+
+	page, err := session.ListMessages("INBOX", 0, 10)
+	if err != nil {
+		t.Fatalf("ListMessages: %v", err)
+	}
+	if page.Total != 2 || len(page.Messages) != 2 {
+		t.Fatalf("ListMessages total=%d len=%d, want 2 and 2", page.Total, len(page.Messages))
+	}
+
+	// Newest first: the appended message comes before the backend's own message
+	header := page.Messages[0]
+	if header.Subject != "Test subject" {
+		t.Errorf("newest subject = %q, want %q", header.Subject, "Test subject")
+	}
+	// The envelope Message-ID keeps its angle brackets
+	if header.MessageID != "<"+messageID+">" {
+		t.Errorf("MessageID = %q, want <%s>", header.MessageID, messageID)
+	}
+	if len(header.From) != 1 || header.From[0] != "sender@example.org" {
+		t.Errorf("From = %v, want [sender@example.org]", header.From)
+	}
+
+	// Paging past the first message returns the older one
+	older, err := session.ListMessages("INBOX", 1, 1)
+	if err != nil {
+		t.Fatalf("ListMessages page 1: %v", err)
+	}
+	if len(older.Messages) != 1 || older.Messages[0].Subject != "A little message, just for you" {
+		t.Fatalf("page 1 = %+v, want the backend's message", older.Messages)
+	}
+
+	msg, err := session.FetchMessage("INBOX", header.UID)
+	if err != nil {
+		t.Fatalf("FetchMessage: %v", err)
+	}
+	if msg.TextBody != "Hello from the test" {
+		t.Errorf("TextBody = %q, want %q", msg.TextBody, "Hello from the test")
+	}
+	if len(msg.Attachments) != 1 || msg.Attachments[0].Filename != "notes.bin" {
+		t.Fatalf("Attachments = %+v, want notes.bin", msg.Attachments)
+	}
+
+	att, err := session.FetchAttachment("INBOX", header.UID, msg.Attachments[0].ID)
+	if err != nil {
+		t.Fatalf("FetchAttachment: %v", err)
+	}
+	if string(att.Data) != "\x01\x02\x03\x04" {
+		t.Errorf("attachment data = %v, want [1 2 3 4]", att.Data)
+	}
+
+	// Fetching must not mark the message as seen
+	headers, err := session.FetchHeaders([]uint32{header.UID})
+	if err != nil {
+		t.Fatalf("FetchHeaders: %v", err)
+	}
+	if len(headers) != 1 || headers[0].Seen() {
+		t.Errorf("FetchHeaders = %+v, want one unseen message", headers)
+	}
+}
+
+func TestDialIMAPRejectsWrongPassword(t *testing.T) {
+	creds := startTestIMAPServer(t)
+	creds.Password = "wrong"
+
+	if session, err := DialIMAP(creds); err == nil {
+		session.Close()
+		t.Fatal("DialIMAP succeeded with a wrong password")
+	}
+}
@@ -0,0 +1,90 @@
+package mailclient
+
+import (
+	"bytes"
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/emersion/go-sasl"
+	"github.com/emersion/go-smtp"
+)
+
+// SMTPDialTimeout is the maximum time allowed to connect to the local SMTP listener
+const SMTPDialTimeout = 10 * time.Second
+
+// SMTPCredentials contains the address and login for the local SMTP listener
+type SMTPCredentials struct {
+	// Address is the SMTP listener address (e.g., "127.0.0.1:1025")
+	Address string
+
+	// Username is the login name (the full yggmail address)
+	Username string
+
+	// Password is the yggmail password
+	Password string
+}
+
+// Submit delivers a raw message to the local SMTP listener for sending
+// The listener queues the message; delivery outcome is reported later
+// through the yggmail OnMailSent/OnMailError callbacks
+func Submit(creds SMTPCredentials, from string, to []string, data []byte) error {
+	if creds.Address == "" {
+		return fmt.Errorf("SMTP address cannot be empty")
+	}
+	if creds.Password == "" {
+		return fmt.Errorf("SMTP password cannot be empty")
+	}
+	if len(to) == 0 {
+		return fmt.Errorf("at least one recipient is required")
+	}
+
+	conn, err := net.DialTimeout("tcp", creds.Address, SMTPDialTimeout)
+	if err != nil {
+		return fmt.Errorf("failed to connect to SMTP listener: %w", err)
+	}
+
+	host, _, err := net.SplitHostPort(creds.Address)
+	if err != nil {
+		host = creds.Address
+	}
+
+	c, err := smtp.NewClient(conn, host)
+	if err != nil {
+		conn.Close()
+		return fmt.Errorf("failed to create SMTP client: %w", err)
+	}
+	defer c.Close()
+
+	if err := c.Hello("localhost"); err != nil {
+		return fmt.Errorf("SMTP greeting failed: %w", err)
+	}
+
+	// The local listener is plain text on loopback, PLAIN auth is expected
+	if err := c.Auth(sasl.NewPlainClient("", creds.Username, creds.Password)); err != nil {
+		return fmt.Errorf("SMTP authentication failed: %w", err)
+	}
+
+	if err := c.Mail(from, nil); err != nil {
+		return fmt.Errorf("SMTP sender rejected: %w", err)
+	}
+	for _, rcpt := range to {
+		if err := c.Rcpt(rcpt); err != nil {
+			return fmt.Errorf("SMTP recipient %s rejected: %w", rcpt, err)
+		}
+	}
+
+	w, err := c.Data()
+	if err != nil {
+		return fmt.Errorf("SMTP DATA failed: %w", err)
+	}
+	if _, err := bytes.NewReader(data).WriteTo(w); err != nil {
+		w.Close()
+		return fmt.Errorf("failed to write message data: %w", err)
+	}
+	if err := w.Close(); err != nil {
+		return fmt.Errorf("SMTP server rejected message: %w", err)
+	}
+
+	return c.Quit()
+}
@@ -0,0 +1,149 @@
+package mailclient
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"mime"
+	"strings"
+
+	"github.com/emersion/go-message"
+	"github.com/emersion/go-message/mail"
+	"github.com/emersion/go-message/textproto"
+)
+
+// StrippedAttachment describes an attachment removed from a message
+type StrippedAttachment struct {
+	// PartID is the IMAP part path of the removed part (e.g., "2" or "1.3")
+	PartID string
+
+	// Filename is the attachment filename (may be empty)
+	Filename string
+
+	// ContentType is the MIME type of the removed part
+	ContentType string
+
+	// Size is the encoded size of the removed part in bytes
+	Size int
+}
+
+// StripAttachments removes attachment parts larger than minSize bytes from a raw message
+// Each removed part is replaced by a short text/plain note built by noteFunc.
+// All other parts, including their transfer encoding, are copied byte for byte.
+// Returns the original bytes unchanged if no part was removed
+func StripAttachments(raw []byte, minSize int, noteFunc func(att StrippedAttachment) string) ([]byte, []StrippedAttachment, error) {
+	br := bufio.NewReader(bytes.NewReader(raw))
+	header, err := textproto.ReadHeader(br)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to parse message header: %w", err)
+	}
+	body, err := io.ReadAll(br)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read message body: %w", err)
+	}
+
+	newHeader, newBody, stripped, err := stripEntity(header, body, "", minSize, noteFunc)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(stripped) == 0 {
+		return raw, nil, nil
+	}
+
+	var buf bytes.Buffer
+	if err := textproto.WriteHeader(&buf, newHeader); err != nil {
+		return nil, nil, fmt.Errorf("failed to write message header: %w", err)
+	}
+	buf.Write(newBody)
+	return buf.Bytes(), stripped, nil
+}
+
+// stripEntity rewrites one entity, recursing into multipart bodies
+// Part IDs follow the same numbering as walkEntity
+func stripEntity(header textproto.Header, body []byte, id string, minSize int, noteFunc func(att StrippedAttachment) string) (textproto.Header, []byte, []StrippedAttachment, error) {
+	mediaType, params, _ := mime.ParseMediaType(header.Get("Content-Type"))
+	if mediaType == "" {
+		mediaType = "text/plain"
+	}
+
+	if strings.HasPrefix(mediaType, "multipart/") && params["boundary"] != "" {
+		var (
+			buf      bytes.Buffer
+			stripped []StrippedAttachment
+		)
+		mr := textproto.NewMultipartReader(bytes.NewReader(body), params["boundary"])
+		mw := textproto.NewMultipartWriter(&buf)
+		if err := mw.SetBoundary(params["boundary"]); err != nil {
+			return header, nil, nil, fmt.Errorf("invalid multipart boundary: %w", err)
+		}
+
+		for i := 1; ; i++ {
+			part, err := mr.NextPart()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				return header, nil, nil, fmt.Errorf("failed to read message part: %w", err)
+			}
+			partBody, err := io.ReadAll(part)
+			if err != nil {
+				return header, nil, nil, fmt.Errorf("failed to read message part: %w", err)
+			}
+
+			childID := fmt.Sprintf("%d", i)
+			if id != "" {
+				childID = id + "." + childID
+			}
+
+			childHeader, childBody, childStripped, err := stripEntity(part.Header, partBody, childID, minSize, noteFunc)
+			if err != nil {
+				return header, nil, nil, err
+			}
+			stripped = append(stripped, childStripped...)
+
+			w, err := mw.CreatePart(childHeader)
+			if err != nil {
+				return header, nil, nil, fmt.Errorf("failed to write message part: %w", err)
+			}
+			if _, err := w.Write(childBody); err != nil {
+				return header, nil, nil, fmt.Errorf("failed to write message part: %w", err)
+			}
+		}
+		if err := mw.Close(); err != nil {
+			return header, nil, nil, fmt.Errorf("failed to finalize multipart body: %w", err)
+		}
+		return header, buf.Bytes(), stripped, nil
+	}
+
+	// The body of a single-part message is part "1"
+	if id == "" {
+		id = "1"
+	}
+
+	disposition, _, _ := mime.ParseMediaType(header.Get("Content-Disposition"))
+	filename, _ := (&mail.AttachmentHeader{Header: message.Header{Header: header}}).Filename()
+	part := MessagePart{ID: id, ContentType: mediaType, Disposition: disposition, Filename: filename}
+	if !part.IsAttachment() || len(body) <= minSize {
+		return header, body, nil, nil
+	}
+
+	att := StrippedAttachment{
+		PartID:      id,
+		Filename:    filename,
+		ContentType: mediaType,
+		Size:        len(body),
+	}
+
+	replacement := header.Copy()
+	replacement.Del("Content-Id")
+	replacement.Set("Content-Type", mime.FormatMediaType("text/plain", map[string]string{"charset": "utf-8"}))
+	replacement.Set("Content-Disposition", "inline")
+	replacement.Set("Content-Transfer-Encoding", "8bit")
+
+	note := noteFunc(att)
+	if !strings.HasSuffix(note, "\r\n") {
+		note += "\r\n"
+	}
+	return replacement, []byte(note), []StrippedAttachment{att}, nil
+}
@@ -30,6 +30,10 @@ type PeerInfoDTO struct {
 	Address string `json:"address"`
 	// Enabled indicates if this peer is enabled in configuration
 	Enabled bool `json:"enabled"`
+	// BypassProxy indicates if this peer is excluded from the global proxy
+	BypassProxy bool `json:"bypassProxy"`
+	// Proxied indicates if connections to this peer actually go through the proxy
+	Proxied bool `json:"proxied"`
 	// Connected indicates if currently connected to this peer
 	Connected bool `json:"connected"`
 	// Latency is the round-trip time in milliseconds (0 if not connected)
@@ -66,6 +70,14 @@ type ConfigDTO struct {
 	IMAPAddress string `json:"imapAddress"`
 	// DatabasePath is the path to the yggmail database file
 	DatabasePath string `json:"databasePath"`
+	// ProxyEnabled indicates if peer connections are routed through the SOCKS5 proxy
+	ProxyEnabled bool `json:"proxyEnabled"`
+	// ProxyAddress is the SOCKS5 proxy address (host:port)
+	ProxyAddress string `json:"proxyAddress"`
+	// AutoconfigServeQR indicates if the autoconfig page serves the mail address QR code
+	AutoconfigServeQR bool `json:"autoconfigServeQR"`
+	// AutoconfigRandomPort indicates if the autoconfig server runs on a random port
+	AutoconfigRandomPort bool `json:"autoconfigRandomPort"`
 }
 
 // PeerConfigDTO represents a peer configuration
@@ -74,6 +86,8 @@ type PeerConfigDTO struct {
 	Address string `json:"address"`
 	// Enabled indicates if this peer is enabled
 	Enabled bool `json:"enabled"`
+	// BypassProxy indicates if this peer connects directly instead of through the proxy
+	BypassProxy bool `json:"bypassProxy"`
 }
 
 // LogEventDTO represents a log message event
@@ -98,14 +112,28 @@ type MailEventDTO struct {
 	Mailbox string `json:"mailbox"`
 	// From is the sender's email address
 	From string `json:"from"`
-	// To is the recipient's email address
+	// To is the recipient's email address (comma-separated for several recipients)
 	To string `json:"to"`
+	// FromName is the sender's contact name, if known
+	FromName string `json:"fromName,omitempty"`
+	// ToName is the recipient's contact name, if known
+	ToName string `json:"toName,omitempty"`
+	// FromDisplay is From for display ("Name <address>" for known contacts)
+	FromDisplay string `json:"fromDisplay"`
+	// ToDisplay is To for display ("Name <address>" for known contacts)
+	ToDisplay string `json:"toDisplay"`
 	// Subject is the email subject
 	Subject string `json:"subject"`
 	// MailID is the internal mail ID
 	MailID int `json:"mailId"`
 	// ErrorMessage contains error details if Type is "error"
 	ErrorMessage string `json:"errorMessage,omitempty"`
+	// Notification is the notification action decided by the rules (notify, silent, suppress)
+	// Only set for new_mail events
+	Notification string `json:"notification,omitempty"`
+	// Filter is the sender filter action (junk, delete, quarantine) if the message was filed
+	// Only set for new_mail events
+	Filter string `json:"filter,omitempty"`
 }
 
 // ConnectionEventDTO represents a connection status change
@@ -170,6 +198,838 @@ type StorageStatsDTO struct {
 	TotalSizeMB float64 `json:"totalSizeMB"`
 	// MaxMessageSizeMB is the current maximum message size limit in megabytes
 	MaxMessageSizeMB int64 `json:"maxMessageSizeMB"`
+	// Encrypted indicates the database and filestore are encrypted at rest
+	Encrypted bool `json:"encrypted"`
+	// EncryptedSizeMB is the size of the encrypted archive in megabytes
+	EncryptedSizeMB float64 `json:"encryptedSizeMB"`
+}
+
+// ProxyTestResultDTO represents the result of a SOCKS5 proxy connectivity test
+type ProxyTestResultDTO struct {
+	// Reachable indicates if the proxy accepted a SOCKS5 handshake
+	Reachable bool `json:"reachable"`
+	// LatencyMs is the handshake round-trip time in milliseconds
+	LatencyMs int64 `json:"latencyMs"`
+	// ErrorMessage contains error details if Reachable is false
+	ErrorMessage string `json:"errorMessage,omitempty"`
+}
+
+// ComposeMailDTO contains a message composed in the frontend for sending
+type ComposeMailDTO struct {
+	// To is the list of primary recipient addresses
+	To []string `json:"to"`
+	// Cc is the list of carbon-copy recipient addresses
+	Cc []string `json:"cc,omitempty"`
+	// Subject is the message subject
+	Subject string `json:"subject"`
+	// TextBody is the plain-text body
+	TextBody string `json:"textBody"`
+	// HTMLBody is the optional HTML alternative body
+	HTMLBody string `json:"htmlBody,omitempty"`
+	// Attachments is the list of file paths to attach
+	Attachments []string `json:"attachments,omitempty"`
+}
+
+// MailboxDTO contains summary information about a mailbox
+type MailboxDTO struct {
+	// Name is the mailbox name (e.g., "INBOX")
+	Name string `json:"name"`
+	// Messages is the total number of messages
+	Messages uint32 `json:"messages"`
+	// Unseen is the number of unread messages
+	Unseen uint32 `json:"unseen"`
+}
+
+// MessageHeaderDTO contains the envelope information of a stored message
+type MessageHeaderDTO struct {
+	// UID is the unique identifier of the message within its mailbox
+	UID uint32 `json:"uid"`
+	// MessageID is the Message-ID header value
+	MessageID string `json:"messageId"`
+	// Subject is the message subject
+	Subject string `json:"subject"`
+	// From is the list of sender addresses
+	From []string `json:"from"`
+	// To is the list of primary recipient addresses
+	To []string `json:"to"`
+	// Cc is the list of carbon-copy recipient addresses
+	Cc []string `json:"cc"`
+	// Date is the message date (RFC3339 format)
+	Date string `json:"date"`
+	// SizeBytes is the size of the raw message in bytes
+	SizeBytes uint32 `json:"sizeBytes"`
+	// Seen indicates whether the message has been read
+	Seen bool `json:"seen"`
+	// Flags is the list of IMAP flags
+	Flags []string `json:"flags"`
+}
+
+// MessagePageDTO contains one page of message headers, newest first
+type MessagePageDTO struct {
+	// Mailbox is the mailbox the page was read from
+	Mailbox string `json:"mailbox"`
+	// Total is the number of messages in the mailbox
+	Total uint32 `json:"total"`
+	// Page is the zero-based page index
+	Page int `json:"page"`
+	// PageSize is the maximum number of messages per page
+	PageSize int `json:"pageSize"`
+	// Messages is the list of headers on this page
+	Messages []MessageHeaderDTO `json:"messages"`
+}
+
+// MessagePartDTO describes a node in the MIME tree of a message
+type MessagePartDTO struct {
+	// ID is the part path used to download the part (e.g., "2.1")
+	ID string `json:"id"`
+	// ContentType is the media type
+	ContentType string `json:"contentType"`
+	// Filename is the attachment filename, if any
+	Filename string `json:"filename,omitempty"`
+	// Disposition is the Content-Disposition value
+	Disposition string `json:"disposition,omitempty"`
+	// SizeBytes is the decoded size of the part in bytes
+	SizeBytes int `json:"sizeBytes"`
+	// Children contains the sub-parts of a multipart node
+	Children []MessagePartDTO `json:"children,omitempty"`
+}
+
+// MessageDTO contains a fully fetched message
+type MessageDTO struct {
+	// Header contains the envelope information
+	Header MessageHeaderDTO `json:"header"`
+	// TextBody is the plain-text body
+	TextBody string `json:"textBody"`
+	// HTMLBody is the HTML body, if present
+	HTMLBody string `json:"htmlBody"`
+	// Structure is the root of the MIME tree
+	Structure MessagePartDTO `json:"structure"`
+	// Attachments is the flat list of downloadable parts
+	Attachments []MessagePartDTO `json:"attachments"`
+}
+
+// ContactDTO represents an address book entry
+type ContactDTO struct {
+	// DisplayName is the human-readable name
+	DisplayName string `json:"displayName"`
+	// Address is the yggmail address
+	Address string `json:"address"`
+	// Notes contains free-form user notes
+	Notes string `json:"notes"`
+	// DateAdded is when the contact was added (RFC3339 format)
+	DateAdded string `json:"dateAdded"`
+	// Verified indicates the user confirmed the key belongs to this person
+	Verified bool `json:"verified"`
+}
+
+// ContactImportResultDTO contains the outcome of a vCard import
+type ContactImportResultDTO struct {
+	// Success indicates if the file was read and parsed
+	Success bool `json:"success"`
+	// Message contains additional information or error message
+	Message string `json:"message,omitempty"`
+	// Added is the number of new contacts
+	Added int `json:"added"`
+	// Updated is the number of existing contacts that were updated
+	Updated int `json:"updated"`
+	// Skipped is the number of entries that were ignored
+	Skipped int `json:"skipped"`
+	// Errors describes why entries were skipped
+	Errors []string `json:"errors"`
+}
+
+// QRCodeDTO contains a rendered QR code
+type QRCodeDTO struct {
+	// Format is the image format ("png" or "svg")
+	Format string `json:"format"`
+	// Data is a PNG data: URL or SVG markup, ready to display
+	Data string `json:"data"`
+	// ContainsPassword indicates the QR code encodes the account password
+	ContainsPassword bool `json:"containsPassword"`
+}
+
+// NotificationRuleDTO represents a new-mail notification rule
+type NotificationRuleDTO struct {
+	// Name is a user-visible label for the rule
+	Name string `json:"name"`
+	// Enabled indicates if the rule is evaluated
+	Enabled bool `json:"enabled"`
+	// Sender is an address or glob pattern (e.g., "1a2b*@yggmail")
+	Sender string `json:"sender"`
+	// Contact restricts by address book membership ("", "known", "unknown", "verified")
+	Contact string `json:"contact"`
+	// Mailbox is the mailbox name to match
+	Mailbox string `json:"mailbox"`
+	// SubjectPattern is a case-insensitive regular expression
+	SubjectPattern string `json:"subjectPattern"`
+	// Action is the notification action (notify, silent, suppress)
+	Action string `json:"action"`
+	// Override applies the action even during quiet hours and do not disturb
+	Override bool `json:"override"`
+}
+
+// NotificationSettingsDTO contains new-mail notification preferences
+type NotificationSettingsDTO struct {
+	// DoNotDisturb suppresses all notifications except override rules
+	DoNotDisturb bool `json:"doNotDisturb"`
+	// QuietHoursEnabled indicates if quiet hours are active
+	QuietHoursEnabled bool `json:"quietHoursEnabled"`
+	// QuietHoursStart is the start time in HH:MM format
+	QuietHoursStart string `json:"quietHoursStart"`
+	// QuietHoursEnd is the end time in HH:MM format
+	QuietHoursEnd string `json:"quietHoursEnd"`
+	// DefaultAction applies when no rule matches
+	DefaultAction string `json:"defaultAction"`
+	// Rules are evaluated in order; the first match wins
+	Rules []NotificationRuleDTO `json:"rules"`
+}
+
+// NotificationDTO is an in-app notification for platforms without native notifications
+type NotificationDTO struct {
+	// Title is the notification summary line
+	Title string `json:"title"`
+	// Body is the notification text
+	Body string `json:"body"`
+	// Silent indicates the notification should not play a sound
+	Silent bool `json:"silent"`
+}
+
+// DeliveryTransitionDTO represents a state change of an outgoing message
+type DeliveryTransitionDTO struct {
+	// State is the new state (sending, queued, delivered, failed, retried)
+	State string `json:"state"`
+	// Time is when the transition happened (RFC3339 format)
+	Time string `json:"time"`
+	// Reason explains the transition (e.g., the delivery error)
+	Reason string `json:"reason,omitempty"`
+}
+
+// OutgoingMessageDTO represents the delivery state of an outgoing message
+type OutgoingMessageDTO struct {
+	// Key identifies the tracked message
+	Key string `json:"key"`
+	// MessageID is the Message-ID header (empty if unknown)
+	MessageID string `json:"messageId"`
+	// To is the list of recipients
+	To []string `json:"to"`
+	// Subject is the message subject
+	Subject string `json:"subject"`
+	// SizeBytes is the size of the message in bytes
+	SizeBytes int64 `json:"sizeBytes"`
+	// State is the current delivery state
+	State string `json:"state"`
+	// Error is the reason of the last failure
+	Error string `json:"error,omitempty"`
+	// Attempts is the number of delivery attempts seen
+	Attempts int `json:"attempts"`
+	// CreatedAt is when the message was sent (RFC3339 format)
+	CreatedAt string `json:"createdAt"`
+	// UpdatedAt is when the state last changed (RFC3339 format)
+	UpdatedAt string `json:"updatedAt"`
+	// History lists all state transitions, oldest first
+	History []DeliveryTransitionDTO `json:"history"`
+	// Recipients lists the delivery state of each recipient, in To order
+	Recipients []RecipientDeliveryDTO `json:"recipients"`
+}
+
+// RecipientDeliveryDTO represents the delivery state of one recipient
+type RecipientDeliveryDTO struct {
+	// Address is the recipient address
+	Address string `json:"address"`
+	// State is the recipient state (delivered, failed) or the message state if not reported yet
+	State string `json:"state"`
+	// Error is the delivery error for this recipient
+	Error string `json:"error,omitempty"`
+}
+
+// RetentionPolicyDTO represents a per-mailbox retention policy
+type RetentionPolicyDTO struct {
+	// Mailbox is the mailbox the policy applies to
+	Mailbox string `json:"mailbox"`
+	// Enabled indicates if the policy is applied
+	Enabled bool `json:"enabled"`
+	// MaxAgeDays removes messages older than this many days (0 = off)
+	MaxAgeDays int `json:"maxAgeDays"`
+	// MaxMessages keeps at most this many of the newest messages (0 = off)
+	MaxMessages int `json:"maxMessages"`
+	// Action is what happens to removed messages ("delete" or "move")
+	Action string `json:"action"`
+	// MoveTo is the destination mailbox for the "move" action
+	MoveTo string `json:"moveTo"`
+	// KeepFlagged excludes flagged messages from the policy
+	KeepFlagged bool `json:"keepFlagged"`
+	// PurgeAttachmentsOverKB removes attachments larger than this size (0 = off)
+	PurgeAttachmentsOverKB int `json:"purgeAttachmentsOverKB"`
+	// PurgeAttachmentsAfterDays only purges attachments of messages older than this
+	PurgeAttachmentsAfterDays int `json:"purgeAttachmentsAfterDays"`
+}
+
+// RetentionSettingsDTO represents retention policies and their schedule
+type RetentionSettingsDTO struct {
+	// Enabled turns on scheduled retention runs
+	Enabled bool `json:"enabled"`
+	// IntervalHours is the time between scheduled runs
+	IntervalHours int `json:"intervalHours"`
+	// LastRun is when retention last ran (RFC3339 format, empty if never)
+	LastRun string `json:"lastRun"`
+	// Policies is the list of per-mailbox policies
+	Policies []RetentionPolicyDTO `json:"policies"`
+}
+
+// RetentionItemDTO represents one message affected by a retention run
+type RetentionItemDTO struct {
+	// Mailbox is the mailbox the message was in
+	Mailbox string `json:"mailbox"`
+	// UID is the message UID at the time of the run
+	UID uint32 `json:"uid"`
+	// Subject is the message subject
+	Subject string `json:"subject"`
+	// From is the sender address
+	From string `json:"from"`
+	// Date is when the message was received (RFC3339 format)
+	Date string `json:"date"`
+	// SizeBytes is the size of the message before the action
+	SizeBytes int64 `json:"sizeBytes"`
+	// Action is "delete", "move" or "purge_attachments"
+	Action string `json:"action"`
+	// Target is the destination mailbox for moved messages
+	Target string `json:"target,omitempty"`
+	// Reason explains which rule matched
+	Reason string `json:"reason"`
+	// Attachments lists the names of purged attachments
+	Attachments []string `json:"attachments,omitempty"`
+	// FreedBytes is the storage released by the action
+	FreedBytes int64 `json:"freedBytes"`
+	// Error is set if the action failed
+	Error string `json:"error,omitempty"`
+}
+
+// RetentionReportDTO represents the outcome of a retention run or preview
+type RetentionReportDTO struct {
+	// StartedAt is when the run started (RFC3339 format)
+	StartedAt string `json:"startedAt"`
+	// FinishedAt is when the run finished (RFC3339 format)
+	FinishedAt string `json:"finishedAt"`
+	// DryRun indicates nothing was changed
+	DryRun bool `json:"dryRun"`
+	// Scheduled indicates the run was started by the scheduler
+	Scheduled bool `json:"scheduled"`
+	// Items lists the affected messages
+	Items []RetentionItemDTO `json:"items"`
+	// Errors lists policy-level failures
+	Errors []string `json:"errors"`
+	// Processed is the number of messages successfully handled
+	Processed int `json:"processed"`
+	// FreedBytes is the total storage released
+	FreedBytes int64 `json:"freedBytes"`
+}
+
+// QuotaSettingsDTO represents the storage budget configuration
+type QuotaSettingsDTO struct {
+	// Enabled turns on quota monitoring
+	Enabled bool `json:"enabled"`
+	// BudgetMB is the storage budget in megabytes
+	BudgetMB int64 `json:"budgetMB"`
+	// WarningThresholds are warning levels in percent of the budget
+	WarningThresholds []int `json:"warningThresholds"`
+	// EnforceHardLimit lowers the accepted incoming message size once the budget is reached
+	EnforceHardLimit bool `json:"enforceHardLimit"`
+	// RestrictedMessageSizeMB is the incoming message size limit while over budget
+	RestrictedMessageSizeMB int64 `json:"restrictedMessageSizeMB"`
+}
+
+// QuotaStatusDTO represents storage usage against the budget
+type QuotaStatusDTO struct {
+	// Enabled indicates if quota monitoring is on
+	Enabled bool `json:"enabled"`
+	// UsedMB is the current total storage usage
+	UsedMB float64 `json:"usedMB"`
+	// BudgetMB is the configured budget
+	BudgetMB int64 `json:"budgetMB"`
+	// Percent is the usage in percent of the budget
+	Percent float64 `json:"percent"`
+	// State is "ok", "warning" or "exceeded"
+	State string `json:"state"`
+	// Threshold is the highest warning threshold crossed (0 if none)
+	Threshold int `json:"threshold"`
+	// Restricted indicates the incoming message size is currently lowered
+	Restricted bool `json:"restricted"`
+	// CheckedAt is when usage was measured (RFC3339 format)
+	CheckedAt string `json:"checkedAt"`
+}
+
+// MailboxUsageDTO represents storage used by one mailbox
+type MailboxUsageDTO struct {
+	// Name is the mailbox name
+	Name string `json:"name"`
+	// Messages is the number of messages
+	Messages int `json:"messages"`
+	// SizeBytes is the total size of the messages
+	SizeBytes int64 `json:"sizeBytes"`
+}
+
+// LargeMessageDTO represents one of the largest stored messages
+type LargeMessageDTO struct {
+	// Mailbox is the mailbox containing the message
+	Mailbox string `json:"mailbox"`
+	// UID is the message UID
+	UID uint32 `json:"uid"`
+	// Subject is the message subject
+	Subject string `json:"subject"`
+	// From is the sender address
+	From string `json:"from"`
+	// Date is when the message was received (RFC3339 format)
+	Date string `json:"date"`
+	// SizeBytes is the message size
+	SizeBytes int64 `json:"sizeBytes"`
+}
+
+// StorageUsageDTO represents a breakdown of storage usage
+type StorageUsageDTO struct {
+	// Mailboxes is sorted by size, largest first
+	Mailboxes []MailboxUsageDTO `json:"mailboxes"`
+	// Largest lists the largest messages across all mailboxes
+	Largest []LargeMessageDTO `json:"largest"`
+}
+
+// ExportOptionsDTO represents what to export and where
+type ExportOptionsDTO struct {
+	// Destination is the directory the export is written to
+	Destination string `json:"destination"`
+	// Format is "mbox", "maildir" or "eml"
+	Format string `json:"format"`
+	// Mailboxes is the list of mailboxes to export (empty exports all)
+	Mailboxes []string `json:"mailboxes"`
+	// Since is the start of the date range (YYYY-MM-DD or RFC3339, empty for no limit)
+	Since string `json:"since"`
+	// Before is the exclusive end of the date range (YYYY-MM-DD or RFC3339, empty for no limit)
+	Before string `json:"before"`
+}
+
+// ExportProgressDTO represents the progress of a running export
+type ExportProgressDTO struct {
+	// Progress is the overall progress (0-100)
+	Progress int `json:"progress"`
+	// Message is a human-readable description of the current step
+	Message string `json:"message"`
+	// Mailbox is the mailbox being exported
+	Mailbox string `json:"mailbox"`
+	// Current is the number of messages handled so far
+	Current int `json:"current"`
+	// Total is the number of messages selected for export
+	Total int `json:"total"`
+}
+
+// ExportResultDTO represents the outcome of an export
+type ExportResultDTO struct {
+	// Destination is the directory the export was written to
+	Destination string `json:"destination"`
+	// Format is the export format
+	Format string `json:"format"`
+	// Mailboxes is the list of exported mailboxes
+	Mailboxes []string `json:"mailboxes"`
+	// Exported is the number of messages written in this run
+	Exported int `json:"exported"`
+	// Skipped is the number of messages already written by an interrupted run
+	Skipped int `json:"skipped"`
+	// Resumed indicates an interrupted export was continued
+	Resumed bool `json:"resumed"`
+	// Cancelled indicates the export was stopped and can be resumed
+	Cancelled bool `json:"cancelled"`
+	// Errors lists messages or mailboxes that could not be exported
+	Errors []string `json:"errors"`
+	// StartedAt is when the run started (RFC3339 format)
+	StartedAt string `json:"startedAt"`
+	// FinishedAt is when the run finished (RFC3339 format)
+	FinishedAt string `json:"finishedAt"`
+}
+
+// ImportOptionsDTO represents what to import and where
+type ImportOptionsDTO struct {
+	// Paths are mbox files, .eml files, Maildir directories or folders of .eml files
+	Paths []string `json:"paths"`
+	// Mailbox is the target mailbox (created if missing)
+	Mailbox string `json:"mailbox"`
+	// Format forces "mbox", "maildir" or "eml" (empty detects per path)
+	Format string `json:"format"`
+}
+
+// ImportProgressDTO represents the progress of a running import
+type ImportProgressDTO struct {
+	// Progress is the overall progress (0-100)
+	Progress int `json:"progress"`
+	// Message is a human-readable description of the current step
+	Message string `json:"message"`
+	// Path is the file being imported
+	Path string `json:"path"`
+	// Messages is the number of messages handled so far
+	Messages int `json:"messages"`
+}
+
+// ImportFileResultDTO represents the outcome for one imported file or directory
+type ImportFileResultDTO struct {
+	// Path is the imported file or directory
+	Path string `json:"path"`
+	// Format is the detected or forced format
+	Format string `json:"format"`
+	// Imported is the number of messages appended
+	Imported int `json:"imported"`
+	// Duplicates is the number of messages skipped because their Message-ID exists
+	Duplicates int `json:"duplicates"`
+	// Failed is the number of messages that could not be imported
+	Failed int `json:"failed"`
+	// Errors lists the failures
+	Errors []string `json:"errors"`
+}
+
+// ImportResultDTO represents the outcome of an import
+type ImportResultDTO struct {
+	// Mailbox is the target mailbox
+	Mailbox string `json:"mailbox"`
+	// Files contains the per-file report
+	Files []ImportFileResultDTO `json:"files"`
+	// Imported is the total number of messages appended
+	Imported int `json:"imported"`
+	// Duplicates is the total number of skipped duplicates
+	Duplicates int `json:"duplicates"`
+	// Failed is the total number of failed messages
+	Failed int `json:"failed"`
+	// Cancelled indicates the import was stopped before all files were read
+	Cancelled bool `json:"cancelled"`
+	// StartedAt is when the run started (RFC3339 format)
+	StartedAt string `json:"startedAt"`
+	// FinishedAt is when the run finished (RFC3339 format)
+	FinishedAt string `json:"finishedAt"`
+}
+
+// AutoReplySettingsDTO represents the vacation auto-responder configuration
+type AutoReplySettingsDTO struct {
+	// Enabled turns on automatic replies
+	Enabled bool `json:"enabled"`
+	// StartDate is the first day replies are sent (YYYY-MM-DD, empty for immediately)
+	StartDate string `json:"startDate"`
+	// EndDate is the last day replies are sent (YYYY-MM-DD, empty for no end)
+	EndDate string `json:"endDate"`
+	// IntervalDays is the minimum time between replies to the same sender
+	IntervalDays int `json:"intervalDays"`
+	// Subject is the reply subject template ({subject}, {sender}, {name}, {end_date})
+	Subject string `json:"subject"`
+	// Body is the reply body template ({subject}, {sender}, {name}, {end_date})
+	Body string `json:"body"`
+	// ContactsOnly restricts replies to senders in the address book
+	ContactsOnly bool `json:"contactsOnly"`
+	// Allowlist restricts replies to these addresses
+	Allowlist []string `json:"allowlist"`
+}
+
+// AutoReplyEventDTO represents an automatic reply that was sent
+type AutoReplyEventDTO struct {
+	// To is the address the reply was sent to
+	To string `json:"to"`
+	// Subject is the reply subject
+	Subject string `json:"subject"`
+}
+
+// HookDTO represents a user-defined hook
+type HookDTO struct {
+	// Name identifies the hook and its log
+	Name string `json:"name"`
+	// Enabled indicates if the hook runs
+	Enabled bool `json:"enabled"`
+	// Events are event names or patterns ("service.status", "mail.*", "connection.connected", "*")
+	Events []string `json:"events"`
+	// Command is the absolute path of the executable to run (empty if URL is set)
+	Command string `json:"command"`
+	// Args are passed to Command
+	Args []string `json:"args"`
+	// URL is a localhost http(s) URL to POST to (empty if Command is set)
+	URL string `json:"url"`
+	// TimeoutSeconds is how long one run may take
+	TimeoutSeconds int `json:"timeoutSeconds"`
+	// MaxConcurrent is how many runs may overlap
+	MaxConcurrent int `json:"maxConcurrent"`
+}
+
+// HookSettingsDTO represents the hook configuration
+type HookSettingsDTO struct {
+	// Enabled turns on hook execution
+	Enabled bool `json:"enabled"`
+	// Hooks is the list of configured hooks
+	Hooks []HookDTO `json:"hooks"`
+}
+
+// HookRunResultDTO represents the outcome of one hook run
+type HookRunResultDTO struct {
+	// Hook is the hook name
+	Hook string `json:"hook"`
+	// Event is the event name
+	Event string `json:"event"`
+	// Success indicates the command exited with 0 or the URL returned 2xx
+	Success bool `json:"success"`
+	// ExitCode is the command exit code or the HTTP status
+	ExitCode int `json:"exitCode"`
+	// DurationMs is how long the run took in milliseconds
+	DurationMs int64 `json:"durationMs"`
+	// Output is the beginning of the command output or response body
+	Output string `json:"output"`
+	// Error describes why the run failed
+	Error string `json:"error,omitempty"`
+}
+
+// SenderFilterSettingsDTO represents sender filtering keyed on the sender's public key
+type SenderFilterSettingsDTO struct {
+	// Enabled turns on sender filtering
+	Enabled bool `json:"enabled"`
+	// AllowlistOnly quarantines mail from senders not in the allowlist or contacts
+	AllowlistOnly bool `json:"allowlistOnly"`
+	// BlockAction is what happens to mail from blocked senders (junk, delete)
+	BlockAction string `json:"blockAction"`
+	// JunkMailbox receives mail from blocked senders
+	JunkMailbox string `json:"junkMailbox"`
+	// QuarantineMailbox receives mail from unknown or rate-limited senders
+	QuarantineMailbox string `json:"quarantineMailbox"`
+	// RateLimit is the maximum number of messages per sender per window (0 for unlimited)
+	RateLimit int `json:"rateLimit"`
+	// RateWindowMinutes is the rate limit window
+	RateWindowMinutes int `json:"rateWindowMinutes"`
+	// Blocked contains the public keys of blocked senders
+	Blocked []string `json:"blocked"`
+	// Allowed contains the public keys of allowed senders
+	Allowed []string `json:"allowed"`
+}
+
+// SenderActionDTO identifies a sender to block or allow
+// Mailbox and MailID are optional and refer to a message to file right away
+type SenderActionDTO struct {
+	// Address is the sender address or public key
+	Address string `json:"address"`
+	// Mailbox is the mailbox containing the message
+	Mailbox string `json:"mailbox,omitempty"`
+	// MailID is the message UID in the mailbox
+	MailID int `json:"mailId,omitempty"`
+}
+
+// SenderFilterEventDTO represents a filtering decision that was carried out
+type SenderFilterEventDTO struct {
+	// Sender is the sender address
+	Sender string `json:"sender"`
+	// Mailbox is the mailbox the message was in
+	Mailbox string `json:"mailbox"`
+	// MailID is the message UID in the mailbox
+	MailID int `json:"mailId"`
+	// Action is deliver, junk, delete, quarantine or restore
+	Action string `json:"action"`
+	// Reason explains the decision
+	Reason string `json:"reason"`
+	// Target is the mailbox the message was moved to
+	Target string `json:"target,omitempty"`
+	// Error contains details if the message could not be filed
+	Error string `json:"error,omitempty"`
+}
+
+// SenderFilterLogEntryDTO represents one logged filtering decision
+type SenderFilterLogEntryDTO struct {
+	// Time is when the decision was made (RFC3339 format)
+	Time string `json:"time"`
+	// Sender is the sender address
+	Sender string `json:"sender"`
+	// Mailbox is the mailbox the message was in
+	Mailbox string `json:"mailbox"`
+	// MailID is the message UID in the mailbox
+	MailID int `json:"mailId"`
+	// Subject is the message subject
+	Subject string `json:"subject,omitempty"`
+	// Action is deliver, junk, delete, quarantine or restore
+	Action string `json:"action"`
+	// Reason explains the decision
+	Reason string `json:"reason"`
+	// Target is the mailbox the message was moved to
+	Target string `json:"target,omitempty"`
+	// Error contains details if the message could not be filed
+	Error string `json:"error,omitempty"`
+}
+
+// StorageEncryptionStatusDTO represents the state of at-rest storage encryption
+type StorageEncryptionStatusDTO struct {
+	// Enabled indicates the database and filestore are encrypted at rest
+	Enabled bool `json:"enabled"`
+	// Unlocked indicates the passphrase was entered and mail is available
+	Unlocked bool `json:"unlocked"`
+	// EncryptedSizeMB is the size of the encrypted archive in megabytes
+	EncryptedSizeMB float64 `json:"encryptedSizeMB"`
+	// WorkDir is the directory holding the decrypted working copy while unlocked
+	WorkDir string `json:"workDir,omitempty"`
+	// WorkDirOnDisk indicates the decrypted working copy is written to persistent disk
+	WorkDirOnDisk bool `json:"workDirOnDisk"`
+	// Warning explains the risk of an on-disk working copy (empty if in memory)
+	Warning string `json:"warning,omitempty"`
+	// WorkDirOnDiskAllowed indicates the user accepted an on-disk working copy
+	WorkDirOnDiskAllowed bool `json:"workDirOnDiskAllowed"`
+	// MinPassphraseLength is the minimum passphrase length
+	MinPassphraseLength int `json:"minPassphraseLength"`
+}
+
+// EncryptionSettingsDTO represents the key derivation cost for newly encrypted data
+type EncryptionSettingsDTO struct {
+	// KDF is the key derivation function used for new data
+	KDF string `json:"kdf"`
+	// KDFTimeCost is the number of Argon2id passes
+	KDFTimeCost int `json:"kdfTimeCost"`
+	// KDFMemoryMB is the Argon2id memory cost in megabytes
+	KDFMemoryMB int `json:"kdfMemoryMB"`
+	// KDFThreads is the Argon2id parallelism
+	KDFThreads int `json:"kdfThreads"`
+}
+
+// PasswordStoreStatusDTO represents how the fallback password file is protected
+type PasswordStoreStatusDTO struct {
+	// InUse indicates the password is kept in a file because the system keyring is unavailable
+	InUse bool `json:"inUse"`
+	// MasterPassphrase indicates the file is encrypted with a master passphrase
+	MasterPassphrase bool `json:"masterPassphrase"`
+	// Unlocked indicates the master passphrase was entered this session
+	Unlocked bool `json:"unlocked"`
+	// MinPassphraseLength is the minimum master passphrase length
+	MinPassphraseLength int `json:"minPassphraseLength"`
+}
+
+// AppLockSettingsDTO represents the application lock settings and state
+type AppLockSettingsDTO struct {
+	// Enabled locks the UI after the idle period, on startup and on demand
+	Enabled bool `json:"enabled"`
+	// IdleMinutes is the idle period before the app locks (0 = only on demand)
+	IdleMinutes int `json:"idleMinutes"`
+	// HasPassphrase indicates an unlock passphrase is set
+	HasPassphrase bool `json:"hasPassphrase"`
+	// Locked indicates the UI is currently locked
+	Locked bool `json:"locked"`
+	// MinPassphraseLength is the minimum passphrase length
+	MinPassphraseLength int `json:"minPassphraseLength"`
+}
+
+// ExportIdentityDTO represents options for exporting the identity key to a file
+type ExportIdentityDTO struct {
+	// Password is the mail password, required to export the key
+	Password string `json:"password"`
+	// Passphrase encrypts the identity file
+	Passphrase string `json:"passphrase"`
+	// Path is the destination file (a save dialog is shown if empty)
+	Path string `json:"path,omitempty"`
+}
+
+// IdentityMnemonicDTO represents the identity key as a recovery phrase
+type IdentityMnemonicDTO struct {
+	// Address is the mail address of the identity
+	Address string `json:"address"`
+	// Words is the 24-word BIP-39 recovery phrase
+	Words []string `json:"words"`
+}
+
+// ImportIdentityDTO represents options for importing an identity key
+type ImportIdentityDTO struct {
+	// Path is an exported identity file (used if Mnemonic is empty)
+	Path string `json:"path,omitempty"`
+	// Passphrase decrypts the identity file
+	Passphrase string `json:"passphrase,omitempty"`
+	// Mnemonic is a 24-word recovery phrase
+	Mnemonic string `json:"mnemonic,omitempty"`
+	// Replace allows replacing a different identity already in the database
+	Replace bool `json:"replace"`
+}
+
+// RotateIdentityDTO represents options for replacing the identity with a new key
+type RotateIdentityDTO struct {
+	// Password is the mail password, required to rotate the identity
+	Password string `json:"password"`
+	// SendNotice sends a signed "moved" notice from the new address
+	SendNotice bool `json:"sendNotice"`
+	// Recipients receive the notice; all contacts if empty
+	Recipients []string `json:"recipients,omitempty"`
+}
+
+// RotateIdentityResultDTO represents the outcome of an identity rotation
+type RotateIdentityResultDTO struct {
+	// OldAddress is the retired mail address
+	OldAddress string `json:"oldAddress"`
+	// NewAddress is the new mail address
+	NewAddress string `json:"newAddress"`
+	// NoticesSent is the number of moved notices sent from the old address
+	NoticesSent int `json:"noticesSent"`
+	// NoticeErrors lists recipients the notice couldn't be sent to
+	NoticeErrors []string `json:"noticeErrors"`
+	// MessagesMoved is the number of messages imported into the new identity
+	MessagesMoved int `json:"messagesMoved"`
+	// UndeliveredMessages lists queued messages ("recipient: subject") the old
+	// identity didn't deliver in time; they are moved with the other mail but not resent
+	UndeliveredMessages []string `json:"undeliveredMessages"`
+}
+
+// LANAccessSettingsDTO represents the TLS listeners serving IMAP/SMTP to the LAN
+type LANAccessSettingsDTO struct {
+	// Enabled starts the TLS listeners with the service
+	Enabled bool `json:"enabled"`
+	// BindAddress is the IP the listeners bind to (0.0.0.0 or :: for all interfaces)
+	BindAddress string `json:"bindAddress"`
+	// TLSMode is "tls" for implicit TLS or "starttls"
+	TLSMode string `json:"tlsMode"`
+	// SMTPPort is the port of the TLS SMTP listener
+	SMTPPort int `json:"smtpPort"`
+	// IMAPPort is the port of the TLS IMAP listener
+	IMAPPort int `json:"imapPort"`
+	// Allowlist contains the IPs and CIDR ranges allowed to connect (empty = private networks)
+	Allowlist []string `json:"allowlist"`
+	// AdvertiseHost is the host name or IP given to clients (empty = detect)
+	AdvertiseHost string `json:"advertiseHost"`
+	// StrictCertificateChecks tells clients to verify the certificate
+	StrictCertificateChecks bool `json:"strictCertificateChecks"`
+}
+
+// LANAccessStatusDTO represents the endpoints mail clients should use
+type LANAccessStatusDTO struct {
+	// Enabled indicates LAN access is configured
+	Enabled bool `json:"enabled"`
+	// Running indicates the TLS listeners are open
+	Running bool `json:"running"`
+	// IMAPHost is the IMAP host given to clients
+	IMAPHost string `json:"imapHost"`
+	// IMAPPort is the IMAP port given to clients
+	IMAPPort string `json:"imapPort"`
+	// SMTPHost is the SMTP host given to clients
+	SMTPHost string `json:"smtpHost"`
+	// SMTPPort is the SMTP port given to clients
+	SMTPPort string `json:"smtpPort"`
+	// Security is the connection security ("plain", "ssl" or "starttls")
+	Security string `json:"security"`
+	// Fingerprint is the SHA-256 fingerprint of the TLS certificate
+	Fingerprint string `json:"fingerprint"`
+}
+
+// AuditEntryDTO represents one entry of the security audit log
+type AuditEntryDTO struct {
+	// Seq is the sequence number of the entry
+	Seq int64 `json:"seq"`
+	// Time is when the action happened (RFC3339 format)
+	Time string `json:"time"`
+	// Action is the audited action (e.g. change_password, create_backup)
+	Action string `json:"action"`
+	// Outcome is success or failure
+	Outcome string `json:"outcome"`
+	// Reason contains the failure reason
+	Reason string `json:"reason,omitempty"`
+	// Hash is the chain hash of the entry
+	Hash string `json:"hash"`
+}
+
+// AuditVerificationDTO represents the result of checking the audit log hash chain
+type AuditVerificationDTO struct {
+	// Valid indicates every entry links to the previous one
+	Valid bool `json:"valid"`
+	// Entries is the number of entries checked, up to the break if any
+	Entries int `json:"entries"`
+	// BrokenAtLine is the line where the chain breaks (0 if valid)
+	BrokenAtLine int `json:"brokenAtLine"`
+	// Reason describes the break
+	Reason string `json:"reason,omitempty"`
 }
 
 // Helper functions to convert internal types to DTOs
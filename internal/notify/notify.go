@@ -0,0 +1,38 @@
+// Package notify shows native desktop notifications
+package notify
+
+import "errors"
+
+// ErrUnsupported is returned when native notifications are not available on this platform
+var ErrUnsupported = errors.New("native desktop notifications are not supported on this platform")
+
+// Notification is a desktop notification
+type Notification struct {
+	// Title is the notification summary line
+	Title string
+
+	// Body is the notification text
+	Body string
+
+	// Silent shows the notification without sound and with low urgency
+	Silent bool
+
+	// OpenLabel is the label of the "open" action button (e.g., "Open")
+	OpenLabel string
+}
+
+// Notifier shows desktop notifications
+type Notifier interface {
+	// Notify shows a notification
+	Notify(n Notification) error
+
+	// Close releases the notifier resources
+	Close() error
+}
+
+// New creates the native notifier for this platform
+// onOpen is called when the user clicks a notification or its "open" action.
+// Returns ErrUnsupported if the platform has no native implementation
+func New(appName string, onOpen func()) (Notifier, error) {
+	return newPlatformNotifier(appName, onOpen)
+}
@@ -0,0 +1,154 @@
+//go:build linux
+
+package notify
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+
+	"github.com/godbus/dbus/v5"
+)
+
+// freedesktop Notifications D-Bus interface
+// See: https://specifications.freedesktop.org/notification-spec/latest/
+const (
+	dbusDestination = "org.freedesktop.Notifications"
+	dbusPath        = "/org/freedesktop/Notifications"
+	dbusInterface   = "org.freedesktop.Notifications"
+
+	// actionDefault is invoked when the notification body is clicked
+	actionDefault = "default"
+
+	// actionOpen is the explicit "open" button
+	actionOpen = "open"
+
+	// notificationIcon is a standard freedesktop icon name
+	notificationIcon = "mail-message-new"
+)
+
+// dbusNotifier sends notifications over the session bus
+type dbusNotifier struct {
+	appName string
+	onOpen  func()
+	conn    *dbus.Conn
+	signals chan *dbus.Signal
+
+	// ids tracks notifications sent by this process, so actions
+	// invoked on other applications' notifications are ignored
+	ids map[uint32]bool
+	mu  sync.Mutex
+}
+
+// newPlatformNotifier connects to the session bus and listens for notification actions
+func newPlatformNotifier(appName string, onOpen func()) (Notifier, error) {
+	conn, err := dbus.ConnectSessionBus()
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to session bus: %w", err)
+	}
+
+	if err := conn.AddMatchSignal(
+		dbus.WithMatchObjectPath(dbusPath),
+		dbus.WithMatchInterface(dbusInterface),
+	); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to subscribe to notification signals: %w", err)
+	}
+
+	n := &dbusNotifier{
+		appName: appName,
+		onOpen:  onOpen,
+		conn:    conn,
+		signals: make(chan *dbus.Signal, 16),
+		ids:     make(map[uint32]bool),
+	}
+	conn.Signal(n.signals)
+	go n.handleSignals()
+
+	return n, nil
+}
+
+// Notify shows a notification with an "open" action
+func (n *dbusNotifier) Notify(notification Notification) error {
+	openLabel := notification.OpenLabel
+	if openLabel == "" {
+		openLabel = "Open"
+	}
+	actions := []string{actionDefault, openLabel, actionOpen, openLabel}
+
+	hints := map[string]dbus.Variant{
+		"category": dbus.MakeVariant("email.arrived"),
+		"urgency":  dbus.MakeVariant(byte(1)),
+	}
+	if notification.Silent {
+		hints["urgency"] = dbus.MakeVariant(byte(0))
+		hints["suppress-sound"] = dbus.MakeVariant(true)
+	}
+
+	var id uint32
+	obj := n.conn.Object(dbusDestination, dbusPath)
+	call := obj.Call(dbusInterface+".Notify", 0,
+		n.appName,
+		uint32(0), // replaces_id
+		notificationIcon,
+		notification.Title,
+		escapeMarkup(notification.Body),
+		actions,
+		hints,
+		int32(-1), // server default timeout
+	)
+	if call.Err != nil {
+		return fmt.Errorf("failed to send notification: %w", call.Err)
+	}
+	if err := call.Store(&id); err != nil {
+		return fmt.Errorf("failed to read notification id: %w", err)
+	}
+
+	n.mu.Lock()
+	n.ids[id] = true
+	n.mu.Unlock()
+
+	return nil
+}
+
+// Close disconnects from the session bus
+// Closing the connection also closes the signal channel and stops handleSignals
+func (n *dbusNotifier) Close() error {
+	return n.conn.Close()
+}
+
+// handleSignals dispatches ActionInvoked and NotificationClosed signals
+func (n *dbusNotifier) handleSignals() {
+	for signal := range n.signals {
+		if len(signal.Body) < 2 {
+			continue
+		}
+		id, ok := signal.Body[0].(uint32)
+		if !ok {
+			continue
+		}
+
+		switch signal.Name {
+		case dbusInterface + ".ActionInvoked":
+			action, _ := signal.Body[1].(string)
+			n.mu.Lock()
+			ours := n.ids[id]
+			n.mu.Unlock()
+			if ours && (action == actionDefault || action == actionOpen) && n.onOpen != nil {
+				log.Printf("[Notify] Open action invoked on notification %d", id)
+				go n.onOpen()
+			}
+
+		case dbusInterface + ".NotificationClosed":
+			n.mu.Lock()
+			delete(n.ids, id)
+			n.mu.Unlock()
+		}
+	}
+}
+
+// escapeMarkup escapes the body for servers that support the markup capability
+func escapeMarkup(s string) string {
+	return strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;").Replace(s)
+}
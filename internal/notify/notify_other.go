@@ -0,0 +1,9 @@
+//go:build !linux
+
+package notify
+
+// newPlatformNotifier reports that native notifications are not implemented
+// The caller falls back to in-app notifications shown by the frontend
+func newPlatformNotifier(appName string, onOpen func()) (Notifier, error) {
+	return nil, ErrUnsupported
+}
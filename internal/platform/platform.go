@@ -4,9 +4,12 @@
 package platform
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"os"
 	"path/filepath"
 	"runtime"
+	"sync"
 )
 
 // OS represents the operating system type
@@ -140,9 +143,113 @@ func GetConfigPath() string {
 	return filepath.Join(GetDataDir(), "config.toml")
 }
 
+// GetContactsPath returns the path to the contacts file
+func GetContactsPath() string {
+	return filepath.Join(GetDataDir(), "contacts.json")
+}
+
+// GetOutboxPath returns the path to the outgoing message tracking file
+func GetOutboxPath() string {
+	return filepath.Join(GetDataDir(), "outbox.json")
+}
+
+// GetRetentionLogPath returns the path to the retention run log
+func GetRetentionLogPath() string {
+	return filepath.Join(GetDataDir(), "retention.log")
+}
+
+// GetAutoReplyStatePath returns the path to the auto-reply history file
+func GetAutoReplyStatePath() string {
+	return filepath.Join(GetDataDir(), "autoreply.json")
+}
+
+// GetSenderFilterLogPath returns the path to the sender filter decision log
+func GetSenderFilterLogPath() string {
+	return filepath.Join(GetDataDir(), "senderfilter.log")
+}
+
+// GetAuditLogPath returns the path to the hash-chained security audit log
+func GetAuditLogPath() string {
+	return filepath.Join(GetDataDir(), "audit.log")
+}
+
+// GetPasswordAttemptsPath returns the path to the failed password attempts state
+func GetPasswordAttemptsPath() string {
+	return filepath.Join(GetDataDir(), "attempts.json")
+}
+
+// GetHooksLogDir returns the directory holding the per-hook log files
+func GetHooksLogDir() string {
+	return filepath.Join(GetDataDir(), "hooks")
+}
+
+// GetTLSDir returns the directory holding the local CA and listener certificate
+func GetTLSDir() string {
+	return filepath.Join(GetDataDir(), "tls")
+}
+
+// databaseDir overrides the directory holding the database and filestore
+// Set while encrypted storage is unlocked so the service uses the decrypted working copy
+var (
+	databaseDir   string
+	databaseDirMu sync.RWMutex
+)
+
+// SetDatabaseDir points the database and filestore at another directory
+// An empty dir restores the default location in the data directory
+func SetDatabaseDir(dir string) {
+	databaseDirMu.Lock()
+	defer databaseDirMu.Unlock()
+	databaseDir = dir
+}
+
 // GetDatabasePath returns the path to the yggmail database file
 func GetDatabasePath() string {
-	return filepath.Join(GetDataDir(), "yggmail.db")
+	databaseDirMu.RLock()
+	dir := databaseDir
+	databaseDirMu.RUnlock()
+
+	if dir == "" {
+		dir = GetDataDir()
+	}
+	return filepath.Join(dir, "yggmail.db")
+}
+
+// GetStorageVaultPath returns the path to the encrypted database and filestore archive
+func GetStorageVaultPath() string {
+	return filepath.Join(GetDataDir(), "yggmail.vault")
+}
+
+// GetStorageWorkDir returns the directory holding the decrypted database while unlocked
+// See storageWorkDir for where it is placed
+func GetStorageWorkDir() string {
+	dir, _ := storageWorkDir()
+	return dir
+}
+
+// IsStorageWorkDirInMemory returns true if the decrypted working copy is kept in
+// memory-backed storage. Otherwise it is written to the temp directory on disk
+func IsStorageWorkDirInMemory() bool {
+	_, inMemory := storageWorkDir()
+	return inMemory
+}
+
+// storageWorkDir picks the working copy directory; the name is unique per data directory
+// Linux uses the per-user runtime directory or /dev/shm, both tmpfs.
+// Other systems have no user-writable memory filesystem and use the temp directory
+func storageWorkDir() (string, bool) {
+	sum := sha256.Sum256([]byte(GetDataDir()))
+	name := "tyr-" + hex.EncodeToString(sum[:6])
+
+	if IsLinux() {
+		if runtimeDir := os.Getenv("XDG_RUNTIME_DIR"); runtimeDir != "" {
+			return filepath.Join(runtimeDir, name), true
+		}
+		if info, err := os.Stat("/dev/shm"); err == nil && info.IsDir() {
+			return filepath.Join("/dev/shm", name), true
+		}
+	}
+	return filepath.Join(os.TempDir(), name), false
 }
 
 // GetLegacyConfigDirs returns the list of legacy configuration directories
@@ -0,0 +1,98 @@
+// Package qr renders QR codes as PNG images or SVG markup
+package qr
+
+import (
+	"encoding/base64"
+	"fmt"
+	"strings"
+
+	"github.com/skip2/go-qrcode"
+)
+
+const (
+	// DefaultSize is the default PNG image size in pixels
+	DefaultSize = 512
+
+	// MinSize is the smallest allowed PNG image size in pixels
+	MinSize = 128
+
+	// MaxSize is the largest allowed PNG image size in pixels
+	MaxSize = 2048
+)
+
+// Supported output formats
+const (
+	FormatPNG = "png"
+	FormatSVG = "svg"
+)
+
+// PNG renders content as a square PNG image of the given size
+// Size is clamped to [MinSize, MaxSize]; 0 uses DefaultSize
+func PNG(content string, size int) ([]byte, error) {
+	if content == "" {
+		return nil, fmt.Errorf("QR content cannot be empty")
+	}
+
+	switch {
+	case size == 0:
+		size = DefaultSize
+	case size < MinSize:
+		size = MinSize
+	case size > MaxSize:
+		size = MaxSize
+	}
+
+	png, err := qrcode.Encode(content, qrcode.Medium, size)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode QR code: %w", err)
+	}
+	return png, nil
+}
+
+// SVG renders content as scalable SVG markup
+// Each dark module is one unit in the viewBox, including the quiet zone border
+func SVG(content string) (string, error) {
+	if content == "" {
+		return "", fmt.Errorf("QR content cannot be empty")
+	}
+
+	code, err := qrcode.New(content, qrcode.Medium)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode QR code: %w", err)
+	}
+
+	bitmap := code.Bitmap()
+	n := len(bitmap)
+
+	var b strings.Builder
+	fmt.Fprintf(&b, `<svg xmlns="http://www.w3.org/2000/svg" viewBox="0 0 %d %d" shape-rendering="crispEdges">`, n, n)
+	fmt.Fprintf(&b, `<rect width="%d" height="%d" fill="#ffffff"/>`, n, n)
+	b.WriteString(`<path fill="#000000" d="`)
+	for y, row := range bitmap {
+		// Merge horizontal runs of dark modules into single path segments
+		for x := 0; x < len(row); x++ {
+			if !row[x] {
+				continue
+			}
+			start := x
+			for x < len(row) && row[x] {
+				x++
+			}
+			fmt.Fprintf(&b, "M%d %dh%dv1h-%dz", start, y, x-start, x-start)
+		}
+	}
+	b.WriteString(`"/></svg>`)
+
+	return b.String(), nil
+}
+
+// AddressContent returns the QR payload for a mail address
+// Uses a mailto: URI so phone scanners and DeltaChat recognize it as an address
+func AddressContent(address string) string {
+	return "mailto:" + address
+}
+
+// DataURL encodes a PNG image as a data: URL for direct use in <img src>
+func DataURL(png []byte) string {
+	return "data:image/png;base64," + base64.StdEncoding.EncodeToString(png)
+}
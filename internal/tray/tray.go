@@ -32,14 +32,22 @@ type Manager struct {
 	onShowCallback     func()
 	onSettingsCallback func()
 	onQuitCallback     func()
+	onDoNotDisturb     func(enabled bool)
+	onLockCallback     func()
 
 	// Menu items
-	mutex       sync.Mutex
-	initialized bool
-	mStatus     *systray.MenuItem
-	mShow       *systray.MenuItem
-	mSettings   *systray.MenuItem
-	mQuit       *systray.MenuItem
+	mutex         sync.Mutex
+	initialized   bool
+	mStatus       *systray.MenuItem
+	mStorage      *systray.MenuItem
+	mShow         *systray.MenuItem
+	mSettings     *systray.MenuItem
+	mDoNotDisturb *systray.MenuItem
+	mLock         *systray.MenuItem
+	mQuit         *systray.MenuItem
+
+	// storageStatus is the last storage quota status shown in the menu
+	storageStatus core.QuotaStatus
 
 	// Shutdown channel to stop click handler goroutines
 	shutdownCh chan struct{}
@@ -51,6 +59,8 @@ func NewManager(
 	cfg *core.Config,
 	sm *core.ServiceManager,
 	onShow, onSettings, onQuit func(),
+	onDoNotDisturb func(enabled bool),
+	onLock func(),
 ) *Manager {
 	return &Manager{
 		ctx:                ctx,
@@ -59,6 +69,8 @@ func NewManager(
 		onShowCallback:     onShow,
 		onSettingsCallback: onSettings,
 		onQuitCallback:     onQuit,
+		onDoNotDisturb:     onDoNotDisturb,
+		onLockCallback:     onLock,
 		shutdownCh:         make(chan struct{}),
 	}
 }
@@ -102,6 +114,9 @@ func (m *Manager) onTrayReady() {
 	initialStatus := fmt.Sprintf("%s: %s", localizer.Get("systray.service_status"), localizer.Get("dashboard.status.stopped"))
 	m.mStatus = systray.AddMenuItem(initialStatus, "")
 	m.mStatus.Disable()
+	m.mStorage = systray.AddMenuItem("", "")
+	m.mStorage.Disable()
+	m.mStorage.Hide()
 
 	systray.AddSeparator()
 
@@ -110,12 +125,19 @@ func (m *Manager) onTrayReady() {
 
 	systray.AddSeparator()
 
+	m.mDoNotDisturb = systray.AddMenuItemCheckbox(localizer.Get("systray.do_not_disturb"), localizer.Get("systray.do_not_disturb"), m.isDoNotDisturb())
+	m.mLock = systray.AddMenuItem(localizer.Get("systray.lock"), localizer.Get("systray.lock"))
+
+	systray.AddSeparator()
+
 	m.mQuit = systray.AddMenuItem(localizer.Get("app.quit"), localizer.Get("app.quit"))
 
 	// Set up menu item click handlers using channels (fyne.io/systray API)
 	// Each handler runs in its own goroutine and listens to the ClickedCh channel
 	go m.handleShowClicks()
 	go m.handleSettingsClicks()
+	go m.handleDoNotDisturbClicks()
+	go m.handleLockClicks()
 	go m.handleQuitClicks()
 
 	// REMOVED: SetOnTapped double-click implementation
@@ -169,6 +191,59 @@ func (m *Manager) handleSettingsClicks() {
 	}
 }
 
+// handleDoNotDisturbClicks listens for clicks on the Do Not Disturb menu item
+func (m *Manager) handleDoNotDisturbClicks() {
+	defer func() {
+		if r := recover(); r != nil {
+			log.Printf("handleDoNotDisturbClicks: recovered from panic: %v", r)
+		}
+	}()
+
+	for {
+		select {
+		case <-m.shutdownCh:
+			return
+		case <-m.mDoNotDisturb.ClickedCh:
+			enabled := !m.mDoNotDisturb.Checked()
+			log.Printf("Tray: Do not disturb toggled (%v)", enabled)
+			if enabled {
+				m.mDoNotDisturb.Check()
+			} else {
+				m.mDoNotDisturb.Uncheck()
+			}
+			if m.onDoNotDisturb != nil {
+				m.executeCallbackWithTimeout("DoNotDisturb", func() {
+					m.onDoNotDisturb(enabled)
+				})
+			}
+		}
+	}
+}
+
+// isDoNotDisturb reports whether do not disturb is enabled in the config
+func (m *Manager) isDoNotDisturb() bool {
+	return m.config != nil && m.config.IsDoNotDisturb()
+}
+
+// handleLockClicks listens for clicks on the Lock menu item
+func (m *Manager) handleLockClicks() {
+	defer func() {
+		if r := recover(); r != nil {
+			log.Printf("handleLockClicks: recovered from panic: %v", r)
+		}
+	}()
+
+	for {
+		select {
+		case <-m.shutdownCh:
+			return
+		case <-m.mLock.ClickedCh:
+			log.Println("Tray: Lock clicked")
+			m.executeCallbackWithTimeout("Lock", m.onLockCallback)
+		}
+	}
+}
+
 // handleQuitClicks listens for clicks on the Quit menu item
 func (m *Manager) handleQuitClicks() {
 	defer func() {
@@ -250,14 +325,22 @@ func (m *Manager) updateMenuInternal() {
 		return
 	}
 
+	// Do not disturb doesn't depend on the service, keep it in sync first
+	localizer := i18n.GetGlobalLocalizer()
+	m.mDoNotDisturb.SetTitle(localizer.Get("systray.do_not_disturb"))
+	m.mLock.SetTitle(localizer.Get("systray.lock"))
+	if m.isDoNotDisturb() {
+		m.mDoNotDisturb.Check()
+	} else {
+		m.mDoNotDisturb.Uncheck()
+	}
+	m.updateStorageItem()
+
 	if m.serviceManager == nil {
 		log.Println("Service manager not initialized, skipping tray update")
 		return
 	}
 
-	// Get localizer for translations
-	localizer := i18n.GetGlobalLocalizer()
-
 	// Get service status
 	status := m.serviceManager.GetStatus()
 	statusKey := "dashboard.status." + strings.ToLower(status.String())
@@ -295,6 +378,36 @@ func (m *Manager) UpdateStatus() {
 	m.updateMenu()
 }
 
+// UpdateStorageStatus shows the storage quota state in the system tray menu
+// The item is hidden while quota monitoring is disabled
+func (m *Manager) UpdateStorageStatus(status core.QuotaStatus) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	m.storageStatus = status
+	if m.initialized {
+		m.updateStorageItem()
+	}
+}
+
+// updateStorageItem updates the storage menu item from the last quota status
+// MUST be called with mutex already locked
+func (m *Manager) updateStorageItem() {
+	status := m.storageStatus
+	if !status.Enabled || status.BudgetMB <= 0 {
+		m.mStorage.Hide()
+		return
+	}
+
+	localizer := i18n.GetGlobalLocalizer()
+	title := fmt.Sprintf(localizer.Get("systray.storage_usage"), status.Percent, status.BudgetMB)
+	if status.State == core.QuotaStateExceeded {
+		title = fmt.Sprintf(localizer.Get("systray.storage_full"), status.BudgetMB)
+	}
+	m.mStorage.SetTitle(title)
+	m.mStorage.Show()
+}
+
 // SetServiceManager updates the service manager reference
 // This should be called when service manager is initialized after tray creation (e.g., after onboarding)
 func (m *Manager) SetServiceManager(sm *core.ServiceManager) {
@@ -309,6 +422,15 @@ func (m *Manager) SetServiceManager(sm *core.ServiceManager) {
 	}
 }
 
+// SetConfig updates the config reference
+// This should be called when the config is replaced (e.g., after restoring a backup)
+func (m *Manager) SetConfig(cfg *core.Config) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	m.config = cfg
+}
+
 // ShowWindow shows the window from system tray with robust recovery
 // Использует асинхронное выполнение с таймаутом для предотвращения зависания
 func ShowWindow(ctx context.Context) {
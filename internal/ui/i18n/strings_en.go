@@ -520,5 +520,16 @@ func GetEnglishStrings() map[string]string {
 		"systray.minutes":            "minutes",
 		"systray.hours":              "hours",
 		"systray.days":               "days",
+
+		// Notifications
+		"systray.do_not_disturb":    "Do Not Disturb",
+		"systray.lock":              "Lock",
+		"notification.new_mail":     "New mail from %s",
+		"notification.no_subject":   "(no subject)",
+		"notification.open":         "Open",
+
+		// Storage quota
+		"systray.storage_usage":     "Storage: %.0f%% of %d MB",
+		"systray.storage_full":      "Storage full (%d MB budget)",
 	}
 }
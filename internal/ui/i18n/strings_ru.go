@@ -520,5 +520,16 @@ func GetRussianStrings() map[string]string {
 		"systray.minutes":            "минут",
 		"systray.hours":              "часов",
 		"systray.days":               "дней",
+
+		// Notifications
+		"systray.do_not_disturb":    "Не беспокоить",
+		"systray.lock":              "Заблокировать",
+		"notification.new_mail":     "Новое письмо от %s",
+		"notification.no_subject":   "(без темы)",
+		"notification.open":         "Открыть",
+
+		// Storage quota
+		"systray.storage_usage":     "Хранилище: %.0f%% из %d МБ",
+		"systray.storage_full":      "Хранилище заполнено (лимит %d МБ)",
 	}
 }
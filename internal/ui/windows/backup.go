@@ -389,7 +389,7 @@ func (mbs *ModernBackupScreen) performRestore(password string) {
 	}
 
 	// Restore backup
-	restoredConfig, dbData, err := core.RestoreBackup(backupData, password)
+	restoredConfig, dbData, contacts, err := core.RestoreBackup(backupData, password)
 	if err != nil {
 		dialogs.ShowError(mbs.app.GetMainWindow(), loc.Get("backup.restore_failed"), fmt.Sprintf(loc.Get("backup.restore_failed_msg"), err))
 		return
@@ -418,6 +418,9 @@ func (mbs *ModernBackupScreen) performRestore(password string) {
 			return
 		}
 	}
+	if err := core.RestoreContacts(contacts); err != nil {
+		log.Printf("Warning: Failed to restore contacts: %v", err)
+	}
 
 	// Clear UI
 	mbs.restorePasswordEntry.SetText("")
@@ -949,7 +949,7 @@ func (mos *ModernOnboardingScreen) finishRestore() {
 	}
 
 	// Restore backup
-	restoredConfig, dbData, err := core.RestoreBackup(backupData, password)
+	restoredConfig, dbData, contacts, err := core.RestoreBackup(backupData, password)
 	if err != nil {
 		dialogs.ShowError(mos.app.GetMainWindow(), loc.Get("backup.restore_failed"), fmt.Sprintf(loc.Get("backup.restore_failed_msg"), err))
 		return
@@ -984,6 +984,9 @@ func (mos *ModernOnboardingScreen) finishRestore() {
 			return
 		}
 	}
+	if err := core.RestoreContacts(contacts); err != nil {
+		log.Printf("Warning: Failed to restore contacts: %v", err)
+	}
 
 	log.Println("Backup restored successfully during onboarding")
 
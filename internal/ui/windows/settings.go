@@ -879,7 +879,7 @@ func (mss *ModernSettingsScreen) performRestore(selectedFile, password string, p
 	}
 
 	// Restore backup
-	restoredConfig, dbData, err := core.RestoreBackup(backupData, password)
+	restoredConfig, dbData, contacts, err := core.RestoreBackup(backupData, password)
 	if err != nil {
 		dialogs.ShowError(mss.app.GetMainWindow(), loc.Get("backup.restore_failed"), fmt.Sprintf(loc.Get("backup.restore_failed_msg"), err))
 		return
@@ -908,6 +908,9 @@ func (mss *ModernSettingsScreen) performRestore(selectedFile, password string, p
 			return
 		}
 	}
+	if err := core.RestoreContacts(contacts); err != nil {
+		log.Printf("Warning: Failed to restore contacts: %v", err)
+	}
 
 	// Clear UI
 	passwordEntry.SetText("")
@@ -95,6 +95,9 @@ type MailEvent struct {
 	MailID int
 	// ErrorMessage contains error details if Type is "error"
 	ErrorMessage string
+	// MessageID is the Message-ID of a sent or failed message, if reported
+	// The current mobile callbacks only report recipient and subject
+	MessageID string
 }
 
 // ConnectionEvent represents a connection status change
@@ -42,3 +42,135 @@ type MessageSizeLimitCheckResultDTO = models.MessageSizeLimitCheckResultDTO
 
 // StorageStatsDTO contains information about storage usage
 type StorageStatsDTO = models.StorageStatsDTO
+
+// ComposeMailDTO contains a message composed in the frontend for sending
+type ComposeMailDTO = models.ComposeMailDTO
+
+// MailboxDTO contains summary information about a mailbox
+type MailboxDTO = models.MailboxDTO
+
+// MessageHeaderDTO contains the envelope information of a stored message
+type MessageHeaderDTO = models.MessageHeaderDTO
+
+// MessagePageDTO contains one page of message headers, newest first
+type MessagePageDTO = models.MessagePageDTO
+
+// MessagePartDTO describes a node in the MIME tree of a message
+type MessagePartDTO = models.MessagePartDTO
+
+// MessageDTO contains a fully fetched message
+type MessageDTO = models.MessageDTO
+
+// ContactDTO represents an address book entry
+type ContactDTO = models.ContactDTO
+
+// ContactImportResultDTO contains the outcome of a vCard import
+type ContactImportResultDTO = models.ContactImportResultDTO
+
+// QRCodeDTO contains a rendered QR code
+type QRCodeDTO = models.QRCodeDTO
+
+// NotificationRuleDTO represents a new-mail notification rule
+type NotificationRuleDTO = models.NotificationRuleDTO
+
+// NotificationSettingsDTO contains new-mail notification preferences
+type NotificationSettingsDTO = models.NotificationSettingsDTO
+
+// DeliveryTransitionDTO represents a state change of an outgoing message
+type DeliveryTransitionDTO = models.DeliveryTransitionDTO
+
+// OutgoingMessageDTO represents the delivery state of an outgoing message
+type OutgoingMessageDTO = models.OutgoingMessageDTO
+
+// RecipientDeliveryDTO represents the delivery state of one recipient
+type RecipientDeliveryDTO = models.RecipientDeliveryDTO
+
+// RetentionPolicyDTO represents a per-mailbox retention policy
+type RetentionPolicyDTO = models.RetentionPolicyDTO
+
+// RetentionSettingsDTO represents retention policies and their schedule
+type RetentionSettingsDTO = models.RetentionSettingsDTO
+
+// RetentionReportDTO represents the outcome of a retention run or preview
+type RetentionReportDTO = models.RetentionReportDTO
+
+// QuotaSettingsDTO represents the storage budget configuration
+type QuotaSettingsDTO = models.QuotaSettingsDTO
+
+// QuotaStatusDTO represents storage usage against the budget
+type QuotaStatusDTO = models.QuotaStatusDTO
+
+// StorageUsageDTO represents a breakdown of storage usage
+type StorageUsageDTO = models.StorageUsageDTO
+
+// ExportOptionsDTO represents what to export and where
+type ExportOptionsDTO = models.ExportOptionsDTO
+
+// ExportResultDTO represents the outcome of an export
+type ExportResultDTO = models.ExportResultDTO
+
+// ImportOptionsDTO represents what to import and where
+type ImportOptionsDTO = models.ImportOptionsDTO
+
+// ImportResultDTO represents the outcome of an import
+type ImportResultDTO = models.ImportResultDTO
+
+// AutoReplySettingsDTO represents the vacation auto-responder configuration
+type AutoReplySettingsDTO = models.AutoReplySettingsDTO
+
+// HookSettingsDTO represents the hook configuration
+type HookSettingsDTO = models.HookSettingsDTO
+
+// HookRunResultDTO represents the outcome of one hook run
+type HookRunResultDTO = models.HookRunResultDTO
+
+// SenderFilterSettingsDTO represents sender filtering keyed on the sender's public key
+type SenderFilterSettingsDTO = models.SenderFilterSettingsDTO
+
+// SenderActionDTO identifies a sender to block or allow
+type SenderActionDTO = models.SenderActionDTO
+
+// SenderFilterLogEntryDTO represents one logged filtering decision
+type SenderFilterLogEntryDTO = models.SenderFilterLogEntryDTO
+
+// StorageEncryptionStatusDTO represents the state of at-rest storage encryption
+type StorageEncryptionStatusDTO = models.StorageEncryptionStatusDTO
+
+// EncryptionSettingsDTO represents the key derivation cost for newly encrypted data
+type EncryptionSettingsDTO = models.EncryptionSettingsDTO
+
+// PasswordStoreStatusDTO represents how the fallback password file is protected
+type PasswordStoreStatusDTO = models.PasswordStoreStatusDTO
+
+// AppLockSettingsDTO represents the application lock settings and state
+type AppLockSettingsDTO = models.AppLockSettingsDTO
+
+// ExportIdentityDTO represents options for exporting the identity key to a file
+type ExportIdentityDTO = models.ExportIdentityDTO
+
+// IdentityMnemonicDTO represents the identity key as a recovery phrase
+type IdentityMnemonicDTO = models.IdentityMnemonicDTO
+
+// ImportIdentityDTO represents options for importing an identity key
+type ImportIdentityDTO = models.ImportIdentityDTO
+
+// RotateIdentityDTO represents options for replacing the identity with a new key
+type RotateIdentityDTO = models.RotateIdentityDTO
+
+// RotateIdentityResultDTO represents the outcome of an identity rotation
+type RotateIdentityResultDTO = models.RotateIdentityResultDTO
+
+// LANAccessSettingsDTO represents the TLS listeners serving IMAP/SMTP to the LAN
+type LANAccessSettingsDTO = models.LANAccessSettingsDTO
+
+// LANAccessStatusDTO represents the endpoints mail clients should use
+type LANAccessStatusDTO = models.LANAccessStatusDTO
+
+// AuditEntryDTO represents one entry of the security audit log
+type AuditEntryDTO = models.AuditEntryDTO
+
+// AuditVerificationDTO represents the result of checking the audit log hash chain
+type AuditVerificationDTO = models.AuditVerificationDTO
+
+// ProxyTestResultDTO represents the result of a SOCKS5 proxy connectivity test
+type ProxyTestResultDTO = models.ProxyTestResultDTO